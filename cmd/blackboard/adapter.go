@@ -0,0 +1,464 @@
+package blackboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	kindBlackboard = "blackboard"
+	kindStickie    = "stickie"
+	blackboardFile = "blackboard.yaml"
+	stickieSuffix  = ".stickie.yaml"
+	// fileKeyPrefix keys stickies without an id by their file name.
+	fileKeyPrefix = "file:"
+)
+
+// stickieValue is the payload of a stickie record: the file content and the
+// normalized material it is compared (and merged) by.
+type stickieValue struct {
+	y   stickieYAML
+	mat stickieHashMaterial
+}
+
+// blackboardAdapter syncs one blackboard with a folder: blackboard.yaml (with
+// the stickie schemas) keyed by the blackboard id, and one *.stickie.yaml per
+// stickie keyed by stickie id. Files without an id are keyed by file name and
+// create new stickies. Timestamps and edit counts are not compared.
+type blackboardAdapter struct {
+	db              *pgxpool.Pool
+	id              string
+	includeArchived bool
+	// clearIDs exports stickies without ids (keyed by file name on both sides).
+	clearIDs bool
+	// importing makes Apply insert rows under the ids of the files.
+	importing bool
+	// dir, when set, is the folder whose files Apply rewrites with the id and
+	// version each write returned, so a later push of them still matches.
+	dir string
+
+	// Last loaded records and the stickies Apply left alone on version
+	// conflicts; the sync commands derive the merge base from them.
+	remote, local []syncfs.Record
+	conflicts     map[string]bool
+}
+
+func (a *blackboardAdapter) Kind() string { return kindBlackboard }
+
+func (a *blackboardAdapter) stickieKey(id, file string) string {
+	if a.clearIDs || strings.TrimSpace(id) == "" {
+		return fileKeyPrefix + file
+	}
+	return strings.TrimSpace(id)
+}
+
+// blackboardRecord compares blackboard.yaml without its timestamps, with prose
+// wrapped like the exporter does.
+func blackboardRecord(key string, y blackboardYAML) (syncfs.Record, error) {
+	b, err := yaml.Marshal(y)
+	if err != nil {
+		return syncfs.Record{}, err
+	}
+	c := y
+	c.Created, c.Updated = nil, nil
+	c.Background = wrapLiteral(c.Background)
+	c.Guidelines = wrapLiteral(c.Guidelines)
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return syncfs.Record{}, err
+	}
+	return syncfs.Record{Kind: kindBlackboard, Key: key, Files: []syncfs.File{{Name: blackboardFile, Data: b}}, Value: y, Content: content}, nil
+}
+
+func wrapLiteral(p *LiteralString) *LiteralString {
+	if p == nil {
+		return nil
+	}
+	v := LiteralString(syncfs.WrapAt(string(*p), 80))
+	return &v
+}
+
+func stickieRecord(key, file string, y stickieYAML, mat stickieHashMaterial) (syncfs.Record, error) {
+	b, err := yaml.Marshal(y)
+	if err != nil {
+		return syncfs.Record{}, err
+	}
+	content, err := yaml.Marshal(mat)
+	if err != nil {
+		return syncfs.Record{}, err
+	}
+	return syncfs.Record{Kind: kindStickie, Key: key, Files: []syncfs.File{{Name: file, Data: b}}, Value: stickieValue{y: y, mat: mat}, Content: content}, nil
+}
+
+// LoadRemote renders the blackboard and its stickies; a missing blackboard has no records.
+func (a *blackboardAdapter) LoadRemote(ctx context.Context) ([]syncfs.Record, error) {
+	a.remote = nil
+	b, err := pgdao.GetBlackboardByID(ctx, a.db, a.id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	by, err := blackboardToYAML(ctx, a.db, b)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blackboardRecord(a.id, by)
+	if err != nil {
+		return nil, err
+	}
+	out := []syncfs.Record{r}
+	stickies, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Stickie, error) {
+		return pgdao.ListStickies(ctx, a.db, b.ID, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stickies {
+		if s.Archived && !a.includeArchived {
+			continue
+		}
+		sy := stickieToYAML(s)
+		file := stickieFileName(s)
+		if a.clearIDs {
+			sy.ID = ""
+		}
+		r, err := stickieRecord(a.stickieKey(sy.ID, file), file, sy, stickieMaterialDB(s))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	a.remote = out
+	return out, nil
+}
+
+// LoadLocal parses blackboard.yaml (keyed by the adapter's blackboard id
+// whatever id it declares) and the stickie files of dir.
+func (a *blackboardAdapter) LoadLocal(dir string) ([]syncfs.Record, error) {
+	a.local = nil
+	var out []syncfs.Record
+	data, err := os.ReadFile(filepath.Join(dir, blackboardFile))
+	switch {
+	case err == nil:
+		var y blackboardYAML
+		if err := yaml.Unmarshal(data, &y); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", blackboardFile, err)
+		}
+		r, err := blackboardRecord(a.id, y)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+	seen := map[string]string{}
+	err = syncfs.EachFile(dir, stickieSuffix, func(name string, data []byte) error {
+		var y stickieYAML
+		if err := yaml.Unmarshal(data, &y); err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		if y.Archived && !a.includeArchived {
+			return nil
+		}
+		key := a.stickieKey(y.ID, name)
+		if prev, dup := seen[key]; dup {
+			return fmt.Errorf("stickie %s declared twice (%s and %s)", key, prev, name)
+		}
+		seen[key] = name
+		r, err := stickieRecord(key, name, y, stickieMaterialYAML(y))
+		if err != nil {
+			return err
+		}
+		out = append(out, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.local = out
+	return out, nil
+}
+
+func (a *blackboardAdapter) Apply(ctx context.Context, r syncfs.Record) error {
+	switch v := r.Value.(type) {
+	case blackboardYAML:
+		return a.applyBlackboard(ctx, v)
+	case stickieValue:
+		return a.applyStickie(ctx, r.Primary(), v.y)
+	}
+	return fmt.Errorf("%s %q: unexpected record payload", r.Kind, r.Key)
+}
+
+// applyBlackboard updates (or, importing, inserts) the blackboard and makes its
+// schemas match stickie_schemas. Updates only apply while the board is at the
+// updated timestamp recorded in the file.
+func (a *blackboardAdapter) applyBlackboard(ctx context.Context, y blackboardYAML) error {
+	if id := strings.TrimSpace(y.ID); id != "" && id != a.id {
+		return fmt.Errorf("%s describes blackboard %s, not %s", blackboardFile, id, a.id)
+	}
+	raw, err := schemasFromYAML(y.StickieSchemas)
+	if err != nil {
+		return err
+	}
+	if a.importing {
+		y.ID = a.id
+		if err := insertBlackboardWithID(ctx, a.db, y); err != nil {
+			return err
+		}
+	} else {
+		b := pgdao.Blackboard{
+			ID: a.id, RoleName: y.Role,
+			ConversationID: syncfs.NullString(y.Conversation),
+			ProjectName:    syncfs.NullString(y.Project),
+			TaskID:         syncfs.NullString(y.TaskID),
+			Background:     syncfs.NullLiteral(y.Background),
+			Guidelines:     syncfs.NullLiteral(y.Guidelines),
+			Lifecycle:      syncfs.NullString(y.Lifecycle),
+		}
+		if u := syncfs.Deref(y.Updated); u != "" {
+			if b.IfMatch, err = pgdao.ParseIfMatch(u); err != nil {
+				return fmt.Errorf("%s: updated: %w", blackboardFile, err)
+			}
+		}
+		if err := pgdao.UpsertBlackboard(ctx, a.db, &b); err != nil {
+			if vc, ok := pgdao.AsVersionConflict(err); ok {
+				return fmt.Errorf("%w: file updated=%s, DB %s; sync id->folder or use --merge", syncfs.ErrConflict, syncfs.Deref(y.Updated), vc.Current)
+			}
+			return err
+		}
+		if err := a.blackboardPushed(y, b); err != nil {
+			return err
+		}
+	}
+	return a.putSchemas(ctx, raw)
+}
+
+// blackboardPushed rewrites blackboard.yaml with the updated timestamp b was
+// stored with.
+func (a *blackboardAdapter) blackboardPushed(y blackboardYAML, b pgdao.Blackboard) error {
+	if a.dir == "" {
+		return nil
+	}
+	y.ID = a.id
+	if b.Updated.Valid {
+		v := b.Updated.Time.Format(time.RFC3339Nano)
+		y.Updated = &v
+	}
+	return syncfs.WriteYAML(filepath.Join(a.dir, blackboardFile), y)
+}
+
+// putSchemas upserts the given schemas and drops the board's other ones.
+func (a *blackboardAdapter) putSchemas(ctx context.Context, raw map[string][]byte) error {
+	labels := make([]string, 0, len(raw))
+	for label := range raw {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		sc := &pgdao.BlackboardSchema{BlackboardID: a.id, Label: label, Schema: raw[label]}
+		if err := pgdao.UpsertBlackboardSchema(ctx, a.db, sc); err != nil {
+			return fmt.Errorf("schema %q: %w", label, err)
+		}
+	}
+	if a.importing {
+		return nil
+	}
+	current, err := pgdao.ListBlackboardSchemas(ctx, a.db, a.id)
+	if err != nil {
+		return err
+	}
+	for _, sc := range current {
+		if _, keep := raw[sc.Label]; keep {
+			continue
+		}
+		if _, err := pgdao.DeleteBlackboardSchema(ctx, a.db, a.id, sc.Label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyStickie creates a stickie from a file without id, or updates the one it
+// names. Security rule: a named stickie must already exist on this blackboard.
+// The file's edit_count guards against overwriting DB edits made since export.
+func (a *blackboardAdapter) applyStickie(ctx context.Context, file string, y stickieYAML) error {
+	if a.importing {
+		return insertStickieWithID(ctx, a.db, a.id, y)
+	}
+	s := stickieFromYAMLForUpsert(y, a.id)
+	id := strings.TrimSpace(y.ID)
+	if id != "" {
+		cur, err := pgdao.GetStickieByID(ctx, a.db, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("stickie %s does not exist", id)
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(cur.BlackboardID) != a.id {
+			return fmt.Errorf("security: stickie %s does not belong to blackboard %s", id, a.id)
+		}
+		s.ID = id
+		if y.EditCount > 0 {
+			s.IfMatch.EditCount = &y.EditCount
+		}
+	}
+	if err := pgdao.UpsertStickie(ctx, a.db, &s); err != nil {
+		if vc, ok := pgdao.AsVersionConflict(err); ok {
+			if a.conflicts == nil {
+				a.conflicts = map[string]bool{}
+			}
+			a.conflicts[id] = true
+			return fmt.Errorf("%w: file edit_count=%d, DB %s; sync id->folder or use --merge", syncfs.ErrConflict, y.EditCount, vc.Current)
+		}
+		return err
+	}
+	if id == "" {
+		fmt.Fprintf(os.Stderr, "created stickie id=%s from %s\n", s.ID, file)
+	}
+	return a.stickiePushed(file, y, s)
+}
+
+// stickiePushed rewrites a pushed stickie file with the id, edit_count and
+// updated timestamp the write returned; the loaded record follows so the
+// merge base is recorded under the id.
+func (a *blackboardAdapter) stickiePushed(file string, y stickieYAML, s pgdao.Stickie) error {
+	if a.dir == "" {
+		return nil
+	}
+	if !a.clearIDs {
+		y.ID = s.ID
+	}
+	y.EditCount = s.EditCount
+	if s.Created.Valid && y.Created == nil {
+		v := s.Created.Time.Format(time.RFC3339Nano)
+		y.Created = &v
+	}
+	if s.Updated.Valid {
+		v := s.Updated.Time.Format(time.RFC3339Nano)
+		y.Updated = &v
+	}
+	if err := syncfs.WriteYAML(filepath.Join(a.dir, file), y); err != nil {
+		return err
+	}
+	for i, r := range a.local {
+		if v, ok := r.Value.(stickieValue); ok && r.Primary() == file {
+			a.local[i].Value = stickieValue{y: y, mat: v.mat}
+		}
+	}
+	return nil
+}
+
+// Delete removes a stickie; the blackboard itself is only deleted by `blackboard delete`.
+func (a *blackboardAdapter) Delete(ctx context.Context, r syncfs.Record) error {
+	v, ok := r.Value.(stickieValue)
+	if !ok {
+		return fmt.Errorf("%s is missing; refusing to delete blackboard %s (use rbc blackboard delete)", blackboardFile, a.id)
+	}
+	_, err := pgdao.DeleteStickie(ctx, a.db, v.y.ID)
+	return err
+}
+
+// schemaSet returns the schemas local stickies must satisfy: those of the local
+// blackboard.yaml when present (it is applied first), else the board's.
+func (a *blackboardAdapter) schemaSet(ctx context.Context, local []syncfs.Record) (*stickieschema.Set, error) {
+	for _, r := range local {
+		y, ok := r.Value.(blackboardYAML)
+		if !ok {
+			continue
+		}
+		raw, err := schemasFromYAML(y.StickieSchemas)
+		if err != nil {
+			return nil, err
+		}
+		set, err := stickieschema.Compile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", blackboardFile, err)
+		}
+		return set, nil
+	}
+	return stickieschema.Load(ctx, a.db, a.id)
+}
+
+// checkStickieRecords validates every local stickie payload and reports all
+// failures at once, so that nothing is written for an invalid folder.
+func checkStickieRecords(set *stickieschema.Set, local []syncfs.Record) error {
+	var errs []error
+	for _, r := range local {
+		if v, ok := r.Value.(stickieValue); ok {
+			if err := checkStickieYAML(set, r.Primary(), v.y); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// blackboardToYAML renders a blackboard and its schemas as blackboard.yaml.
+func blackboardToYAML(ctx context.Context, db *pgxpool.Pool, b *pgdao.Blackboard) (blackboardYAML, error) {
+	by := blackboardYAML{
+		ID:           b.ID,
+		Role:         b.RoleName,
+		Conversation: syncfs.OptString(b.ConversationID.String, b.ConversationID.Valid),
+		Project:      syncfs.OptString(b.ProjectName.String, b.ProjectName.Valid),
+		TaskID:       syncfs.OptString(b.TaskID.String, b.TaskID.Valid),
+		Background:   syncfs.OptLiteral(syncfs.WrapAt(b.Background.String, 80), b.Background.Valid),
+		Guidelines:   syncfs.OptLiteral(syncfs.WrapAt(b.Guidelines.String, 80), b.Guidelines.Valid),
+		Lifecycle:    syncfs.OptString(b.Lifecycle.String, b.Lifecycle.Valid),
+	}
+	if b.Created.Valid {
+		v := b.Created.Time.Format(time.RFC3339Nano)
+		by.Created = &v
+	}
+	if b.Updated.Valid {
+		v := b.Updated.Time.Format(time.RFC3339Nano)
+		by.Updated = &v
+	}
+	schemas, err := pgdao.ListBlackboardSchemas(ctx, db, b.ID)
+	if err != nil {
+		return by, err
+	}
+	for _, sc := range schemas {
+		var v any
+		if err := json.Unmarshal(sc.Schema, &v); err != nil {
+			return by, fmt.Errorf("schema for label %q: %w", sc.Label, err)
+		}
+		if by.StickieSchemas == nil {
+			by.StickieSchemas = map[string]any{}
+		}
+		by.StickieSchemas[sc.Label] = v
+	}
+	return by, nil
+}
+
+// syncedStickies maps the stickie records with an id to their merge base entry.
+func syncedStickies(records []syncfs.Record, skip map[string]bool) map[string]syncStateEntry {
+	out := map[string]syncStateEntry{}
+	for _, r := range records {
+		v, ok := r.Value.(stickieValue)
+		id := strings.TrimSpace(v.y.ID)
+		if !ok || id == "" || skip[id] {
+			continue
+		}
+		out[id] = newStateEntry(r.Primary(), v.mat)
+	}
+	return out
+}
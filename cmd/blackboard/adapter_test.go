@@ -0,0 +1,187 @@
+package blackboard
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+)
+
+func remoteStickie(t *testing.T, s pgdao.Stickie) syncfs.Record {
+	t.Helper()
+	r, err := stickieRecord(s.ID, stickieFileName(s), stickieToYAML(s), stickieMaterialDB(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func localStickie(t *testing.T, y stickieYAML) syncfs.Record {
+	t.Helper()
+	r, err := stickieRecord(y.ID, y.ID+stickieSuffix, y, stickieMaterialYAML(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func changedFields(r, l syncfs.Record) []string {
+	var out []string
+	for _, f := range syncfs.RecordFields(r, l) {
+		out = append(out, f.Name)
+	}
+	return out
+}
+
+func TestStickieRecord_NoteWrappingNormalized(t *testing.T) {
+	long := "This is a very long note that should be wrapped by the exporter at eighty characters per line, ensuring equality in diff when comparing remote DB and local YAML."
+	ls := LiteralString(syncfs.WrapAt(long, 80))
+	r := remoteStickie(t, pgdao.Stickie{ID: "s1", Note: sql.NullString{Valid: true, String: long}})
+	l := localStickie(t, stickieYAML{ID: "s1", Note: &ls})
+	if !r.Equal(l) {
+		t.Fatalf("expected no diff on note after wrapping; got %v", changedFields(r, l))
+	}
+}
+
+func TestStickieRecord_LabelsOrderIgnored(t *testing.T) {
+	r := remoteStickie(t, pgdao.Stickie{ID: "s1", Labels: []string{"b", "a"}})
+	l := localStickie(t, stickieYAML{ID: "s1", Labels: []string{"a", "b"}})
+	if !r.Equal(l) {
+		t.Fatalf("expected labels to be equal as sets; got %v", changedFields(r, l))
+	}
+}
+
+func TestStickieRecord_BookkeepingIgnored(t *testing.T) {
+	r := remoteStickie(t, pgdao.Stickie{ID: "s1", EditCount: 4})
+	updated := "2026-01-02T03:04:05Z"
+	l := localStickie(t, stickieYAML{ID: "s1", EditCount: 2, Updated: &updated})
+	if !r.Equal(l) {
+		t.Fatalf("expected edit_count and timestamps to be ignored; got %v", changedFields(r, l))
+	}
+}
+
+func TestStickieRecord_DetectsNameChange(t *testing.T) {
+	name := "Bar"
+	r := remoteStickie(t, pgdao.Stickie{ID: "s1", Name: sql.NullString{Valid: true, String: "Foo"}})
+	l := localStickie(t, stickieYAML{ID: "s1", Name: &name})
+	if got := changedFields(r, l); len(got) != 1 || got[0] != "name" {
+		t.Fatalf("expected name to be reported changed; got %v", got)
+	}
+}
+
+func TestBlackboardRecord_IgnoresTimestampsAndWrapping(t *testing.T) {
+	long := "Background prose that is long enough to be wrapped by the exporter at eighty characters per line."
+	updated := "2026-01-02T03:04:05Z"
+	raw := LiteralString(long)
+	wrapped := LiteralString(syncfs.WrapAt(long, 80))
+	r, err := blackboardRecord("b1", blackboardYAML{ID: "b1", Role: "dev", Background: &wrapped, Updated: &updated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := blackboardRecord("b1", blackboardYAML{ID: "b1", Role: "dev", Background: &raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Equal(l) {
+		t.Fatalf("expected equal blackboards; got %v", changedFields(r, l))
+	}
+}
+
+func TestLoadLocal_KeysAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		blackboardFile:              "id: other\nrole: dev\n",
+		"s1.stickie.yaml":           "id: s1\nnote: one\narchived: false\n",
+		"about-draft.stickie.yaml":  "note: draft\narchived: false\n",
+		"old.stickie.yaml":          "id: s2\narchived: true\n",
+		"notes.txt":                 "ignored\n",
+		"about-other.conflict.yaml": "id: s1\n",
+		syncStateFile:               "blackboard_id: b1\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &blackboardAdapter{id: "b1"}
+	rs, err := a.LoadLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, r := range rs {
+		got[r.Key] = r.Kind
+	}
+	want := map[string]string{"b1": kindBlackboard, "s1": kindStickie, fileKeyPrefix + "about-draft.stickie.yaml": kindStickie}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+	for k, kind := range want {
+		if got[k] != kind {
+			t.Fatalf("expected %s %q; got %v", kind, k, got)
+		}
+	}
+
+	a.includeArchived = true
+	if rs, err = a.LoadLocal(dir); err != nil || len(rs) != 4 {
+		t.Fatalf("expected the archived stickie with --include-archived; got %d %v", len(rs), err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dup.stickie.yaml"), []byte("id: s1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.LoadLocal(dir); err == nil {
+		t.Fatalf("expected duplicate stickie ids to be rejected")
+	}
+}
+
+// Each push rewrites the file with the version it stored, so editing and
+// pushing the same file again is guarded by the new edit_count.
+func TestStickiePushed_EditPushEditPush(t *testing.T) {
+	dir := t.TempDir()
+	file := "draft.stickie.yaml"
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte("note: first\narchived: false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := &blackboardAdapter{id: "b1", dir: dir}
+	load := func() stickieYAML {
+		t.Helper()
+		rs, err := a.LoadLocal(dir)
+		if err != nil || len(rs) != 1 {
+			t.Fatalf("load: %d records, %v", len(rs), err)
+		}
+		return rs[0].Value.(stickieValue).y
+	}
+	edit := func(note string) {
+		t.Helper()
+		y := load()
+		v := LiteralString(note)
+		y.Note = &v
+		if err := syncfs.WriteYAML(path, y); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, note := range []string{"second", "third"} {
+		edit(note)
+		y := load()
+		// the stored row after the push at edit_count 1+i
+		s := pgdao.Stickie{ID: "s1", EditCount: 2 + i, Updated: sql.NullTime{Time: updated.Add(time.Duration(i) * time.Minute), Valid: true}}
+		if i > 0 && y.EditCount != 1+i {
+			t.Fatalf("push %d would send if-match %d, want %d", i+1, y.EditCount, 1+i)
+		}
+		if err := a.stickiePushed(file, y, s); err != nil {
+			t.Fatal(err)
+		}
+		got := load()
+		if got.ID != "s1" || got.EditCount != s.EditCount || syncfs.DerefLiteral(got.Note) != note {
+			t.Fatalf("after push %d: id=%q edit_count=%d note=%q", i+1, got.ID, got.EditCount, syncfs.DerefLiteral(got.Note))
+		}
+		if e := syncedStickies(a.local, nil); e["s1"].File != file {
+			t.Fatalf("expected a merge base under the new id; got %v", e)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package blackboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/lifecycle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBBGCRole       string
+	flagBBGCAction     string
+	flagBBGCSnapshot   bool
+	flagBBGCSchema     string
+	flagBBGCRollup     bool
+	flagBBGCRollupInto string
+	flagBBGCLimit      int
+	flagBBGCDryRun     bool
+	flagBBGCJSON       bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Archive or delete blackboards whose lifecycle has expired",
+	Long: "Apply blackboard lifecycles. A board expires one period (daily, weekly, monthly, quarterly, yearly) after its last update; permanent boards never expire.\n" +
+		"Expired boards are archived (default) or deleted with --action delete. --snapshot backs up blackboards and stickies first; --rollup replaces the board's stickies with one summary stickie (with delete, the summary goes to --rollup-into).",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opt := lifecycle.Options{
+			Role:       flagBBGCRole,
+			Action:     flagBBGCAction,
+			Rollup:     flagBBGCRollup,
+			RollupInto: flagBBGCRollupInto,
+			Limit:      flagBBGCLimit,
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		plan, err := lifecycle.FindExpired(ctx, db, opt)
+		if err != nil {
+			return err
+		}
+		if !flagBBGCJSON {
+			for _, b := range plan.Boards {
+				fmt.Fprintf(os.Stderr, "%s %s (%s, role=%s, expired %s, %d stickies)\n", b.Action, b.ID, b.Lifecycle, b.Role, b.ExpiresAt.Format(time.RFC3339), b.Stickies)
+			}
+		}
+		if flagBBGCDryRun || len(plan.Boards) == 0 {
+			if flagBBGCJSON {
+				return json.NewEncoder(os.Stdout).Encode(map[string]any{"applied": false, "plan": plan})
+			}
+			fmt.Fprintf(os.Stderr, "expired blackboards: %d (nothing changed)\n", len(plan.Boards))
+			return nil
+		}
+		var backupID string
+		if flagBBGCSnapshot {
+			bdb, err := pgdao.OpenBackup(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			backupID, err = lifecycle.Snapshot(ctx, bdb, flagBBGCSchema, plan)
+			bdb.Close()
+			if err != nil {
+				return err
+			}
+			if !flagBBGCJSON {
+				fmt.Fprintf(os.Stderr, "snapshot %s created\n", backupID)
+			}
+		}
+		done, err := lifecycle.Apply(ctx, db, plan, opt)
+		if flagBBGCJSON {
+			out := map[string]any{"applied": true, "action": plan.Action, "boards": done}
+			if backupID != "" {
+				out["backup_id"] = backupID
+			}
+			if err != nil {
+				out["error"] = err.Error()
+			}
+			if e := json.NewEncoder(os.Stdout).Encode(out); e != nil {
+				return e
+			}
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("gc stopped after %d of %d boards: %w", len(done), len(plan.Boards), err)
+		}
+		fmt.Fprintf(os.Stderr, "blackboard gc: %d boards %sd\n", len(done), plan.Action)
+		return nil
+	},
+}
+
+func init() {
+	BlackboardCmd.AddCommand(gcCmd)
+	gcCmd.Flags().StringVar(&flagBBGCRole, "role", "", "Only collect blackboards of this role (default all roles)")
+	gcCmd.Flags().StringVar(&flagBBGCAction, "action", lifecycle.ActionArchive, "What to do with expired boards: archive|delete")
+	gcCmd.Flags().BoolVar(&flagBBGCSnapshot, "snapshot", false, "Snapshot blackboards and stickies before changing anything")
+	gcCmd.Flags().StringVar(&flagBBGCSchema, "schema", "backup", "Backup schema for --snapshot")
+	gcCmd.Flags().BoolVar(&flagBBGCRollup, "rollup", false, "Roll each board's stickies into a single summary stickie")
+	gcCmd.Flags().StringVar(&flagBBGCRollupInto, "rollup-into", "", "Blackboard UUID receiving summaries (required with --action delete --rollup)")
+	gcCmd.Flags().IntVar(&flagBBGCLimit, "limit", 1000, "Max boards per run")
+	gcCmd.Flags().BoolVar(&flagBBGCDryRun, "dry-run", false, "List expired boards without changing them")
+	gcCmd.Flags().BoolVar(&flagBBGCJSON, "json", false, "Output JSON")
+}
@@ -66,6 +66,10 @@ var getCmd = &cobra.Command{
 		if b.Updated.Valid {
 			out["updated"] = b.Updated.Time.Format(time.RFC3339Nano)
 		}
+		if b.ExpiresAt.Valid {
+			out["expires_at"] = b.ExpiresAt.Time.Format(time.RFC3339Nano)
+		}
+		out["archived"] = b.Archived
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		return enc.Encode(out)
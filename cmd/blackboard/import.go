@@ -12,6 +12,8 @@ import (
 
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
@@ -40,13 +42,9 @@ var importCmd = &cobra.Command{
 			return errors.New("folder must be a relative path inside the workspace")
 		}
 
-		// Load YAMLs from folder
-		bbY, err := readLocalBlackboardYAML(filepath.Join(folder, "blackboard.yaml"))
+		id, err := readBlackboardIDFromFolder(folder)
 		if err != nil {
-			return fmt.Errorf("read blackboard.yaml: %w", err)
-		}
-		if strings.TrimSpace(bbY.ID) == "" {
-			return errors.New("blackboard.yaml missing required id")
+			return err
 		}
 
 		// Open DB
@@ -62,55 +60,53 @@ var importCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		// Run a diff preview first to show intent
-		_ = runBlackboardDiff(bbY.ID, folder, flagImportDetailed, true)
-
-		// Existence checks: blackboard and stickies must NOT exist
-		if _, err := pgdao.GetBlackboardByID(ctx, db, bbY.ID); err == nil {
-			fmt.Fprintf(os.Stderr, "blackboard already exists: id=%s\n", bbY.ID)
-			fmt.Fprintf(os.Stderr, "Hint: use 'rbc blackboard sync folder:%s id:%s' to update it, or change id in %s/blackboard.yaml and stickie YAMLs.\n", folder, bbY.ID, folder)
-			return fmt.Errorf("blackboard already exists: id=%s", bbY.ID)
-		}
-
-		// Load local stickies
-		byID, anon, err := loadLocalStickies(folder, true)
+		a := &blackboardAdapter{db: db, id: id, includeArchived: true, importing: true}
+		local, err := a.LoadLocal(folder)
 		if err != nil {
 			return err
 		}
-		if len(anon) > 0 {
-			return fmt.Errorf("found %d stickie yaml without id; import requires explicit ids", len(anon))
-		}
-		// Check none of the stickie ids exist
-		for id, rec := range byID {
-			if s, err := pgdao.GetStickieByID(ctx, db, id); err == nil && s != nil {
-				fmt.Fprintf(os.Stderr, "stickie already exists: id=%s (file=%s)\n", id, rec.filename)
-				return fmt.Errorf("stickie already exists: id=%s", id)
-			}
+		remote, err := a.LoadRemote(ctx)
+		if err != nil {
+			return err
 		}
-
-		// Insert blackboard with provided ID
-		if err := insertBlackboardWithID(ctx, db, bbY); err != nil {
-			return fmt.Errorf("insert blackboard: %w", err)
+		// Show intent first
+		if err := syncfs.PrintChanges(os.Stdout, syncfs.Diff(remote, local), flagImportDetailed, false); err != nil {
+			return err
 		}
-		fmt.Fprintf(os.Stderr, "imported blackboard id=%s role=%q\n", bbY.ID, bbY.Role)
-
-		// Insert stickies with provided IDs, stable order
-		ids := make([]string, 0, len(byID))
-		for id := range byID {
-			ids = append(ids, id)
+		if len(remote) > 0 {
+			fmt.Fprintf(os.Stderr, "Hint: use 'rbc blackboard sync folder:%s id:%s' to update it, or change id in %s/blackboard.yaml and stickie YAMLs.\n", folder, id, folder)
+			return fmt.Errorf("blackboard already exists: id=%s", id)
 		}
-		sort.Strings(ids)
-		for _, id := range ids {
-			rec := byID[id]
-			if strings.TrimSpace(rec.yaml.ID) == "" {
-				return fmt.Errorf("stickie %s missing id unexpectedly", rec.filename)
+		// Stickie ids are global: none may exist on any board
+		for _, r := range local {
+			if r.Kind != kindStickie {
+				continue
 			}
-			if err := insertStickieWithID(ctx, db, bbY.ID, rec.yaml); err != nil {
-				return fmt.Errorf("insert stickie id=%s from %s: %w", rec.yaml.ID, rec.filename, err)
+			if strings.HasPrefix(r.Key, fileKeyPrefix) {
+				return fmt.Errorf("%s has no id; import requires explicit ids", r.Primary())
 			}
-			fmt.Fprintf(os.Stderr, "imported stickie id=%s file=%s\n", rec.yaml.ID, rec.filename)
+			_, err := pgdao.GetStickieByID(ctx, db, r.Key)
+			if err == nil {
+				return fmt.Errorf("stickie already exists: id=%s (file=%s)", r.Key, r.Primary())
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+		}
+		// Validate structured payloads against the schemas of blackboard.yaml
+		set, err := a.schemaSet(ctx, local)
+		if err != nil {
+			return err
+		}
+		if err := checkStickieRecords(set, local); err != nil {
+			return err
 		}
 
+		sum, err := syncfs.Import(ctx, a, folder, syncfs.Options{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "blackboard import id=%s folder=%s imported=%d\n", id, folder, sum.Applied)
 		return nil
 	},
 }
@@ -127,12 +123,12 @@ func insertBlackboardWithID(ctx context.Context, db *pgxpool.Pool, y blackboardY
 		return errors.New("blackboard yaml requires id and role")
 	}
 	// Prepare fields
-	conv := strings.TrimSpace(deref(y.Conversation))
-	proj := strings.TrimSpace(deref(y.Project))
-	task := strings.TrimSpace(deref(y.TaskID))
-	bg := strings.TrimSpace(derefLS(y.Background))
-	gl := strings.TrimSpace(derefLS(y.Guidelines))
-	lc := strings.TrimSpace(deref(y.Lifecycle))
+	conv := strings.TrimSpace(syncfs.Deref(y.Conversation))
+	proj := strings.TrimSpace(syncfs.Deref(y.Project))
+	task := strings.TrimSpace(syncfs.Deref(y.TaskID))
+	bg := strings.TrimSpace(syncfs.DerefLiteral(y.Background))
+	gl := strings.TrimSpace(syncfs.DerefLiteral(y.Guidelines))
+	lc := strings.TrimSpace(syncfs.Deref(y.Lifecycle))
 	q := `INSERT INTO blackboards (id, role_name, conversation_id, project_name, task_id, background, guidelines, lifecycle)
           VALUES ($1::uuid, $2, CASE WHEN $3='' THEN NULL ELSE $3::uuid END, NULLIF($4,''), CASE WHEN $5='' THEN NULL ELSE $5::uuid END, NULLIF($6,''), NULLIF($7,''), NULLIF($8,''))`
 	_, err := db.Exec(ctx, q, y.ID, y.Role, conv, proj, task, bg, gl, lc)
@@ -145,23 +141,31 @@ func insertStickieWithID(ctx context.Context, db *pgxpool.Pool, blackboardID str
 		return errors.New("stickie yaml requires id")
 	}
 	// Prepare fields
-	note := strings.TrimSpace(getLitPtr(y.Note))
-	code := strings.TrimSpace(getStrPtr(y.Code))
+	note := strings.TrimSpace(syncfs.DerefLiteral(y.Note))
+	code := strings.TrimSpace(syncfs.Deref(y.Code))
 	labels := y.Labels
 	sort.Strings(labels)
-	ctask := strings.TrimSpace(getStrPtr(y.CreatedByTask))
-	prio := strings.TrimSpace(getStrPtr(y.Priority))
+	ctask := strings.TrimSpace(syncfs.Deref(y.CreatedByTask))
+	prio := strings.TrimSpace(syncfs.Deref(y.Priority))
 	var score *float64 = y.Score
-	name := strings.TrimSpace(getStrPtr(y.Name))
+	name := strings.TrimSpace(syncfs.Deref(y.Name))
 	archived := y.Archived
-	q := `INSERT INTO stickies (id, blackboard_id, note, code, labels, created_by_task_id, priority_level, score, name, archived)
-          VALUES ($1::uuid, $2::uuid, NULLIF($3,''), NULLIF($4,''), COALESCE($5, ARRAY[]::text[]), CASE WHEN $6='' THEN NULL ELSE $6::uuid END, NULLIF($7,''), $8::double precision, NULLIF($9,''), COALESCE($10,false))`
+	structured, err := structuredJSON(y.Structured)
+	if err != nil {
+		return err
+	}
+	var structParam any
+	if len(structured) > 0 {
+		structParam = structured
+	}
+	q := `INSERT INTO stickies (id, blackboard_id, note, code, labels, created_by_task_id, priority_level, score, name, archived, structured)
+          VALUES ($1::uuid, $2::uuid, NULLIF($3,''), NULLIF($4,''), COALESCE($5, ARRAY[]::text[]), CASE WHEN $6='' THEN NULL ELSE $6::uuid END, NULLIF($7,''), $8::double precision, NULLIF($9,''), COALESCE($10,false), $11::jsonb)`
 	var lblParam any
 	if len(labels) > 0 {
 		lblParam = labels
 	} else {
 		lblParam = nil
 	}
-	_, err := db.Exec(ctx, q, y.ID, blackboardID, note, code, lblParam, ctask, prio, score, name, archived)
+	_, err = db.Exec(ctx, q, y.ID, blackboardID, note, code, lblParam, ctask, prio, score, name, archived, structParam)
 	return err
 }
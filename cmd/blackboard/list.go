@@ -20,6 +20,7 @@ var (
 	flagBBListOffset int
 	flagBBListOutput string
 	flagBBListRole   string
+	flagBBListAll    bool
 )
 
 var listCmd = &cobra.Command{
@@ -40,7 +41,7 @@ var listCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		bb, err := pgdao.ListBlackboards(ctx, db, flagBBListRole, flagBBListLimit, flagBBListOffset)
+		bb, err := pgdao.ListBlackboards(ctx, db, flagBBListRole, flagBBListAll, flagBBListLimit, flagBBListOffset)
 		if err != nil {
 			return err
 		}
@@ -59,6 +60,12 @@ var listCmd = &cobra.Command{
 				if b.Updated.Valid {
 					item["updated"] = b.Updated.Time.Format(time.RFC3339Nano)
 				}
+				if b.ExpiresAt.Valid {
+					item["expires_at"] = b.ExpiresAt.Time.Format(time.RFC3339Nano)
+				}
+				if b.Archived {
+					item["archived"] = true
+				}
 				arr = append(arr, item)
 			}
 			enc := json.NewEncoder(os.Stdout)
@@ -67,7 +74,7 @@ var listCmd = &cobra.Command{
 		}
 		// table default
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"ID", "PROJECT", "LIFECYCLE", "UPDATED"})
+		table.SetHeader([]string{"ID", "PROJECT", "LIFECYCLE", "UPDATED", "EXPIRES"})
 		for _, b := range bb {
 			updated := ""
 			if b.Updated.Valid {
@@ -81,7 +88,14 @@ var listCmd = &cobra.Command{
 			if b.Lifecycle.Valid {
 				lifecycle = b.Lifecycle.String
 			}
-			table.Append([]string{b.ID, proj, lifecycle, updated})
+			expires := ""
+			if b.ExpiresAt.Valid {
+				expires = b.ExpiresAt.Time.Format(time.RFC3339)
+			}
+			if b.Archived {
+				expires = "archived"
+			}
+			table.Append([]string{b.ID, proj, lifecycle, updated, expires})
 		}
 		table.Render()
 		return nil
@@ -94,4 +108,5 @@ func init() {
 	listCmd.Flags().IntVar(&flagBBListOffset, "offset", 0, "Offset for pagination")
 	listCmd.Flags().StringVar(&flagBBListOutput, "output", "table", "Output format: table or json")
 	listCmd.Flags().StringVar(&flagBBListRole, "role", "", "Role name (required)")
+	listCmd.Flags().BoolVar(&flagBBListAll, "archived", false, "Include archived blackboards")
 }
@@ -0,0 +1,513 @@
+package blackboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// syncStateFile is written next to blackboard.yaml; it records, per stickie, the
+// content both sides agreed on at the last sync. It is the base of three-way merges.
+const syncStateFile = ".rbc-sync.yaml"
+
+type syncState struct {
+	BlackboardID string                    `yaml:"blackboard_id"`
+	Synced       string                    `yaml:"synced,omitempty"`
+	Stickies     map[string]syncStateEntry `yaml:"stickies,omitempty"`
+}
+
+type syncStateEntry struct {
+	File string              `yaml:"file"`
+	Hash string              `yaml:"hash"`
+	Base stickieHashMaterial `yaml:"base"`
+}
+
+func newStateEntry(file string, m stickieHashMaterial) syncStateEntry {
+	return syncStateEntry{File: file, Hash: syncfs.HashMaterial(m), Base: m}
+}
+
+// loadSyncState reads the state file of dir. A missing file, or one recorded for
+// another blackboard, yields an empty state (no base for any stickie).
+func loadSyncState(dir, blackboardID string) (syncState, error) {
+	empty := syncState{BlackboardID: blackboardID, Stickies: map[string]syncStateEntry{}}
+	var st syncState
+	if err := syncfs.ReadYAML(filepath.Join(dir, syncStateFile), &st); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return empty, nil
+		}
+		return empty, err
+	}
+	if st.BlackboardID != blackboardID {
+		return empty, nil
+	}
+	if st.Stickies == nil {
+		st.Stickies = map[string]syncStateEntry{}
+	}
+	return st, nil
+}
+
+func saveSyncState(dir string, st syncState) error {
+	st.Synced = time.Now().UTC().Format(time.RFC3339)
+	return syncfs.WriteYAML(filepath.Join(dir, syncStateFile), st)
+}
+
+// mergeStickieMaterial merges the local and remote edits of a stickie against their
+// common base, field by field. A field changed on one side only takes that side's
+// value; a field changed on both sides to different values is a conflict. Labels
+// merge as a set: additions and removals from both sides are combined.
+func mergeStickieMaterial(base, remote, local stickieHashMaterial) (stickieHashMaterial, []string) {
+	var conflicts []string
+	pick := func(name string, b, r, l any) any {
+		switch {
+		case reflect.DeepEqual(r, b):
+			return l
+		case reflect.DeepEqual(l, b), reflect.DeepEqual(l, r):
+			return r
+		default:
+			conflicts = append(conflicts, name)
+			return r
+		}
+	}
+	m := stickieHashMaterial{
+		Note:          pick("note", base.Note, remote.Note, local.Note).(string),
+		Code:          pick("code", base.Code, remote.Code, local.Code).(string),
+		CreatedByTask: pick("created_by_task_id", base.CreatedByTask, remote.CreatedByTask, local.CreatedByTask).(string),
+		PriorityLevel: pick("priority_level", base.PriorityLevel, remote.PriorityLevel, local.PriorityLevel).(string),
+		Score:         pick("score", base.Score, remote.Score, local.Score).(*float64),
+		Name:          pick("name", base.Name, remote.Name, local.Name).(string),
+		Archived:      pick("archived", base.Archived, remote.Archived, local.Archived).(bool),
+		Structured:    pick("structured", base.Structured, remote.Structured, local.Structured).(string),
+		Labels:        mergeLabels(base.Labels, remote.Labels, local.Labels),
+	}
+	return m, conflicts
+}
+
+func mergeLabels(base, remote, local []string) []string {
+	inBase, inRemote, inLocal := toSet(base), toSet(remote), toSet(local)
+	out := map[string]struct{}{}
+	for _, l := range base {
+		_, r := inRemote[l]
+		_, lo := inLocal[l]
+		if r && lo {
+			out[l] = struct{}{}
+		}
+	}
+	for _, side := range [][]string{remote, local} {
+		for _, l := range side {
+			if _, ok := inBase[l]; !ok {
+				out[l] = struct{}{}
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(out))
+	for l := range out {
+		res = append(res, l)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func toSet(xs []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(xs))
+	for _, x := range xs {
+		m[x] = struct{}{}
+	}
+	return m
+}
+
+// stickieFromMaterial maps merged content back into a stickie for an exact update.
+func stickieFromMaterial(id, blackboardID string, m stickieHashMaterial) pgdao.Stickie {
+	s := pgdao.Stickie{ID: id, BlackboardID: blackboardID, Labels: m.Labels, Archived: m.Archived}
+	s.Note.String, s.Note.Valid = m.Note, m.Note != ""
+	s.Code.String, s.Code.Valid = m.Code, m.Code != ""
+	s.CreatedByTaskID.String, s.CreatedByTaskID.Valid = m.CreatedByTask, m.CreatedByTask != ""
+	s.PriorityLevel.String, s.PriorityLevel.Valid = m.PriorityLevel, m.PriorityLevel != ""
+	s.Name.String, s.Name.Valid = m.Name, m.Name != ""
+	if m.Score != nil {
+		s.Score.Float64, s.Score.Valid = *m.Score, true
+	}
+	if m.Structured != "" {
+		s.Structured = []byte(m.Structured)
+	}
+	return s
+}
+
+// mergeLocal is a stickie file of the folder with its parsed content.
+type mergeLocal struct {
+	file string
+	y    stickieYAML
+}
+
+// conflictYAML is written as <file>.conflict.yaml when --conflict-files is set.
+type conflictYAML struct {
+	ID     string               `yaml:"id"`
+	File   string               `yaml:"file"`
+	Reason string               `yaml:"reason"`
+	Fields []string             `yaml:"fields,omitempty"`
+	Base   *stickieHashMaterial `yaml:"base,omitempty"`
+	Remote *stickieHashMaterial `yaml:"remote,omitempty"`
+	Local  *stickieHashMaterial `yaml:"local,omitempty"`
+}
+
+func conflictFileName(file string) string {
+	return strings.TrimSuffix(file, ".stickie.yaml") + ".conflict.yaml"
+}
+
+// merger carries the state of one three-way merge run.
+type merger struct {
+	ctx          context.Context
+	db           *pgxpool.Pool
+	dir          string
+	blackboardID string
+	schemas      *stickieschema.Set
+	dryRun       bool
+	next         syncState
+	conflicts    []conflictYAML
+	counts       struct{ unchanged, pulled, pushed, merged, created, deleted int }
+}
+
+func (m *merger) logf(format string, args ...any) {
+	prefix := ""
+	if m.dryRun {
+		prefix = "[dry-run] "
+	}
+	fmt.Fprintf(os.Stderr, prefix+format+"\n", args...)
+}
+
+func (m *merger) conflict(c conflictYAML, keep syncStateEntry, hasBase bool) {
+	if hasBase {
+		m.next.Stickies[c.ID] = keep
+	}
+	m.conflicts = append(m.conflicts, c)
+	detail := c.Reason
+	if len(c.Fields) > 0 {
+		detail += ": " + strings.Join(c.Fields, ", ")
+	}
+	fmt.Fprintf(os.Stderr, "! %s %s (%s)\n", c.ID, c.File, detail)
+}
+
+// writeLocal (re)writes the stickie file from the DB row.
+func (m *merger) writeLocal(file string, s pgdao.Stickie) error {
+	if m.dryRun {
+		return nil
+	}
+	return syncfs.WriteYAML(filepath.Join(m.dir, file), stickieToYAML(s))
+}
+
+// pushRemote replaces the DB content with mat and returns the updated row. The
+// write only applies while the stickie is still at editCount, so edits made
+// since it was read surface as a pgdao.VersionConflictError. A merged result
+// that no longer satisfies the blackboard schemas is rejected, against the
+// schemas read in the write transaction.
+func (m *merger) pushRemote(id string, editCount int, mat stickieHashMaterial) (pgdao.Stickie, error) {
+	s := stickieFromMaterial(id, m.blackboardID, mat)
+	s.IfMatch.EditCount = &editCount
+	if m.dryRun {
+		if !s.Archived {
+			return s, m.schemas.Check("stickie "+id, s.Labels, s.Structured)
+		}
+		return s, nil
+	}
+	if !s.Archived {
+		s.Validate = stickieschema.Validate
+	}
+	if err := pgdao.ReplaceStickieContent(m.ctx, m.db, &s); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func (m *merger) mergeOne(id string, remote *pgdao.Stickie, local *mergeLocal, base *syncStateEntry, allowDelete bool) error {
+	switch {
+	case remote != nil && local != nil:
+		mr, ml := stickieMaterialDB(*remote), stickieMaterialYAML(local.y)
+		if reflect.DeepEqual(mr, ml) {
+			m.counts.unchanged++
+			m.next.Stickies[id] = newStateEntry(local.file, ml)
+			return nil
+		}
+		if base == nil {
+			m.conflict(conflictYAML{ID: id, File: local.file, Reason: "changed on both sides without a common base", Remote: &mr, Local: &ml}, syncStateEntry{}, false)
+			return nil
+		}
+		mb := base.Base
+		switch {
+		case reflect.DeepEqual(mr, mb):
+			s, err := m.pushRemote(id, remote.EditCount, ml)
+			if err != nil {
+				if _, ok := pgdao.AsVersionConflict(err); ok {
+					m.conflict(conflictYAML{ID: id, File: local.file, Reason: "changed in DB during sync", Base: &mb, Remote: &mr, Local: &ml}, *base, true)
+					return nil
+				}
+				return err
+			}
+			// Refresh edit_count in the file so a later folder->id push matches.
+			if err := m.writeLocal(local.file, s); err != nil {
+				return err
+			}
+			m.counts.pushed++
+			m.logf("updated stickie id=%s from %s", id, local.file)
+			m.next.Stickies[id] = newStateEntry(local.file, ml)
+		case reflect.DeepEqual(ml, mb):
+			if err := m.writeLocal(local.file, *remote); err != nil {
+				return err
+			}
+			m.counts.pulled++
+			m.logf("wrote %s", filepath.Join(m.dir, local.file))
+			m.next.Stickies[id] = newStateEntry(local.file, mr)
+		default:
+			merged, fields := mergeStickieMaterial(mb, mr, ml)
+			if len(fields) > 0 {
+				m.conflict(conflictYAML{ID: id, File: local.file, Reason: "changed on both sides", Fields: fields, Base: &mb, Remote: &mr, Local: &ml}, *base, true)
+				return nil
+			}
+			s, err := m.pushRemote(id, remote.EditCount, merged)
+			if err != nil {
+				if _, ok := pgdao.AsVersionConflict(err); ok {
+					m.conflict(conflictYAML{ID: id, File: local.file, Reason: "changed in DB during sync", Base: &mb, Remote: &mr, Local: &ml}, *base, true)
+					return nil
+				}
+				return err
+			}
+			if err := m.writeLocal(local.file, s); err != nil {
+				return err
+			}
+			m.counts.merged++
+			m.logf("merged stickie id=%s into %s", id, local.file)
+			m.next.Stickies[id] = newStateEntry(local.file, merged)
+		}
+	case remote != nil:
+		mr := stickieMaterialDB(*remote)
+		if base == nil {
+			if remote.Archived && !flagSyncIncludeArchived {
+				return nil
+			}
+			file := stickieFileName(*remote)
+			if err := m.writeLocal(file, *remote); err != nil {
+				return err
+			}
+			m.counts.pulled++
+			m.logf("wrote %s", filepath.Join(m.dir, file))
+			m.next.Stickies[id] = newStateEntry(file, mr)
+			return nil
+		}
+		if !reflect.DeepEqual(mr, base.Base) {
+			m.conflict(conflictYAML{ID: id, File: base.File, Reason: "deleted locally, changed remotely", Base: &base.Base, Remote: &mr}, *base, true)
+			return nil
+		}
+		if !allowDelete {
+			m.logf("keep stickie id=%s deleted locally (use --delete)", id)
+			m.next.Stickies[id] = *base
+			return nil
+		}
+		if !m.dryRun {
+			if _, err := pgdao.DeleteStickie(m.ctx, m.db, id); err != nil {
+				return err
+			}
+		}
+		m.counts.deleted++
+		m.logf("deleted stickie id=%s", id)
+	case local != nil:
+		ml := stickieMaterialYAML(local.y)
+		if base == nil {
+			// Never synced here: it belongs to another board or was deleted before
+			// the first sync. Leave it for the user rather than abort mid-run.
+			m.conflict(conflictYAML{ID: id, File: local.file, Reason: "not on this blackboard and no common base", Local: &ml}, syncStateEntry{}, false)
+			return nil
+		}
+		if !reflect.DeepEqual(ml, base.Base) {
+			m.conflict(conflictYAML{ID: id, File: local.file, Reason: "deleted remotely, changed locally", Base: &base.Base, Local: &ml}, *base, true)
+			return nil
+		}
+		if !allowDelete {
+			m.logf("keep %s deleted remotely (use --delete)", local.file)
+			m.next.Stickies[id] = *base
+			return nil
+		}
+		p := filepath.Join(m.dir, local.file)
+		if !m.dryRun {
+			if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		m.counts.deleted++
+		m.logf("deleted %s", p)
+	}
+	// deleted on both sides: simply forget the base
+	return nil
+}
+
+// createLocal inserts an anonymous stickie file and rewrites it with its new id.
+func (m *merger) createLocal(l mergeLocal) error {
+	m.counts.created++
+	if m.dryRun {
+		m.logf("create stickie from %s", l.file)
+		return nil
+	}
+	ins := stickieFromYAMLForUpsert(l.y, m.blackboardID)
+	if err := pgdao.UpsertStickie(m.ctx, m.db, &ins); err != nil {
+		return fmt.Errorf("create stickie from %s: %w", l.file, err)
+	}
+	if err := m.writeLocal(l.file, ins); err != nil {
+		return err
+	}
+	m.logf("created stickie id=%s from %s", ins.ID, l.file)
+	m.next.Stickies[ins.ID] = newStateEntry(l.file, stickieMaterialDB(ins))
+	return nil
+}
+
+// syncMerge reconciles a blackboard and a folder in both directions using the
+// state file as common ancestor. Unresolved stickies are left untouched on both
+// sides and reported; the run then fails with a syncfs.ConflictError.
+func syncMerge(blackboardID, relFolder string, allowDelete, dryRun, conflictFiles bool) error {
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create folder: %w", err)
+		}
+	}
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	b, err := pgdao.GetBlackboardByID(ctx, db, blackboardID)
+	if err != nil {
+		return err
+	}
+	state, err := loadSyncState(dir, b.ID)
+	if err != nil {
+		return err
+	}
+
+	stickies, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Stickie, error) {
+		return pgdao.ListStickies(ctx, db, b.ID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+	remote := make(map[string]*pgdao.Stickie, len(stickies))
+	for i := range stickies {
+		remote[stickies[i].ID] = &stickies[i]
+	}
+	local := map[string]*mergeLocal{}
+	var anonymous []mergeLocal
+	err = syncfs.EachFile(dir, ".stickie.yaml", func(name string, _ []byte) error {
+		y, err := readStickieYAML(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		id := strings.TrimSpace(y.ID)
+		if id == "" {
+			anonymous = append(anonymous, mergeLocal{file: name, y: y})
+			return nil
+		}
+		if prev, dup := local[id]; dup {
+			return fmt.Errorf("stickie %s declared twice (%s and %s)", id, prev.file, name)
+		}
+		local[id] = &mergeLocal{file: name, y: y}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Reject invalid payloads before touching either side
+	schemas, err := stickieschema.Load(ctx, db, b.ID)
+	if err != nil {
+		return err
+	}
+	checks := append([]mergeLocal(nil), anonymous...)
+	for _, l := range local {
+		checks = append(checks, *l)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].file < checks[j].file })
+	var invalid []error
+	for _, l := range checks {
+		if err := checkStickieYAML(schemas, l.file, l.y); err != nil {
+			invalid = append(invalid, err)
+		}
+	}
+	if len(invalid) > 0 {
+		return errors.Join(invalid...)
+	}
+
+	ids := map[string]struct{}{}
+	for id := range remote {
+		ids[id] = struct{}{}
+	}
+	for id := range local {
+		ids[id] = struct{}{}
+	}
+	for id := range state.Stickies {
+		ids[id] = struct{}{}
+	}
+	order := make([]string, 0, len(ids))
+	for id := range ids {
+		order = append(order, id)
+	}
+	sort.Strings(order)
+
+	m := &merger{ctx: ctx, db: db, dir: dir, blackboardID: b.ID, schemas: schemas, dryRun: dryRun,
+		next: syncState{BlackboardID: b.ID, Stickies: map[string]syncStateEntry{}}}
+	for _, id := range order {
+		var base *syncStateEntry
+		if e, ok := state.Stickies[id]; ok {
+			base = &e
+		}
+		if err := m.mergeOne(id, remote[id], local[id], base, allowDelete); err != nil {
+			return err
+		}
+	}
+	for _, l := range anonymous {
+		if err := m.createLocal(l); err != nil {
+			return err
+		}
+	}
+
+	if !dryRun {
+		if conflictFiles {
+			for _, c := range m.conflicts {
+				p := filepath.Join(dir, conflictFileName(c.File))
+				if err := syncfs.WriteYAML(p, c); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "wrote %s\n", p)
+			}
+		}
+		if err := saveSyncState(dir, m.next); err != nil {
+			return err
+		}
+	}
+	c := m.counts
+	fmt.Fprintf(os.Stderr, "blackboard merge id=%s folder=%s unchanged=%d pulled=%d pushed=%d merged=%d created=%d deleted=%d conflicts=%d\n",
+		b.ID, dir, c.unchanged, c.pulled, c.pushed, c.merged, c.created, c.deleted, len(m.conflicts))
+	if len(m.conflicts) > 0 {
+		return &syncfs.ConflictError{Count: len(m.conflicts)}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package blackboard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeStickieMaterial_NonOverlappingChangesMerge(t *testing.T) {
+	base := stickieHashMaterial{Note: "idea", Name: "cache", Labels: []string{"a", "b"}}
+	remote := base
+	remote.Note = "idea, refined"
+	remote.Labels = []string{"a", "b", "c"}
+	local := base
+	local.PriorityLevel = "must"
+	local.Labels = []string{"b"}
+
+	got, conflicts := mergeStickieMaterial(base, remote, local)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts; got %v", conflicts)
+	}
+	want := stickieHashMaterial{Note: "idea, refined", Name: "cache", PriorityLevel: "must", Labels: []string{"b", "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("merge mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestMergeStickieMaterial_SameFieldConflicts(t *testing.T) {
+	base := stickieHashMaterial{Note: "idea", Code: "x"}
+	remote := base
+	remote.Note = "remote idea"
+	local := base
+	local.Note = "local idea"
+	local.Code = "y"
+
+	_, conflicts := mergeStickieMaterial(base, remote, local)
+	if !reflect.DeepEqual(conflicts, []string{"note"}) {
+		t.Fatalf("expected a note conflict only; got %v", conflicts)
+	}
+}
+
+func TestMergeStickieMaterial_IdenticalEditsAgree(t *testing.T) {
+	s := 0.5
+	base := stickieHashMaterial{Name: "old"}
+	remote := stickieHashMaterial{Name: "new", Score: &s}
+	local := stickieHashMaterial{Name: "new", Score: &s}
+	got, conflicts := mergeStickieMaterial(base, remote, local)
+	if len(conflicts) != 0 || got.Name != "new" || got.Score == nil || *got.Score != s {
+		t.Fatalf("expected identical edits to merge; got %+v conflicts=%v", got, conflicts)
+	}
+}
+
+func TestStickieMaterial_StructuredComparesCanonically(t *testing.T) {
+	y := stickieYAML{Structured: map[string]any{"status": "accepted", "points": 5}}
+	s := stickieFromYAMLForUpsert(y, "bb")
+	s.Structured = []byte(`{"points": 5.0, "status": "accepted"}`)
+	if a, b := stickieMaterialYAML(y).Structured, stickieMaterialDB(s).Structured; a != b {
+		t.Fatalf("structured material differs: %s vs %s", a, b)
+	}
+}
+
+func TestMergeOne_UnknownLocalStickieIsConflict(t *testing.T) {
+	m := &merger{blackboardID: "b1", dryRun: true, next: syncState{Stickies: map[string]syncStateEntry{}}}
+	note := LiteralString("stray")
+	local := &mergeLocal{file: "s9.stickie.yaml", y: stickieYAML{ID: "s9", Note: &note}}
+	if err := m.mergeOne("s9", nil, local, nil, false); err != nil {
+		t.Fatalf("expected a conflict, not an error: %v", err)
+	}
+	if len(m.conflicts) != 1 || m.conflicts[0].ID != "s9" {
+		t.Fatalf("expected one conflict for s9; got %+v", m.conflicts)
+	}
+	if _, ok := m.next.Stickies["s9"]; ok {
+		t.Fatalf("a stickie without base must not gain one")
+	}
+}
@@ -0,0 +1,428 @@
+package blackboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	flagRenderID              string
+	flagRenderFormat          string
+	flagRenderOut             string
+	flagRenderRelations       string
+	flagRenderIncludeArchived bool
+)
+
+// renderCmd implements: rbc blackboard render --id <uuid> --format md|html
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a blackboard as a Markdown or HTML report",
+	Long: `Render a blackboard and its stickies as a human-friendly document.
+Stickies are grouped by label, then by priority (Must, Should, Could, Won't),
+and sorted by score (highest first). A stickie carrying several labels is shown
+in full under its first label and linked from the others. Code is fenced with
+a guessed language; relations are listed as links or drawn as a Mermaid graph.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagRenderID) == "" {
+			return errors.New("--id is required")
+		}
+		format := strings.ToLower(strings.TrimSpace(flagRenderFormat))
+		if format == "markdown" {
+			format = "md"
+		}
+		if format != "md" && format != "html" {
+			return fmt.Errorf("invalid --format %q (want md or html)", flagRenderFormat)
+		}
+		rels := strings.ToLower(strings.TrimSpace(flagRenderRelations))
+		if rels != "links" && rels != "mermaid" && rels != "none" {
+			return fmt.Errorf("invalid --relations %q (want links, mermaid or none)", flagRenderRelations)
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		b, err := pgdao.GetBlackboardByID(ctx, db, strings.TrimSpace(flagRenderID))
+		if err != nil {
+			return err
+		}
+		stickies, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Stickie, error) {
+			return pgdao.ListStickies(ctx, db, b.ID, limit, offset)
+		})
+		if err != nil {
+			return err
+		}
+		var relations []pgdao.StickieRelation
+		if rels != "none" {
+			if relations, err = pgdao.ListBlackboardRelations(ctx, db, b.ID); err != nil {
+				return err
+			}
+		}
+		doc := buildRenderDoc(*b, stickies, relations, renderOptions{
+			includeArchived: flagRenderIncludeArchived,
+			mermaid:         rels == "mermaid",
+		})
+		var buf bytes.Buffer
+		if format == "html" {
+			err = writeRenderHTML(&buf, doc)
+		} else {
+			err = writeRenderMarkdown(&buf, doc)
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(flagRenderOut) == "" || flagRenderOut == "-" {
+			_, err = os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(flagRenderOut, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "rendered blackboard id=%s stickies=%d format=%s to %s\n", b.ID, doc.count, format, flagRenderOut)
+		return nil
+	},
+}
+
+func init() {
+	BlackboardCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringVar(&flagRenderID, "id", "", "Blackboard UUID (required)")
+	renderCmd.Flags().StringVar(&flagRenderFormat, "format", "md", "Output format: md or html")
+	renderCmd.Flags().StringVar(&flagRenderOut, "out", "", "File to write (default stdout)")
+	renderCmd.Flags().StringVar(&flagRenderRelations, "relations", "links", "Relations: links, mermaid or none")
+	renderCmd.Flags().BoolVar(&flagRenderIncludeArchived, "include-archived", false, "Include archived stickies")
+}
+
+type renderOptions struct {
+	includeArchived bool
+	mermaid         bool
+}
+
+// renderDoc is the format-independent model of a rendered blackboard.
+type renderDoc struct {
+	Title      string
+	Meta       []renderMeta
+	Background string
+	Guidelines string
+	Groups     []renderGroup
+	Mermaid    string
+	count      int
+}
+
+type renderMeta struct{ Key, Value string }
+
+type renderGroup struct {
+	Label      string
+	Priorities []renderPriority
+}
+
+type renderPriority struct {
+	Name  string
+	Cards []renderCard
+}
+
+type renderCard struct {
+	Anchor     string
+	Title      string
+	ID         string
+	Ref        bool // already shown in full under an earlier label
+	Labels     []string
+	Score      string
+	Archived   bool
+	Note       string
+	Code       string
+	CodeLang   string
+	Structured string
+	Out        []renderLink
+	In         []renderLink
+}
+
+// renderLink points at another stickie; Anchor is empty when it is not rendered.
+type renderLink struct {
+	Type   string
+	Title  string
+	Anchor string
+}
+
+const renderUnlabelled = "Unlabelled"
+
+var moscow = []struct{ level, name string }{
+	{"must", "Must"}, {"should", "Should"}, {"could", "Could"}, {"wont", "Won't"}, {"", "Unprioritized"},
+}
+
+func buildRenderDoc(b pgdao.Blackboard, stickies []pgdao.Stickie, relations []pgdao.StickieRelation, opts renderOptions) renderDoc {
+	doc := renderDoc{Title: "Blackboard " + shortRenderID(b.ID)}
+	if b.ProjectName.Valid && b.ProjectName.String != "" {
+		doc.Title = b.ProjectName.String
+	}
+	doc.Meta = append(doc.Meta, renderMeta{"id", b.ID}, renderMeta{"role", b.RoleName})
+	if b.Lifecycle.Valid && b.Lifecycle.String != "" {
+		doc.Meta = append(doc.Meta, renderMeta{"lifecycle", b.Lifecycle.String})
+	}
+	if b.Updated.Valid {
+		doc.Meta = append(doc.Meta, renderMeta{"updated", b.Updated.Time.UTC().Format(time.RFC3339)})
+	}
+	doc.Background = strings.TrimSpace(b.Background.String)
+	doc.Guidelines = strings.TrimSpace(b.Guidelines.String)
+
+	shown := make([]pgdao.Stickie, 0, len(stickies))
+	titles := map[string]string{}
+	for _, s := range stickies {
+		if s.Archived && !opts.includeArchived {
+			continue
+		}
+		shown = append(shown, s)
+		titles[s.ID] = stickieTitle(s)
+	}
+	doc.count = len(shown)
+	sort.SliceStable(shown, func(i, j int) bool { return renderLess(shown[i], shown[j]) })
+
+	out := map[string][]renderLink{}
+	in := map[string][]renderLink{}
+	link := func(id string) renderLink {
+		if t, ok := titles[id]; ok {
+			return renderLink{Title: t, Anchor: stickieAnchor(id)}
+		}
+		return renderLink{Title: shortRenderID(id)}
+	}
+	var edges []pgdao.StickieRelation
+	for _, r := range relations {
+		_, from := titles[r.FromID]
+		_, to := titles[r.ToID]
+		if !from && !to {
+			continue
+		}
+		edges = append(edges, r)
+		typ := strings.ToLower(r.RelType)
+		if from {
+			l := link(r.ToID)
+			l.Type = typ
+			out[r.FromID] = append(out[r.FromID], l)
+		}
+		if to {
+			l := link(r.FromID)
+			l.Type = typ
+			in[r.ToID] = append(in[r.ToID], l)
+		}
+	}
+
+	byLabel := map[string][]pgdao.Stickie{}
+	for _, s := range shown {
+		labels := sortedCopy(s.Labels)
+		if len(labels) == 0 {
+			labels = []string{renderUnlabelled}
+		}
+		for _, l := range labels {
+			byLabel[l] = append(byLabel[l], s)
+		}
+	}
+	labels := make([]string, 0, len(byLabel))
+	for l := range byLabel {
+		if l != renderUnlabelled {
+			labels = append(labels, l)
+		}
+	}
+	sort.Strings(labels)
+	if _, ok := byLabel[renderUnlabelled]; ok {
+		labels = append(labels, renderUnlabelled)
+	}
+	done := map[string]bool{}
+	for _, l := range labels {
+		g := renderGroup{Label: l}
+		for _, p := range moscow {
+			rp := renderPriority{Name: p.name}
+			for _, s := range byLabel[l] {
+				if strings.ToLower(strings.TrimSpace(s.PriorityLevel.String)) != p.level {
+					continue
+				}
+				c := renderCard{Anchor: stickieAnchor(s.ID), Title: titles[s.ID], ID: s.ID, Ref: done[s.ID]}
+				if !c.Ref {
+					fillRenderCard(&c, s)
+					c.Out, c.In = out[s.ID], in[s.ID]
+					if opts.mermaid {
+						c.Out, c.In = nil, nil
+					}
+					done[s.ID] = true
+				}
+				rp.Cards = append(rp.Cards, c)
+			}
+			if len(rp.Cards) > 0 {
+				g.Priorities = append(g.Priorities, rp)
+			}
+		}
+		doc.Groups = append(doc.Groups, g)
+	}
+	if opts.mermaid && len(edges) > 0 {
+		doc.Mermaid = mermaidGraph(edges, titles)
+	}
+	return doc
+}
+
+func fillRenderCard(c *renderCard, s pgdao.Stickie) {
+	c.Labels = sortedCopy(s.Labels)
+	if s.Score.Valid {
+		c.Score = fmt.Sprintf("%g", s.Score.Float64)
+	}
+	c.Archived = s.Archived
+	c.Note = strings.TrimSpace(s.Note.String)
+	c.Code = strings.TrimRight(s.Code.String, "\n")
+	if c.Code != "" {
+		c.CodeLang = guessCodeLang(c.Code, s.Labels)
+	}
+	if len(s.Structured) > 0 {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, s.Structured, "", "  "); err == nil {
+			c.Structured = pretty.String()
+		} else {
+			c.Structured = string(s.Structured)
+		}
+	}
+}
+
+// renderLess orders by score (highest first, unscored last), then title and id.
+func renderLess(a, b pgdao.Stickie) bool {
+	if a.Score.Valid != b.Score.Valid {
+		return a.Score.Valid
+	}
+	if a.Score.Valid && a.Score.Float64 != b.Score.Float64 {
+		return a.Score.Float64 > b.Score.Float64
+	}
+	ta, tb := strings.ToLower(stickieTitle(a)), strings.ToLower(stickieTitle(b))
+	if ta != tb {
+		return ta < tb
+	}
+	return a.ID < b.ID
+}
+
+func stickieAnchor(id string) string { return "stickie-" + shortRenderID(id) }
+
+func shortRenderID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+var codeLangLabels = map[string]string{
+	"go": "go", "golang": "go", "sql": "sql", "yaml": "yaml", "yml": "yaml", "json": "json",
+	"bash": "bash", "shell": "bash", "sh": "bash", "python": "python", "py": "python",
+	"typescript": "typescript", "ts": "typescript", "javascript": "javascript", "js": "javascript",
+	"rust": "rust", "java": "java", "dockerfile": "dockerfile", "makefile": "makefile",
+	"html": "html", "css": "css", "mermaid": "mermaid", "toml": "toml",
+}
+
+// guessCodeLang picks the fence language of a code snippet: a label naming a
+// language wins, otherwise the content is sniffed. Unknown code gets no language.
+func guessCodeLang(code string, labels []string) string {
+	for _, l := range sortedCopy(labels) {
+		if lang, ok := codeLangLabels[strings.ToLower(l)]; ok {
+			return lang
+		}
+	}
+	t := strings.TrimSpace(code)
+	first := t
+	if i := strings.IndexByte(t, '\n'); i >= 0 {
+		first = t[:i]
+	}
+	upper := strings.ToUpper(first)
+	switch {
+	case strings.HasPrefix(first, "#!") && (strings.Contains(first, "sh") || strings.Contains(first, "bash")):
+		return "bash"
+	case strings.HasPrefix(first, "#!") && strings.Contains(first, "python"):
+		return "python"
+	case strings.HasPrefix(first, "package "):
+		return "go"
+	case strings.HasPrefix(first, "FROM "):
+		return "dockerfile"
+	case (strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")) && json.Valid([]byte(t)):
+		return "json"
+	}
+	for _, kw := range []string{"SELECT ", "INSERT ", "UPDATE ", "DELETE ", "CREATE ", "ALTER ", "WITH "} {
+		if strings.HasPrefix(upper, kw) {
+			return "sql"
+		}
+	}
+	if strings.Contains(t, ":") {
+		var m map[string]any
+		if err := yaml.Unmarshal([]byte(t), &m); err == nil && len(m) > 0 {
+			return "yaml"
+		}
+	}
+	return ""
+}
+
+// codeFence returns a backtick fence longer than any backtick run in code.
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// mermaidGraph draws the relations as a left-to-right flowchart. Stickies from
+// other blackboards or archived ones appear with their short id.
+func mermaidGraph(edges []pgdao.StickieRelation, titles map[string]string) string {
+	nodes := map[string]string{}
+	var order []string
+	node := func(id string) string {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		n := fmt.Sprintf("n%d", len(nodes)+1)
+		nodes[id] = n
+		order = append(order, id)
+		return n
+	}
+	var lines []string
+	for _, e := range edges {
+		from, to := node(e.FromID), node(e.ToID)
+		lines = append(lines, fmt.Sprintf("  %s -->|%s| %s", from, strings.ToLower(e.RelType), to))
+	}
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for _, id := range order {
+		title, ok := titles[id]
+		if !ok {
+			title = shortRenderID(id) + " (not shown)"
+		}
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", nodes[id], strings.ReplaceAll(title, `"`, "#quot;"))
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func sortedCopy(in []string) []string {
+	cp := append([]string(nil), in...)
+	sort.Strings(cp)
+	return cp
+}
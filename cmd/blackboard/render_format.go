@@ -0,0 +1,177 @@
+package blackboard
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// writeRenderMarkdown writes doc as GitHub-flavoured Markdown. Each stickie gets
+// an explicit anchor so relations can link to it.
+func writeRenderMarkdown(w io.Writer, doc renderDoc) error {
+	bw := bufio.NewWriter(w)
+	p := func(format string, args ...any) { fmt.Fprintf(bw, format, args...) }
+	p("# %s\n\n", mdInline(doc.Title))
+	for _, m := range doc.Meta {
+		p("- **%s**: `%s`\n", m.Key, m.Value)
+	}
+	p("- **stickies**: %d\n\n", doc.count)
+	if doc.Background != "" {
+		p("## Background\n\n%s\n\n", doc.Background)
+	}
+	if doc.Guidelines != "" {
+		p("## Guidelines\n\n%s\n\n", doc.Guidelines)
+	}
+	for _, g := range doc.Groups {
+		p("## %s\n\n", mdInline(g.Label))
+		for _, pr := range g.Priorities {
+			p("### %s\n\n", pr.Name)
+			for _, c := range pr.Cards {
+				if c.Ref {
+					p("- See [%s](#%s)\n\n", mdInline(c.Title), c.Anchor)
+					continue
+				}
+				p("<a id=\"%s\"></a>\n\n#### %s\n\n", c.Anchor, mdInline(c.Title))
+				facts := []string{"id `" + c.ID + "`"}
+				if c.Score != "" {
+					facts = append(facts, "score "+c.Score)
+				}
+				if len(c.Labels) > 0 {
+					facts = append(facts, "labels "+strings.Join(c.Labels, ", "))
+				}
+				if c.Archived {
+					facts = append(facts, "archived")
+				}
+				p("_%s_\n\n", strings.Join(facts, " · "))
+				if c.Note != "" {
+					p("%s\n\n", c.Note)
+				}
+				if c.Code != "" {
+					f := codeFence(c.Code)
+					p("%s%s\n%s\n%s\n\n", f, c.CodeLang, c.Code, f)
+				}
+				if c.Structured != "" {
+					f := codeFence(c.Structured)
+					p("%sjson\n%s\n%s\n\n", f, c.Structured, f)
+				}
+				for _, l := range c.Out {
+					p("- %s → %s\n", l.Type, mdLink(l))
+				}
+				for _, l := range c.In {
+					p("- %s ← %s\n", l.Type, mdLink(l))
+				}
+				if len(c.Out)+len(c.In) > 0 {
+					p("\n")
+				}
+			}
+		}
+	}
+	if doc.Mermaid != "" {
+		p("## Relations\n\n```mermaid\n%s```\n", doc.Mermaid)
+	}
+	return bw.Flush()
+}
+
+func mdLink(l renderLink) string {
+	if l.Anchor == "" {
+		return mdInline(l.Title) + " (not shown)"
+	}
+	return "[" + mdInline(l.Title) + "](#" + l.Anchor + ")"
+}
+
+// mdInline escapes characters that would start Markdown markup in a title.
+func mdInline(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`", "<", "&lt;", "#", `\#`)
+	return r.Replace(s)
+}
+
+var renderHTMLTemplate = template.Must(template.New("board").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+.meta { color: #555; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 0.5rem 1rem; margin: 0.75rem 0; }
+.card h4 { margin: 0.25rem 0; }
+.facts { color: #666; font-size: 0.9em; }
+.note { white-space: pre-wrap; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; }
+.archived { opacity: 0.6; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul class="meta">
+{{- range .Meta}}
+<li><strong>{{.Key}}</strong>: <code>{{.Value}}</code></li>
+{{- end}}
+<li><strong>stickies</strong>: {{.Count}}</li>
+</ul>
+{{- if .Background}}
+<h2>Background</h2>
+<div class="note">{{.Background}}</div>
+{{- end}}
+{{- if .Guidelines}}
+<h2>Guidelines</h2>
+<div class="note">{{.Guidelines}}</div>
+{{- end}}
+{{- range .Groups}}
+<h2>{{.Label}}</h2>
+{{- range .Priorities}}
+<h3>{{.Name}}</h3>
+{{- range .Cards}}
+{{- if .Ref}}
+<p>See <a href="#{{.Anchor}}">{{.Title}}</a></p>
+{{- else}}
+<section class="card{{if .Archived}} archived{{end}}" id="{{.Anchor}}">
+<h4>{{.Title}}</h4>
+<div class="facts">id <code>{{.ID}}</code>{{if .Score}} · score {{.Score}}{{end}}{{if .Labels}} · labels {{range $i, $l := .Labels}}{{if $i}}, {{end}}{{$l}}{{end}}{{end}}{{if .Archived}} · archived{{end}}</div>
+{{- if .Note}}
+<div class="note">{{.Note}}</div>
+{{- end}}
+{{- if .Code}}
+<pre><code{{if .CodeLang}} class="language-{{.CodeLang}}"{{end}}>{{.Code}}</code></pre>
+{{- end}}
+{{- if .Structured}}
+<pre><code class="language-json">{{.Structured}}</code></pre>
+{{- end}}
+{{- if or .Out .In}}
+<ul class="relations">
+{{- range .Out}}
+<li>{{.Type}} → {{if .Anchor}}<a href="#{{.Anchor}}">{{.Title}}</a>{{else}}{{.Title}} (not shown){{end}}</li>
+{{- end}}
+{{- range .In}}
+<li>{{.Type}} ← {{if .Anchor}}<a href="#{{.Anchor}}">{{.Title}}</a>{{else}}{{.Title}} (not shown){{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Mermaid}}
+<h2>Relations</h2>
+<pre class="mermaid">
+{{.Mermaid}}</pre>
+<script type="module">
+import mermaid from "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs";
+mermaid.initialize({ startOnLoad: true });
+</script>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeRenderHTML writes doc as a standalone HTML page. The Mermaid graph, when
+// present, is drawn client-side by the Mermaid module.
+func writeRenderHTML(w io.Writer, doc renderDoc) error {
+	return renderHTMLTemplate.Execute(w, struct {
+		renderDoc
+		Count int
+	}{doc, doc.count})
+}
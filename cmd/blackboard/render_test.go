@@ -0,0 +1,83 @@
+package blackboard
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func renderFixture() (pgdao.Blackboard, []pgdao.Stickie, []pgdao.StickieRelation) {
+	ns := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	nf := func(f float64) sql.NullFloat64 { return sql.NullFloat64{Float64: f, Valid: true} }
+	b := pgdao.Blackboard{ID: "bb000000-0000", RoleName: "user", ProjectName: ns("acme/build")}
+	ss := []pgdao.Stickie{
+		{ID: "aaaaaaaa-1", Name: ns("Cache"), Labels: []string{"devops", "idea"}, PriorityLevel: ns("could"), Score: nf(0.2), Code: ns("package main\n")},
+		{ID: "bbbbbbbb-2", Name: ns("Pipeline"), Labels: []string{"devops"}, PriorityLevel: ns("must"), Score: nf(0.9)},
+		{ID: "cccccccc-3", Name: ns("Lint"), Labels: []string{"devops"}, PriorityLevel: ns("could"), Score: nf(0.7)},
+		{ID: "dddddddd-4", Name: ns("Old"), Archived: true},
+	}
+	rels := []pgdao.StickieRelation{{FromID: "bbbbbbbb-2", ToID: "aaaaaaaa-1", RelType: "USES"}}
+	return b, ss, rels
+}
+
+func TestBuildRenderDoc_GroupsAndOrders(t *testing.T) {
+	b, ss, rels := renderFixture()
+	doc := buildRenderDoc(b, ss, rels, renderOptions{})
+	if doc.count != 3 || len(doc.Groups) != 2 || doc.Groups[0].Label != "devops" || doc.Groups[1].Label != "idea" {
+		t.Fatalf("unexpected groups: %+v", doc.Groups)
+	}
+	dev := doc.Groups[0].Priorities
+	if dev[0].Name != "Must" || dev[1].Name != "Could" {
+		t.Fatalf("priorities not in MoSCoW order: %+v", dev)
+	}
+	if dev[1].Cards[0].Title != "Lint" || dev[1].Cards[1].Title != "Cache" {
+		t.Fatalf("could cards not sorted by score: %+v", dev[1].Cards)
+	}
+	if c := doc.Groups[1].Priorities[0].Cards[0]; !c.Ref || c.Anchor != "stickie-aaaaaaaa" {
+		t.Fatalf("second label should reference the first card: %+v", c)
+	}
+	if out := dev[0].Cards[0].Out; len(out) != 1 || out[0].Type != "uses" || out[0].Anchor != "stickie-aaaaaaaa" {
+		t.Fatalf("relation link missing: %+v", out)
+	}
+}
+
+func TestWriteRenderMarkdown(t *testing.T) {
+	b, ss, rels := renderFixture()
+	var buf bytes.Buffer
+	if err := writeRenderMarkdown(&buf, buildRenderDoc(b, ss, rels, renderOptions{mermaid: true})); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{"# acme/build", "## devops", "### Must", "```go\npackage main\n```", "```mermaid\ngraph LR\n", "n1 -->|uses| n2"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Old") {
+		t.Errorf("archived stickie rendered:\n%s", md)
+	}
+}
+
+func TestGuessCodeLangAndFence(t *testing.T) {
+	cases := map[string]string{
+		"SELECT 1":            "sql",
+		"{\"a\": 1}":          "json",
+		"#!/bin/bash\necho":   "bash",
+		"name: CI\non: push":  "yaml",
+		"just some text here": "",
+	}
+	for code, want := range cases {
+		if got := guessCodeLang(code, nil); got != want {
+			t.Errorf("guessCodeLang(%q) = %q, want %q", code, got, want)
+		}
+	}
+	if got := guessCodeLang("x", []string{"python"}); got != "python" {
+		t.Errorf("label should win, got %q", got)
+	}
+	if f := codeFence("a ``` b"); f != "````" {
+		t.Errorf("fence = %q", f)
+	}
+}
@@ -0,0 +1,224 @@
+package blackboard
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/jsonschema"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSchemaID     string
+	flagSchemaLabel  string
+	flagSchemaFile   string
+	flagSchemaDesc   string
+	flagSchemaOutput string
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage JSON Schemas for stickie structured payloads",
+	Long:  "Declare JSON Schemas that stickie structured payloads must satisfy. The schema labelled '*' applies to every stickie with a payload; a schema for label L applies to stickies carrying L and makes the payload mandatory for them. stickie set, blackboard import and sync reject payloads that do not validate.",
+}
+
+var schemaSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Create or replace the schema for a label",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagSchemaID) == "" || strings.TrimSpace(flagSchemaFile) == "" {
+			return errors.New("--id and --file are required")
+		}
+		raw, err := os.ReadFile(flagSchemaFile)
+		if err != nil {
+			return err
+		}
+		if _, err := jsonschema.Compile(raw); err != nil {
+			return fmt.Errorf("%s: %w", flagSchemaFile, err)
+		}
+		return withSchemaDB(func(ctx context.Context, db *pgxpool.Pool) error {
+			s := &pgdao.BlackboardSchema{BlackboardID: strings.TrimSpace(flagSchemaID), Label: strings.TrimSpace(flagSchemaLabel), Schema: raw}
+			if strings.TrimSpace(flagSchemaDesc) != "" {
+				s.Description = sql.NullString{String: flagSchemaDesc, Valid: true}
+			}
+			if err := pgdao.UpsertBlackboardSchema(ctx, db, s); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "schema upserted blackboard=%s label=%s\n", s.BlackboardID, s.Label)
+			return encodeJSON(map[string]any{"status": "upserted", "blackboard_id": s.BlackboardID, "label": s.Label, "updated": s.Updated.Time.Format(time.RFC3339Nano)})
+		})
+	},
+}
+
+var schemaListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the schemas of a blackboard",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagSchemaID) == "" {
+			return errors.New("--id is required")
+		}
+		return withSchemaDB(func(ctx context.Context, db *pgxpool.Pool) error {
+			rows, err := pgdao.ListBlackboardSchemas(ctx, db, strings.TrimSpace(flagSchemaID))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "schemas: %d\n", len(rows))
+			if strings.EqualFold(strings.TrimSpace(flagSchemaOutput), "json") {
+				arr := make([]map[string]any, 0, len(rows))
+				for _, r := range rows {
+					item := map[string]any{"label": r.Label, "schema": json.RawMessage(r.Schema), "updated": r.Updated.Time.Format(time.RFC3339Nano)}
+					if r.Description.Valid {
+						item["description"] = r.Description.String
+					}
+					arr = append(arr, item)
+				}
+				return encodeJSON(arr)
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"LABEL", "DESCRIPTION", "UPDATED", "SIZE"})
+			for _, r := range rows {
+				table.Append([]string{r.Label, r.Description.String, r.Updated.Time.Format(time.RFC3339), fmt.Sprintf("%d", len(r.Schema))})
+			}
+			table.Render()
+			return nil
+		})
+	},
+}
+
+var schemaDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the schema for a label",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagSchemaID) == "" {
+			return errors.New("--id is required")
+		}
+		label := strings.TrimSpace(flagSchemaLabel)
+		if label == "" {
+			label = pgdao.SchemaLabelAll
+		}
+		return withSchemaDB(func(ctx context.Context, db *pgxpool.Pool) error {
+			n, err := pgdao.DeleteBlackboardSchema(ctx, db, strings.TrimSpace(flagSchemaID), label)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return fmt.Errorf("no schema for label %q on blackboard %s", label, flagSchemaID)
+			}
+			fmt.Fprintf(os.Stderr, "schema deleted blackboard=%s label=%s\n", flagSchemaID, label)
+			return encodeJSON(map[string]any{"status": "deleted", "blackboard_id": flagSchemaID, "label": label})
+		})
+	},
+}
+
+var schemaCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the existing stickies of a blackboard against its schemas",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagSchemaID) == "" {
+			return errors.New("--id is required")
+		}
+		return withSchemaDB(func(ctx context.Context, db *pgxpool.Pool) error {
+			id := strings.TrimSpace(flagSchemaID)
+			set, err := stickieschema.Load(ctx, db, id)
+			if err != nil {
+				return err
+			}
+			type failure struct {
+				ID         string                 `json:"id"`
+				Name       string                 `json:"name,omitempty"`
+				Violations []jsonschema.Violation `json:"violations"`
+			}
+			var failures []failure
+			checked := 0
+			const page = 500
+			for off := 0; ; off += page {
+				ss, err := pgdao.ListStickies(ctx, db, id, page, off)
+				if err != nil {
+					return err
+				}
+				for _, s := range ss {
+					if s.Archived {
+						continue
+					}
+					checked++
+					err := set.Check("stickie "+s.ID, s.Labels, s.Structured)
+					var ve *jsonschema.ValidationError
+					if errors.As(err, &ve) {
+						failures = append(failures, failure{ID: s.ID, Name: s.Name.String, Violations: ve.Violations})
+					} else if err != nil {
+						return err
+					}
+				}
+				if len(ss) < page {
+					break
+				}
+			}
+			fmt.Fprintf(os.Stderr, "schema check blackboard=%s stickies=%d invalid=%d\n", id, checked, len(failures))
+			if strings.EqualFold(strings.TrimSpace(flagSchemaOutput), "json") {
+				if failures == nil {
+					failures = []failure{}
+				}
+				if err := encodeJSON(failures); err != nil {
+					return err
+				}
+			} else {
+				for _, f := range failures {
+					fmt.Fprintf(os.Stdout, "stickie %s %s\n", f.ID, f.Name)
+					for _, v := range f.Violations {
+						fmt.Fprintf(os.Stdout, "  %s\n", v)
+					}
+				}
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("%d stickies do not match the blackboard schemas", len(failures))
+			}
+			return nil
+		})
+	},
+}
+
+func withSchemaDB(fn func(ctx context.Context, db *pgxpool.Pool) error) error {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(ctx, db)
+}
+
+func encodeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func init() {
+	BlackboardCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaSetCmd, schemaListCmd, schemaDeleteCmd, schemaCheckCmd)
+	schemaCmd.PersistentFlags().StringVar(&flagSchemaID, "id", "", "Blackboard UUID (required)")
+	for _, c := range []*cobra.Command{schemaSetCmd, schemaDeleteCmd} {
+		c.Flags().StringVar(&flagSchemaLabel, "label", pgdao.SchemaLabelAll, "Stickie label the schema applies to ('*' for all stickies)")
+	}
+	schemaSetCmd.Flags().StringVar(&flagSchemaFile, "file", "", "JSON Schema file (required)")
+	schemaSetCmd.Flags().StringVar(&flagSchemaDesc, "description", "", "Plain text description")
+	for _, c := range []*cobra.Command{schemaListCmd, schemaCheckCmd} {
+		c.Flags().StringVar(&flagSchemaOutput, "output", "table", "Output format: table or json")
+	}
+}
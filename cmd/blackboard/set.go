@@ -28,6 +28,8 @@ var (
 	flagBBGuidelines   string
 	flagBBLifecycle    string
 	flagBBCliInputYAML bool
+	flagBBArchived     bool
+	flagBBIfMatch      string
 )
 
 var setCmd = &cobra.Command{
@@ -107,6 +109,13 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(role) == "" {
 			return errors.New("--role is required (provide flag or in YAML)")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagBBIfMatch)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.IsZero() && strings.TrimSpace(id) == "" {
+			return errors.New("--if-match requires --id")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -119,7 +128,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		b := &pgdao.Blackboard{ID: id, RoleName: role}
+		b := &pgdao.Blackboard{ID: id, RoleName: role, IfMatch: ifMatch}
 		if convID != "" {
 			b.ConversationID = sql.NullString{String: convID, Valid: true}
 		}
@@ -142,12 +151,21 @@ var setCmd = &cobra.Command{
 		if err := pgdao.UpsertBlackboard(ctx, db, b); err != nil {
 			return err
 		}
+		if cmd.Flags().Changed("archived") {
+			if _, err := pgdao.SetBlackboardArchived(ctx, db, b.ID, flagBBArchived); err != nil {
+				return err
+			}
+			b.Archived = flagBBArchived
+		}
 
 		fmt.Fprintf(os.Stderr, "blackboard upserted id=%s role=%q\n", b.ID, b.RoleName)
 		out := map[string]any{"status": "upserted", "id": b.ID, "role": b.RoleName}
 		if b.Lifecycle.Valid {
 			out["lifecycle"] = b.Lifecycle.String
 		}
+		if cmd.Flags().Changed("archived") {
+			out["archived"] = b.Archived
+		}
 		if b.Created.Valid {
 			out["created"] = b.Created.Time.Format(time.RFC3339Nano)
 		}
@@ -170,5 +188,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagBBBackground, "background", "", "Background text")
 	setCmd.Flags().StringVar(&flagBBGuidelines, "guidelines", "", "Guidelines text")
 	setCmd.Flags().StringVar(&flagBBLifecycle, "lifecycle", "", "Lifecycle: permanent|yearly|quarterly|monthly|weekly|daily")
+	setCmd.Flags().BoolVar(&flagBBArchived, "archived", false, "Archive (or with =false, unarchive) the blackboard")
 	setCmd.Flags().BoolVar(&flagBBCliInputYAML, "cli-input-yaml", false, "Read blackboard.yaml from stdin to set fields; flags override YAML")
+	setCmd.Flags().StringVar(&flagBBIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
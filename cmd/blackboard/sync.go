@@ -2,21 +2,19 @@ package blackboard
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
-	"unicode"
 
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
 	"github.com/spf13/cobra"
 	yaml "gopkg.in/yaml.v3"
 )
@@ -27,51 +25,61 @@ var (
 	flagSyncClearIDs        bool
 	flagSyncForceWrite      bool
 	flagSyncIncludeArchived bool
+	flagSyncMerge           bool
+	flagSyncConflictFiles   bool
 )
 
-// syncCmd implements: rbc blackboard sync id:UUID folder:relative/path
-// For now only supports id -> folder direction.
+// syncCmd implements: rbc blackboard sync id:UUID folder:relative/path (or reverse).
+// With --merge the direction is irrelevant: both sides are reconciled against the
+// base recorded in the folder's .rbc-sync.yaml.
 var syncCmd = &cobra.Command{
 	Use:   "sync <source> <target>",
 	Short: "Sync a blackboard and stickies between id and folder",
-	Long:  "Sync a blackboard and its stickies between an id:UUID and a folder:RELATIVE_PATH. Supports id->folder and folder->id.",
+	Long:  "Sync a blackboard and its stickies between an id:UUID and a folder:RELATIVE_PATH. Supports id->folder and folder->id, or a three-way --merge of both sides. folder->id only updates stickies still at the edit_count recorded in their file; folder->id and --merge exit with status 3 on conflicts.",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		src, err := parseEndpoint(args[0])
+		src, err := syncfs.ParseEndpoint(args[0])
 		if err != nil {
 			return err
 		}
-		dst, err := parseEndpoint(args[1])
+		dst, err := syncfs.ParseEndpoint(args[1])
 		if err != nil {
 			return err
 		}
 		// Disallow same-kind syncs explicitly for clarity
-		if src.kind == dst.kind {
-			return fmt.Errorf("cannot sync %s -> %s; use id:UUID->folder:PATH or folder:PATH->id:UUID", kindString(src.kind), kindString(dst.kind))
+		if src.Kind == dst.Kind {
+			return fmt.Errorf("cannot sync %s -> %s; use id:UUID->folder:PATH or folder:PATH->id:UUID", src.Kind, dst.Kind)
 		}
 
 		// Shortcut: allow id:_ to read id from the folder's blackboard.yaml
-		if src.kind == epID && src.value == "_" && dst.kind == epFolder {
-			bbid, e := readBlackboardIDFromFolder(dst.value)
+		if src.Kind == syncfs.KindID && src.Value == "_" && dst.Kind == syncfs.KindFolder {
+			bbid, e := readBlackboardIDFromFolder(dst.Value)
 			if e != nil {
 				return e
 			}
-			src.value = bbid
+			src.Value = bbid
 		}
 		// Shortcut: allow id:_ to read id from the folder's blackboard.yaml
-		if src.kind == epFolder && dst.kind == epID && dst.value == "_" {
-			bbid, e := readBlackboardIDFromFolder(src.value)
+		if src.Kind == syncfs.KindFolder && dst.Kind == syncfs.KindID && dst.Value == "_" {
+			bbid, e := readBlackboardIDFromFolder(src.Value)
 			if e != nil {
 				return e
 			}
-			dst.value = bbid
+			dst.Value = bbid
 		}
 
-		if src.kind == epID && dst.kind == epFolder {
-			return syncIDToFolder(src.value, dst.value, flagSyncDelete, flagSyncDryRun)
+		if flagSyncMerge {
+			id, folder := src, dst
+			if src.Kind == syncfs.KindFolder {
+				id, folder = dst, src
+			}
+			return syncMerge(id.Value, folder.Value, flagSyncDelete, flagSyncDryRun, flagSyncConflictFiles)
+		}
+		if src.Kind == syncfs.KindID && dst.Kind == syncfs.KindFolder {
+			return syncIDToFolder(src.Value, dst.Value, flagSyncDelete, flagSyncDryRun)
 		}
-		if src.kind == epFolder && dst.kind == epID {
-			return syncFolderToID(src.value, dst.value, flagSyncDryRun)
+		if src.Kind == syncfs.KindFolder && dst.Kind == syncfs.KindID {
+			return syncFolderToID(src.Value, dst.Value, flagSyncDryRun)
 		}
 
 		return errors.New("supported directions: id:UUID->folder:PATH and folder:PATH->id:UUID")
@@ -85,76 +93,16 @@ func init() {
 	syncCmd.Flags().BoolVar(&flagSyncClearIDs, "clear-ids", false, "When exporting id->folder, omit id fields in stickie YAML files")
 	syncCmd.Flags().BoolVar(&flagSyncForceWrite, "force-write", false, "Force rewrite files even if destination appears up-to-date")
 	syncCmd.Flags().BoolVar(&flagSyncIncludeArchived, "include-archived", false, "Include archived stickies when syncing id->folder (default: active only)")
+	syncCmd.Flags().BoolVar(&flagSyncMerge, "merge", false, "Three-way merge id and folder against the last synced base (.rbc-sync.yaml)")
+	syncCmd.Flags().BoolVar(&flagSyncConflictFiles, "conflict-files", false, "With --merge, write <name>.conflict.yaml (base/remote/local) for each conflict")
 }
 
-type endpointKind int
-
-const (
-	epUnknown endpointKind = iota
-	epID
-	epFolder
+// Local YAML structures; block scalar styles are shared with other folder syncs.
+type (
+	FoldedString  = syncfs.FoldedString
+	LiteralString = syncfs.LiteralString
 )
 
-type endpoint struct {
-	kind  endpointKind
-	value string
-}
-
-func kindString(k endpointKind) string {
-	switch k {
-	case epID:
-		return "id"
-	case epFolder:
-		return "folder"
-	default:
-		return "unknown"
-	}
-}
-
-func parseEndpoint(s string) (endpoint, error) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return endpoint{}, errors.New("empty endpoint")
-	}
-	if strings.HasPrefix(s, "id:") {
-		v := strings.TrimSpace(strings.TrimPrefix(s, "id:"))
-		if v == "" {
-			return endpoint{}, errors.New("id endpoint missing UUID")
-		}
-		return endpoint{kind: epID, value: v}, nil
-	}
-	if strings.HasPrefix(s, "folder:") {
-		v := strings.TrimSpace(strings.TrimPrefix(s, "folder:"))
-		if v == "" {
-			return endpoint{}, errors.New("folder endpoint missing path")
-		}
-		if filepath.IsAbs(v) {
-			return endpoint{}, errors.New("folder path must be relative")
-		}
-		return endpoint{kind: epFolder, value: v}, nil
-	}
-	return endpoint{}, fmt.Errorf("unsupported endpoint %q (use id:<uuid> or folder:<path>)", s)
-}
-
-// Local YAML structures
-// YAML scalar helpers
-// FoldedString renders as a YAML folded block scalar (>), suitable for prose paragraphs.
-type FoldedString string
-
-func (s FoldedString) MarshalYAML() (any, error) {
-	n := yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.FoldedStyle, Value: string(s)}
-	return &n, nil
-}
-
-// LiteralString renders as a YAML literal block scalar (|), preserving newlines exactly.
-// Available for stickies if needed (e.g., multi-line code blocks).
-type LiteralString string
-
-func (s LiteralString) MarshalYAML() (any, error) {
-	n := yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.LiteralStyle, Value: string(s)}
-	return &n, nil
-}
-
 // blackboardYAML controls YAML output for blackboard metadata.
 type blackboardYAML struct {
 	ID           string         `yaml:"id"`
@@ -167,6 +115,9 @@ type blackboardYAML struct {
 	Lifecycle    *string        `yaml:"lifecycle,omitempty"`
 	Created      *string        `yaml:"created,omitempty"`
 	Updated      *string        `yaml:"updated,omitempty"`
+	// StickieSchemas maps a stickie label ("*" for all) to a JSON Schema for
+	// the structured payload.
+	StickieSchemas map[string]any `yaml:"stickie_schemas,omitempty"`
 }
 
 type stickieYAML struct {
@@ -182,25 +133,16 @@ type stickieYAML struct {
 	Score         *float64       `yaml:"score,omitempty"`
 	Name          *string        `yaml:"name,omitempty"`
 	Archived      bool           `yaml:"archived"`
+	Structured    any            `yaml:"structured,omitempty"`
 }
 
-type minimalUpdatedYAML struct {
-	Updated *string `yaml:"updated"`
-}
-
+// syncIDToFolder mirrors the blackboard into the folder and records the pulled
+// stickies as the merge base of later --merge runs.
 func syncIDToFolder(blackboardID, relFolder string, allowDelete, dryRun bool) error {
-	// Prepare destination directory
-	destDir := filepath.Clean(relFolder)
-	if strings.HasPrefix(destDir, "..") {
-		return errors.New("folder path must not escape current directory")
-	}
-	if !dryRun {
-		if err := os.MkdirAll(destDir, 0o755); err != nil {
-			return fmt.Errorf("create dest folder: %w", err)
-		}
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
 	}
-
-	// Open DB
 	cfg, err := cfgpkg.Load()
 	if err != nil {
 		return err
@@ -212,279 +154,38 @@ func syncIDToFolder(blackboardID, relFolder string, allowDelete, dryRun bool) er
 		return err
 	}
 	defer db.Close()
-
-	// Get blackboard
-	b, err := pgdao.GetBlackboardByID(ctx, db, blackboardID)
-	if err != nil {
+	if _, err := pgdao.GetBlackboardByID(ctx, db, blackboardID); err != nil {
 		return err
 	}
 
-	// Prepare YAML for blackboard
-	by := blackboardYAML{
-		ID:   b.ID,
-		Role: b.RoleName,
-	}
-	if b.ConversationID.Valid && b.ConversationID.String != "" {
-		v := b.ConversationID.String
-		by.Conversation = &v
-	}
-	if b.ProjectName.Valid && b.ProjectName.String != "" {
-		v := b.ProjectName.String
-		by.Project = &v
-	}
-	if b.TaskID.Valid && b.TaskID.String != "" {
-		v := b.TaskID.String
-		by.TaskID = &v
-	}
-	if b.Background.Valid && strings.TrimSpace(b.Background.String) != "" {
-		v := LiteralString(wrapAt(b.Background.String, 80))
-		by.Background = &v
-	}
-	if b.Guidelines.Valid && strings.TrimSpace(b.Guidelines.String) != "" {
-		v := LiteralString(wrapAt(b.Guidelines.String, 80))
-		by.Guidelines = &v
-	}
-	if b.Lifecycle.Valid && b.Lifecycle.String != "" {
-		v := b.Lifecycle.String
-		by.Lifecycle = &v
-	}
-	if b.Created.Valid {
-		v := b.Created.Time.Format(time.RFC3339Nano)
-		by.Created = &v
-	}
-	if b.Updated.Valid {
-		v := b.Updated.Time.Format(time.RFC3339Nano)
-		by.Updated = &v
-	}
-
-	// Write blackboard.yaml if needed
-	bbFile := filepath.Join(destDir, "blackboard.yaml")
-	bbWrite := true
-	if !flagSyncForceWrite {
-		if fi, err := os.Stat(bbFile); err == nil && fi.Mode().IsRegular() {
-			// compare updated timestamps
-			if newerOrEqual, err := destIsNewerOrEqual(bbFile, by.Updated); err == nil && newerOrEqual {
-				bbWrite = false
-			}
-		}
-	}
-	if bbWrite {
-		if dryRun {
-			fmt.Fprintf(os.Stderr, "[dry-run] write %s\n", bbFile)
-		} else {
-			if err := writeYAML(bbFile, by); err != nil {
-				return err
-			}
-			fmt.Fprintf(os.Stderr, "wrote %s\n", bbFile)
-		}
-	} else {
-		fmt.Fprintf(os.Stderr, "skip up-to-date %s\n", bbFile)
-	}
-
-	// Fetch stickies for the board (paged)
-	stickies := make([]pgdao.Stickie, 0, 256)
-	const page = 1000
-	for off := 0; ; off += page {
-		ss, err := pgdao.ListStickies(ctx, db, b.ID, page, off)
-		if err != nil {
-			return err
-		}
-		stickies = append(stickies, ss...)
-		if len(ss) < page {
-			break
-		}
-	}
-
-	// Track seen stickie filenames to support --delete
-	seen := make(map[string]struct{}, len(stickies))
-
-	// Write each stickie YAML if newer
-	for _, s := range stickies {
-		// Skip archived unless explicitly included
-		if !flagSyncIncludeArchived && s.Archived {
-			continue
-		}
-		sy := stickieYAML{
-			ID:        s.ID,
-			Labels:    s.Labels,
-			EditCount: s.EditCount,
-			Archived:  s.Archived,
-		}
-		if flagSyncClearIDs {
-			sy.ID = ""
-		}
-		// topics removed; use labels instead
-		if s.Note.Valid && strings.TrimSpace(s.Note.String) != "" {
-			v := LiteralString(wrapAt(s.Note.String, 80))
-			sy.Note = &v
-		}
-		if s.Code.Valid && s.Code.String != "" {
-			v := s.Code.String
-			sy.Code = &v
-		}
-		if s.Created.Valid {
-			v := s.Created.Time.Format(time.RFC3339Nano)
-			sy.Created = &v
-		}
-		if s.Updated.Valid {
-			v := s.Updated.Time.Format(time.RFC3339Nano)
-			sy.Updated = &v
-		}
-		if s.CreatedByTaskID.Valid && s.CreatedByTaskID.String != "" {
-			v := s.CreatedByTaskID.String
-			sy.CreatedByTask = &v
-		}
-		if s.PriorityLevel.Valid && s.PriorityLevel.String != "" {
-			v := s.PriorityLevel.String
-			sy.Priority = &v
-		}
-		if s.Score.Valid {
-			v := s.Score.Float64
-			sy.Score = &v
-		}
-		if s.Name.Valid && strings.TrimSpace(s.Name.String) != "" {
-			v := s.Name.String
-			sy.Name = &v
-		}
-
-		fn := filepath.Join(destDir, stickieFileName(s))
-		seen[filepath.Base(fn)] = struct{}{}
-		write := true
-		if !flagSyncForceWrite {
-			if fi, err := os.Stat(fn); err == nil && fi.Mode().IsRegular() {
-				if newerOrEqual, err := destIsNewerOrEqual(fn, sy.Updated); err == nil && newerOrEqual {
-					write = false
-				}
-			}
-		}
-		if write {
-			if dryRun {
-				fmt.Fprintf(os.Stderr, "[dry-run] write %s\n", fn)
-			} else {
-				if err := writeYAML(fn, sy); err != nil {
-					return err
-				}
-				fmt.Fprintf(os.Stderr, "wrote %s\n", fn)
-			}
-		} else {
-			fmt.Fprintf(os.Stderr, "skip up-to-date %s\n", fn)
-		}
-	}
-
-	// Handle delete: any *.stickie.yaml in dest not in seen
-	if allowDelete {
-		entries, _ := os.ReadDir(destDir)
-		// Collect and sort for stable output
-		var toDelete []string
-		for _, e := range entries {
-			name := e.Name()
-			if e.Type().IsRegular() && strings.HasSuffix(name, ".stickie.yaml") {
-				if _, ok := seen[name]; !ok {
-					toDelete = append(toDelete, filepath.Join(destDir, name))
-				}
-			}
-		}
-		sort.Strings(toDelete)
-		for _, p := range toDelete {
-			if dryRun {
-				fmt.Fprintf(os.Stderr, "[dry-run] delete %s\n", p)
-			} else {
-				if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
-					return err
-				}
-				fmt.Fprintf(os.Stderr, "deleted %s\n", p)
-			}
-		}
-	}
-
-	return nil
-}
-
-// wrapAt inserts newlines so that lines are at most width runes, breaking at spaces.
-// Existing newlines are preserved; multiple spaces are treated as breakable.
-func wrapAt(s string, width int) string {
-	if width <= 0 {
-		return s
-	}
-	// Normalize line endings and split existing lines first
-	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
-	var out []string
-	for _, line := range lines {
-		words := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })
-		if len(words) == 0 {
-			out = append(out, "")
-			continue
-		}
-		cur := words[0]
-		for _, w := range words[1:] {
-			if len([]rune(cur))+1+len([]rune(w)) <= width {
-				cur += " " + w
-			} else {
-				out = append(out, cur)
-				cur = w
-			}
-		}
-		out = append(out, cur)
-	}
-	return strings.Join(out, "\n")
-}
-
-func writeYAML(path string, v any) error {
-	b, err := yaml.Marshal(v)
+	a := &blackboardAdapter{db: db, id: blackboardID, includeArchived: flagSyncIncludeArchived, clearIDs: flagSyncClearIDs}
+	sum, err := syncfs.Pull(ctx, a, dir, syncfs.Options{Delete: allowDelete, DryRun: dryRun, ForceWrite: flagSyncForceWrite})
 	if err != nil {
 		return err
 	}
-	// Write atomically by temp + rename to reduce partial writes
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, b, 0o644); err != nil {
-		return err
+	fmt.Fprintf(os.Stderr, "blackboard sync id->folder id=%s folder=%s written=%d deleted=%d unchanged=%d\n", blackboardID, dir, sum.Written, sum.Deleted, sum.Unchanged)
+	if dryRun {
+		return nil
 	}
-	return os.Rename(tmp, path)
-}
-
-// destIsNewerOrEqual returns true if destination file's updated >= srcUpdated.
-func destIsNewerOrEqual(path string, srcUpdated *string) (bool, error) {
-	if srcUpdated == nil || *srcUpdated == "" {
-		// No source timestamp; always write
-		return false, nil
-	}
-	b, err := os.ReadFile(path)
+	state, err := loadSyncState(dir, blackboardID)
 	if err != nil {
-		return false, err
-	}
-	var m minimalUpdatedYAML
-	if err := yaml.Unmarshal(b, &m); err != nil {
-		return false, nil // on error, prefer writing
-	}
-	if m.Updated == nil || *m.Updated == "" {
-		return false, nil
-	}
-	// Parse timestamps
-	srcT, err := time.Parse(time.RFC3339Nano, *srcUpdated)
-	if err != nil {
-		return false, nil
+		return err
 	}
-	dstT, err := time.Parse(time.RFC3339Nano, *m.Updated)
-	if err != nil {
-		// Try RFC3339 without nanos as fallback
-		if dstT2, e2 := time.Parse(time.RFC3339, *m.Updated); e2 == nil {
-			dstT = dstT2
-		} else {
-			return false, nil
-		}
+	for id, e := range syncedStickies(a.remote, nil) {
+		state.Stickies[id] = e
 	}
-	return !dstT.Before(srcT), nil
+	return saveSyncState(dir, state)
 }
 
-// ---- folder -> id implementation ----
-
+// syncFolderToID applies the folder to the blackboard; it never deletes stickies.
+// The whole folder is validated before anything is written; stickies changed in
+// the DB since their file was exported are reported as conflicts and keep their
+// merge base.
 func syncFolderToID(relFolder, blackboardID string, dryRun bool) error {
-	// Validate folder
-	srcDir := filepath.Clean(relFolder)
-	if strings.HasPrefix(srcDir, "..") {
-		return errors.New("folder path must not escape current directory")
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
 	}
-	// Open DB
 	cfg, err := cfgpkg.Load()
 	if err != nil {
 		return err
@@ -496,77 +197,68 @@ func syncFolderToID(relFolder, blackboardID string, dryRun bool) error {
 		return err
 	}
 	defer db.Close()
+	if _, err := pgdao.GetBlackboardByID(ctx, db, blackboardID); err != nil {
+		return err
+	}
 
-	// Ensure blackboard exists
-	b, err := pgdao.GetBlackboardByID(ctx, db, blackboardID)
+	a := &blackboardAdapter{db: db, id: blackboardID, includeArchived: true, dir: dir}
+	local, err := a.LoadLocal(dir)
 	if err != nil {
 		return err
 	}
-	_ = b // currently unused beyond existence
-
-	// Iterate stickie files
-	entries, err := os.ReadDir(srcDir)
+	set, err := a.schemaSet(ctx, local)
 	if err != nil {
-		return fmt.Errorf("read folder: %w", err)
+		return err
 	}
-	// Stable order
-	var files []string
-	for _, e := range entries {
-		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".stickie.yaml") {
-			files = append(files, filepath.Join(srcDir, e.Name()))
-		}
+	if err := checkStickieRecords(set, local); err != nil {
+		return err
+	}
+	sum, err := syncfs.Push(ctx, a, dir, syncfs.Options{DryRun: dryRun})
+	var conflict *syncfs.ConflictError
+	if err != nil && !errors.As(err, &conflict) {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "blackboard sync folder->id id=%s folder=%s applied=%d unchanged=%d conflicts=%d\n", blackboardID, dir, sum.Applied, sum.Unchanged, sum.Conflicts)
+	if dryRun {
+		return err
+	}
+	state, serr := loadSyncState(dir, blackboardID)
+	if serr != nil {
+		return serr
+	}
+	for id, e := range syncedStickies(a.local, a.conflicts) {
+		state.Stickies[id] = e
+	}
+	if serr := saveSyncState(dir, state); serr != nil {
+		return serr
 	}
-	sort.Strings(files)
+	return err
+}
+
+// checkStickieYAML validates one parsed stickie file; archived ones are exempt.
+func checkStickieYAML(set *stickieschema.Set, subject string, y stickieYAML) error {
+	if y.Archived {
+		return nil
+	}
+	b, err := structuredJSON(y.Structured)
+	if err != nil {
+		return fmt.Errorf("%s: %w", subject, err)
+	}
+	return set.Check(subject, y.Labels, b)
+}
 
-	for _, p := range files {
-		y, err := readStickieYAML(p)
+// schemasFromYAML converts the stickie_schemas of blackboard.yaml into raw
+// JSON documents keyed by label.
+func schemasFromYAML(m map[string]any) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(m))
+	for label, v := range m {
+		b, err := json.Marshal(v)
 		if err != nil {
-			return fmt.Errorf("read %s: %w", p, err)
-		}
-		// Always target destination blackboard; ignore/override yaml's blackboard_id
-		// Security rule: if yaml has an id, it MUST already exist for this blackboard
-		if strings.TrimSpace(y.ID) != "" {
-			s, err := pgdao.GetStickieByID(ctx, db, y.ID)
-			if err != nil {
-				return fmt.Errorf("stickie %s referenced in %s does not exist: %w", y.ID, p, err)
-			}
-			if strings.TrimSpace(s.BlackboardID) != strings.TrimSpace(blackboardID) {
-				return fmt.Errorf("security: stickie %s in %s does not belong to blackboard %s", y.ID, p, blackboardID)
-			}
-			// Decide update based on content hash
-			hSrc := hashStickieYAML(y)
-			hDst := hashStickieDB(*s)
-			if hSrc == hDst {
-				fmt.Fprintf(os.Stderr, "skip unchanged %s (id=%s)\n", p, y.ID)
-				continue
-			}
-			if dryRun {
-				fmt.Fprintf(os.Stderr, "[dry-run] update stickie id=%s from %s\n", y.ID, p)
-				continue
-			}
-			// Perform update
-			upd := stickieFromYAMLForUpsert(y, blackboardID)
-			upd.ID = y.ID
-			if err := pgdao.UpsertStickie(ctx, db, &upd); err != nil {
-				return fmt.Errorf("update stickie %s from %s: %w", y.ID, p, err)
-			}
-			fmt.Fprintf(os.Stderr, "updated stickie id=%s\n", y.ID)
-		} else {
-			// Create new stickie (no id assigned in yaml) -> assign UUID automatically
-			if dryRun {
-				fmt.Fprintf(os.Stderr, "[dry-run] create stickie from %s\n", p)
-				continue
-			}
-			ins := stickieFromYAMLForUpsert(y, blackboardID)
-			ins.ID = "" // ensure create
-			if err := pgdao.UpsertStickie(ctx, db, &ins); err != nil {
-				return fmt.Errorf("create stickie from %s: %w", p, err)
-			}
-			fmt.Fprintf(os.Stderr, "created stickie id=%s from %s\n", ins.ID, p)
+			return nil, fmt.Errorf("stickie_schemas[%s]: %w", label, err)
 		}
+		out[label] = b
 	}
-
-	return nil
+	return out, nil
 }
 
 func readStickieYAML(path string) (stickieYAML, error) {
@@ -581,6 +273,55 @@ func readStickieYAML(path string) (stickieYAML, error) {
 	return y, nil
 }
 
+// stickieToYAML maps a DB stickie into the exported YAML shape (notes wrapped at 80).
+func stickieToYAML(s pgdao.Stickie) stickieYAML {
+	sy := stickieYAML{
+		ID:        s.ID,
+		Labels:    s.Labels,
+		EditCount: s.EditCount,
+		Archived:  s.Archived,
+	}
+	if s.Note.Valid && strings.TrimSpace(s.Note.String) != "" {
+		v := LiteralString(syncfs.WrapAt(s.Note.String, 80))
+		sy.Note = &v
+	}
+	if s.Code.Valid && s.Code.String != "" {
+		v := s.Code.String
+		sy.Code = &v
+	}
+	if s.Created.Valid {
+		v := s.Created.Time.Format(time.RFC3339Nano)
+		sy.Created = &v
+	}
+	if s.Updated.Valid {
+		v := s.Updated.Time.Format(time.RFC3339Nano)
+		sy.Updated = &v
+	}
+	if s.CreatedByTaskID.Valid && s.CreatedByTaskID.String != "" {
+		v := s.CreatedByTaskID.String
+		sy.CreatedByTask = &v
+	}
+	if s.PriorityLevel.Valid && s.PriorityLevel.String != "" {
+		v := s.PriorityLevel.String
+		sy.Priority = &v
+	}
+	if s.Score.Valid {
+		v := s.Score.Float64
+		sy.Score = &v
+	}
+	if s.Name.Valid && strings.TrimSpace(s.Name.String) != "" {
+		v := s.Name.String
+		sy.Name = &v
+	}
+	if len(s.Structured) > 0 {
+		var v any
+		if err := json.Unmarshal(s.Structured, &v); err == nil {
+			sy.Structured = v
+		}
+	}
+	return sy
+}
+
 // stickieFromYAMLForUpsert maps YAML into pgdao.Stickie for UpsertStickie.
 func stickieFromYAMLForUpsert(y stickieYAML, blackboardID string) pgdao.Stickie {
 	var s pgdao.Stickie
@@ -617,28 +358,62 @@ func stickieFromYAMLForUpsert(y stickieYAML, blackboardID string) pgdao.Stickie
 		s.Name.Valid = true
 		s.Name.String = *y.Name
 	}
+	// Payloads are checked by checkStickieRecords before any write
+	s.Structured, _ = structuredJSON(y.Structured)
 	s.Archived = y.Archived
 	return s
 }
 
-// Hash utilities (SHA-256) for stickie content comparison
+// structuredJSON encodes a YAML structured payload as JSON (nil when absent).
+func structuredJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("structured: %w", err)
+	}
+	return b, nil
+}
+
+// canonicalJSON re-encodes a JSON document so that jsonb and YAML round trips
+// compare equal (sorted keys, normalized numbers).
+func canonicalJSON(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+// stickieHashMaterial is the content a stickie is compared by; the same material
+// is stored (with its SHA-256) as the merge base in the folder's sync state.
 type stickieHashMaterial struct {
-	Note          string   `json:"note"`
-	Code          string   `json:"code"`
-	Labels        []string `json:"labels"`
-	CreatedByTask string   `json:"created_by_task_id"`
-	PriorityLevel string   `json:"priority_level"`
-	Score         *float64 `json:"score,omitempty"`
-	Name          string   `json:"name"`
-	Archived      bool     `json:"archived"`
+	Note          string   `json:"note" yaml:"note,omitempty"`
+	Code          string   `json:"code" yaml:"code,omitempty"`
+	Labels        []string `json:"labels" yaml:"labels,omitempty"`
+	CreatedByTask string   `json:"created_by_task_id" yaml:"created_by_task_id,omitempty"`
+	PriorityLevel string   `json:"priority_level" yaml:"priority_level,omitempty"`
+	Score         *float64 `json:"score,omitempty" yaml:"score,omitempty"`
+	Name          string   `json:"name" yaml:"name,omitempty"`
+	Archived      bool     `json:"archived" yaml:"archived"`
+	Structured    string   `json:"structured,omitempty" yaml:"structured,omitempty"`
 }
 
-func hashStickieYAML(y stickieYAML) string {
+// stickieMaterialYAML normalizes a stickie file into comparable content.
+func stickieMaterialYAML(y stickieYAML) stickieHashMaterial {
 	mat := stickieHashMaterial{}
 	// topics removed
 	if y.Note != nil {
 		// Normalize to match exporter behavior (wrap at 80)
-		mat.Note = wrapAt(string(*y.Note), 80)
+		mat.Note = syncfs.WrapAt(string(*y.Note), 80)
 	}
 	if y.Code != nil {
 		mat.Code = *y.Code
@@ -660,15 +435,19 @@ func hashStickieYAML(y stickieYAML) string {
 	if y.Name != nil {
 		mat.Name = *y.Name
 	}
+	if b, err := structuredJSON(y.Structured); err == nil {
+		mat.Structured = canonicalJSON(b)
+	}
 	mat.Archived = y.Archived
-	return hashMaterial(mat)
+	return mat
 }
 
-func hashStickieDB(s pgdao.Stickie) string {
+// stickieMaterialDB normalizes a DB stickie into comparable content.
+func stickieMaterialDB(s pgdao.Stickie) stickieHashMaterial {
 	mat := stickieHashMaterial{}
 	if s.Note.Valid {
 		// Normalize like exporter which wraps notes at 80 columns
-		mat.Note = wrapAt(s.Note.String, 80)
+		mat.Note = syncfs.WrapAt(s.Note.String, 80)
 	}
 	if s.Code.Valid {
 		mat.Code = s.Code.String
@@ -691,14 +470,9 @@ func hashStickieDB(s pgdao.Stickie) string {
 	if s.Name.Valid {
 		mat.Name = s.Name.String
 	}
+	mat.Structured = canonicalJSON(s.Structured)
 	mat.Archived = s.Archived
-	return hashMaterial(mat)
-}
-
-func hashMaterial(v any) string {
-	b, _ := json.Marshal(v)
-	sum := sha256.Sum256(b)
-	return hex.EncodeToString(sum[:])
+	return mat
 }
 
 // readBlackboardIDFromFolder reads blackboard.yaml in the given relative folder
@@ -735,7 +509,7 @@ func stickieFileName(s pgdao.Stickie) string {
 	if s.Name.Valid {
 		base := strings.TrimSpace(s.Name.String)
 		if base != "" {
-			safe := sanitizeForFile(base)
+			safe := syncfs.SanitizeForFile(base)
 			if safe != "" {
 				return fmt.Sprintf("about-%s.stickie.yaml", safe)
 			}
@@ -743,32 +517,3 @@ func stickieFileName(s pgdao.Stickie) string {
 	}
 	return fmt.Sprintf("%s.stickie.yaml", s.ID)
 }
-
-// sanitizeForFile replaces any rune that is not alphanumeric, '_' or '-' with '-'.
-// It also lowercases the result and collapses consecutive '-' and trims leading/trailing '-'.
-func sanitizeForFile(s string) string {
-	// lowercase
-	s = strings.ToLower(s)
-	// replace invalid runes with '-'
-	var b strings.Builder
-	b.Grow(len(s))
-	prevDash := false
-	for _, r := range s {
-		valid := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
-		if !valid {
-			r = '-'
-		}
-		if r == '-' {
-			if prevDash {
-				continue
-			}
-			prevDash = true
-			b.WriteRune(r)
-			continue
-		}
-		prevDash = false
-		b.WriteRune(r)
-	}
-	out := strings.Trim(b.String(), "-")
-	return out
-}
@@ -6,7 +6,7 @@ import (
 
 var ConversationCmd = &cobra.Command{
 	Use:   "conversation",
-	Short: "TODO: Describe the 'admin conversation' command",
+	Short: "Manage conversations (set, get, list, export, import)",
 }
 
 func init() {
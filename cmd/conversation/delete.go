@@ -29,7 +29,7 @@ var deleteCmd = &cobra.Command{
 			return errors.New("--id is required")
 		}
 		if !flagConvDelForce {
-			fmt.Fprintf(os.Stderr, "About to delete conversation id=%d.\n", flagConvDelID)
+			fmt.Fprintf(os.Stderr, "About to delete conversation id=%s.\n", flagConvDelID)
 			fmt.Fprint(os.Stderr, "Type 'yes' to confirm: ")
 			reader := bufio.NewReader(os.Stdin)
 			line, _ := reader.ReadString('\n')
@@ -54,15 +54,15 @@ var deleteCmd = &cobra.Command{
 		}
 		if affected == 0 {
 			if flagConvDelIgnoreMissing {
-				fmt.Fprintf(os.Stderr, "conversation id=%d not found; ignoring\n", flagConvDelID)
+				fmt.Fprintf(os.Stderr, "conversation id=%s not found; ignoring\n", flagConvDelID)
 				out := map[string]any{"status": "not_found_ignored", "id": flagConvDelID}
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
 				return enc.Encode(out)
 			}
-			return fmt.Errorf("conversation id=%d not found", flagConvDelID)
+			return fmt.Errorf("conversation id=%s not found", flagConvDelID)
 		}
-		fmt.Fprintf(os.Stderr, "conversation deleted id=%d\n", flagConvDelID)
+		fmt.Fprintf(os.Stderr, "conversation deleted id=%s\n", flagConvDelID)
 		out := map[string]any{"status": "deleted", "deleted": true, "id": flagConvDelID}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
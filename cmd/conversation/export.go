@@ -0,0 +1,140 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagConvExportID     string
+	flagConvExportFormat string
+	flagConvExportOut    string
+)
+
+var transcriptWriters = map[string]func(io.Writer, *transcript.Transcript) error{
+	"md":       transcript.WriteMarkdown,
+	"markdown": transcript.WriteMarkdown,
+	"json":     transcript.WriteJSON,
+	"jsonl":    transcript.WriteJSONL,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a conversation transcript as Markdown, JSON or JSONL",
+	Long: `Export a conversation with its experiments and their messages in time order:
+roles, statuses, content and the task behind each task run. Markdown is for
+reading and attaching to tickets; JSONL is the portable form read back by
+'rbc conversation import'. Writes to stdout unless --out is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagConvExportID) == "" {
+			return errors.New("--id is required")
+		}
+		write, ok := transcriptWriters[strings.ToLower(strings.TrimSpace(flagConvExportFormat))]
+		if !ok {
+			return fmt.Errorf("--format must be md, json or jsonl")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		c, err := pgdao.GetConversationByID(ctx, db, flagConvExportID)
+		if err != nil {
+			return err
+		}
+		t := &transcript.Transcript{Format: transcript.Format, ExportedAt: time.Now().UTC(), Conversation: transcriptConversation(c)}
+		const page = 100
+		var exps []pgdao.Experiment
+		for offset := 0; ; offset += page {
+			rows, err := pgdao.ListExperiments(ctx, db, c.ID, page, offset)
+			if err != nil {
+				return err
+			}
+			exps = append(exps, rows...)
+			if len(rows) < page {
+				break
+			}
+		}
+		// Listed newest first; a transcript reads oldest first.
+		for i := len(exps) - 1; i >= 0; i-- {
+			msgs, err := pgdao.ListExperimentTranscript(ctx, db, exps[i].ID)
+			if err != nil {
+				return err
+			}
+			t.Experiments = append(t.Experiments, transcriptExperiment(&exps[i], msgs))
+		}
+		var w io.Writer = os.Stdout
+		if flagConvExportOut != "" && flagConvExportOut != "-" {
+			f, err := os.Create(flagConvExportOut)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := write(w, t); err != nil {
+			return err
+		}
+		ne, nm := t.Counts()
+		fmt.Fprintf(os.Stderr, "exported conversation id=%s experiments=%d messages=%d\n", c.ID, ne, nm)
+		return nil
+	},
+}
+
+func init() {
+	ConversationCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&flagConvExportID, "id", "", "Conversation UUID (required)")
+	exportCmd.Flags().StringVar(&flagConvExportFormat, "format", "md", "Output format: md, json or jsonl")
+	exportCmd.Flags().StringVar(&flagConvExportOut, "out", "", "Write to this file instead of stdout")
+}
+
+func transcriptConversation(c *pgdao.Conversation) transcript.Conversation {
+	out := transcript.Conversation{
+		ID: c.ID, Title: c.Title, Role: c.RoleName, Tags: c.Tags,
+		Description: c.Description.String, Project: c.Project.String, Notes: c.Notes.String,
+	}
+	if c.Created.Valid {
+		t := c.Created.Time
+		out.Created = &t
+	}
+	return out
+}
+
+func transcriptExperiment(e *pgdao.Experiment, msgs []pgdao.TranscriptMessage) transcript.Experiment {
+	out := transcript.Experiment{ID: e.ID, Name: e.Name.String, Status: e.Status, Params: e.Params, Metrics: e.Metrics}
+	if e.Started.Valid {
+		t := e.Started.Time
+		out.Started = &t
+	}
+	if e.Finished.Valid {
+		t := e.Finished.Time
+		out.Finished = &t
+	}
+	for _, m := range msgs {
+		tm := transcript.Message{
+			ID: m.ID, ExperimentID: e.ID, Role: m.RoleName, ReplyTo: m.ParentMessageID.String, ToRole: m.ToRole, Status: m.Status, Error: m.ErrorMessage.String,
+			Tags: m.Tags, Created: m.Created, Text: m.Text, JSON: m.ContentJSON,
+		}
+		if m.FromTaskID.Valid || m.TaskVariant.Valid {
+			tm.Task = &transcript.TaskRef{ID: m.FromTaskID.String, Variant: m.TaskVariant.String}
+		}
+		out.Messages = append(out.Messages, tm)
+	}
+	return out
+}
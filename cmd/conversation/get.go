@@ -41,7 +41,7 @@ var getCmd = &cobra.Command{
 			return err
 		}
 		// Human
-		fmt.Fprintf(os.Stderr, "conversation id=%d title=%q\n", c.ID, c.Title)
+		fmt.Fprintf(os.Stderr, "conversation id=%s title=%q\n", c.ID, c.Title)
 		// JSON
 		out := map[string]any{"id": c.ID, "title": c.Title}
 		if c.Project.Valid {
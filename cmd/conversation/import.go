@@ -0,0 +1,208 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/transcript"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagConvImportFile   string
+	flagConvImportTitle  string
+	flagConvImportRole   string
+	flagConvImportDryRun bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Recreate a conversation from a JSONL transcript",
+	Long: `Recreate a conversation, its experiments and messages from a transcript written by
+'rbc conversation export --format jsonl'. Everything gets new ids; message times,
+statuses and content are kept, and task runs are linked to the task with the same
+variant when one exists here. The new conversation is tagged imported_from with
+the original id. Reads stdin unless --file is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var r io.Reader = os.Stdin
+		if flagConvImportFile != "" && flagConvImportFile != "-" {
+			f, err := os.Open(flagConvImportFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		t, err := transcript.ReadJSONL(r)
+		if err != nil {
+			return fmt.Errorf("read transcript: %w", err)
+		}
+		if title := strings.TrimSpace(flagConvImportTitle); title != "" {
+			t.Conversation.Title = title
+		}
+		if role := strings.TrimSpace(flagConvImportRole); role != "" {
+			t.Conversation.Role = role
+		}
+		if strings.TrimSpace(t.Conversation.Title) == "" {
+			return errors.New("transcript has no conversation title; pass --title")
+		}
+		ne, nm := t.Counts()
+		if flagConvImportDryRun {
+			fmt.Fprintf(os.Stderr, "dry run: would import conversation %q experiments=%d messages=%d\n", t.Conversation.Title, ne, nm)
+			return nil
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		res, err := importTranscript(ctx, db, t)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "imported conversation id=%s experiments=%d messages=%d unlinked_tasks=%d\n", res.ConversationID, ne, nm, res.UnlinkedTasks)
+		out := map[string]any{"status": "imported", "id": res.ConversationID, "imported_from": t.Conversation.ID, "experiments": res.Experiments, "messages": nm, "unlinked_tasks": res.UnlinkedTasks}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	},
+}
+
+func init() {
+	ConversationCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&flagConvImportFile, "file", "", "JSONL transcript to read (default stdin)")
+	importCmd.Flags().StringVar(&flagConvImportTitle, "title", "", "Title for the new conversation (default: the exported one)")
+	importCmd.Flags().StringVar(&flagConvImportRole, "role", "", "Role for the new conversation (default: the exported one)")
+	importCmd.Flags().BoolVar(&flagConvImportDryRun, "dry-run", false, "Validate the transcript and print counts without writing")
+}
+
+type importResult struct {
+	ConversationID string
+	Experiments    map[string]string // exported id -> new id
+	UnlinkedTasks  int               // task runs whose variant does not exist here
+}
+
+// importTranscript writes t as a new conversation in one transaction, so a
+// failed import leaves nothing behind.
+func importTranscript(ctx context.Context, db *pgxpool.Pool, t *transcript.Transcript) (importResult, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return importResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	res, err := writeTranscript(ctx, tx, t)
+	if err != nil {
+		return importResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return importResult{}, err
+	}
+	return res, nil
+}
+
+func writeTranscript(ctx context.Context, db pgx.Tx, t *transcript.Transcript) (importResult, error) {
+	res := importResult{Experiments: map[string]string{}}
+	src := t.Conversation
+	tags := map[string]any{}
+	for k, v := range src.Tags {
+		tags[k] = v
+	}
+	if src.ID != "" {
+		tags["imported_from"] = src.ID
+	}
+	role := src.Role
+	if role == "" {
+		role = "user"
+	}
+	c := &pgdao.Conversation{Title: src.Title, RoleName: role, Tags: tags}
+	c.Description = nullString(src.Description)
+	c.Project = nullString(src.Project)
+	c.Notes = nullString(src.Notes)
+	if err := pgdao.InsertConversation(ctx, db, c); err != nil {
+		return res, err
+	}
+	res.ConversationID = c.ID
+
+	tasks := map[string]sql.NullString{} // variant -> task id here
+	msgIDs := map[string]string{}        // exported message id -> new id
+	for _, e := range t.Experiments {
+		ne := &pgdao.Experiment{ConversationID: c.ID, Name: nullString(e.Name), Status: e.Status, Params: e.Params, Metrics: e.Metrics}
+		if !pgdao.ValidExperimentStatus(ne.Status) {
+			ne.Status = ""
+		}
+		if e.Started != nil {
+			ne.Started = sql.NullTime{Time: *e.Started, Valid: true}
+		}
+		if e.Finished != nil {
+			ne.Finished = sql.NullTime{Time: *e.Finished, Valid: true}
+		}
+		if err := pgdao.InsertExperiment(ctx, db, ne); err != nil {
+			return res, err
+		}
+		res.Experiments[e.ID] = ne.ID
+		for _, m := range e.Messages {
+			text := m.Text
+			if strings.TrimSpace(text) == "" {
+				text = fmt.Sprintf("(%s message without text)", m.Status)
+			}
+			cid, err := pgdao.InsertContent(ctx, db, text, m.JSON)
+			if err != nil {
+				return res, err
+			}
+			ev := &pgdao.MessageEvent{
+				ContentID: cid, ExperimentID: sql.NullString{String: ne.ID, Valid: true},
+				RoleName: m.Role, Status: m.Status, ErrorMessage: nullString(m.Error), Tags: m.Tags, Created: m.Created, ToRole: m.ToRole,
+			}
+			// Replies keep their thread when the parent was imported before them.
+			if parent, ok := msgIDs[m.ReplyTo]; ok {
+				ev.ParentMessageID = sql.NullString{String: parent, Valid: true}
+			}
+			if ev.RoleName == "" {
+				ev.RoleName = role
+			}
+			if m.Task != nil && m.Task.Variant != "" {
+				id, seen := tasks[m.Task.Variant]
+				if !seen {
+					if task, err := pgdao.GetTaskByVariant(ctx, db, m.Task.Variant); err == nil && task != nil {
+						id = sql.NullString{String: task.ID, Valid: true}
+					}
+					tasks[m.Task.Variant] = id
+				}
+				ev.FromTaskID = id
+				if !id.Valid {
+					res.UnlinkedTasks++
+				}
+			}
+			id, err := pgdao.InsertMessageEvent(ctx, db, ev)
+			if err != nil {
+				return res, err
+			}
+			msgIDs[m.ID] = id
+		}
+	}
+	return res, nil
+}
+
+func nullString(s string) sql.NullString {
+	if strings.TrimSpace(s) == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
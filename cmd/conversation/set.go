@@ -16,13 +16,14 @@ import (
 )
 
 var (
-	flagConvID    string
-	flagConvTitle string
-	flagConvDesc  string
-	flagConvNotes string
-	flagConvProj  string
-	flagConvTags  []string
-	flagConvRole  string
+	flagConvID      string
+	flagConvTitle   string
+	flagConvDesc    string
+	flagConvNotes   string
+	flagConvProj    string
+	flagConvTags    []string
+	flagConvRole    string
+	flagConvIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -32,6 +33,13 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagConvTitle) == "" {
 			return errors.New("--title is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagConvIfMatch)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.IsZero() && strings.TrimSpace(flagConvID) == "" {
+			return errors.New("--if-match requires --id")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -43,7 +51,7 @@ var setCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		conv := &pgdao.Conversation{ID: flagConvID, Title: flagConvTitle}
+		conv := &pgdao.Conversation{ID: flagConvID, Title: flagConvTitle, IfMatch: ifMatch}
 		if strings.TrimSpace(flagConvRole) != "" {
 			conv.RoleName = strings.TrimSpace(flagConvRole)
 		}
@@ -94,6 +102,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagConvProj, "project", "", "Project name (e.g. GitHub repo)")
 	setCmd.Flags().StringSliceVar(&flagConvTags, "tags", nil, "Tags as key=value pairs (repeat or comma-separated). Plain values mapped to true")
 	setCmd.Flags().StringVar(&flagConvRole, "role", "", "Role name (optional; defaults to 'user')")
+	setCmd.Flags().StringVar(&flagConvIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
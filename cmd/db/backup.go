@@ -42,6 +42,7 @@ var backupTables = []string{
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Backup key tables to JSON (stdout or file)",
+	Long:  "Dump a fixed list of tables as one JSON document. For consistent, verifiable and portable backups prefer 'rbc snapshot backup' followed by 'rbc snapshot export'.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := cfgpkg.Load()
 		if err != nil {
@@ -127,7 +127,7 @@ func upsertRow(ctx context.Context, db *pgxpool.Pool, tbl string, obj rowObj, up
 	case "blackboards":
 		return insertGeneric(ctx, db, tbl, []col{{"id", ":uuid"}, {"role_name", ""}, {"conversation_id", ":uuid"}, {"project_name", ""}, {"task_id", ":uuid"}, {"created", ":timestamptz"}, {"updated", ":timestamptz"}, {"background", ""}, {"guidelines", ""}, {"lifecycle", ""}}, "id", upsert, obj)
 	case "packages":
-		return insertGeneric(ctx, db, tbl, []col{{"id", ":uuid"}, {"role_name", ""}, {"task_id", ":uuid"}, {"created", ":timestamptz"}, {"updated", ":timestamptz"}}, "id", upsert, obj)
+		return insertGeneric(ctx, db, tbl, []col{{"id", ":uuid"}, {"role_name", ""}, {"task_id", ":uuid"}, {"version_constraint", ""}, {"created", ":timestamptz"}, {"updated", ":timestamptz"}}, "id", upsert, obj)
 	case "testcases":
 		return insertGeneric(ctx, db, tbl, []col{{"id", ":uuid"}, {"name", ""}, {"package", ""}, {"classname", ""}, {"title", ""}, {"experiment_id", ":uuid"}, {"role_name", ""}, {"status", ""}, {"error_message", ""}, {"tags", ":jsonb"}, {"level", ""}, {"created", ":timestamptz"}, {"file", ""}, {"line", ""}, {"execution_time", ""}}, "id", upsert, obj)
 	case "tools":
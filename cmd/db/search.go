@@ -67,7 +67,7 @@ var searchCmd = &cobra.Command{
 			return enc.Encode(results)
 		}
 		for _, r := range results {
-			fmt.Fprintf(os.Stdout, "%s\t%s\n", r.ID, r.Preview)
+			fmt.Fprintf(os.Stdout, "%d\t%s\n", r.ID, r.Preview)
 		}
 		return nil
 	},
@@ -0,0 +1,367 @@
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var flagExpCompareOutput string
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <a> <b>",
+	Short: "Compare two experiments: task outcomes, testcases, messages and LLM usage",
+	Long: `Compare experiment b against experiment a:
+  tasks      last run of each task variant (status, duration, exit_code from message metadata)
+  testcases  counts per status and the tests that were fixed, broke, appeared or disappeared
+  messages   counts per status
+  usage      LLM token totals found in message metadata (usage.input_tokens, ...)`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(strings.TrimSpace(flagExpCompareOutput))
+		if output != "table" && output != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		var sides [2]experimentSide
+		for i, id := range args {
+			if _, err := pgdao.GetExperimentByID(ctx, db, id); err != nil {
+				return err
+			}
+			msgs, err := pgdao.ListExperimentMessages(ctx, db, id)
+			if err != nil {
+				return err
+			}
+			tcs, err := pgdao.ListExperimentTestcases(ctx, db, id)
+			if err != nil {
+				return err
+			}
+			sides[i] = summarizeExperiment(id, msgs, tcs)
+		}
+		cmp := compareExperiments(sides[0], sides[1])
+		fmt.Fprintf(os.Stderr, "experiment compare: a=%s b=%s tasks=%d testcases=%d/%d fixed=%d broken=%d\n",
+			cmp.A, cmp.B, len(cmp.Tasks), sides[0].testTotal, sides[1].testTotal, len(cmp.Testcases.Fixed), len(cmp.Testcases.Broken))
+		if output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(cmp)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"SECTION", "ITEM", "A", "B", "CHANGE"})
+		table.SetAutoWrapText(false)
+		for _, r := range cmp.rows() {
+			table.Append(r)
+		}
+		table.Render()
+		return nil
+	},
+}
+
+func init() {
+	ExperimentCmd.AddCommand(compareCmd)
+	compareCmd.Flags().StringVar(&flagExpCompareOutput, "output", "table", "Output format: table or json")
+}
+
+// taskOutcome is the completion recorded by a task run (rbc task run).
+type taskOutcome struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+}
+
+// usageTotals sums the LLM usage found in message metadata.
+type usageTotals struct {
+	Calls        int `json:"calls"`
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// experimentSide is what one experiment contributes to a comparison.
+type experimentSide struct {
+	id        string
+	tasks     map[string]taskOutcome
+	messages  map[string]int
+	testcases map[testreport.TestKey]string
+	statuses  map[string]int
+	testTotal int
+	usage     usageTotals
+}
+
+// summarizeExperiment reads the task runs, message counts and LLM usage of an
+// experiment's messages and the status of each of its testcases. The last run
+// of a variant wins.
+func summarizeExperiment(id string, msgs []pgdao.ExperimentMessage, tcs []pgdao.Testcase) experimentSide {
+	s := experimentSide{id: id, tasks: map[string]taskOutcome{}, messages: map[string]int{}, testcases: map[testreport.TestKey]string{}, statuses: map[string]int{}}
+	for _, m := range msgs {
+		s.messages[m.Status]++
+		var meta map[string]any
+		if len(m.ContentJSON) > 0 {
+			_ = json.Unmarshal(m.ContentJSON, &meta)
+		}
+		if isTaskRun(m.Tags) {
+			variant, _ := meta["variant"].(string)
+			if variant == "" && m.FromTaskID.Valid {
+				variant = "task " + m.FromTaskID.String
+			}
+			if variant != "" {
+				o := taskOutcome{Status: m.Status}
+				if d, ok := meta["duration"].(string); ok {
+					o.Duration = d
+				}
+				if n, ok := meta["exit_code"].(float64); ok {
+					code := int(n)
+					o.ExitCode = &code
+				}
+				s.tasks[variant] = o
+			}
+		}
+		if u, ok := usageOf(meta); ok {
+			s.usage.Calls++
+			s.usage.InputTokens += u.InputTokens
+			s.usage.OutputTokens += u.OutputTokens
+			s.usage.TotalTokens += u.TotalTokens
+		}
+	}
+	for _, t := range tcs {
+		st := strings.ToUpper(strings.TrimSpace(t.Status))
+		s.testcases[testreport.KeyOf(t)] = st
+		s.statuses[st]++
+		s.testTotal++
+	}
+	return s
+}
+
+func isTaskRun(tags map[string]any) bool {
+	task, _ := tags["task"].(bool)
+	run, _ := tags["run"].(bool)
+	return task && run
+}
+
+// usageOf reads token counts from a "usage" object or top-level keys;
+// total_tokens defaults to input plus output.
+func usageOf(meta map[string]any) (usageTotals, bool) {
+	src := meta
+	if u, ok := meta["usage"].(map[string]any); ok {
+		src = u
+	}
+	var u usageTotals
+	found := false
+	for key, dst := range map[string]*int{"input_tokens": &u.InputTokens, "output_tokens": &u.OutputTokens, "total_tokens": &u.TotalTokens} {
+		if n, ok := src[key].(float64); ok {
+			*dst = int(n)
+			found = true
+		}
+	}
+	if u.TotalTokens == 0 {
+		u.TotalTokens = u.InputTokens + u.OutputTokens
+	}
+	return u, found
+}
+
+// experimentComparison is the result of compare, b measured against a.
+type experimentComparison struct {
+	A         string         `json:"a"`
+	B         string         `json:"b"`
+	Tasks     []taskDelta    `json:"tasks"`
+	Testcases testcaseDelta  `json:"testcases"`
+	Messages  []countDelta   `json:"messages"`
+	Usage     [2]usageTotals `json:"usage"`
+	UsageDiff usageTotals    `json:"usage_delta"`
+}
+
+type taskDelta struct {
+	Variant string       `json:"variant"`
+	A       *taskOutcome `json:"a,omitempty"`
+	B       *taskOutcome `json:"b,omitempty"`
+	Change  string       `json:"change"`
+}
+
+type countDelta struct {
+	Key   string `json:"key"`
+	A     int    `json:"a"`
+	B     int    `json:"b"`
+	Delta int    `json:"delta"`
+}
+
+type testcaseDelta struct {
+	Total    [2]int       `json:"total"`
+	Statuses []countDelta `json:"statuses"`
+	Fixed    []string     `json:"fixed"`
+	Broken   []string     `json:"broken"`
+	Added    []string     `json:"added"`
+	Removed  []string     `json:"removed"`
+}
+
+func compareExperiments(a, b experimentSide) experimentComparison {
+	c := experimentComparison{A: a.id, B: b.id, Tasks: []taskDelta{}, Usage: [2]usageTotals{a.usage, b.usage}}
+	c.UsageDiff = usageTotals{
+		Calls:        b.usage.Calls - a.usage.Calls,
+		InputTokens:  b.usage.InputTokens - a.usage.InputTokens,
+		OutputTokens: b.usage.OutputTokens - a.usage.OutputTokens,
+		TotalTokens:  b.usage.TotalTokens - a.usage.TotalTokens,
+	}
+	for _, v := range unionKeys(a.tasks, b.tasks) {
+		d := taskDelta{Variant: v}
+		if o, ok := a.tasks[v]; ok {
+			d.A = &o
+		}
+		if o, ok := b.tasks[v]; ok {
+			d.B = &o
+		}
+		d.Change = taskChange(d.A, d.B)
+		c.Tasks = append(c.Tasks, d)
+	}
+	c.Messages = countDeltas(a.messages, b.messages)
+	c.Testcases = testcaseDelta{Total: [2]int{a.testTotal, b.testTotal}, Statuses: countDeltas(a.statuses, b.statuses), Fixed: []string{}, Broken: []string{}, Added: []string{}, Removed: []string{}}
+	failing := func(s string) bool { return s == testreport.StatusKO || s == testreport.StatusError }
+	for k, sb := range b.testcases {
+		sa, ok := a.testcases[k]
+		switch {
+		case !ok:
+			c.Testcases.Added = append(c.Testcases.Added, k.String())
+		case failing(sa) && sb == testreport.StatusOK:
+			c.Testcases.Fixed = append(c.Testcases.Fixed, k.String())
+		case !failing(sa) && failing(sb):
+			c.Testcases.Broken = append(c.Testcases.Broken, k.String())
+		}
+	}
+	for k := range a.testcases {
+		if _, ok := b.testcases[k]; !ok {
+			c.Testcases.Removed = append(c.Testcases.Removed, k.String())
+		}
+	}
+	for _, l := range [][]string{c.Testcases.Fixed, c.Testcases.Broken, c.Testcases.Added, c.Testcases.Removed} {
+		sort.Strings(l)
+	}
+	return c
+}
+
+// taskChange describes how a task's outcome moved from a to b.
+func taskChange(a, b *taskOutcome) string {
+	switch {
+	case a == nil:
+		return "added"
+	case b == nil:
+		return "removed"
+	}
+	var parts []string
+	if a.Status != b.Status {
+		parts = append(parts, a.Status+" → "+b.Status)
+	}
+	if ea, eb := exitText(a.ExitCode), exitText(b.ExitCode); ea != eb {
+		parts = append(parts, "exit "+ea+" → "+eb)
+	}
+	da, errA := time.ParseDuration(a.Duration)
+	db, errB := time.ParseDuration(b.Duration)
+	if errA == nil && errB == nil && da > 0 {
+		parts = append(parts, fmt.Sprintf("duration %+.0f%%", (float64(db)/float64(da)-1)*100))
+	}
+	if len(parts) == 0 {
+		return "same"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func exitText(code *int) string {
+	if code == nil {
+		return "-"
+	}
+	return strconv.Itoa(*code)
+}
+
+func (o *taskOutcome) String() string {
+	if o == nil {
+		return "-"
+	}
+	s := o.Status
+	if o.Duration != "" {
+		s += " " + o.Duration
+	}
+	if o.ExitCode != nil {
+		s += " exit=" + strconv.Itoa(*o.ExitCode)
+	}
+	return s
+}
+
+func countDeltas(a, b map[string]int) []countDelta {
+	out := []countDelta{}
+	for _, k := range unionKeys(a, b) {
+		out = append(out, countDelta{Key: k, A: a[k], B: b[k], Delta: b[k] - a[k]})
+	}
+	return out
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				out = append(out, k)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// rows flattens the comparison into table rows.
+func (c experimentComparison) rows() [][]string {
+	var out [][]string
+	delta := func(n int) string {
+		if n == 0 {
+			return "="
+		}
+		return fmt.Sprintf("%+d", n)
+	}
+	for _, t := range c.Tasks {
+		out = append(out, []string{"task", t.Variant, t.A.String(), t.B.String(), t.Change})
+	}
+	tc := c.Testcases
+	out = append(out, []string{"testcases", "total", strconv.Itoa(tc.Total[0]), strconv.Itoa(tc.Total[1]), delta(tc.Total[1] - tc.Total[0])})
+	for _, s := range tc.Statuses {
+		out = append(out, []string{"testcases", s.Key, strconv.Itoa(s.A), strconv.Itoa(s.B), delta(s.Delta)})
+	}
+	for _, l := range []struct {
+		kind  string
+		names []string
+	}{{"fixed", tc.Fixed}, {"broken", tc.Broken}, {"added", tc.Added}, {"removed", tc.Removed}} {
+		for _, n := range l.names {
+			out = append(out, []string{"testcase", n, "", "", l.kind})
+		}
+	}
+	for _, m := range c.Messages {
+		out = append(out, []string{"messages", m.Key, strconv.Itoa(m.A), strconv.Itoa(m.B), delta(m.Delta)})
+	}
+	ua, ub := c.Usage[0], c.Usage[1]
+	for _, u := range []struct {
+		name string
+		a, b int
+	}{{"calls", ua.Calls, ub.Calls}, {"input_tokens", ua.InputTokens, ub.InputTokens}, {"output_tokens", ua.OutputTokens, ub.OutputTokens}, {"total_tokens", ua.TotalTokens, ub.TotalTokens}} {
+		out = append(out, []string{"usage", u.name, strconv.Itoa(u.a), strconv.Itoa(u.b), delta(u.b - u.a)})
+	}
+	return out
+}
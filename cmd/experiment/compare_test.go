@@ -0,0 +1,63 @@
+package experiment
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func taskMsg(status, meta string) pgdao.ExperimentMessage {
+	m := pgdao.ExperimentMessage{ContentJSON: []byte(meta)}
+	m.Status = status
+	m.Tags = map[string]any{"task": true, "run": true}
+	return m
+}
+
+func tc(name, status string) pgdao.Testcase {
+	return pgdao.Testcase{Title: name, Name: sql.NullString{String: name, Valid: true}, Package: sql.NullString{String: "app", Valid: true}, Status: status}
+}
+
+func TestCompareExperiments(t *testing.T) {
+	llm := pgdao.ExperimentMessage{ContentJSON: []byte(`{"usage":{"input_tokens":100,"output_tokens":20}}`)}
+	llm.Status = "ingested"
+	a := summarizeExperiment("a",
+		[]pgdao.ExperimentMessage{
+			taskMsg("failed", `{"variant":"unit/go","status":"failed","duration":"2s","exit_code":1}`),
+			taskMsg("succeeded", `{"variant":"lint/go","status":"succeeded","duration":"1s","exit_code":0}`),
+			llm,
+		},
+		[]pgdao.Testcase{tc("TestA", "KO"), tc("TestB", "OK"), tc("TestGone", "OK")})
+	llm.ContentJSON = []byte(`{"usage":{"input_tokens":80,"output_tokens":30,"total_tokens":110}}`)
+	b := summarizeExperiment("b",
+		[]pgdao.ExperimentMessage{
+			taskMsg("failed", `{"variant":"unit/go","status":"failed","duration":"9s","exit_code":2}`),
+			// the last run of a variant wins
+			taskMsg("succeeded", `{"variant":"unit/go","status":"succeeded","duration":"3s","exit_code":0}`),
+			llm, llm,
+		},
+		[]pgdao.Testcase{tc("TestA", "OK"), tc("TestB", "ERROR"), tc("TestNew", "OK")})
+	c := compareExperiments(a, b)
+
+	if len(c.Tasks) != 2 || c.Tasks[0].Variant != "lint/go" || c.Tasks[0].Change != "removed" {
+		t.Fatalf("tasks = %+v", c.Tasks)
+	}
+	if got := c.Tasks[1].Change; got != "failed → succeeded, exit 1 → 0, duration +50%" {
+		t.Fatalf("unit/go change = %q", got)
+	}
+	if !reflect.DeepEqual(c.Testcases.Fixed, []string{"app › TestA"}) || !reflect.DeepEqual(c.Testcases.Broken, []string{"app › TestB"}) ||
+		!reflect.DeepEqual(c.Testcases.Added, []string{"app › TestNew"}) || !reflect.DeepEqual(c.Testcases.Removed, []string{"app › TestGone"}) {
+		t.Fatalf("testcases = %+v", c.Testcases)
+	}
+	if c.Usage[0].TotalTokens != 120 || c.Usage[1].TotalTokens != 220 || c.UsageDiff.Calls != 1 || c.UsageDiff.OutputTokens != 40 {
+		t.Fatalf("usage = %+v delta %+v", c.Usage, c.UsageDiff)
+	}
+	want := []countDelta{{Key: "failed", A: 1, B: 1}, {Key: "ingested", A: 1, B: 2, Delta: 1}, {Key: "succeeded", A: 1, B: 1}}
+	if !reflect.DeepEqual(c.Messages, want) {
+		t.Fatalf("messages = %+v", c.Messages)
+	}
+	if rows := c.rows(); len(rows) == 0 || rows[0][0] != "task" {
+		t.Fatalf("rows = %v", rows)
+	}
+}
@@ -2,10 +2,12 @@ package experiment
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,15 +18,36 @@ import (
 
 var (
 	flagExpConversation string
+	flagExpName         string
+	flagExpParams       []string
+	flagExpParamsJSON   string
+	flagExpStatus       string
+	flagExpMetrics      []string
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new experiment linked to a conversation",
+	Long: `Create an experiment, optionally naming it and recording what varies in it:
+  rbc experiment create --conversation <uuid> --name prompt-v2 \
+    --param model=gpt-4o --param temperature=0.2 --params '{"tasks":{"unit/go":"1.4.0"}}'
+Param values are read as JSON when they parse (numbers, booleans, objects), else as text.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if strings.TrimSpace(flagExpConversation) == "" {
 			return errors.New("--conversation is required")
 		}
+		params, err := parseParams(flagExpParams, flagExpParamsJSON)
+		if err != nil {
+			return err
+		}
+		metrics, err := parseMetrics(flagExpMetrics)
+		if err != nil {
+			return err
+		}
+		status := strings.ToLower(strings.TrimSpace(flagExpStatus))
+		if status != "" && !pgdao.ValidExperimentStatus(status) {
+			return fmt.Errorf("--status must be running, succeeded, failed or aborted")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -36,14 +59,19 @@ var createCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		e, err := pgdao.CreateExperiment(ctx, db, flagExpConversation)
-		if err != nil {
+		e := &pgdao.Experiment{ConversationID: flagExpConversation, Params: params, Status: status, Metrics: metrics}
+		if n := strings.TrimSpace(flagExpName); n != "" {
+			e.Name = sql.NullString{String: n, Valid: true}
+		}
+		if err := pgdao.InsertExperiment(ctx, db, e); err != nil {
 			return err
 		}
 		// Human
-		fmt.Fprintf(os.Stderr, "experiment created id=%s conversation_id=%s\n", e.ID, e.ConversationID)
+		fmt.Fprintf(os.Stderr, "experiment created id=%s conversation_id=%s status=%s\n", e.ID, e.ConversationID, e.Status)
 		// JSON
-		out := map[string]any{"status": "created", "id": e.ID, "conversation_id": e.ConversationID}
+		out := experimentJSON(e)
+		out["status"] = "created"
+		out["experiment_status"] = e.Status
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		return enc.Encode(out)
@@ -53,4 +81,84 @@ var createCmd = &cobra.Command{
 func init() {
 	ExperimentCmd.AddCommand(createCmd)
 	createCmd.Flags().StringVar(&flagExpConversation, "conversation", "", "Conversation UUID (required)")
+	createCmd.Flags().StringVar(&flagExpName, "name", "", "Experiment name")
+	createCmd.Flags().StringArrayVar(&flagExpParams, "param", nil, "Parameter as key=value (repeatable)")
+	createCmd.Flags().StringVar(&flagExpParamsJSON, "params", "", "Parameters as a JSON object")
+	createCmd.Flags().StringVar(&flagExpStatus, "status", "", "Initial status: running (default), succeeded, failed or aborted")
+	createCmd.Flags().StringArrayVar(&flagExpMetrics, "metric", nil, "Numeric metric as key=value (repeatable)")
+}
+
+// parseParams reads key=value pairs and an optional JSON object; pairs win.
+func parseParams(pairs []string, jsonObj string) (map[string]any, error) {
+	out := map[string]any{}
+	if s := strings.TrimSpace(jsonObj); s != "" {
+		if err := json.Unmarshal([]byte(s), &out); err != nil {
+			return nil, fmt.Errorf("--params must be a JSON object: %w", err)
+		}
+	}
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("--param %q: want key=value", p)
+		}
+		var val any
+		if err := json.Unmarshal([]byte(v), &val); err != nil {
+			val = v
+		}
+		out[k] = val
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// parseMetrics reads numeric key=value pairs.
+func parseMetrics(pairs []string) (map[string]float64, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	out := map[string]float64{}
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("--metric %q: want key=number", p)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("--metric %q: %w", p, err)
+		}
+		out[k] = f
+	}
+	return out, nil
+}
+
+// experimentJSON is the JSON shape shared by get, list and update; create
+// keeps "status":"created" and reports the lifecycle as experiment_status.
+func experimentJSON(e *pgdao.Experiment) map[string]any {
+	m := map[string]any{"id": e.ID, "conversation_id": e.ConversationID, "status": e.Status}
+	if e.Created != "" {
+		m["created"] = e.Created
+	}
+	if e.Name.Valid {
+		m["name"] = e.Name.String
+	}
+	if len(e.Params) > 0 {
+		m["params"] = e.Params
+	}
+	if len(e.Metrics) > 0 {
+		m["metrics"] = e.Metrics
+	}
+	if e.Started.Valid {
+		m["started"] = e.Started.Time.Format(time.RFC3339)
+	}
+	if e.Finished.Valid {
+		m["finished"] = e.Finished.Time.Format(time.RFC3339)
+		if e.Started.Valid {
+			m["duration"] = e.Finished.Time.Sub(e.Started.Time).Round(time.Millisecond).String()
+		}
+	}
+	return m
 }
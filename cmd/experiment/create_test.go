@@ -0,0 +1,33 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	got, err := parseParams([]string{"model=gpt-4o", "temperature=0.2", "stream=true"}, `{"model":"x","tasks":{"unit/go":"1.4.0"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"model": "gpt-4o", "temperature": 0.2, "stream": true, "tasks": map[string]any{"unit/go": "1.4.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("params = %v", got)
+	}
+	if _, err := parseParams([]string{"novalue"}, ""); err == nil {
+		t.Fatal("want error for missing =")
+	}
+	if _, err := parseParams(nil, "[1]"); err == nil {
+		t.Fatal("want error for non-object --params")
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	got, err := parseMetrics([]string{"score=0.82", "tokens = 120"})
+	if err != nil || !reflect.DeepEqual(got, map[string]float64{"score": 0.82, "tokens": 120}) {
+		t.Fatalf("metrics = %v, %v", got, err)
+	}
+	if _, err := parseMetrics([]string{"score=high"}); err == nil {
+		t.Fatal("want error for non-numeric metric")
+	}
+}
@@ -29,7 +29,7 @@ var deleteCmd = &cobra.Command{
 			return errors.New("--id is required")
 		}
 		if !flagExpDelForce {
-			fmt.Fprintf(os.Stderr, "About to delete experiment id=%d.\n", flagExpDelID)
+			fmt.Fprintf(os.Stderr, "About to delete experiment id=%s.\n", flagExpDelID)
 			fmt.Fprint(os.Stderr, "Type 'yes' to confirm: ")
 			reader := bufio.NewReader(os.Stdin)
 			line, _ := reader.ReadString('\n')
@@ -54,15 +54,15 @@ var deleteCmd = &cobra.Command{
 		}
 		if affected == 0 {
 			if flagExpDelIgnoreMissing {
-				fmt.Fprintf(os.Stderr, "experiment id=%d not found; ignoring\n", flagExpDelID)
+				fmt.Fprintf(os.Stderr, "experiment id=%s not found; ignoring\n", flagExpDelID)
 				out := map[string]any{"status": "not_found_ignored", "id": flagExpDelID}
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
 				return enc.Encode(out)
 			}
-			return fmt.Errorf("experiment id=%d not found", flagExpDelID)
+			return fmt.Errorf("experiment id=%s not found", flagExpDelID)
 		}
-		fmt.Fprintf(os.Stderr, "experiment deleted id=%d\n", flagExpDelID)
+		fmt.Fprintf(os.Stderr, "experiment deleted id=%s\n", flagExpDelID)
 		out := map[string]any{"status": "deleted", "deleted": true, "id": flagExpDelID}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
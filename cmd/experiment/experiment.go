@@ -4,5 +4,5 @@ import "github.com/spf13/cobra"
 
 var ExperimentCmd = &cobra.Command{
 	Use:   "experiment",
-	Short: "Manage experiments (create)",
+	Short: "Manage experiments (create, update, list, compare)",
 }
@@ -41,9 +41,9 @@ var getCmd = &cobra.Command{
 			return err
 		}
 		// Human
-		fmt.Fprintf(os.Stderr, "experiment id=%s conversation_id=%s\n", e.ID, e.ConversationID)
+		fmt.Fprintf(os.Stderr, "experiment id=%s conversation_id=%s status=%s\n", e.ID, e.ConversationID, e.Status)
 		// JSON
-		out := map[string]any{"id": e.ID, "conversation_id": e.ConversationID}
+		out := experimentJSON(e)
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		return enc.Encode(out)
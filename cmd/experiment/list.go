@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,23 +50,62 @@ var listCmd = &cobra.Command{
 		fmt.Fprintf(os.Stderr, "experiments: %d\n", len(rows))
 		if strings.ToLower(strings.TrimSpace(flagExpListOutput)) == "json" {
 			arr := make([]map[string]any, 0, len(rows))
-			for _, e := range rows {
-				arr = append(arr, map[string]any{"id": e.ID, "conversation_id": e.ConversationID})
+			for i := range rows {
+				arr = append(arr, experimentJSON(&rows[i]))
 			}
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(arr)
 		}
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"ID", "CONVERSATION"})
+		table.SetHeader([]string{"ID", "NAME", "STATUS", "STARTED", "DURATION", "PARAMS", "METRICS"})
 		for _, e := range rows {
-			table.Append([]string{e.ID, e.ConversationID})
+			started, duration := "", ""
+			if e.Started.Valid {
+				started = e.Started.Time.Format(time.RFC3339)
+				if e.Finished.Valid {
+					duration = e.Finished.Time.Sub(e.Started.Time).Round(time.Second).String()
+				}
+			}
+			table.Append([]string{e.ID, e.Name.String, e.Status, started, duration, compactParams(e.Params), compactMetrics(e.Metrics)})
 		}
 		table.Render()
 		return nil
 	},
 }
 
+// compactParams renders params as sorted key=value pairs; nested values as JSON.
+func compactParams(p map[string]any) string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v, ok := p[k].(string)
+		if !ok {
+			b, _ := json.Marshal(p[k])
+			v = string(b)
+		}
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, " ")
+}
+
+func compactMetrics(m map[string]float64) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strconv.FormatFloat(m[k], 'g', 6, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
 func init() {
 	ExperimentCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&flagExpListConv, "conversation", "", "Filter by conversation UUID")
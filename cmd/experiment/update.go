@@ -0,0 +1,85 @@
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagExpUpdID         string
+	flagExpUpdName       string
+	flagExpUpdStatus     string
+	flagExpUpdParams     []string
+	flagExpUpdParamsJSON string
+	flagExpUpdMetrics    []string
+	flagExpUpdAdd        []string
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an experiment's name, status, params or metrics",
+	Long: `Update part of an experiment. Params are merged into the stored ones (nested
+objects too); --metric replaces a metric and --add adds to it. Moving the status
+away from running stamps the finished time:
+  rbc experiment update --id <uuid> --status succeeded --metric score=0.82`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagExpUpdID) == "" {
+			return errors.New("--id is required")
+		}
+		status := strings.ToLower(strings.TrimSpace(flagExpUpdStatus))
+		if status != "" && !pgdao.ValidExperimentStatus(status) {
+			return fmt.Errorf("--status must be running, succeeded, failed or aborted")
+		}
+		params, err := parseParams(flagExpUpdParams, flagExpUpdParamsJSON)
+		if err != nil {
+			return err
+		}
+		metrics, err := parseMetrics(flagExpUpdMetrics)
+		if err != nil {
+			return err
+		}
+		add, err := parseMetrics(flagExpUpdAdd)
+		if err != nil {
+			return err
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		e, err := pgdao.UpdateExperiment(ctx, db, flagExpUpdID, pgdao.ExperimentUpdate{Name: strings.TrimSpace(flagExpUpdName), Status: status, Params: params, Metrics: metrics, AddMetrics: add})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "experiment updated id=%s status=%s\n", e.ID, e.Status)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(experimentJSON(e))
+	},
+}
+
+func init() {
+	ExperimentCmd.AddCommand(updateCmd)
+	updateCmd.Flags().StringVar(&flagExpUpdID, "id", "", "Experiment UUID (required)")
+	updateCmd.Flags().StringVar(&flagExpUpdName, "name", "", "New name")
+	updateCmd.Flags().StringVar(&flagExpUpdStatus, "status", "", "Status: running, succeeded, failed or aborted")
+	updateCmd.Flags().StringArrayVar(&flagExpUpdParams, "param", nil, "Parameter as key=value (repeatable)")
+	updateCmd.Flags().StringVar(&flagExpUpdParamsJSON, "params", "", "Parameters as a JSON object, merged")
+	updateCmd.Flags().StringArrayVar(&flagExpUpdMetrics, "metric", nil, "Set a metric as key=number (repeatable)")
+	updateCmd.Flags().StringArrayVar(&flagExpUpdAdd, "add", nil, "Add to a metric as key=number (repeatable)")
+}
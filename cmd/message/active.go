@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
@@ -31,7 +32,7 @@ var (
 // activeCmd shows messages grouped by experiment for a conversation; cycle experiments with 'n'
 var activeCmd = &cobra.Command{
 	Use:   "active",
-	Short: "Interactive list of messages by experiment for a conversation",
+	Short: "Interactive tree of messages and replies by experiment for a conversation",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if strings.TrimSpace(flagMsgActiveConversation) == "" {
 			return errors.New("--conversation is required")
@@ -63,7 +64,7 @@ var activeCmd = &cobra.Command{
 			return fmt.Errorf("no experiment found for conversation %s", conv.ID)
 		}
 		// initial messages for latest experiment
-		msgs, err := pgdao.ListMessages(ctx, db, role, exps[0].ID, "", "", 100, 0)
+		msgs, err := listActiveMessages(ctx, db, exps[0].ID)
 		if err != nil {
 			return err
 		}
@@ -86,7 +87,8 @@ type msgActiveModel struct {
 	role         string
 	experiments  []pgdao.Experiment
 	expIdx       int
-	messages     []pgdao.MessageEvent
+	messages     []pgdao.MessageEvent // in reply-tree order
+	prefixes     []string             // tree guides, parallel to messages
 	cursor       int
 	quitting     bool
 	err          string
@@ -97,7 +99,21 @@ func newMsgActiveModel(conversation, role string, experiments []pgdao.Experiment
 	if expIdx < 0 || expIdx >= len(experiments) {
 		expIdx = 0
 	}
-	return msgActiveModel{conversation: conversation, role: role, experiments: experiments, expIdx: expIdx, messages: messages, contentCache: map[string]pgdao.ContentRecord{}}
+	m := msgActiveModel{conversation: conversation, role: role, experiments: experiments, expIdx: expIdx, contentCache: map[string]pgdao.ContentRecord{}}
+	m.setMessages(messages)
+	return m
+}
+
+// setMessages arranges messages as reply trees; replies whose parent is not
+// listed (another experiment) show as roots.
+func (m *msgActiveModel) setMessages(msgs []pgdao.MessageEvent) {
+	rows := threadRows(msgs)
+	m.messages = make([]pgdao.MessageEvent, len(rows))
+	m.prefixes = make([]string, len(rows))
+	for i, r := range rows {
+		m.messages[i] = r.msg
+		m.prefixes[i] = r.prefix
+	}
 }
 
 func (m msgActiveModel) Init() tea.Cmd { return nil }
@@ -131,17 +147,33 @@ func (m msgActiveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, nil
+		case "p":
+			// Jump to the message the selected one replies to.
+			if m.cursor >= 0 && m.cursor < len(m.messages) {
+				if parent := m.messages[m.cursor].ParentMessageID; parent.Valid {
+					for i, me := range m.messages {
+						if me.ID == parent.String {
+							m.cursor = i
+							if _, ok := m.contentCache[me.ContentID]; !ok {
+								return m, fetchContentCmd(me.ContentID)
+							}
+							break
+						}
+					}
+				}
+			}
+			return m, nil
 		case "r":
-			return m, refreshMsgsCmd(m.role, m.experiments[m.expIdx].ID)
+			return m, refreshMsgsCmd(m.experiments[m.expIdx].ID)
 		case "n":
 			if len(m.experiments) > 0 {
 				m.expIdx = (m.expIdx + 1) % len(m.experiments)
 				m.cursor = 0
-				return m, refreshMsgsCmd(m.role, m.experiments[m.expIdx].ID)
+				return m, refreshMsgsCmd(m.experiments[m.expIdx].ID)
 			}
 		}
 	case msgRefreshMsg:
-		m.messages = msg.messages
+		m.setMessages(msg.messages)
 		// reset cursor safely
 		if m.cursor >= len(m.messages) {
 			if len(m.messages) == 0 {
@@ -205,7 +237,7 @@ func (m msgActiveModel) View() string {
 	}
 	// Divider and help
 	b.WriteString(mStyleDivider.Render(strings.Repeat("─", 60)) + "\n")
-	b.WriteString(mStyleHelp.Render("Keys: ↑/k, ↓/j, n=next exp, r=refresh, q") + "\n")
+	b.WriteString(mStyleHelp.Render("Keys: ↑/k, ↓/j, p=parent, n=next exp, r=refresh, q") + "\n")
 
 	if len(m.messages) == 0 {
 		b.WriteString("No messages found.\n")
@@ -218,8 +250,12 @@ func (m msgActiveModel) View() string {
 			cursor = mStyleCursor.Render("> ")
 		}
 		created := me.Created.Format(time.RFC3339)
-		// Show status and created
-		fmt.Fprintf(&b, "%s%s %s %s\n", cursor, colorStatus(me.Status), mStyleValue.Render(created), mStyleValue.Render(me.ID))
+		// Show the tree guides, status, created and recipients
+		fmt.Fprintf(&b, "%s%s%s %s %s", cursor, mStyleDivider.Render(m.prefixes[i]), colorStatus(me.Status), mStyleValue.Render(created), mStyleValue.Render(me.ID))
+		if to := recipients(me.ToRole); to != "" {
+			b.WriteString(" " + mStyleLabel.Render(to))
+		}
+		b.WriteString("\n")
 	}
 	// Error line
 	if m.err != "" {
@@ -242,6 +278,12 @@ func (m msgActiveModel) View() string {
 		if me.FromTaskID.Valid {
 			b.WriteString(mStyleLabel.Render("From.task: ") + mStyleValue.Render(me.FromTaskID.String) + "\n")
 		}
+		if me.ParentMessageID.Valid {
+			b.WriteString(mStyleLabel.Render("Reply to: ") + mStyleValue.Render(me.ParentMessageID.String) + "\n")
+		}
+		if len(me.ToRole) > 0 {
+			b.WriteString(mStyleLabel.Render("To: ") + mStyleValue.Render(strings.Join(me.ToRole, ", ")) + "\n")
+		}
 		cr, ok := m.contentCache[me.ContentID]
 		if !ok {
 			b.WriteString(mStyleLabel.Render("Text: ") + mStyleValue.Render("(loading…)") + "\n")
@@ -272,7 +314,21 @@ type contentLoadedMsg struct {
 }
 type msgErrMsg struct{ err error }
 
-func refreshMsgsCmd(role, experimentID string) tea.Cmd {
+// listActiveMessages lists every message of the experiment, whatever its role,
+// newest first, so replies across roles stay under their parent.
+func listActiveMessages(ctx context.Context, db *pgxpool.Pool, experimentID string) ([]pgdao.MessageEvent, error) {
+	rows, err := pgdao.ListExperimentMessages(ctx, db, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]pgdao.MessageEvent, len(rows))
+	for i, r := range rows {
+		out[len(rows)-1-i] = r.MessageEvent
+	}
+	return out, nil
+}
+
+func refreshMsgsCmd(experimentID string) tea.Cmd {
 	return func() tea.Msg {
 		cfg, err := cfgpkg.Load()
 		if err != nil {
@@ -285,7 +341,7 @@ func refreshMsgsCmd(role, experimentID string) tea.Cmd {
 			return msgErrMsg{err}
 		}
 		defer db.Close()
-		rows, err := pgdao.ListMessages(ctx, db, role, experimentID, "", "", 100, 0)
+		rows, err := listActiveMessages(ctx, db, experimentID)
 		if err != nil {
 			return msgErrMsg{err}
 		}
@@ -29,7 +29,7 @@ var deleteCmd = &cobra.Command{
 			return errors.New("--id is required")
 		}
 		if !flagMsgDelForce {
-			fmt.Fprintf(os.Stderr, "About to delete message id=%d.\n", flagMsgDelID)
+			fmt.Fprintf(os.Stderr, "About to delete message id=%s.\n", flagMsgDelID)
 			fmt.Fprint(os.Stderr, "Type 'yes' to confirm: ")
 			reader := bufio.NewReader(os.Stdin)
 			line, _ := reader.ReadString('\n')
@@ -54,15 +54,15 @@ var deleteCmd = &cobra.Command{
 		}
 		if affected == 0 {
 			if flagMsgDelIgnoreMissing {
-				fmt.Fprintf(os.Stderr, "message id=%d not found; ignoring\n", flagMsgDelID)
+				fmt.Fprintf(os.Stderr, "message id=%s not found; ignoring\n", flagMsgDelID)
 				out := map[string]any{"status": "not_found_ignored", "id": flagMsgDelID}
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
 				return enc.Encode(out)
 			}
-			return fmt.Errorf("message id=%d not found", flagMsgDelID)
+			return fmt.Errorf("message id=%s not found", flagMsgDelID)
 		}
-		fmt.Fprintf(os.Stderr, "message deleted id=%d\n", flagMsgDelID)
+		fmt.Fprintf(os.Stderr, "message deleted id=%s\n", flagMsgDelID)
 		out := map[string]any{"status": "deleted", "deleted": true, "id": flagMsgDelID}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
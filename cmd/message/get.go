@@ -65,6 +65,15 @@ var getCmd = &cobra.Command{
 		if m.ErrorMessage.Valid {
 			out["error_message"] = m.ErrorMessage.String
 		}
+		if m.RoleName != "" {
+			out["role"] = m.RoleName
+		}
+		if m.ParentMessageID.Valid {
+			out["parent_message_id"] = m.ParentMessageID.String
+		}
+		if len(m.ToRole) > 0 {
+			out["to_role"] = m.ToRole
+		}
 		if len(m.Tags) > 0 {
 			out["tags"] = m.Tags
 		}
@@ -24,11 +24,12 @@ var (
 	flagMsgListMax        int
 	flagMsgListOutput     string
 	flagMsgListRole       string
+	flagMsgListThread     string
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List messages (filter by experiment, task, or status)",
+	Short: "List messages (filter by experiment, task, or status; or a whole reply thread)",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := cfgpkg.Load()
 		if err != nil {
@@ -45,6 +46,13 @@ var listCmd = &cobra.Command{
 		if effLimit <= 0 {
 			effLimit = flagMsgListLimit
 		}
+		if id := strings.TrimSpace(flagMsgListThread); id != "" {
+			ms, err := pgdao.ListMessageThread(ctx, db, id)
+			if err != nil {
+				return err
+			}
+			return printThread(threadRows(ms))
+		}
 		if strings.TrimSpace(flagMsgListRole) == "" {
 			return errors.New("--role is required")
 		}
@@ -56,16 +64,7 @@ var listCmd = &cobra.Command{
 		if strings.ToLower(strings.TrimSpace(flagMsgListOutput)) == "json" {
 			arr := make([]map[string]any, 0, len(ms))
 			for _, m := range ms {
-				item := map[string]any{"id": m.ID, "content_id": m.ContentID, "status": m.Status, "created": m.Created.Format(time.RFC3339Nano)}
-				if m.FromTaskID.Valid {
-					item["from_task_id"] = m.FromTaskID.String
-				}
-				if m.ExperimentID.Valid {
-					item["experiment_id"] = m.ExperimentID.String
-				}
-				if len(m.Tags) > 0 {
-					item["tags"] = m.Tags
-				}
+				item := messageItem(m)
 				arr = append(arr, item)
 			}
 			enc := json.NewEncoder(os.Stdout)
@@ -82,6 +81,54 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// printThread prints a reply thread as an indented tree.
+func printThread(rows []threadRow) error {
+	fmt.Fprintf(os.Stderr, "thread messages: %d\n", len(rows))
+	if strings.ToLower(strings.TrimSpace(flagMsgListOutput)) == "json" {
+		arr := make([]map[string]any, 0, len(rows))
+		for _, r := range rows {
+			item := messageItem(r.msg)
+			item["depth"] = r.depth
+			arr = append(arr, item)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(arr)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"THREAD", "ROLE", "TO", "STATUS", "CREATED"})
+	table.SetAutoWrapText(false)
+	for _, r := range rows {
+		table.Append([]string{r.prefix + r.msg.ID, r.msg.RoleName, strings.Join(r.msg.ToRole, ","), r.msg.Status, r.msg.Created.Format(time.RFC3339)})
+	}
+	table.Render()
+	return nil
+}
+
+// messageItem is the JSON shape of a listed message.
+func messageItem(m pgdao.MessageEvent) map[string]any {
+	item := map[string]any{"id": m.ID, "content_id": m.ContentID, "status": m.Status, "created": m.Created.Format(time.RFC3339Nano)}
+	if m.RoleName != "" {
+		item["role"] = m.RoleName
+	}
+	if m.FromTaskID.Valid {
+		item["from_task_id"] = m.FromTaskID.String
+	}
+	if m.ExperimentID.Valid {
+		item["experiment_id"] = m.ExperimentID.String
+	}
+	if m.ParentMessageID.Valid {
+		item["parent_message_id"] = m.ParentMessageID.String
+	}
+	if len(m.ToRole) > 0 {
+		item["to_role"] = m.ToRole
+	}
+	if len(m.Tags) > 0 {
+		item["tags"] = m.Tags
+	}
+	return item
+}
+
 func init() {
 	MessageCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&flagMsgListExperiment, "experiment", "", "Filter by experiment UUID")
@@ -91,5 +138,6 @@ func init() {
 	listCmd.Flags().IntVar(&flagMsgListOffset, "offset", 0, "Offset for pagination")
 	listCmd.Flags().IntVar(&flagMsgListMax, "max-results", 20, "Max results to return (default 20)")
 	listCmd.Flags().StringVar(&flagMsgListOutput, "output", "table", "Output format: table or json")
-	listCmd.Flags().StringVar(&flagMsgListRole, "role", "", "Role name (required)")
+	listCmd.Flags().StringVar(&flagMsgListRole, "role", "", "Role name (required unless --thread)")
+	listCmd.Flags().StringVar(&flagMsgListThread, "thread", "", "Message UUID: list its whole reply thread, any role")
 }
@@ -6,7 +6,7 @@ import (
 
 var MessageCmd = &cobra.Command{
 	Use:   "message",
-	Short: "TODO: Describe the 'admin message' command",
+	Short: "Send, list, thread and prune messages",
 }
 
 func init() {
@@ -0,0 +1,158 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagMsgPruneOlder     string
+	flagMsgPruneStatus    string
+	flagMsgPruneRole      string
+	flagMsgPruneKeep      []string
+	flagMsgPruneBatchSize int
+	flagMsgPruneDryRun    bool
+	flagMsgPruneJSON      bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old messages, then collect content nothing references",
+	Long: `Delete messages older than --older-than, optionally only with a given --status or
+--role, keeping any message tagged with a --keep-tagged key. Afterwards message
+content and script content no longer referenced are deleted too, and the bytes
+reclaimed are reported. Work is done in batches of --batch-size rows.
+
+Defaults come from messages.retention in the config (30d, keep "pin", 500).
+Use --dry-run to see what would go first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ret := cfg.Messages.Retention
+		older := ret.OlderThan
+		if cmd.Flags().Changed("older-than") {
+			older = flagMsgPruneOlder
+		}
+		age, err := cfgpkg.ParseAge(older)
+		if err != nil {
+			return fmt.Errorf("--older-than: %w", err)
+		}
+		keep := ret.KeepTagged
+		if cmd.Flags().Changed("keep-tagged") {
+			keep = cleanNames(flagMsgPruneKeep)
+		}
+		batch := ret.BatchSize
+		if cmd.Flags().Changed("batch-size") {
+			batch = flagMsgPruneBatchSize
+		}
+		if batch <= 0 {
+			return errors.New("--batch-size must be positive")
+		}
+		f := pgdao.MessagePruneFilter{
+			Cutoff:     time.Now().Add(-age),
+			Status:     strings.TrimSpace(flagMsgPruneStatus),
+			RoleName:   strings.TrimSpace(flagMsgPruneRole),
+			KeepTagged: keep,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if flagMsgPruneDryRun {
+			plan, err := pgdao.PlanMessagePrune(ctx, db, f)
+			if err != nil {
+				return err
+			}
+			if flagMsgPruneJSON {
+				return json.NewEncoder(os.Stdout).Encode(map[string]any{"applied": false, "cutoff": f.Cutoff, "plan": plan})
+			}
+			fmt.Fprintf(os.Stderr, "dry run: cutoff=%s messages=%d contents=%d scripts=%d reclaim=%s (nothing changed)\n",
+				f.Cutoff.Format(time.RFC3339), plan.Messages, plan.Contents, plan.Scripts, humanBytes(plan.ReclaimedBytes))
+			return nil
+		}
+
+		var res pgdao.MessagePrunePlan
+		res.Messages, _, err = inBatches(batch, func(limit int) (int64, int64, error) {
+			n, err := pgdao.DeleteMessagesBatch(ctx, db, f, limit)
+			return n, 0, err
+		})
+		if err != nil {
+			return fmt.Errorf("after deleting %d messages: %w", res.Messages, err)
+		}
+		res.Contents, res.ContentBytes, err = inBatches(batch, func(limit int) (int64, int64, error) {
+			return pgdao.GCMessageContentBatch(ctx, db, limit)
+		})
+		if err != nil {
+			return fmt.Errorf("after deleting %d messages and %d contents: %w", res.Messages, res.Contents, err)
+		}
+		res.Scripts, res.ScriptBytes, err = inBatches(batch, func(limit int) (int64, int64, error) {
+			return pgdao.GCScriptContentBatch(ctx, db, limit)
+		})
+		if err != nil {
+			return fmt.Errorf("after deleting %d messages, %d contents and %d scripts: %w", res.Messages, res.Contents, res.Scripts, err)
+		}
+		res.ReclaimedBytes = res.ContentBytes + res.ScriptBytes
+		if flagMsgPruneJSON {
+			return json.NewEncoder(os.Stdout).Encode(map[string]any{"applied": true, "cutoff": f.Cutoff, "deleted": res})
+		}
+		fmt.Fprintf(os.Stderr, "pruned: messages=%d contents=%d scripts=%d reclaimed=%s\n",
+			res.Messages, res.Contents, res.Scripts, humanBytes(res.ReclaimedBytes))
+		return nil
+	},
+}
+
+func init() {
+	MessageCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().StringVar(&flagMsgPruneOlder, "older-than", "30d", "Age threshold, e.g. 30d, 2w, 6mo, 1y (default from config)")
+	pruneCmd.Flags().StringVar(&flagMsgPruneStatus, "status", "", "Only prune messages with this status")
+	pruneCmd.Flags().StringVar(&flagMsgPruneRole, "role", "", "Only prune messages of this role")
+	pruneCmd.Flags().StringSliceVar(&flagMsgPruneKeep, "keep-tagged", []string{"pin"}, "Keep messages having any of these tag keys (default from config)")
+	pruneCmd.Flags().IntVar(&flagMsgPruneBatchSize, "batch-size", 500, "Rows deleted per statement (default from config)")
+	pruneCmd.Flags().BoolVar(&flagMsgPruneDryRun, "dry-run", false, "Report what would be deleted without changing anything")
+	pruneCmd.Flags().BoolVar(&flagMsgPruneJSON, "json", false, "Output JSON")
+}
+
+// inBatches calls step with limit until a call handles fewer than limit rows,
+// and returns the summed row counts and bytes. On error the totals so far are
+// returned with it.
+func inBatches(limit int, step func(limit int) (rows, bytes int64, err error)) (int64, int64, error) {
+	var rows, bytes int64
+	for {
+		n, b, err := step(limit)
+		rows += n
+		bytes += b
+		if err != nil || n < int64(limit) {
+			return rows, bytes, err
+		}
+	}
+}
+
+// humanBytes renders n as B, KiB, MiB or GiB.
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	v, i := float64(n)/unit, 0
+	for v >= unit && i < 2 {
+		v /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", v, []string{"KiB", "MiB", "GiB"}[i])
+}
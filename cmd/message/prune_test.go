@@ -0,0 +1,57 @@
+package message
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInBatches(t *testing.T) {
+	// 1200 rows of 10 bytes in batches of 500: 500, 500, 200.
+	left := int64(1200)
+	calls := 0
+	rows, bytes, err := inBatches(500, func(limit int) (int64, int64, error) {
+		calls++
+		n := min(left, int64(limit))
+		left -= n
+		return n, n * 10, nil
+	})
+	if err != nil || rows != 1200 || bytes != 12000 || calls != 3 {
+		t.Fatalf("got rows=%d bytes=%d calls=%d err=%v", rows, bytes, calls, err)
+	}
+
+	// An exact multiple needs one more, empty, batch to finish.
+	calls = 0
+	rows, _, _ = inBatches(5, func(limit int) (int64, int64, error) {
+		calls++
+		if calls <= 2 {
+			return 5, 0, nil
+		}
+		return 0, 0, nil
+	})
+	if rows != 10 || calls != 3 {
+		t.Fatalf("exact multiple: rows=%d calls=%d", rows, calls)
+	}
+
+	// An error stops the loop and keeps the totals so far.
+	boom := errors.New("boom")
+	calls = 0
+	rows, _, err = inBatches(5, func(limit int) (int64, int64, error) {
+		calls++
+		if calls == 2 {
+			return 0, 0, boom
+		}
+		return 5, 0, nil
+	})
+	if !errors.Is(err, boom) || rows != 5 || calls != 2 {
+		t.Fatalf("error: rows=%d calls=%d err=%v", rows, calls, err)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	cases := map[int64]string{0: "0B", 1023: "1023B", 1536: "1.5KiB", 5 << 20: "5.0MiB", 3 << 30: "3.0GiB"}
+	for n, want := range cases {
+		if got := humanBytes(n); got != want {
+			t.Errorf("humanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -35,6 +35,8 @@ var (
 	flagMsgRole     string
 	flagFromTask    string
 	flagFormat      string
+	flagReplyTo     string
+	flagToRole      []string
 )
 
 var setCmd = &cobra.Command{
@@ -120,12 +122,7 @@ var setCmd = &cobra.Command{
 		default:
 			return fmt.Errorf("unsupported --format value: %s", flagFormat)
 		}
-		cid, insErr := pgdao.InsertContent(ctx, db, string(stdinData), parsed)
-		if insErr != nil {
-			return insErr
-		}
-		// Insert event referencing content
-		ev := &pgdao.MessageEvent{ContentID: cid, Status: "ingested", Tags: parseTags(flagTags)}
+		ev := &pgdao.MessageEvent{Status: "ingested", Tags: parseTags(flagTags)}
 		if strings.TrimSpace(flagMsgRole) != "" {
 			ev.RoleName = strings.TrimSpace(flagMsgRole)
 		}
@@ -136,10 +133,36 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagExperiment) != "" {
 			ev.ExperimentID = sql.NullString{String: flagExperiment, Valid: true}
 		}
-		if _, err := pgdao.InsertMessageEvent(ctx, db, ev); err != nil {
+		ev.ToRole = cleanNames(flagToRole)
+		// A reply joins its parent's experiment and, unless told otherwise,
+		// is addressed to the parent's author.
+		if id := strings.TrimSpace(flagReplyTo); id != "" {
+			parent, err := pgdao.GetMessageEventByID(ctx, db, id)
+			if err != nil {
+				return fmt.Errorf("--reply-to: %w", err)
+			}
+			if err := ev.ReplyTo(parent); err != nil {
+				return fmt.Errorf("--reply-to: %w", err)
+			}
+		}
+		// Insert the content, then the event referencing it
+		cid, insErr := pgdao.InsertContent(ctx, db, string(stdinData), parsed)
+		if insErr != nil {
+			return insErr
+		}
+		ev.ContentID = cid
+		msgID, err := pgdao.InsertMessageEvent(ctx, db, ev)
+		if err != nil {
 			return err
 		}
-		fmt.Fprintf(os.Stderr, "stored content id=%d and message row\n", cid)
+		fmt.Fprintf(os.Stderr, "stored content id=%s and message id=%s", cid, msgID)
+		if ev.ParentMessageID.Valid {
+			fmt.Fprintf(os.Stderr, " reply_to=%s", ev.ParentMessageID.String)
+		}
+		if len(ev.ToRole) > 0 {
+			fmt.Fprintf(os.Stderr, " to_role=%s", strings.Join(ev.ToRole, ","))
+		}
+		fmt.Fprintln(os.Stderr)
 		// Return parse error if any (after storing content and message)
 		if parseErr != nil {
 			return parseErr
@@ -159,6 +182,23 @@ func init() {
 	setCmd.Flags().StringVar(&flagExperiment, "experiment", "", "Experiment UUID to link this message to")
 	setCmd.Flags().StringVar(&flagMsgRole, "role", "", "Role name (optional; defaults to 'user')")
 	setCmd.Flags().StringVar(&flagFormat, "format", "", "Optional: interpret stdin as json or yaml and save parsed JSON alongside text")
+	setCmd.Flags().StringVar(&flagReplyTo, "reply-to", "", "Message UUID this message answers (inherits its experiment, which --experiment must match; addressed to its role unless --to-role)")
+	setCmd.Flags().StringSliceVar(&flagToRole, "to-role", nil, "Recipient role(s) (repeat or comma-separated)")
+}
+
+// cleanNames trims and de-duplicates names (roles, tag keys), dropping empty ones.
+func cleanNames(items []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range items {
+		r = strings.TrimSpace(r)
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
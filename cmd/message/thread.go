@@ -0,0 +1,79 @@
+package message
+
+import (
+	"sort"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// threadRow is a message placed in its reply tree.
+type threadRow struct {
+	msg    pgdao.MessageEvent
+	depth  int
+	prefix string // tree guides, e.g. "│  └─ "
+}
+
+// threadRows orders messages as reply trees. Messages whose parent is not in
+// the set are roots and keep their input order; replies follow their parent,
+// oldest first.
+func threadRows(msgs []pgdao.MessageEvent) []threadRow {
+	present := make(map[string]bool, len(msgs))
+	for _, m := range msgs {
+		present[m.ID] = true
+	}
+	var roots []pgdao.MessageEvent
+	children := map[string][]pgdao.MessageEvent{}
+	for _, m := range msgs {
+		if p := m.ParentMessageID; p.Valid && p.String != m.ID && present[p.String] {
+			children[p.String] = append(children[p.String], m)
+			continue
+		}
+		roots = append(roots, m)
+	}
+	for _, c := range children {
+		sort.SliceStable(c, func(i, j int) bool { return c[i].Created.Before(c[j].Created) })
+	}
+	out := make([]threadRow, 0, len(msgs))
+	seen := make(map[string]bool, len(msgs))
+	var walk func(m pgdao.MessageEvent, depth int, guides string, last bool)
+	walk = func(m pgdao.MessageEvent, depth int, guides string, last bool) {
+		if seen[m.ID] {
+			return
+		}
+		seen[m.ID] = true
+		prefix := ""
+		next := ""
+		if depth > 0 {
+			branch, cont := "├─ ", "│  "
+			if last {
+				branch, cont = "└─ ", "   "
+			}
+			prefix = guides + branch
+			next = guides + cont
+		}
+		out = append(out, threadRow{msg: m, depth: depth, prefix: prefix})
+		kids := children[m.ID]
+		for i, k := range kids {
+			walk(k, depth+1, next, i == len(kids)-1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0, "", true)
+	}
+	// Messages caught in a parent cycle have no root; list them flat.
+	for _, m := range msgs {
+		if !seen[m.ID] {
+			walk(m, 0, "", true)
+		}
+	}
+	return out
+}
+
+// recipients renders to_role for display: "→ a,b" or empty.
+func recipients(toRole []string) string {
+	if len(toRole) == 0 {
+		return ""
+	}
+	return "→ " + strings.Join(toRole, ",")
+}
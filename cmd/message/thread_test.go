@@ -0,0 +1,56 @@
+package message
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func msg(id, parent string, minute int) pgdao.MessageEvent {
+	m := pgdao.MessageEvent{ID: id, Created: time.Date(2026, 3, 1, 10, minute, 0, 0, time.UTC)}
+	if parent != "" {
+		m.ParentMessageID = sql.NullString{String: parent, Valid: true}
+	}
+	return m
+}
+
+func TestThreadRows(t *testing.T) {
+	// Newest first, as listed; "x" replies to a message not in the set.
+	in := []pgdao.MessageEvent{
+		msg("c2", "a", 4), msg("x", "gone", 3), msg("b1", "a", 2), msg("b2", "b1", 3), msg("a", "", 1),
+	}
+	rows := threadRows(in)
+	want := []struct {
+		id     string
+		prefix string
+	}{
+		{"x", ""}, {"a", ""}, {"b1", "├─ "}, {"b2", "│  └─ "}, {"c2", "└─ "},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %d, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		if rows[i].msg.ID != w.id || rows[i].prefix != w.prefix {
+			t.Errorf("row %d = %s %q, want %s %q", i, rows[i].msg.ID, rows[i].prefix, w.id, w.prefix)
+		}
+	}
+	if rows[3].depth != 2 {
+		t.Errorf("b2 depth = %d, want 2", rows[3].depth)
+	}
+}
+
+func TestThreadRowsCycle(t *testing.T) {
+	rows := threadRows([]pgdao.MessageEvent{msg("p", "q", 1), msg("q", "p", 2)})
+	if len(rows) != 2 {
+		t.Fatalf("cycle: rows = %d, want 2", len(rows))
+	}
+}
+
+func TestCleanRoles(t *testing.T) {
+	got := cleanNames([]string{" qa", "", "dev", "qa"})
+	if len(got) != 2 || got[0] != "qa" || got[1] != "dev" {
+		t.Fatalf("cleanNames = %v", got)
+	}
+}
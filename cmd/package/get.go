@@ -48,9 +48,9 @@ var getCmd = &cobra.Command{
 			return err
 		}
 		// Human
-		fmt.Fprintf(os.Stderr, "package id=%s role_name=%q task_id=%s\n", p.ID, p.RoleName, p.TaskID)
+		fmt.Fprintf(os.Stderr, "package id=%s role_name=%q task_id=%s constraint=%s\n", p.ID, p.RoleName, p.TaskID, p.Constraint)
 		// JSON
-		out := map[string]any{"id": p.ID, "role_name": p.RoleName, "task_id": p.TaskID}
+		out := map[string]any{"id": p.ID, "role_name": p.RoleName, "task_id": p.TaskID, "constraint": p.Constraint}
 		if p.Created.Valid {
 			out["created"] = p.Created.Time.Format(time.RFC3339Nano)
 		}
@@ -49,7 +49,7 @@ var listCmd = &cobra.Command{
 		if strings.ToLower(strings.TrimSpace(flagPkgListOutput)) == "json" {
 			arr := make([]map[string]any, 0, len(items))
 			for _, p := range items {
-				m := map[string]any{"id": p.ID, "role_name": p.RoleName, "task_id": p.TaskID}
+				m := map[string]any{"id": p.ID, "role_name": p.RoleName, "task_id": p.TaskID, "constraint": p.Constraint}
 				if p.Created.Valid {
 					m["created"] = p.Created.Time.Format(time.RFC3339Nano)
 				}
@@ -63,9 +63,9 @@ var listCmd = &cobra.Command{
 			return enc.Encode(arr)
 		}
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"ID", "ROLE", "TASK_ID"})
+		table.SetHeader([]string{"ID", "ROLE", "TASK_ID", "CONSTRAINT"})
 		for _, p := range items {
-			table.Append([]string{p.ID, p.RoleName, p.TaskID})
+			table.Append([]string{p.ID, p.RoleName, p.TaskID, p.Constraint})
 		}
 		table.Render()
 		return nil
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// resolveFunc returns the task a package reaches under constraint.
+type resolveFunc func(p *pgdao.Package, constraint string) (string, error)
+
+// resolution is where one package currently points.
+type resolution struct {
+	Pkg      *pgdao.Package
+	Resolved string // task id; the pin itself when nothing newer matches
+}
+
+// Behind reports whether a newer task matches the package constraint.
+func (r resolution) Behind() bool { return r.Resolved != r.Pkg.TaskID }
+
+// resolvePackages resolves each package under its own constraint.
+func resolvePackages(items []pgdao.Package, resolve resolveFunc) ([]resolution, error) {
+	out := make([]resolution, 0, len(items))
+	for i := range items {
+		p := &items[i]
+		id, err := resolve(p, p.Constraint)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, resolution{Pkg: p, Resolved: id})
+	}
+	return out, nil
+}
+
+// upgradeStep is one package whose pin would move.
+type upgradeStep struct {
+	Pkg    *pgdao.Package
+	Level  string // the narrower of --level and the package constraint
+	Target string
+	// Skipped is set when another package of the role already pins Target;
+	// (role_name, task_id) is unique so the pin cannot move there.
+	Skipped bool
+}
+
+// parseUpgradeLevel validates --level.
+func parseUpgradeLevel(s string) (string, error) {
+	level := strings.ToLower(strings.TrimSpace(s))
+	if level != "patch" && level != "minor" && level != "major" {
+		return "", errors.New("--level must be one of: patch|minor|major")
+	}
+	return level, nil
+}
+
+// planUpgrades resolves each package under the narrower of level and its own
+// constraint and returns the packages whose pin would move, in order. Moves
+// are applied to the plan as it goes, so a pin freed by an earlier package can
+// be taken by a later one.
+func planUpgrades(items []pgdao.Package, level string, resolve resolveFunc) ([]upgradeStep, error) {
+	pinned := map[string]bool{}
+	for _, p := range items {
+		pinned[p.TaskID] = true
+	}
+	var steps []upgradeStep
+	for i := range items {
+		p := &items[i]
+		eff := pgdao.NarrowerPackageConstraint(level, p.Constraint)
+		target, err := resolve(p, eff)
+		if err != nil {
+			return nil, err
+		}
+		if target == p.TaskID {
+			continue
+		}
+		st := upgradeStep{Pkg: p, Level: eff, Target: target, Skipped: pinned[target]}
+		if !st.Skipped {
+			delete(pinned, p.TaskID)
+			pinned[target] = true
+		}
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// fakeResolve follows chains[task][constraint] and stays put when absent.
+func fakeResolve(chains map[string]map[string]string, seen map[string]string) resolveFunc {
+	return func(p *pgdao.Package, constraint string) (string, error) {
+		if seen != nil {
+			seen[p.ID] = constraint
+		}
+		if to, ok := chains[p.TaskID][constraint]; ok {
+			return to, nil
+		}
+		return p.TaskID, nil
+	}
+}
+
+func TestResolvePackages(t *testing.T) {
+	items := []pgdao.Package{
+		{ID: "a", TaskID: "t1", Constraint: "minor"},
+		{ID: "b", TaskID: "t5", Constraint: "minor"}, // no newer matching version
+	}
+	res, err := resolvePackages(items, fakeResolve(map[string]map[string]string{"t1": {"minor": "t2"}}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0].Behind() || res[0].Resolved != "t2" {
+		t.Fatalf("a: %+v", res[0])
+	}
+	if res[1].Behind() || res[1].Resolved != "t5" {
+		t.Fatalf("b: %+v", res[1])
+	}
+
+	boom := errors.New("boom")
+	if _, err := resolvePackages(items, func(*pgdao.Package, string) (string, error) { return "", boom }); !errors.Is(err, boom) {
+		t.Fatalf("error not returned: %v", err)
+	}
+}
+
+func TestParseUpgradeLevel(t *testing.T) {
+	if l, err := parseUpgradeLevel(" Minor "); err != nil || l != "minor" {
+		t.Fatalf("got %q, %v", l, err)
+	}
+	for _, bad := range []string{"", "exact", "latest"} {
+		if _, err := parseUpgradeLevel(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestPlanUpgrades(t *testing.T) {
+	chains := map[string]map[string]string{
+		"t1": {"patch": "t1p", "minor": "t1m"},
+		"t2": {"minor": "t2m"},
+	}
+	cases := []struct {
+		name      string
+		level     string
+		pkg       pgdao.Package
+		wantLevel string
+		wantMove  string // "" when the pin stays
+	}{
+		{"level narrower than constraint", "patch", pgdao.Package{ID: "p", TaskID: "t1", Constraint: "major"}, "patch", "t1p"},
+		{"constraint already narrower", "major", pgdao.Package{ID: "p", TaskID: "t1", Constraint: "patch"}, "patch", "t1p"},
+		{"exact never moves", "major", pgdao.Package{ID: "p", TaskID: "t1", Constraint: "exact"}, "exact", ""},
+		{"no matching version", "patch", pgdao.Package{ID: "p", TaskID: "t2", Constraint: "minor"}, "patch", ""},
+		{"unknown task", "major", pgdao.Package{ID: "p", TaskID: "t9", Constraint: "major"}, "major", ""},
+	}
+	for _, c := range cases {
+		seen := map[string]string{}
+		steps, err := planUpgrades([]pgdao.Package{c.pkg}, c.level, fakeResolve(chains, seen))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if seen["p"] != c.wantLevel {
+			t.Errorf("%s: resolved with %q, want %q", c.name, seen["p"], c.wantLevel)
+		}
+		switch {
+		case c.wantMove == "" && len(steps) != 0:
+			t.Errorf("%s: expected no step, got %+v", c.name, steps)
+		case c.wantMove != "" && (len(steps) != 1 || steps[0].Target != c.wantMove || steps[0].Skipped):
+			t.Errorf("%s: expected move to %s, got %+v", c.name, c.wantMove, steps)
+		}
+	}
+}
+
+func TestPlanUpgradesPinCollisions(t *testing.T) {
+	// a would move onto b's pin; b moves on first only if listed first.
+	chains := map[string]map[string]string{"t1": {"minor": "t2"}, "t2": {"minor": "t3"}}
+	items := []pgdao.Package{
+		{ID: "a", TaskID: "t1", Constraint: "minor"},
+		{ID: "b", TaskID: "t2", Constraint: "minor"},
+	}
+	steps, err := planUpgrades(items, "minor", fakeResolve(chains, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || !steps[0].Skipped || steps[1].Skipped || steps[1].Target != "t3" {
+		t.Fatalf("a blocked by b's pin, b moves: %+v", steps)
+	}
+
+	items[0], items[1] = items[1], items[0]
+	steps, err = planUpgrades(items, "minor", fakeResolve(chains, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || steps[0].Skipped || steps[1].Skipped || steps[1].Target != "t2" {
+		t.Fatalf("b frees t2 for a: %+v", steps)
+	}
+}
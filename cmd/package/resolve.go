@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagPkgResolveRole   string
+	flagPkgResolveOutput string
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Print the concrete task each package of a role resolves to",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagPkgResolveRole) == "" {
+			return errors.New("--role is required")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		items, err := pgdao.ListPackages(ctx, db, flagPkgResolveRole, "", 1000, 0)
+		if err != nil {
+			return err
+		}
+		res, err := resolvePackages(items, func(p *pgdao.Package, constraint string) (string, error) {
+			id, err := pgdao.ResolvePackageTask(ctx, db, p, constraint)
+			if err != nil {
+				return "", fmt.Errorf("cmd=package resolve params={role:%s,package:%s}: %w", flagPkgResolveRole, p.ID, err)
+			}
+			return id, nil
+		})
+		if err != nil {
+			return err
+		}
+		rows := make([]map[string]any, 0, len(res))
+		for _, r := range res {
+			pinned, err := pgdao.GetTaskByID(ctx, db, r.Pkg.TaskID)
+			if err != nil {
+				return err
+			}
+			resolved := pinned
+			if r.Behind() {
+				if resolved, err = pgdao.GetTaskByID(ctx, db, r.Resolved); err != nil {
+					return err
+				}
+			}
+			rows = append(rows, map[string]any{
+				"id":               r.Pkg.ID,
+				"constraint":       r.Pkg.Constraint,
+				"pinned_task_id":   pinned.ID,
+				"pinned_variant":   pinned.Variant,
+				"resolved_task_id": resolved.ID,
+				"resolved_variant": resolved.Variant,
+				"behind":           r.Behind(),
+			})
+		}
+		fmt.Fprintf(os.Stderr, "packages resolved: %d\n", len(rows))
+		if strings.ToLower(strings.TrimSpace(flagPkgResolveOutput)) == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rows)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "CONSTRAINT", "PINNED", "RESOLVED", "RESOLVED_TASK_ID"})
+		for _, r := range rows {
+			table.Append([]string{r["id"].(string), r["constraint"].(string), r["pinned_variant"].(string), r["resolved_variant"].(string), r["resolved_task_id"].(string)})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+func init() {
+	PackageCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().StringVar(&flagPkgResolveRole, "role", "", "Role name (required)")
+	resolveCmd.Flags().StringVar(&flagPkgResolveOutput, "output", "table", "Output format: table or json")
+}
@@ -17,6 +17,8 @@ import (
 var (
 	flagPkgRoleName string
 	flagPkgVariant  string
+	flagPkgConstr   string
+	flagPkgIfMatch  string
 )
 
 var setCmd = &cobra.Command{
@@ -26,6 +28,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagPkgRoleName) == "" || strings.TrimSpace(flagPkgVariant) == "" {
 			return errors.New("--role and --variant are required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagPkgIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -37,19 +43,20 @@ var setCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		p, err := pgdao.UpsertPackage(ctx, db, flagPkgRoleName, flagPkgVariant)
+		p, err := pgdao.UpsertPackage(ctx, db, flagPkgRoleName, flagPkgVariant, flagPkgConstr, ifMatch)
 		if err != nil {
 			return err
 		}
 		// Human-readable
-		fmt.Fprintf(os.Stderr, "package set role_name=%q variant=%q task_id=%s id=%s\n", flagPkgRoleName, flagPkgVariant, p.TaskID, p.ID)
+		fmt.Fprintf(os.Stderr, "package set role_name=%q variant=%q task_id=%s constraint=%s id=%s\n", flagPkgRoleName, flagPkgVariant, p.TaskID, p.Constraint, p.ID)
 		// JSON
 		out := map[string]any{
-			"status":    "upserted",
-			"id":        p.ID,
-			"role_name": p.RoleName,
-			"variant":   flagPkgVariant,
-			"task_id":   p.TaskID,
+			"status":     "upserted",
+			"id":         p.ID,
+			"role_name":  p.RoleName,
+			"variant":    flagPkgVariant,
+			"task_id":    p.TaskID,
+			"constraint": p.Constraint,
 		}
 		if p.Created.Valid {
 			out["created"] = p.Created.Time.Format(time.RFC3339Nano)
@@ -67,4 +74,6 @@ func init() {
 	PackageCmd.AddCommand(setCmd)
 	setCmd.Flags().StringVar(&flagPkgRoleName, "role", "", "Role name (e.g., user, admin) (required)")
 	setCmd.Flags().StringVar(&flagPkgVariant, "variant", "", "Task selector variant (e.g., unit/go) (required)")
+	setCmd.Flags().StringVar(&flagPkgConstr, "constraint", "", "Version constraint: exact|patch|minor|major (default keeps current; exact for new)")
+	setCmd.Flags().StringVar(&flagPkgIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set)")
 }
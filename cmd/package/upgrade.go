@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagPkgUpgradeRole   string
+	flagPkgUpgradeLevel  string
+	flagPkgUpgradeDryRun bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Move package pins forward along task replacements (bounded by each package constraint)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagPkgUpgradeRole) == "" {
+			return errors.New("--role is required")
+		}
+		level, err := parseUpgradeLevel(flagPkgUpgradeLevel)
+		if err != nil {
+			return err
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		items, err := pgdao.ListPackages(ctx, db, flagPkgUpgradeRole, "", 1000, 0)
+		if err != nil {
+			return err
+		}
+		steps, err := planUpgrades(items, level, func(p *pgdao.Package, constraint string) (string, error) {
+			id, err := pgdao.ResolvePackageTask(ctx, db, p, constraint)
+			if err != nil {
+				return "", fmt.Errorf("cmd=package upgrade params={role:%s,level:%s,package:%s}: %w", flagPkgUpgradeRole, level, p.ID, err)
+			}
+			return id, nil
+		})
+		if err != nil {
+			return err
+		}
+		changes := make([]map[string]any, 0, len(steps))
+		moved := 0
+		for _, st := range steps {
+			p := st.Pkg
+			from, err := pgdao.GetTaskByID(ctx, db, p.TaskID)
+			if err != nil {
+				return err
+			}
+			to, err := pgdao.GetTaskByID(ctx, db, st.Target)
+			if err != nil {
+				return err
+			}
+			change := map[string]any{
+				"id":           p.ID,
+				"constraint":   p.Constraint,
+				"level":        st.Level,
+				"from_task_id": from.ID,
+				"from_variant": from.Variant,
+				"to_task_id":   to.ID,
+				"to_variant":   to.Variant,
+			}
+			switch {
+			case st.Skipped:
+				change["status"] = "skipped-already-pinned"
+			case flagPkgUpgradeDryRun:
+				change["status"] = "would-upgrade"
+				fmt.Fprintf(os.Stderr, "[dry-run] package %s %s -> %s (%s)\n", p.ID, from.Variant, to.Variant, st.Level)
+			default:
+				if err := pgdao.UpdatePackageTask(ctx, db, p.ID, st.Target); err != nil {
+					return err
+				}
+				moved++
+				change["status"] = "upgraded"
+				fmt.Fprintf(os.Stderr, "package %s %s -> %s (%s)\n", p.ID, from.Variant, to.Variant, st.Level)
+			}
+			changes = append(changes, change)
+		}
+		fmt.Fprintf(os.Stderr, "packages upgraded: %d (candidates=%d, total=%d)\n", moved, len(changes), len(items))
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	},
+}
+
+func init() {
+	PackageCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().StringVar(&flagPkgUpgradeRole, "role", "", "Role name (required)")
+	upgradeCmd.Flags().StringVar(&flagPkgUpgradeLevel, "level", "minor", "Highest replacement level to follow: patch|minor|major")
+	upgradeCmd.Flags().BoolVar(&flagPkgUpgradeDryRun, "dry-run", false, "Report what would change without moving pins")
+}
@@ -16,11 +16,12 @@ import (
 )
 
 var (
-	flagPrjName  string
-	flagPrjRole  string
-	flagPrjDesc  string
-	flagPrjNotes string
-	flagPrjTags  []string
+	flagPrjName    string
+	flagPrjRole    string
+	flagPrjDesc    string
+	flagPrjNotes   string
+	flagPrjTags    []string
+	flagPrjIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -33,6 +34,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagPrjRole) == "" {
 			return errors.New("--role is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagPrjIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -45,7 +50,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		p := &pgdao.Project{Name: flagPrjName, RoleName: flagPrjRole}
+		p := &pgdao.Project{Name: flagPrjName, RoleName: flagPrjRole, IfMatch: ifMatch}
 		if flagPrjDesc != "" {
 			p.Description = sql.NullString{String: flagPrjDesc, Valid: true}
 		}
@@ -86,6 +91,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagPrjDesc, "description", "", "Plain text description")
 	setCmd.Flags().StringVar(&flagPrjNotes, "notes", "", "Markdown-formatted notes")
 	setCmd.Flags().StringSliceVar(&flagPrjTags, "tags", nil, "Tags as key=value pairs (repeat or comma-separated). Plain values mapped to true")
+	setCmd.Flags().StringVar(&flagPrjIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
@@ -0,0 +1,72 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+)
+
+// projectYAML is the folder format for projects: <name>.project.yaml.
+// The role comes from the id:<role> scope, so it is not stored in the file.
+type projectYAML struct {
+	Name        string                `yaml:"name"`
+	Description *string               `yaml:"description,omitempty"`
+	Notes       *syncfs.LiteralString `yaml:"notes,omitempty"`
+	Tags        map[string]any        `yaml:"tags,omitempty"`
+}
+
+func openProjectAdapter(ctx context.Context, role string) (syncfs.Adapter, func(), error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := &syncfs.DocAdapter[projectYAML]{
+		Noun:  "project",
+		KeyOf: func(y projectYAML) string { return y.Name },
+		List: func(ctx context.Context) ([]projectYAML, error) {
+			ps, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Project, error) {
+				return pgdao.ListProjects(ctx, db, role, limit, offset)
+			})
+			if err != nil {
+				return nil, err
+			}
+			out := make([]projectYAML, 0, len(ps))
+			for _, p := range ps {
+				out = append(out, projectYAML{
+					Name: p.Name, Tags: p.Tags,
+					Description: syncfs.OptString(p.Description.String, p.Description.Valid),
+					Notes:       syncfs.OptLiteral(p.Notes.String, p.Notes.Valid),
+				})
+			}
+			return out, nil
+		},
+		Put: func(ctx context.Context, y projectYAML) error {
+			p := &pgdao.Project{Name: y.Name, RoleName: role, Tags: y.Tags}
+			if d := syncfs.Deref(y.Description); d != "" {
+				p.Description = sql.NullString{String: d, Valid: true}
+			}
+			if n := syncfs.DerefLiteral(y.Notes); n != "" {
+				p.Notes = sql.NullString{String: n, Valid: true}
+			}
+			return pgdao.UpsertProject(ctx, db, p)
+		},
+		Remove: func(ctx context.Context, y projectYAML) error {
+			_, err := pgdao.DeleteProject(ctx, db, y.Name, role)
+			return err
+		},
+	}
+	return a, db.Close, nil
+}
+
+func init() {
+	for _, c := range syncfs.Commands(syncfs.CommandSpec{Noun: "project", ScopeHelp: "role", Open: openProjectAdapter}) {
+		ProjectCmd.AddCommand(c)
+	}
+}
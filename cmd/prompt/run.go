@@ -31,6 +31,7 @@ var (
 	flagMaxOutTokens   int
 	flagHasMaxTokens   bool
 	flagJSON           bool
+	flagExperiment     string
 )
 
 var runCmd = &cobra.Command{
@@ -125,6 +126,20 @@ var runCmd = &cobra.Command{
 		// Call service
 		svcCfg := convertToServiceConfig(cfg)
 		resp, err := deps.ResponsesService.CreateResponse(ctx, svcCfg, req, llm)
+		if strings.TrimSpace(flagExperiment) != "" {
+			params := map[string]any{"tool": cfg.Name, "model": cfg.Model}
+			if req.Temperature != nil {
+				params["temperature"] = *req.Temperature
+			}
+			if req.MaxOutputTokens != nil {
+				params["max_output_tokens"] = *req.MaxOutputTokens
+			}
+			var usage *responsesvc.Usage
+			if resp != nil {
+				usage = resp.Usage
+			}
+			recordPromptRun(flagExperiment, params, usage, err)
+		}
 		if err != nil {
 			return err
 		}
@@ -152,12 +167,49 @@ func init() {
 	runCmd.Flags().BoolVar(&flagJSON, "json", false, "Print full JSON response")
 	runCmd.Flags().Float32Var(&flagTemperature, "temperature", 0, "Sampling temperature")
 	runCmd.Flags().IntVar(&flagMaxOutTokens, "max-output-tokens", 0, "Max output tokens")
+	runCmd.Flags().StringVar(&flagExperiment, "experiment", "", "Experiment UUID to record params, token usage and status on")
 	// Track whether flags were explicitly set
 	runCmd.Flags().Lookup("temperature").NoOptDefVal = "0"
 	runCmd.Flags().Lookup("temperature").DefValue = ""
 	runCmd.Flags().Lookup("max-output-tokens").NoOptDefVal = "0"
 }
 
+// recordPromptRun stores the settings a prompt ran with on its experiment,
+// adds its token usage to the metrics and folds its outcome into the status.
+// It only warns on failure so the response is still printed.
+func recordPromptRun(experimentID string, params map[string]any, usage *responsesvc.Usage, runErr error) {
+	warn := func(err error) {
+		fmt.Fprintf(os.Stderr, "warning: experiment %s not updated: %v\n", experimentID, err)
+	}
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		warn(err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		warn(err)
+		return
+	}
+	defer db.Close()
+	outcome := pgdao.ExperimentSucceeded
+	if runErr != nil {
+		outcome = pgdao.ExperimentFailed
+	}
+	add := map[string]float64{"prompt_runs": 1}
+	if usage != nil {
+		add["input_tokens"] = float64(usage.InputTokens)
+		add["output_tokens"] = float64(usage.OutputTokens)
+		add["total_tokens"] = float64(usage.TotalTokens)
+	}
+	u := pgdao.ExperimentUpdate{Params: params, AddMetrics: add, Outcome: outcome}
+	if _, err := pgdao.UpdateExperiment(ctx, db, experimentID, u); err != nil {
+		warn(err)
+	}
+}
+
 func readInput(inline, path string) (any, error) {
 	if strings.TrimSpace(inline) != "" {
 		return inline, nil
@@ -269,8 +321,30 @@ func runRemote(cmd *cobra.Command, args []string) error {
 	}
 
 	var out map[string]any
-	if err := conn.Invoke(context.Background(), "/prompt.v1.PromptService/Run", req, &out); err != nil {
-		return err
+	invokeErr := conn.Invoke(context.Background(), "/prompt.v1.PromptService/Run", req, &out)
+	if strings.TrimSpace(flagExperiment) != "" {
+		params := map[string]any{"tool": flagToolName}
+		if m, ok := out["model"].(string); ok && m != "" {
+			params["model"] = m
+		}
+		if flagHasTemperature {
+			params["temperature"] = flagTemperature
+		}
+		if flagHasMaxTokens {
+			params["max_output_tokens"] = flagMaxOutTokens
+		}
+		var usage *responsesvc.Usage
+		if u, ok := out["usage"]; ok {
+			b, _ := json.Marshal(u)
+			usage = &responsesvc.Usage{}
+			if json.Unmarshal(b, usage) != nil {
+				usage = nil
+			}
+		}
+		recordPromptRun(flagExperiment, params, usage, invokeErr)
+	}
+	if invokeErr != nil {
+		return invokeErr
 	}
 	// If --json, pretty-print raw JSON
 	if flagJSON {
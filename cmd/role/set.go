@@ -16,11 +16,12 @@ import (
 )
 
 var (
-	flagRoleName  string
-	flagRoleTitle string
-	flagRoleDesc  string
-	flagRoleNotes string
-	flagRoleTags  []string
+	flagRoleName    string
+	flagRoleTitle   string
+	flagRoleDesc    string
+	flagRoleNotes   string
+	flagRoleTags    []string
+	flagRoleIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -30,6 +31,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagRoleName) == "" || strings.TrimSpace(flagRoleTitle) == "" {
 			return errors.New("--name and --title are required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagRoleIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -41,7 +46,7 @@ var setCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		r := &pgdao.Role{Name: flagRoleName, Title: flagRoleTitle}
+		r := &pgdao.Role{Name: flagRoleName, Title: flagRoleTitle, IfMatch: ifMatch}
 		if flagRoleDesc != "" {
 			r.Description = sql.NullString{String: flagRoleDesc, Valid: true}
 		}
@@ -90,6 +95,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagRoleDesc, "description", "", "Role description")
 	setCmd.Flags().StringVar(&flagRoleNotes, "notes", "", "Notes (markdown)")
 	setCmd.Flags().StringSliceVar(&flagRoleTags, "tags", nil, "Tags as key=value pairs (repeat or comma-separated). Plain values mapped to true")
+	setCmd.Flags().StringVar(&flagRoleIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
@@ -0,0 +1,89 @@
+package role
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
+)
+
+// roleYAML is the folder format for roles: <name>.role.yaml
+type roleYAML struct {
+	Name        string                `yaml:"name"`
+	Title       string                `yaml:"title"`
+	Description *string               `yaml:"description,omitempty"`
+	Notes       *syncfs.LiteralString `yaml:"notes,omitempty"`
+	Tags        map[string]any        `yaml:"tags,omitempty"`
+}
+
+// openRoleAdapter scopes the adapter to one role name, or to every role with id:*.
+func openRoleAdapter(ctx context.Context, scope string) (syncfs.Adapter, func(), error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := &syncfs.DocAdapter[roleYAML]{
+		Noun:  "role",
+		KeyOf: func(y roleYAML) string { return y.Name },
+		List: func(ctx context.Context) ([]roleYAML, error) {
+			var rs []pgdao.Role
+			if scope == "*" {
+				all, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Role, error) { return pgdao.ListRoles(ctx, db, limit, offset) })
+				if err != nil {
+					return nil, err
+				}
+				rs = all
+			} else {
+				r, err := pgdao.GetRoleByName(ctx, db, scope)
+				switch {
+				case err == nil:
+					rs = append(rs, *r)
+				case !errors.Is(err, pgx.ErrNoRows):
+					return nil, err
+				}
+			}
+			out := make([]roleYAML, 0, len(rs))
+			for _, r := range rs {
+				out = append(out, roleYAML{
+					Name: r.Name, Title: r.Title, Tags: r.Tags,
+					Description: syncfs.OptString(r.Description.String, r.Description.Valid),
+					Notes:       syncfs.OptLiteral(r.Notes.String, r.Notes.Valid),
+				})
+			}
+			return out, nil
+		},
+		Put: func(ctx context.Context, y roleYAML) error {
+			r := &pgdao.Role{Name: y.Name, Title: y.Title, Tags: y.Tags}
+			if d := syncfs.Deref(y.Description); d != "" {
+				r.Description = sql.NullString{String: d, Valid: true}
+			}
+			if n := syncfs.DerefLiteral(y.Notes); n != "" {
+				r.Notes = sql.NullString{String: n, Valid: true}
+			}
+			return pgdao.UpsertRole(ctx, db, r)
+		},
+		Remove: func(ctx context.Context, y roleYAML) error {
+			_, err := pgdao.DeleteRole(ctx, db, y.Name)
+			return err
+		},
+	}
+	if scope != "*" {
+		// a single-role scope ignores other roles' files in the folder
+		a.Keep = func(y roleYAML) bool { return y.Name == scope }
+	}
+	return a, db.Close, nil
+}
+
+func init() {
+	for _, c := range syncfs.Commands(syncfs.CommandSpec{Noun: "role", ScopeHelp: "name|*", Open: openRoleAdapter}) {
+		RoleCmd.AddCommand(c)
+	}
+}
@@ -28,6 +28,7 @@ var (
 	flagScrName     string
 	flagScrVariant  string
 	flagScrArchived bool
+	flagScrIfMatch  string
 )
 
 var setCmd = &cobra.Command{
@@ -62,6 +63,13 @@ var setCmd = &cobra.Command{
 			return errors.New("no script content on stdin")
 		}
 
+		ifMatch, err := pgdao.ParseIfMatch(flagScrIfMatch)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.IsZero() && strings.TrimSpace(flagScrID) == "" {
+			return errors.New("--if-match requires --id")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -81,7 +89,7 @@ var setCmd = &cobra.Command{
 		}
 
 		// Upsert script
-		s := &pgdao.Script{ID: flagScrID, Title: flagScrTitle, RoleName: flagScrRole, ScriptContentID: cid}
+		s := &pgdao.Script{ID: flagScrID, Title: flagScrTitle, RoleName: flagScrRole, ScriptContentID: cid, IfMatch: ifMatch}
 		if flagScrDesc != "" {
 			s.Description = sql.NullString{String: flagScrDesc, Valid: true}
 		}
@@ -136,6 +144,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagScrName, "name", "", "Complex-name: name (exact lookup key)")
 	setCmd.Flags().StringVar(&flagScrVariant, "variant", "", "Complex-name: variant (exact lookup key; may be empty)")
 	setCmd.Flags().BoolVar(&flagScrArchived, "archived", false, "Mark script as archived (excluded from active lookups)")
+	setCmd.Flags().StringVar(&flagScrIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
@@ -0,0 +1,242 @@
+package script
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// scriptYAML is the folder format for scripts: <name>[-<variant>].script.yaml plus the
+// body as a sibling file named by body_file. Identity is the complex name (name, variant)
+// within the id:<role> scope.
+type scriptYAML struct {
+	Name        string                `yaml:"name"`
+	Variant     string                `yaml:"variant,omitempty"`
+	Title       string                `yaml:"title"`
+	Description *string               `yaml:"description,omitempty"`
+	Motivation  *string               `yaml:"motivation,omitempty"`
+	Notes       *syncfs.LiteralString `yaml:"notes,omitempty"`
+	Tags        map[string]any        `yaml:"tags,omitempty"`
+	Archived    bool                  `yaml:"archived"`
+	BodyFile    string                `yaml:"body_file"`
+}
+
+type scriptRecord struct {
+	yaml scriptYAML
+	id   string // remote only
+	body string
+}
+
+const scriptSuffix = ".script.yaml"
+
+func scriptKey(name, variant string) string {
+	if variant == "" {
+		return name
+	}
+	return name + "/" + variant
+}
+
+func scriptFileBase(name, variant string) string {
+	base := syncfs.SanitizeForFile(name)
+	if v := syncfs.SanitizeForFile(variant); v != "" {
+		base += "-" + v
+	}
+	if base == "" {
+		base = "script"
+	}
+	return base
+}
+
+// scriptAdapter syncs the scripts of one role; scripts are never matched across roles.
+type scriptAdapter struct {
+	db   *pgxpool.Pool
+	role string
+	// bodyFiles keeps the body_file of each local script (by key) so remote
+	// records are rendered under the same name.
+	bodyFiles map[string]string
+}
+
+func (a *scriptAdapter) Kind() string { return "script" }
+
+func (a *scriptAdapter) record(y scriptYAML, file, body string) (syncfs.Record, error) {
+	b, err := yaml.Marshal(y)
+	if err != nil {
+		return syncfs.Record{}, err
+	}
+	return syncfs.Record{
+		Kind: "script",
+		Key:  scriptKey(y.Name, y.Variant),
+		Files: []syncfs.File{
+			{Name: file, Data: b},
+			{Name: y.BodyFile, Data: []byte(pgdao.CanonicalizeText(body) + "\n")},
+		},
+	}, nil
+}
+
+func (a *scriptAdapter) LoadRemote(ctx context.Context) ([]syncfs.Record, error) {
+	ss, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Script, error) {
+		return pgdao.ListScripts(ctx, a.db, a.role, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Listing is most recent first; keep one script per complex name, preferring active ones.
+	chosen := map[string]pgdao.Script{}
+	var order []string
+	for _, s := range ss {
+		k := scriptKey(s.ComplexName.Name, s.ComplexName.Variant)
+		prev, ok := chosen[k]
+		if !ok {
+			order = append(order, k)
+			chosen[k] = s
+			continue
+		}
+		if prev.Archived && !s.Archived {
+			chosen[k] = s
+		}
+	}
+	out := make([]syncfs.Record, 0, len(order))
+	for _, k := range order {
+		s := chosen[k]
+		body, err := pgdao.GetScriptContent(ctx, a.db, s.ScriptContentID)
+		if err != nil {
+			return nil, err
+		}
+		y := a.remoteYAML(s)
+		r, err := a.record(y, scriptFileBase(y.Name, y.Variant)+scriptSuffix, body)
+		if err != nil {
+			return nil, err
+		}
+		r.Value = scriptRecord{yaml: y, id: s.ID, body: body}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// remoteYAML renders a stored script; its body file keeps the local name when
+// the folder has the script, else <base>.sh.
+func (a *scriptAdapter) remoteYAML(s pgdao.Script) scriptYAML {
+	y := scriptYAML{
+		Name: s.ComplexName.Name, Variant: s.ComplexName.Variant, Title: s.Title, Tags: s.Tags, Archived: s.Archived,
+		Description: syncfs.OptString(s.Description.String, s.Description.Valid),
+		Motivation:  syncfs.OptString(s.Motivation.String, s.Motivation.Valid),
+		Notes:       syncfs.OptLiteral(s.Notes.String, s.Notes.Valid),
+		BodyFile:    scriptFileBase(s.ComplexName.Name, s.ComplexName.Variant) + ".sh",
+	}
+	if name, ok := a.bodyFiles[scriptKey(y.Name, y.Variant)]; ok {
+		y.BodyFile = name
+	}
+	return y
+}
+
+func (a *scriptAdapter) LoadLocal(dir string) ([]syncfs.Record, error) {
+	a.bodyFiles = map[string]string{}
+	var out []syncfs.Record
+	seen := map[string]string{}
+	err := syncfs.EachFile(dir, scriptSuffix, func(name string, data []byte) error {
+		var y scriptYAML
+		if err := yaml.Unmarshal(data, &y); err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		if strings.TrimSpace(y.Name) == "" {
+			return fmt.Errorf("%s: missing script name", name)
+		}
+		if strings.TrimSpace(y.BodyFile) == "" || strings.ContainsAny(y.BodyFile, `/\`) {
+			return fmt.Errorf("%s: body_file must name a sibling file", name)
+		}
+		k := scriptKey(y.Name, y.Variant)
+		if prev, dup := seen[k]; dup {
+			return fmt.Errorf("script %q declared twice (%s and %s)", k, prev, name)
+		}
+		seen[k] = name
+		a.bodyFiles[k] = y.BodyFile
+		body, err := os.ReadFile(filepath.Join(dir, y.BodyFile))
+		if err != nil {
+			return fmt.Errorf("%s: read body_file: %w", name, err)
+		}
+		r, err := a.record(y, name, string(body))
+		if err != nil {
+			return err
+		}
+		r.Value = scriptRecord{yaml: y, body: string(body)}
+		out = append(out, r)
+		return nil
+	})
+	return out, err
+}
+
+// Apply updates the role's script with the same complex name (active first), else creates one.
+func (a *scriptAdapter) Apply(ctx context.Context, r syncfs.Record) error {
+	rec, ok := r.Value.(scriptRecord)
+	if !ok {
+		return fmt.Errorf("script %q: unexpected record payload", r.Key)
+	}
+	y := rec.yaml
+	id := ""
+	for _, archived := range []bool{false, true} {
+		s, err := pgdao.GetScriptByComplexNameRole(ctx, a.db, y.Name, y.Variant, archived, a.role)
+		if err == nil {
+			id = s.ID
+			break
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+	cid, err := pgdao.InsertScriptContent(ctx, a.db, rec.body)
+	if err != nil {
+		return err
+	}
+	s := &pgdao.Script{
+		ID: id, Title: y.Title, RoleName: a.role, ScriptContentID: cid, Tags: y.Tags, Archived: y.Archived,
+		ComplexName: pgdao.ScriptComplexName{Name: y.Name, Variant: y.Variant},
+	}
+	if d := syncfs.Deref(y.Description); d != "" {
+		s.Description = sql.NullString{String: d, Valid: true}
+	}
+	if m := syncfs.Deref(y.Motivation); m != "" {
+		s.Motivation = sql.NullString{String: m, Valid: true}
+	}
+	if n := syncfs.DerefLiteral(y.Notes); n != "" {
+		s.Notes = sql.NullString{String: n, Valid: true}
+	}
+	return pgdao.UpsertScript(ctx, a.db, s)
+}
+
+func (a *scriptAdapter) Delete(ctx context.Context, r syncfs.Record) error {
+	rec, ok := r.Value.(scriptRecord)
+	if !ok || rec.id == "" {
+		return fmt.Errorf("script %q: missing remote id", r.Key)
+	}
+	_, err := pgdao.DeleteScript(ctx, a.db, rec.id)
+	return err
+}
+
+func openScriptAdapter(ctx context.Context, role string) (syncfs.Adapter, func(), error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &scriptAdapter{db: db, role: role}, db.Close, nil
+}
+
+func init() {
+	for _, c := range syncfs.Commands(syncfs.CommandSpec{Noun: "script", ScopeHelp: "role", Open: openScriptAdapter}) {
+		ScriptCmd.AddCommand(c)
+	}
+}
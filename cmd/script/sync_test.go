@@ -0,0 +1,41 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func TestRemoteYAML_KeepsLocalBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"deploy.script.yaml": "name: deploy\ntitle: Deploy\narchived: false\nbody_file: run.py\n",
+		"run.py":             "print('deploy')\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &scriptAdapter{role: "dev"}
+	local, err := a.LoadLocal(dir)
+	if err != nil || len(local) != 1 {
+		t.Fatalf("load: %d records, %v", len(local), err)
+	}
+	s := pgdao.Script{Title: "Deploy", ComplexName: pgdao.ScriptComplexName{Name: "deploy"}}
+	y := a.remoteYAML(s)
+	if y.BodyFile != "run.py" {
+		t.Fatalf("expected the local body_file; got %q", y.BodyFile)
+	}
+	remote, err := a.record(y, "deploy.script.yaml", "print('deploy')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !remote.Equal(local[0]) {
+		t.Fatalf("expected an unchanged script; remote files %v", remote.Files)
+	}
+	if got := (&scriptAdapter{}).remoteYAML(s).BodyFile; got != "deploy.sh" {
+		t.Fatalf("expected deploy.sh without a local script; got %q", got)
+	}
+}
@@ -4,32 +4,91 @@ import (
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	"github.com/flarebyte/baldrick-rebec/internal/scheduler"
 	srv "github.com/flarebyte/baldrick-rebec/internal/server"
 	"github.com/spf13/cobra"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show current server state",
+	Short: "Show current server state and scheduled jobs",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pidPath := srv.DefaultPIDPath()
-		pid, err := srv.ReadPID(pidPath)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "server: not running (no pid)")
-			return nil
+		return printSchedule(serverRunning())
+	},
+}
+
+func serverRunning() bool {
+	pidPath := srv.DefaultPIDPath()
+	pid, err := srv.ReadPID(pidPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "server: not running (no pid)")
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: stale pid %d\n", pid)
+		return false
+	}
+	// Signal 0 check (Unix); on other OSes, just report pid
+	if err := proc.Signal(syscall.Signal(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "server: not running (pid=%d not alive)\n", pid)
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "server: running (pid=%d)\n", pid)
+	return true
+}
+
+// printSchedule lists scheduled jobs. Next run times come from the config so
+// they are shown even when the server is down; last-run details come from the
+// state file the running server maintains.
+func printSchedule(running bool) error {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return err
+	}
+	jobs, err := scheduler.Jobs(cfg.Scheduler)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		fmt.Fprintln(os.Stderr, "scheduler: no jobs configured")
+		return nil
+	}
+	st, err := scheduler.LoadState(scheduler.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+	last := map[string]scheduler.JobState{}
+	for _, js := range st.Jobs {
+		last[js.Name] = js
+	}
+	if !running {
+		fmt.Fprintln(os.Stderr, "scheduler: jobs will not run until the server is started")
+	}
+	now := time.Now()
+	for _, j := range jobs {
+		next := j.Schedule.Next(now)
+		if js, ok := last[j.Name]; ok && running && js.NextRun != nil && js.Cron == j.Schedule.String() {
+			next = *js.NextRun
 		}
-		proc, err := os.FindProcess(pid)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "server: stale pid %d\n", pid)
-			return nil
+		nextStr := "never"
+		if !next.IsZero() {
+			nextStr = next.Local().Format(time.RFC3339) + " (in " + time.Until(next).Round(time.Minute).String() + ")"
 		}
-		// Signal 0 check (Unix); on other OSes, just report pid
-		if err := proc.Signal(syscall.Signal(0)); err != nil {
-			fmt.Fprintf(os.Stderr, "server: not running (pid=%d not alive)\n", pid)
-			return nil
+		fmt.Fprintf(os.Stderr, "  %-16s %-13s %-14s next=%s\n", j.Name, j.Kind, j.Schedule.String(), nextStr)
+		if js, ok := last[j.Name]; ok && js.LastRun != nil {
+			line := fmt.Sprintf("  %-16s %-13s %-14s last=%s %s", "", "", "", js.LastRun.Local().Format(time.RFC3339), js.LastStatus)
+			if js.LastBackupID != "" {
+				line += " backup=" + js.LastBackupID
+			}
+			if js.LastError != "" {
+				line += " error=" + js.LastError
+			}
+			fmt.Fprintln(os.Stderr, line)
 		}
-		fmt.Fprintf(os.Stderr, "server: running (pid=%d)\n", pid)
-		return nil
-	},
+	}
+	return nil
 }
@@ -74,7 +74,7 @@ var backupCmd = &cobra.Command{
 		}
 		// retention
 		if strings.TrimSpace(flagBkpRetention) != "" {
-			if d, err := parseHumanDuration(flagBkpRetention); err == nil {
+			if d, err := cfgpkg.ParseAge(flagBkpRetention); err == nil {
 				// we'll pass retention via description tags for now; service will set retention_until from options in future.
 				if opt.Tags == nil {
 					opt.Tags = map[string]any{}
@@ -83,14 +83,24 @@ var backupCmd = &cobra.Command{
 				_ = d // retained for future direct support
 			} // if invalid, ignore; prefer explicit prune later
 		}
-		id, err := bkp.CreateBackup(ctx, db, bkp.DefaultEntities(), opt)
+		res, err := bkp.CreateBackup(ctx, db, bkp.DefaultEntities(), opt)
 		if err != nil {
 			return err
 		}
+		for _, e := range res.Entities {
+			fmt.Fprintf(os.Stderr, "captured %-18s %8d records in %s\n", e.Entity, e.Records, e.Duration.Round(time.Millisecond))
+		}
+		fmt.Fprintf(os.Stderr, "backup %s: %d records in %s (%.0f records/s)\n", res.ID, res.Records, res.Duration.Round(time.Millisecond), res.RecordsPerSecond())
 		if flagBkpJSON {
-			return json.NewEncoder(os.Stdout).Encode(map[string]any{"id": id})
+			return json.NewEncoder(os.Stdout).Encode(map[string]any{
+				"id":                 res.ID,
+				"records":            res.Records,
+				"entities":           res.Entities,
+				"duration_ms":        res.Duration.Milliseconds(),
+				"records_per_second": res.RecordsPerSecond(),
+			})
 		}
-		fmt.Fprintln(os.Stdout, id)
+		fmt.Fprintln(os.Stdout, res.ID)
 		return nil
 	},
 }
@@ -0,0 +1,78 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	bkp "github.com/flarebyte/baldrick-rebec/internal/backup"
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagImportSchema     string
+	flagImportAllowDrift bool
+	flagImportDry        bool
+	flagImportJSON       bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a .tar.zst archive as a backup",
+	Long:  "Verify an archive written by 'snapshot export' (checksums, record counts, and entity schemas against the live tables), then load it into the backup schema under its original id. Restore it afterwards with 'snapshot restore'.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		db, err := pgdao.OpenBackup(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		res, err := bkp.ImportArchive(ctx, db, f, bkp.ImportOptions{Schema: flagImportSchema, AllowDrift: flagImportAllowDrift, DryRun: flagImportDry})
+		if res != nil && !flagImportJSON {
+			for _, is := range res.Issues {
+				level := "note"
+				if is.Blocking {
+					level = "BLOCKING"
+				}
+				fmt.Fprintf(os.Stderr, "schema %s: %s.%s %s\n", level, is.Entity, is.Field, is.Problem)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if flagImportJSON {
+			return json.NewEncoder(os.Stdout).Encode(res)
+		}
+		if flagImportDry {
+			fmt.Fprintf(os.Stderr, "[dry-run] archive %s verified: backup %s, %d records\n", args[0], res.Manifest.Backup.ID, res.Records)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "imported backup %s: %d records\n", res.Manifest.Backup.ID, res.Records)
+		fmt.Fprintln(os.Stdout, res.Manifest.Backup.ID)
+		return nil
+	},
+}
+
+func init() {
+	SnapshotCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&flagImportSchema, "schema", "backup", "Backup schema name")
+	importCmd.Flags().BoolVar(&flagImportAllowDrift, "allow-drift", false, "Import even when the live schema has blocking differences")
+	importCmd.Flags().BoolVar(&flagImportDry, "dry-run", false, "Verify checksums and schema without importing")
+	importCmd.Flags().BoolVar(&flagImportJSON, "json", false, "Output JSON")
+}
@@ -26,7 +26,7 @@ var pruneCmd = &cobra.Command{
 		if flagPruneOlder == "" {
 			flagPruneOlder = "90d"
 		}
-		d, err := parseHumanDuration(flagPruneOlder)
+		d, err := cfgpkg.ParseAge(flagPruneOlder)
 		if err != nil {
 			return fmt.Errorf("--older-than: %w", err)
 		}
@@ -18,6 +18,8 @@ var (
 	flagRestoreSchema string
 	flagRestoreMode   string
 	flagRestoreEntity string
+	flagRestoreRole   string
+	flagRestoreWhere  []string
 	flagRestoreDry    bool
 	flagRestoreJSON   bool
 )
@@ -25,9 +27,16 @@ var (
 var restoreCmd = &cobra.Command{
 	Use:   "restore <backup-id>",
 	Short: "Restore a backup into live tables",
-	Args:  cobra.ExactArgs(1),
+	Long: "Restore a backup into live tables in one transaction. Entities are written parents first, following foreign keys, and the plan is printed before it is applied.\n" +
+		"Modes: append (insert missing keys), merge (insert missing and update changed rows), replace (truncate, then insert; every table referencing a restored one must be restored too).\n" +
+		"Narrow with --entity, --role and --where field=value (or entity.field=value), e.g. --entity blackboards,stickies --where blackboards.id=<uuid> --where stickies.blackboard_id=<uuid>.",
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		id := args[0]
+		where, err := bkp.ParseFilters(flagRestoreWhere)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -40,36 +49,70 @@ var restoreCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		// validate mode
-		mode := strings.ToLower(strings.TrimSpace(flagRestoreMode))
-		if mode == "" {
-			mode = "append"
+		opt := bkp.RestoreOptions{
+			Schema:   flagRestoreSchema,
+			Entities: splitCSV(flagRestoreEntity),
+			Mode:     flagRestoreMode,
+			Role:     strings.TrimSpace(flagRestoreRole),
+			Where:    where,
 		}
-		if mode != "replace" && mode != "append" {
-			return fmt.Errorf("invalid --mode, want replace|append")
+		plan, err := bkp.PlanRestore(ctx, db, bkp.DefaultEntities(), id, opt)
+		if err != nil {
+			return err
+		}
+		if !flagRestoreJSON {
+			printRestorePlan(plan)
 		}
-		ents := bkp.DefaultEntities()
-		list := []string{}
-		if strings.TrimSpace(flagRestoreEntity) != "" {
-			list = strings.Split(flagRestoreEntity, ",")
+		if flagRestoreDry {
+			if flagRestoreJSON {
+				return json.NewEncoder(os.Stdout).Encode(map[string]any{"restored": false, "plan": plan})
+			}
+			return nil
 		}
-		opt := bkp.RestoreOptions{Schema: flagRestoreSchema, Entities: list, Mode: mode, DryRun: flagRestoreDry}
-		if err := bkp.RestoreFromBackup(ctx, db, ents, id, opt); err != nil {
+		started := time.Now()
+		stats, err := bkp.ApplyRestore(ctx, db, plan, flagRestoreSchema)
+		if err != nil {
 			return err
 		}
+		var total int64
+		for _, e := range stats {
+			total += e.Records
+		}
 		if flagRestoreJSON {
-			return json.NewEncoder(os.Stdout).Encode(map[string]any{"restored": true, "mode": mode})
+			return json.NewEncoder(os.Stdout).Encode(map[string]any{"restored": true, "mode": plan.Mode, "records": total, "entities": stats, "plan": plan})
+		}
+		for _, e := range stats {
+			fmt.Fprintf(os.Stderr, "restored %-18s %8d records\n", e.Entity, e.Records)
 		}
-		fmt.Fprintln(os.Stderr, "restore completed")
+		fmt.Fprintf(os.Stderr, "restore completed: %d records in %s\n", total, time.Since(started).Round(time.Millisecond))
 		return nil
 	},
 }
 
+func printRestorePlan(p *bkp.RestorePlan) {
+	fmt.Fprintf(os.Stderr, "restore plan for %s (mode=%s):\n", p.BackupID, p.Mode)
+	for i, s := range p.Steps {
+		line := fmt.Sprintf("  %2d. %-18s %8d records", i+1, s.Entity, s.Records)
+		if len(s.DependsOn) > 0 {
+			line += " after " + strings.Join(s.DependsOn, ", ")
+		}
+		if len(s.Missing) > 0 {
+			line += " (requires live " + strings.Join(s.Missing, ", ") + ")"
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	if len(p.Cycle) > 0 {
+		fmt.Fprintf(os.Stderr, "  note: foreign key cycle between %s; restored in name order\n", strings.Join(p.Cycle, ", "))
+	}
+}
+
 func init() {
 	SnapshotCmd.AddCommand(restoreCmd)
 	restoreCmd.Flags().StringVar(&flagRestoreSchema, "schema", "backup", "Backup schema name")
-	restoreCmd.Flags().StringVar(&flagRestoreMode, "mode", "append", "Restore mode: replace|append")
+	restoreCmd.Flags().StringVar(&flagRestoreMode, "mode", "append", "Restore mode: append|merge|replace")
 	restoreCmd.Flags().StringVar(&flagRestoreEntity, "entity", "", "Comma-separated list of entities to restore (default all)")
-	restoreCmd.Flags().BoolVar(&flagRestoreDry, "dry-run", false, "Do not apply changes; validate and print summary")
+	restoreCmd.Flags().StringVar(&flagRestoreRole, "role", "", "Only restore records with this role_name (entities without roles are skipped)")
+	restoreCmd.Flags().StringArrayVar(&flagRestoreWhere, "where", nil, "Record filter field=value or entity.field=value (repeatable; all must match)")
+	restoreCmd.Flags().BoolVar(&flagRestoreDry, "dry-run", false, "Show the restore plan without applying it")
 	restoreCmd.Flags().BoolVar(&flagRestoreJSON, "json", false, "Output JSON status")
 }
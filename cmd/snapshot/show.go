@@ -7,6 +7,7 @@ import (
 	"os"
 	"time"
 
+	bkp "github.com/flarebyte/baldrick-rebec/internal/backup"
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
 	"github.com/olekukonko/tablewriter"
@@ -38,40 +39,21 @@ var showCmd = &cobra.Command{
 		defer db.Close()
 
 		// Load metadata
-		rows, err := db.Query(ctx, `SELECT id, created_at, description, tags, initiated_by, retention_until FROM `+flagShowSchema+`.backups WHERE id=$1`, id)
+		b, err := bkp.GetBackup(ctx, db, flagShowSchema, id)
 		if err != nil {
 			return err
 		}
-		var meta any
-		var found bool
-		var idOut string
-		var created time.Time
-		var desc *string
-		var tags map[string]any
-		var who *string
-		var retain *time.Time
-		for rows.Next() {
-			if err := rows.Scan(&idOut, &created, &desc, &tags, &who, &retain); err != nil {
-				rows.Close()
-				return err
-			}
-			found = true
-		}
-		rows.Close()
-		if !found {
-			return fmt.Errorf("backup not found: %s", id)
-		}
 		counts, err := pgdao.CountPerEntity(ctx, db, flagShowSchema, id)
 		if err != nil {
 			return err
 		}
-		meta = map[string]any{
-			"id":              idOut,
-			"created_at":      created,
-			"description":     desc,
-			"tags":            tags,
-			"initiated_by":    who,
-			"retention_until": retain,
+		meta := map[string]any{
+			"id":              b.ID,
+			"created_at":      b.CreatedAt,
+			"description":     b.Description,
+			"tags":            b.Tags,
+			"initiated_by":    b.InitiatedBy,
+			"retention_until": b.RetentionUntil,
 			"entities":        counts,
 		}
 		if flagShowJSON {
@@ -79,15 +61,15 @@ var showCmd = &cobra.Command{
 			enc.SetIndent("", "  ")
 			return enc.Encode(meta)
 		}
-		fmt.Fprintf(os.Stderr, "id: %s\ncreated_at: %s\n", idOut, created.Format(time.RFC3339))
-		if desc != nil {
-			fmt.Fprintf(os.Stderr, "description: %s\n", *desc)
+		fmt.Fprintf(os.Stderr, "id: %s\ncreated_at: %s\n", b.ID, b.CreatedAt.Format(time.RFC3339))
+		if b.Description != nil {
+			fmt.Fprintf(os.Stderr, "description: %s\n", *b.Description)
 		}
-		if who != nil {
-			fmt.Fprintf(os.Stderr, "initiated_by: %s\n", *who)
+		if b.InitiatedBy != nil {
+			fmt.Fprintf(os.Stderr, "initiated_by: %s\n", *b.InitiatedBy)
 		}
-		if retain != nil {
-			fmt.Fprintf(os.Stderr, "retention_until: %s\n", retain.Format(time.RFC3339))
+		if b.RetentionUntil != nil {
+			fmt.Fprintf(os.Stderr, "retention_until: %s\n", b.RetentionUntil.Format(time.RFC3339))
 		}
 		tw := tablewriter.NewWriter(os.Stdout)
 		tw.SetHeader([]string{"ENTITY", "COUNT"})
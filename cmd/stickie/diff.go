@@ -0,0 +1,155 @@
+package stickie
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStDiffID     string
+	flagStDiffFrom   string
+	flagStDiffTo     string
+	flagStDiffOutput string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show changes between two versions of a stickie",
+	Long:  "Compare two revisions of a stickie (see `rbc stickie history`). --to defaults to the current content. Note and code are diffed line by line; other fields show old → new.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(flagStDiffID)
+		if id == "" || strings.TrimSpace(flagStDiffFrom) == "" {
+			return errors.New("--id and --from are required")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		from, err := loadVersion(ctx, db, id, flagStDiffFrom)
+		if err != nil {
+			return err
+		}
+		to, err := loadVersion(ctx, db, id, flagStDiffTo)
+		if err != nil {
+			return err
+		}
+		changes := diffVersions(from, to)
+		if strings.EqualFold(strings.TrimSpace(flagStDiffOutput), "json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(map[string]any{"id": id, "from": from.Rev, "to": to.Rev, "changes": changes})
+		}
+		fmt.Fprintf(os.Stderr, "stickie %s: rev %d → %d, %d fields changed\n", id, from.Rev, to.Rev, len(changes))
+		for _, c := range changes {
+			if len(c.Lines) > 0 {
+				fmt.Printf("~ %s\n", c.Field)
+				for _, l := range c.Lines {
+					fmt.Printf("    %s\n", l)
+				}
+				continue
+			}
+			fmt.Printf("~ %s: %q → %q\n", c.Field, c.From, c.To)
+		}
+		return nil
+	},
+}
+
+// fieldChange is one differing field between two versions. Multi-line text
+// fields carry a line diff instead of From/To.
+type fieldChange struct {
+	Field string   `json:"field"`
+	From  string   `json:"from,omitempty"`
+	To    string   `json:"to,omitempty"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+func diffVersions(a, b stickieVersion) []fieldChange {
+	fa, fb := a.fields(), b.fields()
+	keys := make([]string, 0, len(fa))
+	for k := range fa {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []fieldChange
+	for _, k := range keys {
+		if fa[k] == fb[k] {
+			continue
+		}
+		c := fieldChange{Field: k}
+		if (k == "note" || k == "code") && (strings.Contains(fa[k], "\n") || strings.Contains(fb[k], "\n")) {
+			c.Lines = lineDiff(fa[k], fb[k])
+		} else {
+			c.From, c.To = fa[k], fb[k]
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// lineDiff returns a minimal line diff of a and b using a longest common
+// subsequence; lines are prefixed with "-", "+" or " ".
+func lineDiff(a, b string) []string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	n, m := len(x), len(y)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out []string
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, "  "+x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+x[i])
+			i++
+		default:
+			out = append(out, "+ "+y[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, "- "+x[i])
+	}
+	for ; j < m; j++ {
+		out = append(out, "+ "+y[j])
+	}
+	return out
+}
+
+func init() {
+	StickieCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVar(&flagStDiffID, "id", "", "Stickie UUID (required)")
+	diffCmd.Flags().StringVar(&flagStDiffFrom, "from", "", "Revision to compare from (required)")
+	diffCmd.Flags().StringVar(&flagStDiffTo, "to", "current", "Revision to compare to (number or current)")
+	diffCmd.Flags().StringVar(&flagStDiffOutput, "output", "text", "Output format: text or json")
+}
@@ -0,0 +1,30 @@
+package stickie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a\nb\nc", "a\nx\nc\nd")
+	want := []string{"  a", "- b", "+ x", "  c", "+ d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("lineDiff:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestDiffVersions_OnlyChangedFields(t *testing.T) {
+	a := stickieVersion{Rev: 1, Blackboard: "bb", Note: "one\ntwo", Labels: []string{"x"}}
+	b := stickieVersion{Rev: 2, Blackboard: "bb", Note: "one\nthree", Labels: []string{"x", "y"}, Priority: "must"}
+	changes := diffVersions(a, b)
+	fields := map[string]fieldChange{}
+	for _, c := range changes {
+		fields[c.Field] = c
+	}
+	if len(fields) != 3 {
+		t.Fatalf("expected labels, note and priority_level, got %+v", changes)
+	}
+	if len(fields["note"].Lines) == 0 || fields["labels"].To != "x,y" || fields["priority_level"].To != "must" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
@@ -61,6 +61,9 @@ var getCmd = &cobra.Command{
 		if s.Name.Valid {
 			out["name"] = s.Name.String
 		}
+		if len(s.Structured) > 0 {
+			out["structured"] = json.RawMessage(s.Structured)
+		}
 		if s.Created.Valid {
 			out["created"] = s.Created.Time.Format(time.RFC3339Nano)
 		}
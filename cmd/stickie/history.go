@@ -0,0 +1,188 @@
+package stickie
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStHistID     string
+	flagStHistLimit  int
+	flagStHistOutput string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List prior versions of a stickie",
+	Long:  "List the revisions recorded each time a stickie's content changed. REV is the edit count of the stored version; CHANGED/BY describe the update that replaced it.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(flagStHistID)
+		if id == "" {
+			return errors.New("--id is required")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		cur, err := pgdao.GetStickieByID(ctx, db, id)
+		if err != nil {
+			return err
+		}
+		revs, err := pgdao.ListStickieRevisions(ctx, db, id, flagStHistLimit)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "stickie %s: current rev %d, %d stored revisions\n", id, cur.EditCount, len(revs))
+		if strings.EqualFold(strings.TrimSpace(flagStHistOutput), "json") {
+			arr := make([]map[string]any, 0, len(revs))
+			for _, r := range revs {
+				item := map[string]any{
+					"rev": r.Rev, "blackboard_id": r.BlackboardID,
+					"valid_from": r.ValidFrom.Format(time.RFC3339Nano), "changed_at": r.ChangedAt.Format(time.RFC3339Nano),
+				}
+				if r.ChangedByRole.Valid {
+					item["changed_by_role"] = r.ChangedByRole.String
+				}
+				if r.ChangedByTaskID.Valid {
+					item["changed_by_task_id"] = r.ChangedByTaskID.String
+				}
+				for k, v := range versionFromRevision(&r).fields() {
+					if v != "" {
+						item[k] = v
+					}
+				}
+				arr = append(arr, item)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(map[string]any{"id": id, "current_rev": cur.EditCount, "revisions": arr})
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"REV", "FROM", "CHANGED", "BY", "NAME", "NOTE"})
+		for _, r := range revs {
+			by := r.ChangedByRole.String
+			if r.ChangedByTaskID.Valid {
+				by += " task:" + shortID(r.ChangedByTaskID.String)
+			}
+			table.Append([]string{
+				strconv.Itoa(r.Rev), r.ValidFrom.Format(time.RFC3339), r.ChangedAt.Format(time.RFC3339),
+				strings.TrimSpace(by), r.Name.String, oneLine(r.Note.String, 48),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// stickieVersion is the comparable content of a stickie at one revision.
+type stickieVersion struct {
+	Rev        int
+	Blackboard string
+	Name       string
+	Note       string
+	Code       string
+	Structured string
+	Labels     []string
+	Priority   string
+	Score      string
+	Archived   bool
+}
+
+func (v stickieVersion) fields() map[string]string {
+	return map[string]string{
+		"blackboard_id":  v.Blackboard,
+		"name":           v.Name,
+		"note":           v.Note,
+		"code":           v.Code,
+		"structured":     v.Structured,
+		"labels":         strings.Join(v.Labels, ","),
+		"priority_level": v.Priority,
+		"score":          v.Score,
+		"archived":       strconv.FormatBool(v.Archived),
+	}
+}
+
+func versionFromRevision(r *pgdao.StickieRevision) stickieVersion {
+	v := stickieVersion{
+		Rev: r.Rev, Blackboard: r.BlackboardID, Name: r.Name.String, Note: r.Note.String, Code: r.Code.String,
+		Structured: string(r.Structured), Labels: r.Labels, Priority: r.PriorityLevel.String, Archived: r.Archived,
+	}
+	if r.Score.Valid {
+		v.Score = strconv.FormatFloat(r.Score.Float64, 'g', -1, 64)
+	}
+	return v
+}
+
+func versionFromStickie(s *pgdao.Stickie) stickieVersion {
+	v := stickieVersion{
+		Rev: s.EditCount, Blackboard: s.BlackboardID, Name: s.Name.String, Note: s.Note.String, Code: s.Code.String,
+		Structured: string(s.Structured), Labels: s.Labels, Priority: s.PriorityLevel.String, Archived: s.Archived,
+	}
+	if s.Score.Valid {
+		v.Score = strconv.FormatFloat(s.Score.Float64, 'g', -1, 64)
+	}
+	return v
+}
+
+// loadVersion resolves ref ("current", "" or a rev number) to a version. A rev
+// equal to the stickie's edit_count is the current content.
+func loadVersion(ctx context.Context, db *pgxpool.Pool, id, ref string) (stickieVersion, error) {
+	ref = strings.TrimSpace(ref)
+	cur, err := pgdao.GetStickieByID(ctx, db, id)
+	if err != nil {
+		return stickieVersion{}, err
+	}
+	if ref == "" || strings.EqualFold(ref, "current") || ref == strconv.Itoa(cur.EditCount) {
+		return versionFromStickie(cur), nil
+	}
+	rev, err := strconv.Atoi(ref)
+	if err != nil {
+		return stickieVersion{}, fmt.Errorf("invalid revision %q (want a number or current)", ref)
+	}
+	r, err := pgdao.GetStickieRevision(ctx, db, id, rev)
+	if err != nil {
+		return stickieVersion{}, fmt.Errorf("revision %d of stickie %s not found: %w", rev, id, err)
+	}
+	return versionFromRevision(r), nil
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func oneLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func init() {
+	StickieCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&flagStHistID, "id", "", "Stickie UUID (required)")
+	historyCmd.Flags().IntVar(&flagStHistLimit, "limit", 100, "Max number of revisions")
+	historyCmd.Flags().StringVar(&flagStHistOutput, "output", "table", "Output format: table or json")
+}
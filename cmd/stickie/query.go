@@ -0,0 +1,104 @@
+package stickie
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStQueryPath       string
+	flagStQueryBlackboard string
+	flagStQueryLabels     []string
+	flagStQueryArchived   bool
+	flagStQueryLimit      int
+	flagStQueryOffset     int
+	flagStQueryOutput     string
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Find stickies whose structured payload matches a JSON path",
+	Long: `Find stickies whose structured payload matches a SQL/JSON path expression
+(PostgreSQL jsonpath, exists semantics). Examples:
+  --jsonpath '$.status ? (@ == "accepted")'
+  --jsonpath '$ ? (@.points >= 5 && @.owner like_regex "^ops")'
+  --jsonpath '$.steps[*] ? (@.name == "build")'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagStQueryPath) == "" {
+			return errors.New("--jsonpath is required")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ss, err := pgdao.QueryStickies(ctx, db, pgdao.StickieQuery{
+			BlackboardID:    strings.TrimSpace(flagStQueryBlackboard),
+			JSONPath:        flagStQueryPath,
+			Labels:          flagStQueryLabels,
+			IncludeArchived: flagStQueryArchived,
+			Limit:           flagStQueryLimit,
+			Offset:          flagStQueryOffset,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "stickies: %d\n", len(ss))
+		if strings.EqualFold(strings.TrimSpace(flagStQueryOutput), "json") {
+			arr := make([]map[string]any, 0, len(ss))
+			for _, s := range ss {
+				item := map[string]any{"id": s.ID, "blackboard_id": s.BlackboardID, "edit_count": s.EditCount, "structured": json.RawMessage(s.Structured)}
+				if s.Name.Valid && s.Name.String != "" {
+					item["name"] = s.Name.String
+				}
+				if len(s.Labels) > 0 {
+					item["labels"] = s.Labels
+				}
+				if s.Updated.Valid {
+					item["updated"] = s.Updated.Time.Format(time.RFC3339Nano)
+				}
+				if s.Archived {
+					item["archived"] = true
+				}
+				arr = append(arr, item)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(arr)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "BLACKBOARD", "NAME", "LABELS", "STRUCTURED"})
+		for _, s := range ss {
+			table.Append([]string{s.ID, shortID(s.BlackboardID), s.Name.String, strings.Join(s.Labels, ","), oneLine(string(s.Structured), 60)})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+func init() {
+	StickieCmd.AddCommand(queryCmd)
+	queryCmd.Flags().StringVar(&flagStQueryPath, "jsonpath", "", "SQL/JSON path over the structured payload (required)")
+	queryCmd.Flags().StringVar(&flagStQueryBlackboard, "blackboard", "", "Filter by blackboard UUID")
+	queryCmd.Flags().StringSliceVar(&flagStQueryLabels, "labels", nil, "Only stickies carrying all these labels")
+	queryCmd.Flags().BoolVar(&flagStQueryArchived, "include-archived", false, "Include archived stickies")
+	queryCmd.Flags().IntVar(&flagStQueryLimit, "limit", 100, "Max number of rows")
+	queryCmd.Flags().IntVar(&flagStQueryOffset, "offset", 0, "Offset for pagination")
+	queryCmd.Flags().StringVar(&flagStQueryOutput, "output", "table", "Output format: table or json")
+}
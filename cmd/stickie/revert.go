@@ -0,0 +1,69 @@
+package stickie
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStRevID   string
+	flagStRevRev  int
+	flagStRevRole string
+	flagStRevIf   string
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert",
+	Short: "Restore a stickie's content from a prior revision",
+	Long:  "Restore note, code, structured data, labels, priority, score, name and archived state from a revision. The replaced content is kept as a new revision, so a revert can be undone.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(flagStRevID)
+		if id == "" || !cmd.Flags().Changed("rev") {
+			return errors.New("--id and --rev are required")
+		}
+		ifMatch, err := pgdao.ParseIfMatch(flagStRevIf)
+		if err != nil {
+			return err
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if strings.TrimSpace(flagStRevRole) != "" {
+			ctx = pgdao.WithActorRole(ctx, flagStRevRole)
+		}
+		editCount, err := pgdao.RevertStickie(ctx, db, id, flagStRevRev, ifMatch, stickieschema.Validate)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "stickie %s reverted to rev %d (now rev %d)\n", id, flagStRevRev, editCount)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{"status": "reverted", "id": id, "rev": flagStRevRev, "edit_count": editCount})
+	},
+}
+
+func init() {
+	StickieCmd.AddCommand(revertCmd)
+	revertCmd.Flags().StringVar(&flagStRevID, "id", "", "Stickie UUID (required)")
+	revertCmd.Flags().IntVar(&flagStRevRev, "rev", 0, "Revision to restore (required; see stickie history)")
+	revertCmd.Flags().StringVar(&flagStRevRole, "role", "", "Role making the change (recorded in history)")
+	revertCmd.Flags().StringVar(&flagStRevIf, "if-match", "", "Only revert if the stickie is still at this edit_count or updated timestamp")
+}
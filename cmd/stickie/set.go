@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +29,10 @@ var (
 	flagStName      string
 	flagStArchived  bool
 	flagStScore     float64
+	flagStRole      string
+	flagStIfMatch   string
+	flagStStruct    string
+	flagStStructF   string
 )
 
 var setCmd = &cobra.Command{
@@ -36,6 +42,13 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagStID) == "" && strings.TrimSpace(flagStBlackboard) == "" {
 			return errors.New("--blackboard is required when creating a stickie")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagStIfMatch)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.IsZero() && strings.TrimSpace(flagStID) == "" {
+			return errors.New("--if-match requires --id")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -48,7 +61,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		st := &pgdao.Stickie{ID: strings.TrimSpace(flagStID)}
+		st := &pgdao.Stickie{ID: strings.TrimSpace(flagStID), IfMatch: ifMatch}
 		if strings.TrimSpace(flagStBlackboard) != "" {
 			st.BlackboardID = strings.TrimSpace(flagStBlackboard)
 		}
@@ -71,12 +84,21 @@ var setCmd = &cobra.Command{
 			st.Name = sql.NullString{String: strings.TrimSpace(flagStName), Valid: true}
 		}
 		st.Archived = flagStArchived
+		if st.Structured, err = readStructured(flagStStruct, flagStStructF); err != nil {
+			return err
+		}
+		if st.Structured != nil || len(st.Labels) > 0 || st.BlackboardID != "" {
+			st.Validate = stickieschema.Validate
+		}
 
 		// Optional score; only set if flag provided
 		if cmd.Flags().Changed("score") {
 			st.Score = sql.NullFloat64{Float64: flagStScore, Valid: true}
 		}
 
+		if strings.TrimSpace(flagStRole) != "" {
+			ctx = pgdao.WithActorRole(ctx, flagStRole)
+		}
 		if err := pgdao.UpsertStickie(ctx, db, st); err != nil {
 			return err
 		}
@@ -108,4 +130,37 @@ func init() {
 	setCmd.Flags().StringVar(&flagStName, "name", "", "Human-readable name (exact lookup key)")
 	setCmd.Flags().BoolVar(&flagStArchived, "archived", false, "Mark stickie as archived (excluded from active lookups)")
 	setCmd.Flags().Float64Var(&flagStScore, "score", 0, "Optimisation score (optional; double precision)")
+	setCmd.Flags().StringVar(&flagStRole, "role", "", "Role making the change (recorded in history; default the board's role)")
+	setCmd.Flags().StringVar(&flagStStruct, "structured", "", "Structured JSON payload (validated against the blackboard's schemas)")
+	setCmd.Flags().StringVar(&flagStStructF, "structured-file", "", "Read the structured JSON payload from a file ('-' for stdin)")
+	setCmd.Flags().StringVar(&flagStIfMatch, "if-match", "", "Only update if the stickie is still at this edit_count or updated timestamp")
+}
+
+// readStructured returns the payload given inline or by file, nil when neither is set.
+func readStructured(inline, file string) ([]byte, error) {
+	var b []byte
+	switch {
+	case strings.TrimSpace(inline) != "" && strings.TrimSpace(file) != "":
+		return nil, errors.New("use either --structured or --structured-file")
+	case strings.TrimSpace(inline) != "":
+		b = []byte(inline)
+	case file == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+		b = data
+	case strings.TrimSpace(file) != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		b = data
+	default:
+		return nil, nil
+	}
+	if !json.Valid(b) {
+		return nil, errors.New("structured payload is not valid JSON")
+	}
+	return b, nil
 }
@@ -2,6 +2,7 @@ package stickie_rel
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -14,10 +15,11 @@ import (
 )
 
 var (
-	flagRelFrom   string
-	flagRelTo     string
-	flagRelType   string
-	flagRelLabels []string
+	flagRelFrom    string
+	flagRelTo      string
+	flagRelType    string
+	flagRelLabels  []string
+	flagRelIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -33,6 +35,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagRelType) == "" {
 			return errors.New("--type is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagRelIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -49,7 +55,10 @@ var setCmd = &cobra.Command{
 		// Try graph first; if it fails, fall back to SQL mirror
 		// Try graph first; control fallback via config
 		allowFallback := cfg.Graph.AllowFallback
-		if err := pgdao.CreateStickieEdge(ctx, db, flagRelFrom, flagRelTo, flagRelType, labels); err != nil {
+		if err := pgdao.CreateStickieEdge(ctx, db, flagRelFrom, flagRelTo, flagRelType, labels, ifMatch); err != nil {
+			if _, ok := pgdao.AsVersionConflict(err); ok {
+				return err
+			}
 			if allowFallback {
 				fmt.Fprintf(os.Stderr, "warn: graph edge creation failed: %v; falling back to SQL mirror\n", err)
 			} else {
@@ -66,7 +75,7 @@ var setCmd = &cobra.Command{
 		} else {
 			// Fallback allowed: if graph missing, mirror into SQL
 			if rel, err := pgdao.GetStickieEdge(ctx, db, flagRelFrom, flagRelTo, flagRelType); err != nil || rel == nil {
-				if err := pgdao.UpsertStickieRelation(ctx, db, pgdao.StickieRelation{FromID: flagRelFrom, ToID: flagRelTo, RelType: strings.ToUpper(flagRelType), Labels: labels}); err != nil {
+				if err := pgdao.UpsertStickieRelation(ctx, db, pgdao.StickieRelation{FromID: flagRelFrom, ToID: flagRelTo, RelType: strings.ToUpper(flagRelType), Labels: labels, IfMatch: ifMatch}); err != nil {
 					return fmt.Errorf("sql mirror upsert failed: %w", err)
 				}
 				if _, gerr := pgdao.GetStickieRelation(ctx, db, flagRelFrom, flagRelTo, strings.ToUpper(flagRelType)); gerr != nil {
@@ -75,8 +84,14 @@ var setCmd = &cobra.Command{
 			}
 		}
 		fmt.Fprintf(os.Stderr, "stickie relation set from=%s to=%s type=%s\n", flagRelFrom, flagRelTo, flagRelType)
-		fmt.Fprintf(os.Stdout, "{\n  \"status\": \"upserted\", \"from\": \"%s\", \"to\": \"%s\", \"type\": \"%s\"\n}\n", flagRelFrom, flagRelTo, flagRelType)
-		return nil
+		out := map[string]any{"status": "upserted", "from": flagRelFrom, "to": flagRelTo, "type": flagRelType}
+		// updated is the --if-match value for the next write
+		if rel, err := pgdao.GetStickieRelation(ctx, db, flagRelFrom, flagRelTo, strings.ToUpper(flagRelType)); err == nil && rel.Updated.Valid {
+			out["updated"] = rel.Updated.Time.Format(time.RFC3339Nano)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
 	},
 }
 
@@ -86,6 +101,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagRelTo, "to", "", "To stickie UUID (required)")
 	setCmd.Flags().StringVar(&flagRelType, "type", "", "Relation type: includes|causes|uses|represents|contrasts_with")
 	setCmd.Flags().StringSliceVar(&flagRelLabels, "labels", nil, "Labels (repeat or comma-separated)")
+	setCmd.Flags().StringVar(&flagRelIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set)")
 }
 
 func splitCSV(items []string) []string {
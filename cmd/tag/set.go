@@ -16,11 +16,12 @@ import (
 )
 
 var (
-	flagTagName  string
-	flagTagTitle string
-	flagTagDesc  string
-	flagTagNotes string
-	flagTagRole  string
+	flagTagName    string
+	flagTagTitle   string
+	flagTagDesc    string
+	flagTagNotes   string
+	flagTagRole    string
+	flagTagIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -34,6 +35,10 @@ var setCmd = &cobra.Command{
 			return errors.New("--title is required")
 		}
 
+		ifMatch, err := pgdao.ParseIfMatch(flagTagIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -46,7 +51,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		t := &pgdao.Tag{Name: flagTagName, Title: flagTagTitle}
+		t := &pgdao.Tag{Name: flagTagName, Title: flagTagTitle, IfMatch: ifMatch}
 		if strings.TrimSpace(flagTagRole) != "" {
 			t.RoleName = strings.TrimSpace(flagTagRole)
 		}
@@ -87,4 +92,5 @@ func init() {
 	setCmd.Flags().StringVar(&flagTagDesc, "description", "", "Plain text description")
 	setCmd.Flags().StringVar(&flagTagNotes, "notes", "", "Markdown-formatted notes")
 	setCmd.Flags().StringVar(&flagTagRole, "role", "", "Role name (optional; defaults to 'user')")
+	setCmd.Flags().StringVar(&flagTagIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+)
+
+// tagYAML is the folder format for tags: <name>.tag.yaml.
+// The role comes from the id:<role> scope, so it is not stored in the file.
+type tagYAML struct {
+	Name        string                `yaml:"name"`
+	Title       string                `yaml:"title"`
+	Description *string               `yaml:"description,omitempty"`
+	Notes       *syncfs.LiteralString `yaml:"notes,omitempty"`
+}
+
+func openTagAdapter(ctx context.Context, role string) (syncfs.Adapter, func(), error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := &syncfs.DocAdapter[tagYAML]{
+		Noun:  "tag",
+		KeyOf: func(y tagYAML) string { return y.Name },
+		List: func(ctx context.Context) ([]tagYAML, error) {
+			ts, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Tag, error) {
+				return pgdao.ListTags(ctx, db, role, limit, offset)
+			})
+			if err != nil {
+				return nil, err
+			}
+			out := make([]tagYAML, 0, len(ts))
+			for _, t := range ts {
+				out = append(out, tagYAML{
+					Name: t.Name, Title: t.Title,
+					Description: syncfs.OptString(t.Description.String, t.Description.Valid),
+					Notes:       syncfs.OptLiteral(t.Notes.String, t.Notes.Valid),
+				})
+			}
+			return out, nil
+		},
+		Put: func(ctx context.Context, y tagYAML) error {
+			t := &pgdao.Tag{Name: y.Name, Title: y.Title, RoleName: role}
+			if d := syncfs.Deref(y.Description); d != "" {
+				t.Description = sql.NullString{String: d, Valid: true}
+			}
+			if n := syncfs.DerefLiteral(y.Notes); n != "" {
+				t.Notes = sql.NullString{String: n, Valid: true}
+			}
+			return pgdao.UpsertTag(ctx, db, t)
+		},
+		Remove: func(ctx context.Context, y tagYAML) error {
+			_, err := pgdao.DeleteTag(ctx, db, y.Name)
+			return err
+		},
+	}
+	return a, db.Close, nil
+}
+
+func init() {
+	for _, c := range syncfs.Commands(syncfs.CommandSpec{Noun: "tag", ScopeHelp: "role", Open: openTagAdapter}) {
+		TagCmd.AddCommand(c)
+	}
+}
@@ -65,6 +65,9 @@ var getCmd = &cobra.Command{
 		if t.Created.Valid {
 			out["created"] = t.Created.Time.Format(time.RFC3339Nano)
 		}
+		if t.Updated.Valid {
+			out["updated"] = t.Updated.Time.Format(time.RFC3339Nano)
+		}
 		if t.Title.Valid {
 			out["title"] = t.Title.String
 		}
@@ -0,0 +1,156 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTaskHistVariant string
+	flagTaskHistID      string
+	flagTaskHistOutput  string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the replacement lineage of a task with computed versions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagTaskHistVariant) == "" && strings.TrimSpace(flagTaskHistID) == "" {
+			return errors.New("provide --variant or --id")
+		}
+		format := strings.ToLower(strings.TrimSpace(flagTaskHistOutput))
+		switch format {
+		case "", "table":
+			format = "table"
+		case "json", "mermaid", "dot":
+		default:
+			return errors.New("--output must be one of: table|json|mermaid|dot")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		id := strings.TrimSpace(flagTaskHistID)
+		if id == "" {
+			t, err := pgdao.GetTaskByVariant(ctx, db, flagTaskHistVariant)
+			if err != nil {
+				return err
+			}
+			id = t.ID
+		}
+		tasks, edges, err := pgdao.ListTaskLineage(ctx, db, id)
+		if err != nil {
+			return fmt.Errorf("cmd=task history params={variant:%s,id:%s}: %w", flagTaskHistVariant, flagTaskHistID, err)
+		}
+		if len(tasks) == 0 {
+			return fmt.Errorf("cmd=task history no result params={variant:%s,id:%s}", flagTaskHistVariant, flagTaskHistID)
+		}
+		ids := make([]string, 0, len(tasks))
+		byID := map[string]pgdao.Task{}
+		for _, t := range tasks {
+			ids = append(ids, t.ID)
+			byID[t.ID] = t
+		}
+		order, versions := computeTaskVersions(ids, edges)
+		fmt.Fprintf(os.Stderr, "task lineage: %d tasks, %d edges\n", len(tasks), len(edges))
+		switch format {
+		case "mermaid":
+			_, err := fmt.Fprint(os.Stdout, renderLineageMermaid(tasks, edges, versions))
+			return err
+		case "dot":
+			_, err := fmt.Fprint(os.Stdout, renderLineageDOT(tasks, edges, versions))
+			return err
+		}
+		replaces := map[string][]pgdao.TaskReplace{}
+		for _, e := range edges {
+			replaces[e.NewTaskID] = append(replaces[e.NewTaskID], e)
+		}
+		if format == "json" {
+			arr := make([]map[string]any, 0, len(order))
+			for _, tid := range order {
+				t := byID[tid]
+				item := map[string]any{"id": t.ID, "variant": t.Variant, "command": t.Command}
+				if v, ok := versions[tid]; ok {
+					item["version"] = v.String()
+				}
+				if t.Title.Valid {
+					item["title"] = t.Title.String
+				}
+				if t.Created.Valid {
+					item["created"] = t.Created.Time.Format(time.RFC3339Nano)
+				}
+				if t.Archived {
+					item["archived"] = true
+				}
+				if len(replaces[tid]) > 0 {
+					reps := make([]map[string]any, 0, len(replaces[tid]))
+					for _, e := range replaces[tid] {
+						r := map[string]any{"id": e.OldTaskID, "level": e.Level}
+						if e.Comment.Valid {
+							r["comment"] = e.Comment.String
+						}
+						if e.Created.Valid {
+							r["created"] = e.Created.Time.Format(time.RFC3339Nano)
+						}
+						reps = append(reps, r)
+					}
+					item["replaces"] = reps
+				}
+				arr = append(arr, item)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(arr)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"VERSION", "ID", "VARIANT", "REPLACES", "LEVEL", "COMMENT"})
+		for _, tid := range order {
+			t := byID[tid]
+			ver := "cycle"
+			if v, ok := versions[tid]; ok {
+				ver = v.String()
+			}
+			if len(replaces[tid]) == 0 {
+				table.Append([]string{ver, t.ID, t.Variant, "", "", ""})
+				continue
+			}
+			for i, e := range replaces[tid] {
+				comment := ""
+				if e.Comment.Valid {
+					comment = e.Comment.String
+				}
+				if i == 0 {
+					table.Append([]string{ver, t.ID, t.Variant, e.OldTaskID, e.Level, comment})
+				} else {
+					table.Append([]string{"", "", "", e.OldTaskID, e.Level, comment})
+				}
+			}
+		}
+		table.Render()
+		return nil
+	},
+}
+
+func init() {
+	TaskCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&flagTaskHistVariant, "variant", "", "Task variant on the lineage (e.g., unit/go)")
+	historyCmd.Flags().StringVar(&flagTaskHistID, "id", "", "Task UUID on the lineage")
+	historyCmd.Flags().StringVar(&flagTaskHistOutput, "output", "table", "Output format: table|json|mermaid|dot")
+}
@@ -0,0 +1,147 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// taskVersion is a semver-like version computed from replacement hops:
+// each field counts the major/minor/patch edges walked from the lineage root.
+type taskVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v taskVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v taskVersion) less(o taskVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+func (v taskVersion) bump(level string) taskVersion {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "major":
+		v.Major++
+	case "minor":
+		v.Minor++
+	case "patch":
+		v.Patch++
+	}
+	return v
+}
+
+// computeTaskVersions walks the lineage from its roots (tasks replacing nothing)
+// towards newer tasks and returns the ids in that order with their versions.
+// When a task replaces several older tasks the highest resulting version wins.
+// Tasks caught in a cycle are never reached and are returned without a version.
+func computeTaskVersions(ids []string, edges []pgdao.TaskReplace) ([]string, map[string]taskVersion) {
+	pending := map[string]int{}
+	newer := map[string][]pgdao.TaskReplace{}
+	for _, id := range ids {
+		pending[id] = 0
+	}
+	for _, e := range edges {
+		if _, ok := pending[e.NewTaskID]; !ok {
+			continue
+		}
+		if _, ok := pending[e.OldTaskID]; !ok {
+			continue
+		}
+		pending[e.NewTaskID]++
+		newer[e.OldTaskID] = append(newer[e.OldTaskID], e)
+	}
+	versions := map[string]taskVersion{}
+	queue := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if pending[id] == 0 {
+			versions[id] = taskVersion{}
+			queue = append(queue, id)
+		}
+	}
+	order := make([]string, 0, len(ids))
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		order = append(order, cur)
+		for _, e := range newer[cur] {
+			next := versions[cur].bump(e.Level)
+			if prev, ok := versions[e.NewTaskID]; !ok || prev.less(next) {
+				versions[e.NewTaskID] = next
+			}
+			pending[e.NewTaskID]--
+			if pending[e.NewTaskID] == 0 {
+				queue = append(queue, e.NewTaskID)
+			}
+		}
+	}
+	for _, id := range ids {
+		if pending[id] > 0 {
+			delete(versions, id)
+			order = append(order, id)
+		}
+	}
+	return order, versions
+}
+
+// lineageNodeLabel renders a short label for graph exports.
+func lineageNodeLabel(t pgdao.Task, versions map[string]taskVersion) string {
+	v, ok := versions[t.ID]
+	if !ok {
+		return t.Variant + " (cycle)"
+	}
+	return t.Variant + " v" + v.String()
+}
+
+// lineageNodeKey returns a graph-safe node identifier derived from a task UUID.
+func lineageNodeKey(id string) string {
+	return "t_" + strings.ReplaceAll(id, "-", "")
+}
+
+// renderLineageMermaid renders the lineage as a Mermaid flowchart (old --> new).
+func renderLineageMermaid(tasks []pgdao.Task, edges []pgdao.TaskReplace, versions map[string]taskVersion) string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, t := range sortedLineageTasks(tasks) {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", lineageNodeKey(t.ID), strings.ReplaceAll(lineageNodeLabel(t, versions), "\"", "'"))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", lineageNodeKey(e.OldTaskID), e.Level, lineageNodeKey(e.NewTaskID))
+	}
+	return b.String()
+}
+
+// renderLineageDOT renders the lineage as a Graphviz digraph (old -> new).
+func renderLineageDOT(tasks []pgdao.Task, edges []pgdao.TaskReplace, versions map[string]taskVersion) string {
+	var b strings.Builder
+	b.WriteString("digraph task_lineage {\n  rankdir=LR;\n")
+	for _, t := range sortedLineageTasks(tasks) {
+		fmt.Fprintf(&b, "  %s [label=%q];\n", lineageNodeKey(t.ID), lineageNodeLabel(t, versions))
+	}
+	for _, e := range edges {
+		label := e.Level
+		if e.Comment.Valid && strings.TrimSpace(e.Comment.String) != "" {
+			label += ": " + strings.TrimSpace(e.Comment.String)
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%q];\n", lineageNodeKey(e.OldTaskID), lineageNodeKey(e.NewTaskID), label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func sortedLineageTasks(tasks []pgdao.Task) []pgdao.Task {
+	out := append([]pgdao.Task(nil), tasks...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,50 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func TestComputeTaskVersions_SumsHopsFromRoot(t *testing.T) {
+	ids := []string{"c", "a", "b"}
+	edges := []pgdao.TaskReplace{
+		{NewTaskID: "b", OldTaskID: "a", Level: "minor"},
+		{NewTaskID: "c", OldTaskID: "b", Level: "patch"},
+	}
+	order, versions := computeTaskVersions(ids, edges)
+	if strings.Join(order, ",") != "a,b,c" {
+		t.Fatalf("expected root-first order a,b,c; got %v", order)
+	}
+	if got := versions["c"].String(); got != "0.1.1" {
+		t.Fatalf("expected c=0.1.1; got %s", got)
+	}
+}
+
+func TestComputeTaskVersions_MergeKeepsHighest(t *testing.T) {
+	ids := []string{"a", "b", "m"}
+	edges := []pgdao.TaskReplace{
+		{NewTaskID: "m", OldTaskID: "a", Level: "patch"},
+		{NewTaskID: "m", OldTaskID: "b", Level: "major"},
+	}
+	_, versions := computeTaskVersions(ids, edges)
+	if got := versions["m"].String(); got != "1.0.0" {
+		t.Fatalf("expected m=1.0.0; got %s", got)
+	}
+}
+
+func TestComputeTaskVersions_CycleLeftUnversioned(t *testing.T) {
+	ids := []string{"a", "b"}
+	edges := []pgdao.TaskReplace{
+		{NewTaskID: "b", OldTaskID: "a", Level: "patch"},
+		{NewTaskID: "a", OldTaskID: "b", Level: "patch"},
+	}
+	order, versions := computeTaskVersions(ids, edges)
+	if len(order) != 2 {
+		t.Fatalf("expected every task listed; got %v", order)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no versions inside a cycle; got %v", versions)
+	}
+}
@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTaskReplNew     string
+	flagTaskReplOld     string
+	flagTaskReplLevel   string
+	flagTaskReplComment string
+	flagTaskReplCreated string
+)
+
+var replaceCmd = &cobra.Command{
+	Use:   "replace",
+	Short: "Record that a task replaces another (rejects cycles)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newID := strings.TrimSpace(flagTaskReplNew)
+		oldID := strings.TrimSpace(flagTaskReplOld)
+		if newID == "" || oldID == "" {
+			return errors.New("--new and --old are required")
+		}
+		level := strings.ToLower(strings.TrimSpace(flagTaskReplLevel))
+		if level != "patch" && level != "minor" && level != "major" {
+			return errors.New("--level must be one of: patch|minor|major")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		for _, id := range []string{newID, oldID} {
+			if _, err := pgdao.GetTaskByID(ctx, db, id); err != nil {
+				return err
+			}
+		}
+		err = pgdao.ReplaceTask(ctx, db, newID, oldID, level, flagTaskReplComment, strings.TrimSpace(flagTaskReplCreated))
+		if errors.Is(err, pgdao.ErrTaskReplacesCycle) {
+			return fmt.Errorf("cmd=task replace rejected: %s already replaces %s (directly or transitively); params={new:%s,old:%s,level:%s}", oldID, newID, newID, oldID, level)
+		}
+		if err != nil {
+			return fmt.Errorf("cmd=task replace params={new:%s,old:%s,level:%s}: %w", newID, oldID, level, err)
+		}
+		fmt.Fprintf(os.Stderr, "task replace recorded new=%s old=%s level=%s\n", newID, oldID, level)
+		out := map[string]any{"status": "recorded", "new": newID, "old": oldID, "level": level}
+		if strings.TrimSpace(flagTaskReplComment) != "" {
+			out["comment"] = flagTaskReplComment
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	},
+}
+
+func init() {
+	TaskCmd.AddCommand(replaceCmd)
+	replaceCmd.Flags().StringVar(&flagTaskReplNew, "new", "", "Replacing (newer) task UUID (required)")
+	replaceCmd.Flags().StringVar(&flagTaskReplOld, "old", "", "Replaced (older) task UUID (required)")
+	replaceCmd.Flags().StringVar(&flagTaskReplLevel, "level", "minor", "Replacement level: patch|minor|major (default minor)")
+	replaceCmd.Flags().StringVar(&flagTaskReplComment, "comment", "", "Optional comment for the replacement edge")
+	replaceCmd.Flags().StringVar(&flagTaskReplCreated, "created", "", "Optional timestamp (RFC3339) for the edge; defaults to now on DB side")
+}
@@ -15,6 +15,7 @@ import (
 
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
@@ -67,11 +68,17 @@ var runCmd = &cobra.Command{
 		if toDur <= 0 {
 			toDur = 10 * time.Minute
 		}
+		// Resolve script body from resolved attachment
+		body, err := pgdao.GetScriptContent(ctx, db, scr.ScriptContentID)
+		if err != nil {
+			return err
+		}
 
 		// Determine role for message: prefer experiment's conversation role, else task's role
 		var roleForMessage string
+		var exp *pgdao.Experiment
 		if strings.TrimSpace(flagRunExperiment) != "" {
-			if exp, err := pgdao.GetExperimentByID(ctx, db, flagRunExperiment); err == nil && exp != nil {
+			if exp, err = pgdao.GetExperimentByID(ctx, db, flagRunExperiment); err == nil && exp != nil {
 				if conv, err := pgdao.GetConversationByID(ctx, db, exp.ConversationID); err == nil && conv != nil {
 					if strings.TrimSpace(conv.RoleName) != "" {
 						roleForMessage = strings.TrimSpace(conv.RoleName)
@@ -111,16 +118,19 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Fprintf(os.Stderr, "running task %s (message id=%d)\n", task.Variant, msgID)
+		fmt.Fprintf(os.Stderr, "running task %s (message id=%s)\n", task.Variant, msgID)
+		// Only now that the run is recorded, note which task and script the
+		// experiment ran and mark it running.
+		if exp != nil {
+			params := map[string]any{"tasks": map[string]any{task.Variant: map[string]any{"task_id": task.ID, "script_id": scr.ID, "script_content_id": scr.ScriptContentID}}}
+			if _, err := pgdao.UpdateExperiment(ctx, db, exp.ID, pgdao.ExperimentUpdate{Params: params, Outcome: pgdao.ExperimentRunning}); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: experiment %s not updated: %v\n", exp.ID, err)
+			}
+		}
 
 		// Prepare command with context timeout
 		runCtx, cancelRun := context.WithTimeout(context.Background(), toDur)
 		defer cancelRun()
-		// Resolve script body from resolved attachment
-		body, err := pgdao.GetScriptContent(ctx, db, scr.ScriptContentID)
-		if err != nil {
-			return err
-		}
 		cmdExec, interpreter := buildCommand(runCtx, task, body)
 		// Environment
 		if len(flagRunEnv) > 0 {
@@ -156,6 +166,10 @@ var runCmd = &cobra.Command{
 			}
 		}
 
+		if strings.TrimSpace(flagRunExperiment) != "" {
+			recordTaskOutcome(db, flagRunExperiment, task.Variant, status, dur, exitCode)
+		}
+
 		// Prepare completion content and update message
 		compMeta := map[string]any{
 			"variant":   task.Variant,
@@ -205,6 +219,22 @@ func init() {
 	runCmd.Flags().StringVar(&flagRunScriptName, "script", "run", "Logical script name attached to the task")
 }
 
+// recordTaskOutcome folds a run into its experiment: the status follows the
+// run unless the experiment already failed, and per-variant metrics are kept.
+// Failures only warn; the run itself is already recorded.
+func recordTaskOutcome(db *pgxpool.Pool, experimentID, variant, status string, dur time.Duration, exitCode int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := pgdao.UpdateExperiment(ctx, db, experimentID, pgdao.ExperimentUpdate{
+		Outcome:    status,
+		Metrics:    map[string]float64{variant + ".duration_s": dur.Seconds(), variant + ".exit_code": float64(exitCode)},
+		AddMetrics: map[string]float64{"task_runs": 1},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: experiment %s not updated: %v\n", experimentID, err)
+	}
+}
+
 // chooseTimeout selects a time.Duration given an optional Go duration string and
 // a Postgres interval textual representation. Returns error only if an override
 // is provided but cannot be parsed.
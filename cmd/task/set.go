@@ -37,6 +37,7 @@ var (
 	flagTaskReplaceLevel   string
 	flagTaskReplaceComment string
 	flagTaskReplaceCreated string
+	flagTaskIfMatch        string
 )
 
 var setCmd = &cobra.Command{
@@ -46,6 +47,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagTaskWF) == "" || strings.TrimSpace(flagTaskCmd) == "" {
 			return errors.New("--workflow and --command are required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagTaskIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -57,7 +62,7 @@ var setCmd = &cobra.Command{
 			return err
 		}
 		defer db.Close()
-		t := &pgdao.Task{WorkflowID: flagTaskWF, Command: flagTaskCmd, Variant: flagTaskVar}
+		t := &pgdao.Task{WorkflowID: flagTaskWF, Command: flagTaskCmd, Variant: flagTaskVar, IfMatch: ifMatch}
 		if strings.TrimSpace(flagTaskRole) != "" {
 			t.RoleName = strings.TrimSpace(flagTaskRole)
 		}
@@ -101,7 +106,12 @@ var setCmd = &cobra.Command{
 			if level != "patch" && level != "minor" && level != "major" {
 				return fmt.Errorf("--replace-level must be patch|minor|major")
 			}
-			if err := pgdao.CreateTaskReplacesEdge(ctx, db, t.ID, flagTaskReplaces, level, flagTaskReplaceComment, strings.TrimSpace(flagTaskReplaceCreated)); err != nil {
+			err := pgdao.ReplaceTask(ctx, db, t.ID, flagTaskReplaces, level, flagTaskReplaceComment, strings.TrimSpace(flagTaskReplaceCreated))
+			switch {
+			case errors.Is(err, pgdao.ErrTaskReplacesCycle):
+				// An edge closing a cycle is never stored; see `task replace`
+				fmt.Fprintf(os.Stderr, "warn: graph REPLACES edge not created: %s already replaces %s\n", flagTaskReplaces, t.ID)
+			case err != nil:
 				// Non-fatal: print a warning and proceed
 				fmt.Fprintf(os.Stderr, "warn: graph REPLACES edge not created: %v\n", err)
 			}
@@ -119,6 +129,9 @@ var setCmd = &cobra.Command{
 		if t.Created.Valid {
 			out["created"] = t.Created.Time.Format(time.RFC3339Nano)
 		}
+		if t.Updated.Valid {
+			out["updated"] = t.Updated.Time.Format(time.RFC3339Nano)
+		}
 		if t.ToolWorkspaceID.Valid {
 			out["tool_workspace_id"] = t.ToolWorkspaceID.String
 		}
@@ -149,6 +162,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagTaskReplaceComment, "replace-comment", "", "Optional comment for replacement edge")
 	setCmd.Flags().StringVar(&flagTaskReplaceCreated, "replace-created", "", "Optional timestamp (RFC3339) for replacement edge creation; defaults to now on DB side")
 	setCmd.Flags().BoolVar(&flagTaskArchived, "archived", false, "Mark task as archived (excluded from active lookups)")
+	setCmd.Flags().StringVar(&flagTaskIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
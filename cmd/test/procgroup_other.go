@@ -0,0 +1,9 @@
+//go:build !unix
+
+package test
+
+import "os/exec"
+
+// setProcessGroup is a no-op without process groups: cancellation kills the
+// shell only and WaitDelay bounds the wait for its children.
+func setProcessGroup(c *exec.Cmd) {}
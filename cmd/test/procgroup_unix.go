@@ -0,0 +1,17 @@
+//go:build unix
+
+package test
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts c in its own process group and makes cancellation
+// kill the whole group, so runner children holding stdout die with the shell.
+func setProcessGroup(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+}
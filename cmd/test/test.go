@@ -6,7 +6,7 @@ import (
 
 var TestCmd = &cobra.Command{
 	Use:   "test",
-	Short: "TODO: Describe the 'test' command",
+	Short: "Run tests and record them as testcases",
 }
 
 func init() {
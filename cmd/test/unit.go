@@ -1,15 +1,473 @@
 package test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
+var (
+	flagUnitVariant      string
+	flagUnitScript       string
+	flagUnitCommand      string
+	flagUnitFormat       string
+	flagUnitLang         string
+	flagUnitExperiment   string
+	flagUnitConversation string
+	flagUnitRole         string
+	flagUnitTimeout      string
+	flagUnitNoRecord     bool
+	flagUnitVerbose      bool
+)
+
+// reportPlaceholder in a test command is replaced by a temporary file path; the
+// report is then read from that file instead of stdout (e.g. pytest --junitxml).
+const reportPlaceholder = "{report}"
+
+// unitWaitDelay bounds how long a cancelled run may keep its output pipes open.
+const unitWaitDelay = 5 * time.Second
+
+// unitRunner is a language default: how to run its unit tests and read the report.
+type unitRunner struct {
+	Lang    string
+	Markers []string // files whose presence in the working directory selects it
+	Command string
+	Format  string
+}
+
+var unitRunners = []unitRunner{
+	{Lang: "go", Markers: []string{"go.mod"}, Command: "go test -json ./...", Format: "gotest-json"},
+	{Lang: "node", Markers: []string{"package.json"}, Command: "node --test --test-reporter=tap", Format: "tap"},
+	{Lang: "python", Markers: []string{"pyproject.toml", "pytest.ini", "setup.py", "setup.cfg", "tox.ini"}, Command: "python3 -m pytest -q --junitxml=" + reportPlaceholder, Format: "junit"},
+}
+
 var unitCmd = &cobra.Command{
 	Use:   "unit",
-	Short: "TODO: Describe the 'test unit' command",
-	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: Implement unit test runner
-		fmt.Println("Running unit tests...")
+	Short: "Run the unit tests of the current project and record them as testcases",
+	Long: `Run the unit tests of the current directory and record every case.
+
+The command is, in order: --command; the "run" script of the --variant task;
+the script of the task "unit/<lang>" when it exists; the language default
+(go: go test -json ./..., node: node --test with TAP, python: pytest JUnit).
+The language is detected from go.mod, package.json or pyproject.toml (--lang
+overrides). The report format comes from --format, the task tag report_format,
+or the language. A command containing {report} writes its report to that file.
+
+Cases are stored under --experiment, or a new experiment of --conversation.
+The exit code is the test command's: 0 when every case passed.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		record := !flagUnitNoRecord
+		if record && strings.TrimSpace(flagUnitExperiment) == "" && strings.TrimSpace(flagUnitConversation) == "" {
+			return errors.New("--experiment or --conversation is required (or --no-record)")
+		}
+		timeout := 30 * time.Minute
+		if strings.TrimSpace(flagUnitTimeout) != "" {
+			d, err := time.ParseDuration(strings.TrimSpace(flagUnitTimeout))
+			if err != nil {
+				return fmt.Errorf("invalid --timeout: %w", err)
+			}
+			timeout = d
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		var db *pgxpool.Pool
+		if record || flagUnitVariant != "" || flagUnitCommand == "" {
+			cfg, err := cfgpkg.Load()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			db, err = pgdao.OpenApp(ctx, cfg)
+			cancel()
+			if err != nil {
+				if record || flagUnitVariant != "" {
+					return err
+				}
+				db = nil // language default only
+			} else {
+				defer db.Close()
+			}
+		}
+
+		plan, err := resolveUnitPlan(db, cwd)
+		if err != nil {
+			return err
+		}
+		shown := strings.TrimSpace(plan.command)
+		if i := strings.IndexByte(shown, '\n'); i >= 0 {
+			shown = shown[:i] + " ..."
+		}
+		fmt.Fprintf(os.Stderr, "test unit: %s (format=%s, source=%s)\n", shown, plan.format, plan.source)
+
+		experimentID := strings.TrimSpace(flagUnitExperiment)
+		if record && experimentID == "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			e, err := pgdao.CreateExperiment(ctx, db, strings.TrimSpace(flagUnitConversation))
+			cancel()
+			if err != nil {
+				return err
+			}
+			experimentID = e.ID
+			fmt.Fprintf(os.Stderr, "experiment created id=%s\n", e.ID)
+		}
+
+		res, err := runUnitTests(plan, timeout)
+		if err != nil {
+			if record {
+				abortUnitExperiment(db, experimentID)
+			}
+			return err
+		}
+		sum := testreport.Summarize(res.cases)
+		if record && len(res.cases) > 0 {
+			tags := map[string]any{"source": plan.format, "runner": "test unit"}
+			if plan.variant != "" {
+				tags["variant"] = plan.variant
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			err := pgdao.InsertTestcases(ctx, db, testreport.Rows(res.cases, flagUnitRole, experimentID, tags))
+			cancel()
+			if err != nil {
+				abortUnitExperiment(db, experimentID)
+				return err
+			}
+		}
+
+		status := "PASS"
+		if !sum.OK() || res.exitCode != 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(os.Stderr, "%s %s duration=%s exit_code=%d", status, sum, res.duration.Round(time.Millisecond), res.exitCode)
+		if record {
+			fmt.Fprintf(os.Stderr, " experiment=%s", experimentID)
+		}
+		fmt.Fprintln(os.Stderr)
+		printFailures(res.cases)
+		out := map[string]any{"status": strings.ToLower(status), "source": plan.source, "format": plan.format, "exit_code": res.exitCode, "duration": res.duration.String(), "summary": sum}
+		if record {
+			out["experiment_id"] = experimentID
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+
+		switch {
+		case res.timedOut:
+			return &unitError{code: 124, msg: fmt.Sprintf("test command timed out after %s", timeout)}
+		case len(res.cases) == 0 && res.exitCode != 0:
+			return &unitError{code: res.exitCode, msg: fmt.Sprintf("test command failed before reporting any case (exit code %d)", res.exitCode)}
+		case len(res.cases) == 0:
+			return errors.New("test command reported no test cases")
+		case res.exitCode != 0:
+			return &unitError{code: res.exitCode, msg: fmt.Sprintf("tests failed: %s", sum)}
+		case !sum.OK():
+			return &unitError{code: 1, msg: fmt.Sprintf("tests failed: %s", sum)}
+		}
+		return nil
 	},
 }
+
+func init() {
+	unitCmd.Flags().StringVar(&flagUnitVariant, "variant", "", "Task variant whose script runs the tests, e.g. unit/go")
+	unitCmd.Flags().StringVar(&flagUnitScript, "script", "run", "Logical script name attached to the task")
+	unitCmd.Flags().StringVar(&flagUnitCommand, "command", "", "Shell command to run instead of a task or language default")
+	unitCmd.Flags().StringVar(&flagUnitFormat, "format", "", "Report format: "+strings.Join(testreport.Formats(), ", "))
+	unitCmd.Flags().StringVar(&flagUnitLang, "lang", "", "Language default to use: go, node or python (default detected)")
+	unitCmd.Flags().StringVar(&flagUnitExperiment, "experiment", "", "Experiment UUID to record under")
+	unitCmd.Flags().StringVar(&flagUnitConversation, "conversation", "", "Conversation UUID; a new experiment is created for the run")
+	unitCmd.Flags().StringVar(&flagUnitRole, "role", "user", "Role name of the recorded testcases")
+	unitCmd.Flags().StringVar(&flagUnitTimeout, "timeout", "", "Timeout as Go duration (default 30m)")
+	unitCmd.Flags().BoolVar(&flagUnitNoRecord, "no-record", false, "Run and summarize without storing testcases")
+	unitCmd.Flags().BoolVarP(&flagUnitVerbose, "verbose", "v", false, "Print every case, not only failures")
+}
+
+// unitError carries the exit code of a failed run.
+type unitError struct {
+	code int
+	msg  string
+}
+
+func (e *unitError) Error() string { return e.msg }
+func (e *unitError) ExitCode() int { return e.code }
+
+// unitPlan is the resolved command of a run.
+type unitPlan struct {
+	command string
+	shell   string
+	format  string
+	source  string // command, task or language
+	variant string
+	dir     string
+}
+
+func resolveUnitPlan(db *pgxpool.Pool, dir string) (unitPlan, error) {
+	plan := unitPlan{shell: "bash", format: strings.ToLower(strings.TrimSpace(flagUnitFormat)), dir: dir}
+	var runner *unitRunner
+	if flagUnitLang != "" {
+		for i := range unitRunners {
+			if strings.EqualFold(unitRunners[i].Lang, flagUnitLang) {
+				runner = &unitRunners[i]
+			}
+		}
+		if runner == nil {
+			return plan, fmt.Errorf("unknown --lang %q", flagUnitLang)
+		}
+	} else {
+		runner = detectUnitRunner(dir)
+	}
+	fallbackFormat := func() {
+		if plan.format == "" && runner != nil {
+			plan.format = runner.Format
+		}
+	}
+
+	switch {
+	case strings.TrimSpace(flagUnitCommand) != "":
+		plan.command, plan.source = flagUnitCommand, "command"
+		fallbackFormat()
+	default:
+		variant := strings.TrimSpace(flagUnitVariant)
+		if variant == "" && runner != nil && db != nil {
+			variant = "unit/" + runner.Lang
+		}
+		if variant != "" && db != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			task, err := pgdao.GetTaskByVariant(ctx, db, variant)
+			switch {
+			case err == nil:
+				scr, err := pgdao.ResolveTaskScript(ctx, db, task.ID, flagUnitScript)
+				if err != nil {
+					return plan, fmt.Errorf("no script named %q attached to task %s (variant=%q): %w", flagUnitScript, task.ID, variant, err)
+				}
+				body, err := pgdao.GetScriptContent(ctx, db, scr.ScriptContentID)
+				if err != nil {
+					return plan, err
+				}
+				plan.command, plan.source, plan.variant = body, "task "+variant, variant
+				if sh := strings.ToLower(strings.TrimSpace(task.Shell.String)); sh == "sh" {
+					plan.shell = sh
+				}
+				if f, ok := task.Tags["report_format"].(string); ok && plan.format == "" {
+					plan.format = strings.ToLower(f)
+				}
+				if runner == nil {
+					lang := variant[strings.LastIndex(variant, "/")+1:]
+					for i := range unitRunners {
+						if unitRunners[i].Lang == lang {
+							runner = &unitRunners[i]
+						}
+					}
+				}
+				fallbackFormat()
+			case flagUnitVariant != "":
+				return plan, err
+			}
+		}
+		if plan.command == "" {
+			if runner == nil {
+				return plan, errors.New("cannot detect the project language (no go.mod, package.json or pyproject.toml); use --lang, --variant or --command")
+			}
+			plan.command, plan.source = runner.Command, "language "+runner.Lang
+			fallbackFormat()
+		}
+	}
+	if plan.format == "" {
+		return plan, errors.New("cannot tell the report format of the test command; use --format")
+	}
+	if _, err := testreport.Lookup(plan.format); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// detectUnitRunner picks the language default whose marker file exists in dir.
+func detectUnitRunner(dir string) *unitRunner {
+	for i := range unitRunners {
+		for _, m := range unitRunners[i].Markers {
+			if fi, err := os.Stat(filepath.Join(dir, m)); err == nil && !fi.IsDir() {
+				return &unitRunners[i]
+			}
+		}
+	}
+	return nil
+}
+
+type unitResult struct {
+	cases    []testreport.Case
+	exitCode int
+	timedOut bool
+	duration time.Duration
+}
+
+// runUnitTests runs the plan, streaming the runner's output: stderr is passed
+// through, stdout is parsed live when the format supports it and echoed otherwise.
+func runUnitTests(plan unitPlan, timeout time.Duration) (unitResult, error) {
+	var res unitResult
+	command := plan.command
+	reportFile := ""
+	if strings.Contains(command, reportPlaceholder) {
+		f, err := os.CreateTemp("", "rbc-test-report-*")
+		if err != nil {
+			return res, err
+		}
+		reportFile = f.Name()
+		f.Close()
+		defer os.Remove(reportFile)
+		command = strings.ReplaceAll(command, reportPlaceholder, reportFile)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, plan.shell, "-c", command)
+	c.Dir = plan.dir
+	c.Stderr = os.Stderr
+	c.WaitDelay = unitWaitDelay
+	setProcessGroup(c)
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return res, err
+	}
+	start := time.Now()
+	if err := c.Start(); err != nil {
+		return res, err
+	}
+	stream, streaming := testreport.NewStream(plan.format)
+	if reportFile != "" {
+		streaming = false
+	}
+	var buffered bytes.Buffer
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var feedErr error
+	for sc.Scan() {
+		line := sc.Bytes()
+		if !streaming {
+			os.Stderr.Write(line)
+			os.Stderr.Write([]byte("\n"))
+			if reportFile == "" {
+				buffered.Write(line)
+				buffered.WriteByte('\n')
+			}
+			continue
+		}
+		if t := bytes.TrimSpace(line); len(t) == 0 || t[0] != '{' {
+			fmt.Fprintf(os.Stderr, "%s\n", line)
+			continue
+		}
+		tc, err := stream.Feed(line)
+		if err != nil && feedErr == nil {
+			feedErr = err
+		}
+		if tc != nil {
+			printCase(*tc)
+		}
+	}
+	// Drain the pipe on scan errors so the runner is not blocked on write
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := c.Wait()
+	res.duration = time.Since(start)
+	if waitErr != nil {
+		var ee *exec.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			res.timedOut, res.exitCode = true, 124
+		case errors.As(waitErr, &ee):
+			res.exitCode = ee.ExitCode()
+		default:
+			return res, waitErr
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return res, err
+	}
+	if feedErr != nil {
+		return res, feedErr
+	}
+	switch {
+	case streaming:
+		res.cases = stream.Cases()
+	default:
+		parse, err := testreport.Lookup(plan.format)
+		if err != nil {
+			return res, err
+		}
+		var in io.Reader = &buffered
+		if reportFile != "" {
+			f, err := os.Open(reportFile)
+			if err != nil {
+				return res, err
+			}
+			defer f.Close()
+			if fi, err := f.Stat(); err == nil && fi.Size() == 0 {
+				return res, nil
+			}
+			in = f
+		}
+		cases, err := parse(in)
+		if err != nil {
+			return res, err
+		}
+		res.cases = cases
+	}
+	return res, nil
+}
+
+// abortUnitExperiment marks the experiment aborted when the run ends before its
+// testcases are recorded, so it does not stay running. Failures only warn.
+func abortUnitExperiment(db *pgxpool.Pool, experimentID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := pgdao.UpdateExperiment(ctx, db, experimentID, pgdao.ExperimentUpdate{Outcome: pgdao.ExperimentAborted}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: experiment %s not updated: %v\n", experimentID, err)
+	}
+}
+
+// printCase reports a case as soon as it completes: failures always, others
+// with --verbose.
+func printCase(c testreport.Case) {
+	if c.Result == testreport.Passed || c.Result == testreport.Skipped {
+		if flagUnitVerbose {
+			fmt.Fprintf(os.Stderr, "%-5s %s %s (%.2fs)\n", c.Result.Status(), c.Package, c.Title(), c.Time)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%-5s %s %s (%.2fs)\n", c.Result.Status(), c.Package, c.Title(), c.Time)
+}
+
+// printFailures lists the failed cases with their location and first message line.
+func printFailures(cases []testreport.Case) {
+	for _, c := range cases {
+		if c.Result != testreport.Failed && c.Result != testreport.Errored {
+			continue
+		}
+		loc := ""
+		if c.File != "" && c.Line > 0 {
+			loc = fmt.Sprintf(" %s:%d", c.File, c.Line)
+		}
+		msg := c.Message
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg = msg[:i]
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s %s%s %s\n", c.Result.Status(), c.Package, c.Title(), loc, msg)
+	}
+}
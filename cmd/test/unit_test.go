@@ -0,0 +1,65 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+)
+
+func TestDetectUnitRunner(t *testing.T) {
+	dir := t.TempDir()
+	if r := detectUnitRunner(dir); r != nil {
+		t.Fatalf("empty dir detected %s", r.Lang)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := detectUnitRunner(dir); r == nil || r.Lang != "python" || r.Format != "junit" {
+		t.Fatalf("python not detected: %+v", r)
+	}
+}
+
+func TestRunUnitTests_ParsesReportAndExitCode(t *testing.T) {
+	plan := unitPlan{shell: "sh", format: "tap", dir: t.TempDir(),
+		command: `printf 'TAP version 13\n1..2\nok 1 - adds\nnot ok 2 - divides\n'; exit 1`}
+	res, err := runUnitTests(plan, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testreport.Summarize(res.cases)
+	if res.exitCode != 1 || s.Total != 2 || s.Failed != 1 {
+		t.Fatalf("unexpected result: exit=%d %s", res.exitCode, s)
+	}
+}
+
+func TestRunUnitTests_ReportFile(t *testing.T) {
+	plan := unitPlan{shell: "sh", format: "junit", dir: t.TempDir(),
+		command: `echo '<testsuite name="p"><testcase name="a"/></testsuite>' > ` + reportPlaceholder}
+	res, err := runUnitTests(plan, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.cases) != 1 || res.cases[0].Package != "p" || res.exitCode != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestRunUnitTests_TimeoutKillsChildren(t *testing.T) {
+	// The shell forks sleep, which inherits stdout: killing the shell alone
+	// would leave the pipe open until sleep exits.
+	plan := unitPlan{shell: "sh", format: "tap", dir: t.TempDir(), command: `sleep 30; echo done`}
+	start := time.Now()
+	res, err := runUnitTests(plan, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.timedOut || res.exitCode != 124 {
+		t.Fatalf("expected timeout: %+v", res)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("run outlived its timeout: %s", d)
+	}
+}
@@ -2,9 +2,13 @@ package testcase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,11 +17,19 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/stickieschema"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
 var (
 	flagTCActiveConversation string
+	flagTCActiveExperiment   string
+	flagTCActiveStatus       string
+	flagTCActivePackage      string
+	flagTCActiveLevel        string
+	flagTCActiveBlackboard   string
+	flagTCActiveRefresh      time.Duration
 )
 
 // UI styles
@@ -29,312 +41,804 @@ var (
 	styleCursor  = lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)   // magenta
 	styleDivider = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))               // gray line
 	styleNewest  = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Italic(true) // yellow italic
+	styleNotice  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))              // green
+	stylePane    = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).BorderForeground(lipgloss.Color("8")).PaddingLeft(1)
 )
 
-// activeCmd is an interactive variant of list (dummy implementation).
-// For now, it validates required flags and returns a mock payload.
+// Status filter values cycled with 's'; "failing" matches KO and ERROR.
+var activeStatusFilters = []string{"", "failing", "OK", "SKIP", "TODO"}
+
+// activeCmd is a live explorer of the testcases recorded under a conversation's
+// experiments.
 var activeCmd = &cobra.Command{
 	Use:   "active",
-	Short: "Interactive list of active testcases (mock)",
+	Short: "Live, filterable explorer of a conversation's testcases",
+	Long: `Browse the testcases of a conversation's experiments. The list follows the
+newest experiment and refreshes every --refresh while new testcases arrive.
+
+Keys:
+  ↑/k ↓/j pgup/pgdn  move           n/N  older/newer experiment
+  s  cycle status (all, failing, OK, SKIP, TODO)   e  failing only
+  p  cycle package    l  cycle level    x  clear filters    r  refresh now
+  o/enter  open the file in $EDITOR at the line
+  R  re-run the originating task (rbc test unit)
+  c  create a stickie from the selected failure (needs --blackboard)
+  q  quit`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if strings.TrimSpace(flagTCActiveConversation) == "" {
 			return errors.New("--conversation is required")
 		}
+		status := normalizeStatusFilter(flagTCActiveStatus)
+		if status == "?" {
+			return fmt.Errorf("--status must be one of failing, OK, KO, ERROR, SKIP, TODO")
+		}
 
-		// Load config and resolve role_name from the conversation
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		// The pool outlives ctx: it serves every refresh until the TUI exits
 		db, err := pgdao.OpenApp(ctx, cfg)
 		if err != nil {
 			return err
 		}
 		defer db.Close()
 
-		// Resolve conversation and role via DAO
 		conv, err := pgdao.GetConversationByID(ctx, db, flagTCActiveConversation)
 		if err != nil {
 			return err
 		}
-		role := strings.TrimSpace(conv.RoleName)
-		if role == "" {
-			return errors.New("conversation has no role_name")
+		if b := strings.TrimSpace(flagTCActiveBlackboard); b != "" {
+			if _, err := pgdao.GetBlackboardByID(ctx, db, b); err != nil {
+				return err
+			}
 		}
-
-		// Fetch recent experiments for the conversation (up to 6)
-		exps, err := pgdao.ListExperiments(ctx, db, conv.ID, 6, 0)
-		if err != nil {
-			return err
+		m := newActiveModel(db, conv.ID, strings.TrimSpace(conv.RoleName))
+		m.blackboard = strings.TrimSpace(flagTCActiveBlackboard)
+		m.refresh = flagTCActiveRefresh
+		m.filter = activeFilter{status: status, pkg: strings.TrimSpace(flagTCActivePackage), level: strings.ToLower(strings.TrimSpace(flagTCActiveLevel))}
+		if e := strings.TrimSpace(flagTCActiveExperiment); e != "" {
+			m.experiment, m.follow = e, false
 		}
-		if len(exps) == 0 {
+		loaded := loadActive(ctx, db, conv.ID, m.experiment)
+		if e, ok := loaded.(errMsg); ok {
+			return e.err
+		}
+		next, _ := m.Update(loaded)
+		m = next.(activeModel)
+		if len(m.experiments) == 0 {
 			return fmt.Errorf("no experiment found for conversation %s", conv.ID)
 		}
-		exp := exps[0]
+		fmt.Fprintf(os.Stderr, "testcase active: conversation=%s role=%s experiments=%d current=%s count=%d\n", conv.ID, m.role, len(m.experiments), m.experiment, len(m.testcases))
+		_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+		return err
+	},
+}
 
-		// Fetch testcases for that experiment and role
-		tcs, err := pgdao.ListTestcases(ctx, db, role, exp.ID, "", 100, 0)
-		if err != nil {
-			return err
+// activeFilter narrows the testcases of the current experiment; empty fields
+// match everything.
+type activeFilter struct {
+	status string
+	pkg    string
+	level  string
+}
+
+func (f activeFilter) matches(tc pgdao.Testcase) bool {
+	switch f.status {
+	case "":
+	case "failing":
+		if !isErrorStatus(tc.Status) {
+			return false
 		}
+	default:
+		if !strings.EqualFold(strings.TrimSpace(tc.Status), f.status) {
+			return false
+		}
+	}
+	if f.pkg != "" && packageOf(tc) != f.pkg {
+		return false
+	}
+	if f.level != "" && normalizedLevel(tc) != f.level {
+		return false
+	}
+	return true
+}
 
-		// Build model with full testcase records
+func (f activeFilter) String() string {
+	parts := []string{}
+	if f.status != "" {
+		parts = append(parts, "status="+f.status)
+	}
+	if f.pkg != "" {
+		parts = append(parts, "package="+f.pkg)
+	}
+	if f.level != "" {
+		parts = append(parts, "level="+f.level)
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, " ")
+}
 
-		// Launch Bubble Tea TUI (list of titles)
-		fmt.Fprintf(os.Stderr, "testcase active: conversation=%s role=%s experiments=%d current=%s count=%d\n", conv.ID, role, len(exps), exp.ID, len(tcs))
-		m := newActiveModel(conv.ID, role, exps, 0, tcs)
-		if _, err := tea.NewProgram(m).Run(); err != nil {
-			return err
-		}
-		return nil
-	},
+// normalizeStatusFilter maps a --status value to a filter; "?" means invalid.
+func normalizeStatusFilter(s string) string {
+	s = strings.TrimSpace(s)
+	switch strings.ToUpper(s) {
+	case "", "ALL":
+		return ""
+	case "FAILING", "FAILED", "ERRORS":
+		return "failing"
+	case "OK", "KO", "ERROR", "SKIP", "TODO":
+		return strings.ToUpper(s)
+	}
+	return "?"
 }
 
-// Bubble Tea model for interactive testcase list
+// Bubble Tea model for the live testcase explorer
 type activeModel struct {
+	db           *pgxpool.Pool
 	conversation string
 	role         string
+	blackboard   string
 	experiments  []pgdao.Experiment
-	expIdx       int
+	experiment   string
+	follow       bool // jump to new experiments as they are created
 	testcases    []pgdao.Testcase
+	filter       activeFilter
 	cursor       int
-	quitting     bool
+	width        int
+	height       int
+	refresh      time.Duration
+	updated      time.Time
+	notice       string
 	err          string
-	errorsOnly   bool
+	quitting     bool
 }
 
-func newActiveModel(conversation, role string, experiments []pgdao.Experiment, expIdx int, testcases []pgdao.Testcase) activeModel {
-	if expIdx < 0 || expIdx >= len(experiments) {
-		expIdx = 0
+func newActiveModel(db *pgxpool.Pool, conversation, role string) activeModel {
+	return activeModel{db: db, conversation: conversation, role: role, follow: true, width: 120, height: 30}
+}
+
+func (m activeModel) Init() tea.Cmd { return m.tick() }
+
+func (m activeModel) tick() tea.Cmd {
+	if m.refresh <= 0 {
+		return nil
 	}
-	return activeModel{conversation: conversation, role: role, experiments: experiments, expIdx: expIdx, testcases: testcases}
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg { return tickMsg(t) })
 }
 
-func (m activeModel) Init() tea.Cmd { return nil }
+func (m activeModel) reload() tea.Cmd {
+	db, conv, exp := m.db, m.conversation, m.experiment
+	if m.follow {
+		exp = ""
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return loadActive(ctx, db, conv, exp)
+	}
+}
 
 func (m activeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			m.quitting = true
-			return m, tea.Quit
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-			}
-		case "down", "j":
-			// move within filtered list
-			if m.cursor < len(m.filteredIndices())-1 {
-				m.cursor++
-			}
-		case "enter":
-			// Reserved for future: open a detail pane or actions. No-op for now.
-		case "r":
-			// Refresh testcases for current experiment
-			m.err = ""
-			return m, refreshForExpCmd(m.role, m.experiments[m.expIdx].ID)
-		case "n":
-			// Next experiment (cycle)
-			if len(m.experiments) > 0 {
-				m.err = ""
-				m.expIdx = (m.expIdx + 1) % len(m.experiments)
-				m.cursor = 0
-				return m, refreshForExpCmd(m.role, m.experiments[m.expIdx].ID)
-			}
-		case "e":
-			// Toggle errors-only view
-			m.errorsOnly = !m.errorsOnly
-			// Normalize cursor against new filtered length
-			if m.cursor >= len(m.filteredIndices()) {
-				m.cursor = 0
-			}
-			return m, nil
-		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case tickMsg:
+		return m, tea.Batch(m.reload(), m.tick())
 	case refreshMsg:
-		m.testcases = msg.testcases
-		// find index of experiment in list, if provided
-		for i, e := range m.experiments {
-			if e.ID == msg.experiment {
-				m.expIdx = i
-				break
-			}
+		m.applyRefresh(msg)
+		return m, nil
+	case actionMsg:
+		m.notice, m.err = msg.notice, ""
+		if msg.err != nil {
+			m.notice, m.err = "", msg.err.Error()
 		}
-		// Clamp cursor against filtered length after refresh
-		if m.cursor >= len(m.filteredIndices()) {
-			m.cursor = len(m.filteredIndices()) - 1
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
+		if msg.follow {
+			m.follow = true
+		}
+		if msg.reload {
+			return m, m.reload()
 		}
 		return m, nil
 	case errMsg:
 		m.err = msg.err.Error()
 		return m, nil
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m activeModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	visible := m.filteredIndices()
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(visible)-1 {
+			m.cursor++
+		}
+	case "pgup":
+		m.cursor = max(0, m.cursor-m.listHeight())
+	case "pgdown":
+		m.cursor = max(0, min(len(visible)-1, m.cursor+m.listHeight()))
+	case "n", "N":
+		if len(m.experiments) == 0 {
+			return m, nil
+		}
+		i := m.expIndex()
+		if msg.String() == "n" {
+			i = (i + 1) % len(m.experiments)
+		} else {
+			i = (i - 1 + len(m.experiments)) % len(m.experiments)
+		}
+		m.experiment, m.follow, m.cursor, m.err = m.experiments[i].ID, i == 0, 0, ""
+		return m, m.reload()
+	case "r":
+		m.err, m.notice = "", ""
+		return m, m.reload()
+	case "s":
+		m.filter.status = cycle(activeStatusFilters, m.filter.status)
+		m.cursor = 0
+	case "e":
+		if m.filter.status == "failing" {
+			m.filter.status = ""
+		} else {
+			m.filter.status = "failing"
+		}
+		m.cursor = 0
+	case "p":
+		m.filter.pkg = cycle(append([]string{""}, m.packages()...), m.filter.pkg)
+		m.cursor = 0
+	case "l":
+		m.filter.level = cycle(append([]string{""}, m.levels()...), m.filter.level)
+		m.cursor = 0
+	case "x":
+		m.filter, m.cursor = activeFilter{}, 0
+	case "o", "enter":
+		tc, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		c, err := editorCommand(os.Getenv("EDITOR"), tc)
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		return m, tea.ExecProcess(c, func(err error) tea.Msg {
+			if err != nil {
+				return actionMsg{err: fmt.Errorf("editor: %w", err)}
+			}
+			return actionMsg{}
+		})
+	case "R":
+		tc, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		c, err := rerunCommand(m.conversation, tc)
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		return m, tea.ExecProcess(c, func(err error) tea.Msg {
+			notice := "re-run finished: tests passed"
+			var exit *exec.ExitError
+			switch {
+			case errors.As(err, &exit):
+				notice = fmt.Sprintf("re-run finished: exit code %d", exit.ExitCode())
+			case err != nil:
+				return actionMsg{err: fmt.Errorf("re-run: %w", err)}
+			}
+			return actionMsg{notice: notice, follow: true, reload: true}
+		})
+	case "c":
+		tc, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if m.blackboard == "" {
+			m.err = "start with --blackboard <uuid> to create stickies"
+			return m, nil
+		}
+		return m, createStickieCmd(m.db, m.blackboard, tc)
 	}
 	return m, nil
 }
 
+// applyRefresh installs freshly loaded data, keeping the cursor on the same
+// testcase when it is still visible.
+func (m *activeModel) applyRefresh(msg refreshMsg) {
+	selectedID := ""
+	if tc, ok := m.selected(); ok {
+		selectedID = tc.ID
+	}
+	if msg.experiment != m.experiment {
+		selectedID = ""
+		m.cursor = 0
+	}
+	m.experiments, m.experiment, m.testcases = msg.experiments, msg.experiment, msg.testcases
+	m.updated = time.Now()
+	m.err = ""
+	visible := m.filteredIndices()
+	if selectedID != "" {
+		for i, idx := range visible {
+			if m.testcases[idx].ID == selectedID {
+				m.cursor = i
+				return
+			}
+		}
+	}
+	if m.cursor >= len(visible) {
+		m.cursor = max(0, len(visible)-1)
+	}
+}
+
 func (m activeModel) View() string {
+	if m.quitting {
+		return ""
+	}
 	var b strings.Builder
-	// Header
-	b.WriteString(styleHeader.Render("Testcases Viewer") + "\n")
-	b.WriteString(styleLabel.Render("Conversation: ") + styleValue.Render(m.conversation) + "\n")
-	b.WriteString(styleLabel.Render("Role: ") + styleValue.Render(m.role) + "\n")
-	// Experiment header
-	expID := ""
-	expCreated := ""
-	if len(m.experiments) > 0 && m.expIdx >= 0 && m.expIdx < len(m.experiments) {
-		expID = m.experiments[m.expIdx].ID
-		if c := strings.TrimSpace(m.experiments[m.expIdx].Created); c != "" {
-			expCreated = c
-		}
-	}
-	newest := ""
-	if m.expIdx == 0 {
-		newest = " (newest ✨)"
-	}
-	if expCreated != "" {
-		b.WriteString(styleLabel.Render("Experiment: "))
-		b.WriteString(styleValue.Render(fmt.Sprintf("[%d/%d] %s (created %s)", m.expIdx+1, len(m.experiments), expID, expCreated)))
-		if newest != "" {
-			b.WriteString(styleNewest.Render(" " + newest))
-		}
-		b.WriteString("\n")
-	} else {
-		b.WriteString(styleLabel.Render("Experiment: "))
-		b.WriteString(styleValue.Render(fmt.Sprintf("[%d/%d] %s", m.expIdx+1, len(m.experiments), expID)))
-		if newest != "" {
-			b.WriteString(styleNewest.Render(" " + newest))
-		}
-		b.WriteString("\n")
-	}
-	// Filter status line
-	filterLbl := "all"
-	if m.errorsOnly {
-		filterLbl = "errors-only"
-	}
-	b.WriteString(styleLabel.Render("Filter: ") + styleValue.Render(filterLbl) + "\n")
-
-	// Divider
-	b.WriteString(styleDivider.Render(strings.Repeat("─", 60)) + "\n")
-
-	filtered := m.filteredIndices()
-	if len(m.testcases) == 0 || len(filtered) == 0 {
-		if m.errorsOnly {
-			b.WriteString("No error testcases. Press 'e' to show all; 'q' to quit.\n")
-		} else {
-			b.WriteString("No testcases found. Press q to quit.\n")
+	b.WriteString(styleHeader.Render("Testcases Explorer") + "\n")
+	b.WriteString(styleLabel.Render("Conversation: ") + styleValue.Render(m.conversation))
+	b.WriteString(styleLabel.Render("  Role: ") + styleValue.Render(m.role) + "\n")
+	i := m.expIndex()
+	b.WriteString(styleLabel.Render("Experiment: "))
+	exp := fmt.Sprintf("[%d/%d] %s", i+1, len(m.experiments), m.experiment)
+	if i >= 0 && i < len(m.experiments) && strings.TrimSpace(m.experiments[i].Created) != "" {
+		exp += " (created " + m.experiments[i].Created + ")"
+	}
+	b.WriteString(styleValue.Render(exp))
+	if i == 0 {
+		b.WriteString(styleNewest.Render(" (newest ✨)"))
+	}
+	if m.follow {
+		b.WriteString(styleNewest.Render(" following"))
+	}
+	b.WriteString("\n")
+	visible := m.filteredIndices()
+	b.WriteString(styleLabel.Render("Filter: ") + styleValue.Render(m.filter.String()))
+	b.WriteString(styleLabel.Render("  Shown: ") + styleValue.Render(fmt.Sprintf("%d/%d", len(visible), len(m.testcases))))
+	b.WriteString(styleLabel.Render("  Counts: ") + styleValue.Render(statusCounts(m.testcases)))
+	if !m.updated.IsZero() {
+		b.WriteString(styleLabel.Render("  Updated: ") + styleValue.Render(m.updated.Format("15:04:05")))
+	}
+	b.WriteString("\n")
+	b.WriteString(styleDivider.Render(strings.Repeat("─", max(20, m.width-1))) + "\n")
+
+	listWidth := m.width * 45 / 100
+	if listWidth < 30 {
+		listWidth = 30
+	}
+	paneWidth := max(20, m.width-listWidth-3)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).Render(m.viewList(visible, listWidth)),
+		stylePane.Width(paneWidth).Render(m.viewDetail(paneWidth)),
+	))
+	b.WriteString("\n")
+	if m.err != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("Error: ") + m.err + "\n")
+	} else if m.notice != "" {
+		b.WriteString(styleNotice.Render(m.notice) + "\n")
+	}
+	b.WriteString(styleHelp.Render("Keys: ↑↓ move, n/N experiment, s status, e failing, p package, l level, x clear, o open, R re-run, c stickie, r refresh, q quit") + "\n")
+	return b.String()
+}
+
+func (m activeModel) viewList(visible []int, width int) string {
+	if len(visible) == 0 {
+		if len(m.testcases) == 0 {
+			return "No testcases yet; waiting for new ones."
 		}
-		return b.String()
+		return "No testcase matches the filter. Press x to clear."
+	}
+	h := m.listHeight()
+	start := 0
+	if m.cursor >= h {
+		start = m.cursor - h + 1
 	}
-	b.WriteString(styleHelp.Render("Keys: ↑/k, ↓/j, enter, n=next exp, r=refresh, e=errors-only, q") + "\n")
-	for i, idx := range filtered {
-		tc := m.testcases[idx]
+	end := min(len(visible), start+h)
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		tc := m.testcases[visible[i]]
 		cursor := "  "
 		if i == m.cursor {
 			cursor = styleCursor.Render("> ")
 		}
-		statusIcon := formatStatus(tc.Status)
-		indent := indentForLevel(levelFromTestcase(tc))
-		fmt.Fprintf(&b, "%s%s%s %s\n", cursor, indent, statusIcon, styleValue.Render(tc.Title))
+		title := truncate(tc.Title, width-6-len(indentForLevel(levelFromTestcase(tc))))
+		fmt.Fprintf(&b, "%s%s%s %s\n", cursor, indentForLevel(levelFromTestcase(tc)), formatStatus(tc.Status), styleValue.Render(title))
 	}
-	if m.err != "" {
-		b.WriteString("\n")
-		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("Error: "))
-		b.WriteString(m.err)
-		b.WriteString("\n")
-	}
-	// Details section for selected testcase
-	if m.cursor >= 0 && m.cursor < len(filtered) {
-		tc := m.testcases[filtered[m.cursor]]
-		// Divider before details
-		b.WriteString(styleDivider.Render(strings.Repeat("─", 60)) + "\n")
-		b.WriteString(styleHeader.Render("Details") + "\n")
-		b.WriteString(styleLabel.Render("ID: ") + styleValue.Render(tc.ID) + "\n")
-		b.WriteString(styleLabel.Render("Title: ") + styleValue.Render(tc.Title) + "\n")
-		b.WriteString(styleLabel.Render("Status: ") + styleValue.Render(tc.Status) + "\n")
-		if tc.Name.Valid {
-			b.WriteString(styleLabel.Render("Name: ") + styleValue.Render(tc.Name.String) + "\n")
-		}
-		if tc.Package.Valid {
-			b.WriteString(styleLabel.Render("Package: ") + styleValue.Render(tc.Package.String) + "\n")
-		}
-		if tc.Classname.Valid {
-			b.WriteString(styleLabel.Render("Classname: ") + styleValue.Render(tc.Classname.String) + "\n")
-		}
-		if tc.File.Valid {
-			if tc.Line.Valid {
-				b.WriteString(styleLabel.Render("File: ") + styleValue.Render(fmt.Sprintf("%s:%d", tc.File.String, tc.Line.Int64)) + "\n")
-			} else {
-				b.WriteString(styleLabel.Render("File: ") + styleValue.Render(tc.File.String) + "\n")
-			}
-		}
-		if tc.ExecutionTime.Valid {
-			b.WriteString(styleLabel.Render("Execution: ") + styleValue.Render(fmt.Sprintf("%.3fs", tc.ExecutionTime.Float64)) + "\n")
+	if end < len(visible) {
+		b.WriteString(styleHelp.Render(fmt.Sprintf("  … %d more", len(visible)-end)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (m activeModel) viewDetail(width int) string {
+	tc, ok := m.selected()
+	if !ok {
+		return styleHelp.Render("No selection")
+	}
+	var b strings.Builder
+	row := func(label, value string) {
+		b.WriteString(styleLabel.Render(label+": ") + styleValue.Render(value) + "\n")
+	}
+	b.WriteString(styleHeader.Render("Details") + "\n")
+	row("Title", tc.Title)
+	row("Status", tc.Status)
+	if tc.Package.Valid {
+		row("Package", tc.Package.String)
+	}
+	if tc.Classname.Valid {
+		row("Classname", tc.Classname.String)
+	}
+	if tc.Name.Valid && tc.Name.String != tc.Title {
+		row("Name", tc.Name.String)
+	}
+	if loc := testcaseLocation(tc); loc != "" {
+		row("File", loc)
+	}
+	if tc.ExecutionTime.Valid {
+		row("Execution", fmt.Sprintf("%.3fs", tc.ExecutionTime.Float64))
+	}
+	if tc.Level.Valid {
+		row("Level", tc.Level.String)
+	}
+	if len(tc.Tags) > 0 {
+		keys := make([]string, 0, len(tc.Tags))
+		for k := range tc.Tags {
+			keys = append(keys, k)
 		}
-		if tc.ErrorMessage.Valid {
-			b.WriteString(styleLabel.Render("Error: ") + styleValue.Render(tc.ErrorMessage.String) + "\n")
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, tc.Tags[k]))
 		}
-		if tc.Created.Valid {
-			b.WriteString(styleLabel.Render("Created: ") + styleValue.Render(tc.Created.Time.Format(time.RFC3339)) + "\n")
+		row("Tags", strings.Join(parts, " "))
+	}
+	if tc.Created.Valid {
+		row("Created", tc.Created.Time.Format(time.RFC3339))
+	}
+	row("ID", tc.ID)
+	if tc.ErrorMessage.Valid && strings.TrimSpace(tc.ErrorMessage.String) != "" {
+		b.WriteString(styleDivider.Render(strings.Repeat("─", max(10, width-2))) + "\n")
+		b.WriteString(styleLabel.Render("Error") + "\n")
+		lines := strings.Split(strings.TrimRight(tc.ErrorMessage.String, "\n"), "\n")
+		if room := m.listHeight() - 12; room > 3 && len(lines) > room {
+			lines = append(lines[:room], fmt.Sprintf("… %d more lines", len(lines)-room))
 		}
+		b.WriteString(strings.Join(lines, "\n"))
 	}
-	return b.String()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// listHeight is the number of rows available to the list and the pane.
+func (m activeModel) listHeight() int {
+	return max(5, m.height-8)
 }
 
 // Helpers
 func (m activeModel) filteredIndices() []int {
-	if !m.errorsOnly {
-		out := make([]int, len(m.testcases))
-		for i := range m.testcases {
-			out[i] = i
-		}
-		return out
-	}
 	out := make([]int, 0, len(m.testcases))
 	for i, tc := range m.testcases {
-		if isErrorStatus(tc.Status) {
+		if m.filter.matches(tc) {
 			out = append(out, i)
 		}
 	}
 	return out
 }
 
+func (m activeModel) selected() (pgdao.Testcase, bool) {
+	visible := m.filteredIndices()
+	if m.cursor < 0 || m.cursor >= len(visible) {
+		return pgdao.Testcase{}, false
+	}
+	return m.testcases[visible[m.cursor]], true
+}
+
+func (m activeModel) expIndex() int {
+	for i, e := range m.experiments {
+		if e.ID == m.experiment {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m activeModel) packages() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, tc := range m.testcases {
+		if p := packageOf(tc); !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (m activeModel) levels() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, tc := range m.testcases {
+		if l := normalizedLevel(tc); !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// cycle returns the value after cur in values, wrapping around.
+func cycle(values []string, cur string) string {
+	for i, v := range values {
+		if v == cur {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
 func isErrorStatus(status string) bool {
-	return strings.ToLower(strings.TrimSpace(status)) == "ko"
+	s := strings.ToLower(strings.TrimSpace(status))
+	return s == "ko" || s == "error"
+}
+
+func packageOf(tc pgdao.Testcase) string {
+	if tc.Package.Valid && tc.Package.String != "" {
+		return tc.Package.String
+	}
+	return "(no package)"
+}
+
+// normalizedLevel is the level used by the filter; unset counts as h1.
+func normalizedLevel(tc pgdao.Testcase) string {
+	if l := strings.ToLower(strings.TrimSpace(levelFromTestcase(tc))); l != "" {
+		return l
+	}
+	return "h1"
+}
+
+func testcaseLocation(tc pgdao.Testcase) string {
+	if !tc.File.Valid || tc.File.String == "" {
+		return ""
+	}
+	if tc.Line.Valid && tc.Line.Int64 > 0 {
+		return fmt.Sprintf("%s:%d", tc.File.String, tc.Line.Int64)
+	}
+	return tc.File.String
+}
+
+func statusCounts(tcs []pgdao.Testcase) string {
+	counts := map[string]int{}
+	for _, tc := range tcs {
+		counts[strings.ToUpper(strings.TrimSpace(tc.Status))]++
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 1 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
 }
 
 // Messages
 type refreshMsg struct {
-	testcases  []pgdao.Testcase
-	experiment string
+	experiments []pgdao.Experiment
+	experiment  string
+	testcases   []pgdao.Testcase
+}
+
+type tickMsg time.Time
+
+// actionMsg reports the outcome of an action; follow and reload ask for the
+// newest experiment to be shown.
+type actionMsg struct {
+	notice string
+	err    error
+	follow bool
+	reload bool
 }
 
 type errMsg struct{ err error }
 
-// Commands
-func refreshForExpCmd(role, experimentID string) tea.Cmd {
+// loadActive reads the conversation's recent experiments and the testcases of
+// experimentID, or of the newest experiment when it is empty.
+func loadActive(ctx context.Context, db *pgxpool.Pool, conversation, experimentID string) tea.Msg {
+	exps, err := pgdao.ListExperiments(ctx, db, conversation, 20, 0)
+	if err != nil {
+		return errMsg{err}
+	}
+	if experimentID == "" {
+		if len(exps) == 0 {
+			return refreshMsg{}
+		}
+		experimentID = exps[0].ID
+	}
+	tcs, err := pgdao.ListExperimentTestcases(ctx, db, experimentID)
+	if err != nil {
+		return errMsg{err}
+	}
+	return refreshMsg{experiments: exps, experiment: experimentID, testcases: tcs}
+}
+
+// editorCommand opens the testcase's file at its line. Editors are told the
+// line the way they expect it: --goto file:line for VS Code and friends,
+// file:line for Sublime and Zed, +line file for the rest.
+func editorCommand(editor string, tc pgdao.Testcase) (*exec.Cmd, error) {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return nil, errors.New("$EDITOR is not set")
+	}
+	if !tc.File.Valid || tc.File.String == "" {
+		return nil, errors.New("testcase has no file")
+	}
+	cwd, _ := os.Getwd()
+	file, err := resolveTestFile(cwd, tc.File.String, tc.Package.String)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(fields[0], append(fields[1:], editorArgs(fields[0], file, int(tc.Line.Int64))...)...), nil
+}
+
+func editorArgs(editor, file string, line int) []string {
+	if line <= 0 {
+		return []string{file}
+	}
+	switch filepath.Base(editor) {
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", fmt.Sprintf("%s:%d", file, line)}
+	case "subl", "zed":
+		return []string{fmt.Sprintf("%s:%d", file, line)}
+	}
+	return []string{"+" + strconv.Itoa(line), file}
+}
+
+// resolveTestFile finds a reported file on disk. Runners often report a path
+// relative to the test package (go test) rather than to the working directory,
+// so the package is tried as a directory too, after stripping the module path
+// declared in go.mod.
+func resolveTestFile(dir, file, pkg string) (string, error) {
+	candidates := []string{file}
+	if !filepath.IsAbs(file) {
+		candidates = []string{filepath.Join(dir, file)}
+		if pkg != "" {
+			rel := pkg
+			if mod := goModulePath(dir); mod != "" {
+				switch {
+				case pkg == mod:
+					rel = ""
+				case strings.HasPrefix(pkg, mod+"/"):
+					rel = strings.TrimPrefix(pkg, mod+"/")
+				}
+			}
+			candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(rel), filepath.Base(file)))
+		}
+	}
+	for _, c := range candidates {
+		if st, err := os.Stat(c); err == nil && !st.IsDir() {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("file %s not found from %s", file, dir)
+}
+
+func goModulePath(dir string) string {
+	b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, l := range strings.Split(string(b), "\n") {
+		if f := strings.Fields(l); len(f) == 2 && f[0] == "module" {
+			return strings.Trim(f[1], `"`)
+		}
+	}
+	return ""
+}
+
+// rerunCommand re-runs the task that recorded the testcase as a new
+// experiment of the conversation. Only runs of `rbc test unit` are known.
+func rerunCommand(conversation string, tc pgdao.Testcase) (*exec.Cmd, error) {
+	args, err := rerunArgs(conversation, tc)
+	if err != nil {
+		return nil, err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(exe, args...), nil
+}
+
+func rerunArgs(conversation string, tc pgdao.Testcase) ([]string, error) {
+	variant, _ := tc.Tags["variant"].(string)
+	runner, _ := tc.Tags["runner"].(string)
+	if variant == "" && runner != "test unit" {
+		return nil, errors.New("testcase was not recorded by a task run; nothing to re-run")
+	}
+	args := []string{"test", "unit", "--conversation", conversation}
+	if variant != "" {
+		args = append(args, "--variant", variant)
+	}
+	if tc.RoleName != "" {
+		args = append(args, "--role", tc.RoleName)
+	}
+	return args, nil
+}
+
+// stickieFromTestcase drafts a stickie describing a failing testcase: the
+// note locates it, the code holds the error and the structured payload keeps
+// the testcase's identity.
+func stickieFromTestcase(blackboardID string, tc pgdao.Testcase) (*pgdao.Stickie, error) {
+	title := tc.Title
+	if tc.Package.Valid && tc.Package.String != "" {
+		title = tc.Package.String + " › " + title
+	}
+	note := fmt.Sprintf("%s %s", strings.ToUpper(tc.Status), title)
+	if loc := testcaseLocation(tc); loc != "" {
+		note += "\n" + loc
+	}
+	payload := map[string]any{"testcase_id": tc.ID, "title": tc.Title, "status": tc.Status}
+	for k, v := range map[string]string{"package": tc.Package.String, "classname": tc.Classname.String, "name": tc.Name.String, "file": tc.File.String, "experiment_id": tc.ExperimentID.String} {
+		if v != "" {
+			payload[k] = v
+		}
+	}
+	if tc.Line.Valid {
+		payload["line"] = tc.Line.Int64
+	}
+	structured, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	st := &pgdao.Stickie{BlackboardID: blackboardID, Labels: []string{"test-failure"}, Structured: structured}
+	st.Note.String, st.Note.Valid = note, true
+	if msg := strings.TrimSpace(tc.ErrorMessage.String); msg != "" {
+		st.Code.String, st.Code.Valid = msg, true
+	}
+	return st, nil
+}
+
+func createStickieCmd(db *pgxpool.Pool, blackboardID string, tc pgdao.Testcase) tea.Cmd {
 	return func() tea.Msg {
-		cfg, err := cfgpkg.Load()
+		st, err := stickieFromTestcase(blackboardID, tc)
 		if err != nil {
-			return errMsg{err}
+			return actionMsg{err: err}
 		}
+		st.Validate = stickieschema.Validate
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		db, err := pgdao.OpenApp(ctx, cfg)
-		if err != nil {
-			return errMsg{err}
-		}
-		defer db.Close()
-		// Fetch testcases for the provided experiment
-		tcs, err := pgdao.ListTestcases(ctx, db, role, experimentID, "", 100, 0)
-		if err != nil {
-			return errMsg{err}
+		if err := pgdao.UpsertStickie(ctx, db, st); err != nil {
+			return actionMsg{err: err}
 		}
-		return refreshMsg{testcases: tcs, experiment: experimentID}
+		return actionMsg{notice: "stickie created id=" + st.ID}
 	}
 }
 
@@ -350,6 +854,10 @@ func formatStatus(status string) string {
 		return green.Render("✔")
 	case "ko":
 		return red.Render("✗")
+	case "error":
+		return red.Render("‼")
+	case "skip":
+		return grey.Render("↷")
 	case "todo":
 		return yellow.Render("⏳")
 	default:
@@ -360,6 +868,12 @@ func formatStatus(status string) string {
 func init() {
 	TestcaseCmd.AddCommand(activeCmd)
 	activeCmd.Flags().StringVar(&flagTCActiveConversation, "conversation", "", "Conversation ID (required)")
+	activeCmd.Flags().StringVar(&flagTCActiveExperiment, "experiment", "", "Start on this experiment instead of following the newest")
+	activeCmd.Flags().StringVar(&flagTCActiveStatus, "status", "", "Initial status filter: failing, OK, KO, ERROR, SKIP or TODO")
+	activeCmd.Flags().StringVar(&flagTCActivePackage, "package", "", "Initial package filter")
+	activeCmd.Flags().StringVar(&flagTCActiveLevel, "level", "", "Initial level filter (h1..h6)")
+	activeCmd.Flags().StringVar(&flagTCActiveBlackboard, "blackboard", "", "Blackboard UUID receiving stickies created with 'c'")
+	activeCmd.Flags().DurationVar(&flagTCActiveRefresh, "refresh", 3*time.Second, "Live refresh interval; 0 disables")
 }
 
 // levelFromTestcase returns the raw level string (e.g., "h1") or empty if unset.
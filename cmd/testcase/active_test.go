@@ -0,0 +1,127 @@
+package testcase
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func ns(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+
+func TestActiveFilter(t *testing.T) {
+	m := activeModel{testcases: []pgdao.Testcase{
+		{ID: "1", Title: "a", Status: "OK", Package: ns("app/store")},
+		{ID: "2", Title: "b", Status: "KO", Package: ns("app/store"), Level: ns("h2")},
+		{ID: "3", Title: "c", Status: "ERROR", Package: ns("app/api")},
+		{ID: "4", Title: "d", Status: "SKIP"},
+	}}
+	ids := func() []string {
+		var out []string
+		for _, i := range m.filteredIndices() {
+			out = append(out, m.testcases[i].ID)
+		}
+		return out
+	}
+	m.filter = activeFilter{status: "failing"}
+	if got := ids(); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Fatalf("failing = %v", got)
+	}
+	m.filter = activeFilter{pkg: "app/store", level: "h1"}
+	if got := ids(); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Fatalf("package+level = %v", got)
+	}
+	m.filter = activeFilter{pkg: "(no package)"}
+	if got := ids(); !reflect.DeepEqual(got, []string{"4"}) {
+		t.Fatalf("no package = %v", got)
+	}
+	if got := m.packages(); !reflect.DeepEqual(got, []string{"(no package)", "app/api", "app/store"}) {
+		t.Fatalf("packages = %v", got)
+	}
+	if got := cycle(activeStatusFilters, "TODO"); got != "" {
+		t.Fatalf("cycle wraps to all, got %q", got)
+	}
+	if normalizeStatusFilter("ko") != "KO" || normalizeStatusFilter("bogus") != "?" {
+		t.Fatal("normalizeStatusFilter")
+	}
+}
+
+func TestApplyRefreshKeepsSelection(t *testing.T) {
+	m := activeModel{experiment: "e1", testcases: []pgdao.Testcase{{ID: "1", Status: "OK"}, {ID: "2", Status: "KO"}}, cursor: 1}
+	m.applyRefresh(refreshMsg{experiment: "e1", testcases: []pgdao.Testcase{{ID: "0", Status: "KO"}, {ID: "1", Status: "OK"}, {ID: "2", Status: "KO"}}})
+	if tc, _ := m.selected(); tc.ID != "2" {
+		t.Fatalf("selected %q after refresh", tc.ID)
+	}
+	m.applyRefresh(refreshMsg{experiment: "e2", testcases: []pgdao.Testcase{{ID: "9"}}})
+	if tc, _ := m.selected(); tc.ID != "9" {
+		t.Fatalf("selected %q after switching experiment", tc.ID)
+	}
+	if v := m.View(); v == "" {
+		t.Fatal("empty view")
+	}
+}
+
+func TestEditorArgs(t *testing.T) {
+	cases := map[string][]string{
+		"vim":                 {"+42", "a.go"},
+		"/usr/local/bin/code": {"--goto", "a.go:42"},
+		"subl":                {"a.go:42"},
+	}
+	for ed, want := range cases {
+		if got := editorArgs(ed, "a.go", 42); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: %v, want %v", ed, got, want)
+		}
+	}
+	if got := editorArgs("vim", "a.go", 0); !reflect.DeepEqual(got, []string{"a.go"}) {
+		t.Errorf("no line: %v", got)
+	}
+}
+
+func TestResolveTestFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "store"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "store", "store_test.go")
+	if err := os.WriteFile(want, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"example.com/app/store", "store"} {
+		got, err := resolveTestFile(dir, "store_test.go", pkg)
+		if err != nil || got != want {
+			t.Fatalf("pkg %s: %q %v", pkg, got, err)
+		}
+	}
+	if _, err := resolveTestFile(dir, "missing_test.go", "store"); err == nil {
+		t.Fatal("missing file resolved")
+	}
+}
+
+func TestRerunArgsAndStickie(t *testing.T) {
+	tc := pgdao.Testcase{ID: "tc-1", Title: "TestPut", Status: "KO", RoleName: "user", Package: ns("app/store"), File: ns("store_test.go"), Line: sql.NullInt64{Int64: 42, Valid: true}, ErrorMessage: ns("want 2, got 3"), Tags: map[string]any{"variant": "unit/go", "runner": "test unit"}}
+	args, err := rerunArgs("conv-1", tc)
+	if err != nil || !reflect.DeepEqual(args, []string{"test", "unit", "--conversation", "conv-1", "--variant", "unit/go", "--role", "user"}) {
+		t.Fatalf("rerun args = %v %v", args, err)
+	}
+	if _, err := rerunArgs("conv-1", pgdao.Testcase{Title: "manual"}); err == nil {
+		t.Fatal("manual testcase should not be re-runnable")
+	}
+	st, err := stickieFromTestcase("bb-1", tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.BlackboardID != "bb-1" || st.Note.String != "KO app/store › TestPut\nstore_test.go:42" || st.Code.String != "want 2, got 3" {
+		t.Fatalf("stickie = %+v", st)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(st.Structured, &payload); err != nil || payload["testcase_id"] != "tc-1" || payload["line"] != float64(42) {
+		t.Fatalf("payload = %v %v", payload, err)
+	}
+}
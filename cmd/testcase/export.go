@@ -0,0 +1,87 @@
+package testcase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTCExportExperiment string
+	flagTCExportFormat     string
+	flagTCExportOut        string
+)
+
+var exportWriters = map[string]func(io.Writer, string, []testreport.Suite) error{
+	"junit":    testreport.WriteJUnit,
+	"html":     testreport.WriteHTML,
+	"markdown": testreport.WriteMarkdown,
+	"md":       testreport.WriteMarkdown,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the testcases of an experiment as JUnit XML, HTML or Markdown",
+	Long: `Export every testcase of an experiment, grouped into one suite per package.
+Failures carry their error_message and file:line; timing and tags are kept
+(as testcase properties in JUnit XML). Writes to stdout unless --out is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagTCExportExperiment) == "" {
+			return errors.New("--experiment is required")
+		}
+		write, ok := exportWriters[strings.ToLower(strings.TrimSpace(flagTCExportFormat))]
+		if !ok {
+			return fmt.Errorf("--format must be junit, html or markdown")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		rows, err := pgdao.ListExperimentTestcases(ctx, db, flagTCExportExperiment)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return fmt.Errorf("no testcases for experiment %s", flagTCExportExperiment)
+		}
+		suites := testreport.Suites(rows)
+		var w io.Writer = os.Stdout
+		if flagTCExportOut != "" && flagTCExportOut != "-" {
+			f, err := os.Create(flagTCExportOut)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := write(w, flagTCExportExperiment, suites); err != nil {
+			return err
+		}
+		tot := testreport.ExportTotals(suites)
+		fmt.Fprintf(os.Stderr, "exported testcases: suites=%d tests=%d failed=%d errored=%d skipped=%d\n", len(suites), tot.Tests, tot.Failures, tot.Errors, tot.Skipped)
+		return nil
+	},
+}
+
+func init() {
+	TestcaseCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&flagTCExportExperiment, "experiment", "", "Experiment UUID (required)")
+	exportCmd.Flags().StringVar(&flagTCExportFormat, "format", "junit", "Output format: junit, html or markdown")
+	exportCmd.Flags().StringVar(&flagTCExportOut, "out", "", "Write to this file instead of stdout")
+}
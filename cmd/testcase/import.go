@@ -0,0 +1,113 @@
+package testcase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTCImportFormat     string
+	flagTCImportExperiment string
+	flagTCImportRole       string
+	flagTCImportPackage    string
+	flagTCImportTags       []string
+	flagTCImportDryRun     bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Bulk import testcases from a JUnit XML, TAP or go test -json report",
+	Long: `Import every test case of a report in one transaction.
+Results map to statuses: passed=OK, failed=KO, errored=ERROR, skipped=SKIP
+(TAP TODO counts as skipped). Use - to read the report from stdin, e.g.
+  go test -json ./... | rbc testcase import --format gotest-json --experiment <uuid> -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parse, err := testreport.Lookup(flagTCImportFormat)
+		if err != nil {
+			return err
+		}
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		cases, err := parse(in)
+		if err != nil {
+			return err
+		}
+		if len(cases) == 0 {
+			return errors.New("no test cases found in report")
+		}
+		tags := parseTags(flagTCImportTags)
+		if tags == nil {
+			tags = map[string]any{}
+		}
+		tags["source"] = strings.ToLower(flagTCImportFormat)
+		for i := range cases {
+			if cases[i].Package == "" {
+				cases[i].Package = flagTCImportPackage
+			}
+		}
+		rows := testreport.Rows(cases, flagTCImportRole, flagTCImportExperiment, tags)
+		sum := testreport.Summarize(cases)
+		if !flagTCImportDryRun {
+			cfg, err := cfgpkg.Load()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+			db, err := pgdao.OpenApp(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			if err := pgdao.InsertTestcases(ctx, db, rows); err != nil {
+				return err
+			}
+		}
+		prefix := ""
+		if flagTCImportDryRun {
+			prefix = "[dry-run] "
+		}
+		fmt.Fprintf(os.Stderr, "%simported testcases %s\n", prefix, sum)
+		for _, c := range cases {
+			if c.Result == testreport.Failed || c.Result == testreport.Errored {
+				fmt.Fprintf(os.Stderr, "  %s %s %s\n", c.Result.Status(), c.Package, c.Title())
+			}
+		}
+		out := map[string]any{"summary": sum, "format": strings.ToLower(flagTCImportFormat), "dry_run": flagTCImportDryRun}
+		if flagTCImportExperiment != "" {
+			out["experiment_id"] = flagTCImportExperiment
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	},
+}
+
+func init() {
+	TestcaseCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&flagTCImportFormat, "format", "", "Report format: "+strings.Join(testreport.Formats(), ", ")+" (required)")
+	importCmd.Flags().StringVar(&flagTCImportExperiment, "experiment", "", "Experiment UUID")
+	importCmd.Flags().StringVar(&flagTCImportRole, "role", "user", "Role name")
+	importCmd.Flags().StringVar(&flagTCImportPackage, "package", "", "Package for cases whose report has none (e.g. TAP)")
+	importCmd.Flags().StringSliceVar(&flagTCImportTags, "tags", nil, "Tags added to every case as key=value pairs")
+	importCmd.Flags().BoolVar(&flagTCImportDryRun, "dry-run", false, "Parse and summarize without writing")
+}
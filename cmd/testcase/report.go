@@ -0,0 +1,168 @@
+package testcase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/testreport"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagTCReportConversation string
+	flagTCReportRole         string
+	flagTCReportSince        string
+	flagTCReportFlips        int
+	flagTCReportSlower       float64
+	flagTCReportMinTime      float64
+	flagTCReportOutput       string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report flaky tests, new failures and slowdowns across experiments",
+	Long: `Compare the testcases of successive experiments. A test is identified by
+(package, classname, name). The report flags:
+  flaky         tests whose status flipped between pass and fail --flips times or more
+  new failure   tests failing in the latest experiment but not in the previous one
+  fixed         tests passing in the latest experiment after failing in the previous one
+  slower        tests whose latest time exceeds the median of earlier runs by --slower percent`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(flagTCReportConversation) == "" && strings.TrimSpace(flagTCReportRole) == "" {
+			return errors.New("--conversation or --role is required")
+		}
+		since, err := parseSince(flagTCReportSince, time.Now())
+		if err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		output := strings.ToLower(strings.TrimSpace(flagTCReportOutput))
+		if output != "table" && output != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		cfg, err := cfgpkg.Load()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		db, err := pgdao.OpenApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		runs, err := pgdao.ListTestcaseRuns(ctx, db, flagTCReportConversation, flagTCReportRole, since)
+		if err != nil {
+			return err
+		}
+		rep := testreport.AnalyzeTrends(runs, testreport.TrendOptions{FlakyFlips: flagTCReportFlips, SlowerPct: flagTCReportSlower, MinTime: flagTCReportMinTime})
+		fmt.Fprintf(os.Stderr, "testcase report: %s\n", rep.Summary())
+		if output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rep)
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"KIND", "TEST", "LATEST", "PREVIOUS", "DETAIL"})
+		for _, row := range trendRows(rep) {
+			table.Append(row)
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// trendRows flattens a report into table rows, worst findings first.
+func trendRows(rep testreport.TrendReport) [][]string {
+	var out [][]string
+	status := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	for _, t := range rep.NewFailures {
+		detail := firstLine(t.Message)
+		if t.File != "" {
+			loc := t.File
+			if t.Line > 0 {
+				loc += ":" + strconv.Itoa(t.Line)
+			}
+			detail = strings.TrimSpace(loc + " " + detail)
+		}
+		out = append(out, []string{"new failure", t.String(), status(t.Latest), status(t.Previous), detail})
+	}
+	for _, t := range rep.Flaky {
+		out = append(out, []string{"flaky", t.String(), status(t.Latest), status(t.Previous), fmt.Sprintf("flips=%d failures=%d/%d", t.Flips, t.Failures, t.Runs)})
+	}
+	for _, t := range rep.Slower {
+		out = append(out, []string{"slower", t.String(), status(t.Latest), status(t.Previous), fmt.Sprintf("%.3fs -> %.3fs (+%.0f%%)", t.BaselineTime, t.LatestTime, t.SlowdownPct)})
+	}
+	for _, t := range rep.Fixed {
+		out = append(out, []string{"fixed", t.String(), status(t.Latest), status(t.Previous), ""})
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// parseSince accepts an RFC3339 timestamp, a date (2006-01-02) or a relative
+// age as read by config.ParseAge (36h, 14d, 2w...); a zero age (0d, 0h) means
+// now. Empty means no lower bound.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := cfgpkg.ParseAge(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if zeroAge(s) {
+		return now, nil
+	}
+	return time.Time{}, fmt.Errorf("want RFC3339, YYYY-MM-DD or an age like 36h or 14d, got %q", s)
+}
+
+// zeroAge reports whether s is a zero age, which config.ParseAge rejects.
+func zeroAge(s string) bool {
+	s = strings.ToLower(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d == 0
+	}
+	switch rest := strings.TrimLeft(s, "0"); rest {
+	case "d", "w", "mo", "y":
+		return rest != s
+	}
+	return false
+}
+
+func init() {
+	TestcaseCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&flagTCReportConversation, "conversation", "", "Conversation UUID whose experiments are compared")
+	reportCmd.Flags().StringVar(&flagTCReportRole, "role", "", "Role name")
+	reportCmd.Flags().StringVar(&flagTCReportSince, "since", "", "Only experiments created since: RFC3339, YYYY-MM-DD, or an age like 14d")
+	reportCmd.Flags().IntVar(&flagTCReportFlips, "flips", 2, "Pass/fail flips that make a test flaky")
+	reportCmd.Flags().Float64Var(&flagTCReportSlower, "slower", 50, "Percent above the median time flagged as a regression")
+	reportCmd.Flags().Float64Var(&flagTCReportMinTime, "min-time", 0.05, "Seconds; tests with a faster median are not compared")
+	reportCmd.Flags().StringVar(&flagTCReportOutput, "output", "table", "Output format: table or json")
+}
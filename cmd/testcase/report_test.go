@@ -0,0 +1,32 @@
+package testcase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2025-05-01T08:00:00Z", time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"0d", now},
+		{"0h", now},
+	}
+	for _, c := range cases {
+		got, err := parseSince(c.in, now)
+		if err != nil || !got.Equal(c.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", c.in, got, err, c.want)
+		}
+	}
+	for _, in := range []string{"soon", "0x", "-3d"} {
+		if _, err := parseSince(in, now); err == nil {
+			t.Errorf("parseSince(%q) accepted", in)
+		}
+	}
+}
@@ -24,6 +24,7 @@ var (
 	flagToolTags     []string
 	flagToolSettings string
 	flagToolType     string
+	flagToolIfMatch  string
 )
 
 var setCmd = &cobra.Command{
@@ -39,6 +40,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagToolRole) == "" {
 			return errors.New("--role is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagToolIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -51,7 +56,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		t := &pgdao.Tool{Name: flagToolName, Title: flagToolTitle, RoleName: flagToolRole}
+		t := &pgdao.Tool{Name: flagToolName, Title: flagToolTitle, RoleName: flagToolRole, IfMatch: ifMatch}
 		if flagToolDesc != "" {
 			t.Description = sql.NullString{String: flagToolDesc, Valid: true}
 		}
@@ -99,6 +104,7 @@ func init() {
 	setCmd.Flags().StringSliceVar(&flagToolTags, "tags", nil, "Tags as key=value (repeat or comma-separated)")
 	setCmd.Flags().StringVar(&flagToolSettings, "settings", "", "Settings as JSON object (optional)")
 	setCmd.Flags().StringVar(&flagToolType, "type", "", "Tool type (optional)")
+	setCmd.Flags().StringVar(&flagToolIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
@@ -0,0 +1,79 @@
+package tool
+
+import (
+	"context"
+	"database/sql"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+)
+
+// toolYAML is the folder format for tools: <name>.tool.yaml.
+// The role comes from the id:<role> scope, so it is not stored in the file.
+type toolYAML struct {
+	Name        string                `yaml:"name"`
+	Title       string                `yaml:"title"`
+	Type        *string               `yaml:"type,omitempty"`
+	Description *string               `yaml:"description,omitempty"`
+	Notes       *syncfs.LiteralString `yaml:"notes,omitempty"`
+	Tags        map[string]any        `yaml:"tags,omitempty"`
+	Settings    map[string]any        `yaml:"settings,omitempty"`
+}
+
+func openToolAdapter(ctx context.Context, role string) (syncfs.Adapter, func(), error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	a := &syncfs.DocAdapter[toolYAML]{
+		Noun:  "tool",
+		KeyOf: func(y toolYAML) string { return y.Name },
+		List: func(ctx context.Context) ([]toolYAML, error) {
+			ts, err := syncfs.Paged(func(limit, offset int) ([]pgdao.Tool, error) {
+				return pgdao.ListTools(ctx, db, role, limit, offset)
+			})
+			if err != nil {
+				return nil, err
+			}
+			out := make([]toolYAML, 0, len(ts))
+			for _, t := range ts {
+				out = append(out, toolYAML{
+					Name: t.Name, Title: t.Title, Tags: t.Tags, Settings: t.Settings,
+					Type:        syncfs.OptString(t.ToolType.String, t.ToolType.Valid),
+					Description: syncfs.OptString(t.Description.String, t.Description.Valid),
+					Notes:       syncfs.OptLiteral(t.Notes.String, t.Notes.Valid),
+				})
+			}
+			return out, nil
+		},
+		Put: func(ctx context.Context, y toolYAML) error {
+			t := &pgdao.Tool{Name: y.Name, Title: y.Title, RoleName: role, Tags: y.Tags, Settings: y.Settings}
+			if v := syncfs.Deref(y.Type); v != "" {
+				t.ToolType = sql.NullString{String: v, Valid: true}
+			}
+			if d := syncfs.Deref(y.Description); d != "" {
+				t.Description = sql.NullString{String: d, Valid: true}
+			}
+			if n := syncfs.DerefLiteral(y.Notes); n != "" {
+				t.Notes = sql.NullString{String: n, Valid: true}
+			}
+			return pgdao.UpsertTool(ctx, db, t)
+		},
+		Remove: func(ctx context.Context, y toolYAML) error {
+			_, err := pgdao.DeleteTool(ctx, db, y.Name)
+			return err
+		},
+	}
+	return a, db.Close, nil
+}
+
+func init() {
+	for _, c := range syncfs.Commands(syncfs.CommandSpec{Noun: "tool", ScopeHelp: "role", Open: openToolAdapter}) {
+		ToolCmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"os"
+	"time"
+
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagDiffDetailed bool
+	flagDiffOutput   string
+)
+
+// diffCmd implements: rbc workflow diff id:NAME folder:relative/path (or reverse)
+var diffCmd = &cobra.Command{
+	Use:   "diff <left> <right>",
+	Short: "Diff a workflow (id) against a folder",
+	Long:  "Compare a remote workflow (id:NAME) with a local folder (folder:RELATIVE_PATH): one line per workflow, task and script, concise, detailed or JSON.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, err := syncfs.ParseDiffOutput(flagDiffOutput)
+		if err != nil {
+			return err
+		}
+		left, err := syncfs.ParseEndpoint(args[0])
+		if err != nil {
+			return err
+		}
+		right, err := syncfs.ParseEndpoint(args[1])
+		if err != nil {
+			return err
+		}
+		idEp, folderEp, _, err := syncfs.SplitPair(left, right)
+		if err != nil {
+			return err
+		}
+		if idEp.Value == "_" {
+			name, e := readWorkflowNameFromFolder(folderEp.Value)
+			if e != nil {
+				return e
+			}
+			idEp.Value = name
+		}
+		return runWorkflowDiff(idEp.Value, folderEp.Value, flagDiffDetailed, asJSON)
+	},
+}
+
+func init() {
+	WorkflowCmd.AddCommand(diffCmd)
+	syncfs.AddDiffFlags(diffCmd, &flagDiffDetailed, &flagDiffOutput)
+}
+
+// runWorkflowDiff prints one change per record:
+// '=' unchanged, '~' changed, '+' remote-only, '-' local-only.
+func runWorkflowDiff(name, relFolder string, detailed, asJSON bool) error {
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
+	}
+	local, err := loadLocalWorkflow(dir)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, db, err := openDB(45 * time.Second)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer db.Close()
+	remote, err := loadRemoteWorkflow(ctx, db, name, local.bodyFiles())
+	if err != nil {
+		return err
+	}
+	return syncfs.PrintChanges(os.Stdout, syncfs.Diff(remote.records(), local.records()), detailed, asJSON)
+}
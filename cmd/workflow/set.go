@@ -16,11 +16,12 @@ import (
 )
 
 var (
-	flagWFName  string
-	flagWFTitle string
-	flagWFDesc  string
-	flagWFNotes string
-	flagWFRole  string
+	flagWFName    string
+	flagWFTitle   string
+	flagWFDesc    string
+	flagWFNotes   string
+	flagWFRole    string
+	flagWFIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -33,6 +34,10 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagWFTitle) == "" {
 			return errors.New("--title is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagWFIfMatch)
+		if err != nil {
+			return err
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -45,8 +50,9 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 		w := &pgdao.Workflow{
-			Name:  flagWFName,
-			Title: flagWFTitle,
+			Name:    flagWFName,
+			Title:   flagWFTitle,
+			IfMatch: ifMatch,
 		}
 		if strings.TrimSpace(flagWFRole) != "" {
 			w.RoleName = strings.TrimSpace(flagWFRole)
@@ -90,6 +96,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagWFDesc, "description", "", "Plain text description")
 	setCmd.Flags().StringVar(&flagWFNotes, "notes", "", "Markdown-formatted notes")
 	setCmd.Flags().StringVar(&flagWFRole, "role", "", "Role name (optional; defaults to 'user')")
+	setCmd.Flags().StringVar(&flagWFIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // no extras
@@ -0,0 +1,392 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfgpkg "github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSyncDelete     bool
+	flagSyncDryRun     bool
+	flagSyncForceWrite bool
+)
+
+// syncCmd implements: rbc workflow sync id:NAME folder:relative/path (or reverse)
+var syncCmd = &cobra.Command{
+	Use:   "sync <source> <target>",
+	Short: "Sync a workflow, its tasks and scripts between id and folder",
+	Long:  "Sync a workflow with its tasks, task variants, scripts and script attachments between id:NAME and folder:RELATIVE_PATH. Supports id->folder and folder->id.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := syncfs.ParseEndpoint(args[0])
+		if err != nil {
+			return err
+		}
+		dst, err := syncfs.ParseEndpoint(args[1])
+		if err != nil {
+			return err
+		}
+		idEp, folderEp, idFirst, err := syncfs.SplitPair(src, dst)
+		if err != nil {
+			return err
+		}
+		// Shortcut: allow id:_ to read the name from the folder's workflow.yaml
+		if idEp.Value == "_" {
+			name, e := readWorkflowNameFromFolder(folderEp.Value)
+			if e != nil {
+				return e
+			}
+			idEp.Value = name
+		}
+		if idFirst {
+			return syncWorkflowToFolder(idEp.Value, folderEp.Value, flagSyncDelete, flagSyncDryRun, flagSyncForceWrite)
+		}
+		return syncFolderToWorkflow(folderEp.Value, idEp.Value, flagSyncDelete, flagSyncDryRun)
+	},
+}
+
+func init() {
+	WorkflowCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolVar(&flagSyncDelete, "delete", false, "Delete destination items (files, tasks, attachments) not present at source")
+	syncCmd.Flags().BoolVar(&flagSyncDryRun, "dry-run", false, "Show what would change without writing or deleting")
+	syncCmd.Flags().BoolVar(&flagSyncForceWrite, "force-write", false, "Force rewrite files even if destination appears up-to-date")
+}
+
+func readWorkflowNameFromFolder(rel string) (string, error) {
+	dir, err := syncfs.CleanFolder(rel)
+	if err != nil {
+		return "", err
+	}
+	var wy workflowYAML
+	if err := syncfs.ReadYAML(filepath.Join(dir, workflowFileName), &wy); err != nil {
+		return "", fmt.Errorf("id:_ requires %s in folder: %w", workflowFileName, err)
+	}
+	if strings.TrimSpace(wy.Name) == "" {
+		return "", fmt.Errorf("%s has no name", workflowFileName)
+	}
+	return strings.TrimSpace(wy.Name), nil
+}
+
+func openDB(timeout time.Duration) (context.Context, context.CancelFunc, *pgxpool.Pool, error) {
+	cfg, err := cfgpkg.Load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return ctx, cancel, db, nil
+}
+
+func syncWorkflowToFolder(name, relFolder string, allowDelete, dryRun, forceWrite bool) error {
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, db, err := openDB(60 * time.Second)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer db.Close()
+
+	local, err := loadLocalWorkflow(dir)
+	if err != nil {
+		return err
+	}
+	remote, err := loadRemoteWorkflow(ctx, db, name, local.bodyFiles())
+	if err != nil {
+		return err
+	}
+	if remote.workflow == nil {
+		return fmt.Errorf("workflow %q not found", name)
+	}
+	if !dryRun {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create dest folder: %w", err)
+		}
+	}
+	written, deleted := 0, 0
+	for _, fn := range remote.sortedNames() {
+		rf := remote.files[fn]
+		if lf, ok := local.files[fn]; ok && sameFile(rf, lf) && !forceWrite {
+			continue
+		}
+		p := filepath.Join(dir, fn)
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "[dry-run] write %s\n", p)
+		} else if err := syncfs.WriteFileAtomic(p, rf.data); err != nil {
+			return fmt.Errorf("write %s: %w", p, err)
+		}
+		written++
+	}
+	if allowDelete {
+		for _, fn := range local.sortedNames() {
+			if _, ok := remote.files[fn]; ok {
+				continue
+			}
+			p := filepath.Join(dir, fn)
+			if dryRun {
+				fmt.Fprintf(os.Stderr, "[dry-run] delete %s\n", p)
+			} else if err := os.Remove(p); err != nil {
+				return fmt.Errorf("delete %s: %w", p, err)
+			}
+			deleted++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "workflow sync id->folder name=%q folder=%s written=%d deleted=%d\n", name, dir, written, deleted)
+	return nil
+}
+
+func syncFolderToWorkflow(relFolder, name string, allowDelete, dryRun bool) error {
+	dir, err := syncfs.CleanFolder(relFolder)
+	if err != nil {
+		return err
+	}
+	local, err := loadLocalWorkflow(dir)
+	if err != nil {
+		return err
+	}
+	if local.workflow == nil {
+		return fmt.Errorf("%s not found in %s", workflowFileName, dir)
+	}
+	if strings.TrimSpace(local.workflow.Name) != name {
+		return fmt.Errorf("%s declares name %q but target is id:%s", workflowFileName, local.workflow.Name, name)
+	}
+	ctx, cancel, db, err := openDB(120 * time.Second)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer db.Close()
+
+	remote, err := loadRemoteWorkflow(ctx, db, name, local.bodyFiles())
+	if err != nil {
+		return err
+	}
+	a := &wfApplier{ctx: ctx, db: db, dryRun: dryRun, role: local.workflow.Role}
+
+	// 1) workflow
+	if rf, ok := remote.files[workflowFileName]; !ok || !sameFile(rf, local.files[workflowFileName]) {
+		if err := a.upsertWorkflow(*local.workflow); err != nil {
+			return err
+		}
+	}
+	// 2) scripts, keyed by base name so task files can reference them
+	scriptIDs := map[string]string{}
+	for _, fn := range local.sortedNames() {
+		sy, ok := local.scripts[fn]
+		if !ok {
+			continue
+		}
+		base := strings.TrimSuffix(fn, ".script.yaml")
+		id, err := a.upsertScript(fn, sy, local, remote)
+		if err != nil {
+			return err
+		}
+		scriptIDs[base] = id
+	}
+	// 3) tasks and their attachments
+	for _, fn := range local.sortedNames() {
+		ty, ok := local.tasks[fn]
+		if !ok {
+			continue
+		}
+		for _, ts := range ty.Scripts {
+			if _, ok := scriptIDs[ts.Script]; !ok {
+				return fmt.Errorf("%s: script %q has no %s in folder", fn, ts.Name, scriptFileName(ts.Script))
+			}
+		}
+		if rf, ok := remote.files[fn]; ok && sameFile(rf, local.files[fn]) {
+			continue
+		}
+		if err := a.upsertTask(name, ty, remote.tasks[fn], remote.taskIDs[fn], remote.scriptIDs, scriptIDs, allowDelete); err != nil {
+			return err
+		}
+	}
+	// 4) tasks removed locally
+	if allowDelete {
+		for _, fn := range remote.sortedNames() {
+			id, ok := remote.taskIDs[fn]
+			if !ok {
+				continue
+			}
+			if _, keep := local.tasks[fn]; keep {
+				continue
+			}
+			if dryRun {
+				fmt.Fprintf(os.Stderr, "[dry-run] delete task variant=%q\n", remote.tasks[fn].Variant)
+			} else if _, err := pgdao.DeleteTaskByID(ctx, db, id); err != nil {
+				return err
+			}
+			a.deleted++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "workflow sync folder->id name=%q folder=%s upserted=%d attached=%d detached=%d deleted=%d\n", name, dir, a.upserted, a.attached, a.detached, a.deleted)
+	return nil
+}
+
+// wfApplier writes local definitions to the DB, honouring --dry-run.
+type wfApplier struct {
+	ctx    context.Context
+	db     *pgxpool.Pool
+	dryRun bool
+	role   string
+
+	upserted int
+	attached int
+	detached int
+	deleted  int
+}
+
+func (a *wfApplier) roleOr(r string) string {
+	if strings.TrimSpace(r) != "" {
+		return strings.TrimSpace(r)
+	}
+	if strings.TrimSpace(a.role) != "" {
+		return strings.TrimSpace(a.role)
+	}
+	return "user"
+}
+
+func (a *wfApplier) upsertWorkflow(wy workflowYAML) error {
+	a.upserted++
+	if a.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] upsert workflow name=%q\n", wy.Name)
+		return nil
+	}
+	w := &pgdao.Workflow{Name: wy.Name, Title: wy.Title, RoleName: a.roleOr(wy.Role), Description: syncfs.NullString(wy.Description), Notes: syncfs.NullLiteral(wy.Notes)}
+	return pgdao.UpsertWorkflow(a.ctx, a.db, w)
+}
+
+// upsertScript updates the script already attached in this workflow, else one matching
+// its complex name and role, else creates it. Returns the script id (a placeholder in
+// dry-run for scripts that do not exist yet).
+func (a *wfApplier) upsertScript(fn string, sy scriptYAML, local, remote *wfFolder) (string, error) {
+	base := strings.TrimSuffix(fn, ".script.yaml")
+	id := remote.scriptIDs[base]
+	if id != "" {
+		rf, okf := remote.files[fn]
+		rb, okb := remote.files[sy.BodyFile]
+		if okf && okb && sameFile(rf, local.files[fn]) && sameFile(rb, local.files[sy.BodyFile]) {
+			return id, nil
+		}
+	}
+	role := a.roleOr(sy.Role)
+	if id == "" {
+		s, err := pgdao.GetScriptByComplexNameRole(a.ctx, a.db, sy.Name, sy.Variant, sy.Archived, role)
+		switch {
+		case err == nil:
+			id = s.ID
+		case !errors.Is(err, pgx.ErrNoRows):
+			return "", err
+		}
+	}
+	a.upserted++
+	if a.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] upsert script name=%q variant=%q\n", sy.Name, sy.Variant)
+		if id == "" {
+			id = "(new:" + base + ")"
+		}
+		return id, nil
+	}
+	body := string(local.files[sy.BodyFile].data)
+	cid, err := pgdao.InsertScriptContent(a.ctx, a.db, body)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", fn, err)
+	}
+	s := &pgdao.Script{
+		ID: id, Title: sy.Title, RoleName: role, ScriptContentID: cid,
+		Description: syncfs.NullString(sy.Description), Motivation: syncfs.NullString(sy.Motivation), Notes: syncfs.NullLiteral(sy.Notes),
+		Tags: sy.Tags, ComplexName: pgdao.ScriptComplexName{Name: sy.Name, Variant: sy.Variant}, Archived: sy.Archived,
+	}
+	if err := pgdao.UpsertScript(a.ctx, a.db, s); err != nil {
+		return "", err
+	}
+	return s.ID, nil
+}
+
+func (a *wfApplier) upsertTask(workflow string, ty, remoteTY taskYAML, remoteID string, remoteScriptIDs, scriptIDs map[string]string, allowDelete bool) error {
+	a.upserted++
+	taskID := remoteID
+	if a.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] upsert task variant=%q\n", ty.Variant)
+	} else {
+		t := &pgdao.Task{
+			WorkflowID: workflow, Command: ty.Command, Variant: ty.Variant, RoleName: a.roleOr(ty.Role),
+			Title: syncfs.NullString(ty.Title), Description: syncfs.NullString(ty.Description), Motivation: syncfs.NullString(ty.Motivation),
+			Notes: syncfs.NullLiteral(ty.Notes), Shell: syncfs.NullString(ty.Shell), Timeout: syncfs.NullString(ty.Timeout), Level: syncfs.NullString(ty.Level),
+			ToolWorkspaceID: syncfs.NullString(ty.ToolWorkspace), Tags: ty.Tags, Archived: ty.Archived,
+		}
+		if err := pgdao.UpsertTask(a.ctx, a.db, t); err != nil {
+			return err
+		}
+		taskID = t.ID
+	}
+	// Reconcile attachments by logical name: re-attach when the target script or alias changed.
+	current := map[string]taskScriptYAML{}
+	for _, ts := range remoteTY.Scripts {
+		current[ts.Name] = ts
+	}
+	desired := map[string]struct{}{}
+	for _, ts := range ty.Scripts {
+		desired[ts.Name] = struct{}{}
+		if cur, ok := current[ts.Name]; ok {
+			if remoteScriptIDs[cur.Script] == scriptIDs[ts.Script] && syncfs.Deref(cur.Alias) == syncfs.Deref(ts.Alias) {
+				continue
+			}
+			if err := a.detach(taskID, ty.Variant, cur.Name); err != nil {
+				return err
+			}
+		}
+		if err := a.attach(taskID, ty.Variant, ts, scriptIDs[ts.Script]); err != nil {
+			return err
+		}
+	}
+	if allowDelete {
+		for _, cur := range remoteTY.Scripts {
+			if _, ok := desired[cur.Name]; ok {
+				continue
+			}
+			if err := a.detach(taskID, ty.Variant, cur.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *wfApplier) attach(taskID, variant string, ts taskScriptYAML, scriptID string) error {
+	a.attached++
+	if a.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] attach script name=%q task=%q\n", ts.Name, variant)
+		return nil
+	}
+	_, err := pgdao.AddTaskScript(a.ctx, a.db, taskID, scriptID, ts.Name, syncfs.NullString(ts.Alias))
+	return err
+}
+
+func (a *wfApplier) detach(taskID, variant, name string) error {
+	a.detached++
+	if a.dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] detach script name=%q task=%q\n", name, variant)
+		return nil
+	}
+	_, err := pgdao.RemoveTaskScript(a.ctx, a.db, taskID, name)
+	return err
+}
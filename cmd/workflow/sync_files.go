@@ -0,0 +1,328 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Folder layout used by `workflow sync` and `workflow diff`:
+//   workflow.yaml          workflow metadata; name is the identity
+//   <variant>.task.yaml    one file per task, including its script attachments
+//   <script>.script.yaml   script metadata, identified by complex name (name, variant)
+//   <body_file>            script body referenced by the script yaml (sibling file)
+// Files carry natural keys only (no UUIDs) so the folder can live in git.
+
+const workflowFileName = "workflow.yaml"
+
+// LiteralString renders as a YAML literal block scalar (|), preserving newlines exactly.
+type LiteralString = syncfs.LiteralString
+
+type workflowYAML struct {
+	Name        string         `yaml:"name"`
+	Title       string         `yaml:"title"`
+	Role        string         `yaml:"role"`
+	Description *string        `yaml:"description,omitempty"`
+	Notes       *LiteralString `yaml:"notes,omitempty"`
+}
+
+type taskScriptYAML struct {
+	Name   string  `yaml:"name"`
+	Alias  *string `yaml:"alias,omitempty"`
+	Script string  `yaml:"script"` // base name of the sibling <script>.script.yaml
+}
+
+type taskYAML struct {
+	Variant       string           `yaml:"variant"`
+	Command       string           `yaml:"command"`
+	Role          string           `yaml:"role"`
+	Title         *string          `yaml:"title,omitempty"`
+	Description   *string          `yaml:"description,omitempty"`
+	Motivation    *string          `yaml:"motivation,omitempty"`
+	Notes         *LiteralString   `yaml:"notes,omitempty"`
+	Shell         *string          `yaml:"shell,omitempty"`
+	Timeout       *string          `yaml:"timeout,omitempty"`
+	Level         *string          `yaml:"level,omitempty"`
+	ToolWorkspace *string          `yaml:"tool_workspace_id,omitempty"`
+	Tags          map[string]any   `yaml:"tags,omitempty"`
+	Archived      bool             `yaml:"archived"`
+	Scripts       []taskScriptYAML `yaml:"scripts,omitempty"`
+}
+
+type scriptYAML struct {
+	Name        string         `yaml:"name"`
+	Variant     string         `yaml:"variant,omitempty"`
+	Title       string         `yaml:"title"`
+	Role        string         `yaml:"role"`
+	Description *string        `yaml:"description,omitempty"`
+	Motivation  *string        `yaml:"motivation,omitempty"`
+	Notes       *LiteralString `yaml:"notes,omitempty"`
+	Tags        map[string]any `yaml:"tags,omitempty"`
+	Archived    bool           `yaml:"archived"`
+	BodyFile    string         `yaml:"body_file"`
+}
+
+// wfFile is one managed file of a workflow folder in normalized form, so that
+// remote (rendered from DB) and local (parsed then re-marshalled) compare byte-wise.
+type wfFile struct {
+	name string
+	kind string // workflow|task|script|body
+	key  string // workflow name, task variant or script name[/variant]
+	data []byte
+}
+
+// wfFolder is the parsed state of one side of a sync.
+type wfFolder struct {
+	workflow  *workflowYAML
+	tasks     map[string]taskYAML   // by file name
+	scripts   map[string]scriptYAML // by file name
+	files     map[string]wfFile
+	taskIDs   map[string]string // remote only: task file -> task id
+	scriptIDs map[string]string // remote only: script base -> script id
+}
+
+func newWFFolder() *wfFolder {
+	return &wfFolder{
+		tasks:     map[string]taskYAML{},
+		scripts:   map[string]scriptYAML{},
+		files:     map[string]wfFile{},
+		taskIDs:   map[string]string{},
+		scriptIDs: map[string]string{},
+	}
+}
+
+func (f *wfFolder) addYAML(name, kind, key string, v any) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", name, err)
+	}
+	f.files[name] = wfFile{name: name, kind: kind, key: key, data: b}
+	return nil
+}
+
+func (f *wfFolder) addBody(name, key, body string) {
+	f.files[name] = wfFile{name: name, kind: "body", key: key, data: []byte(pgdao.CanonicalizeText(body) + "\n")}
+}
+
+func (f *wfFolder) sortedNames() []string {
+	out := make([]string, 0, len(f.files))
+	for n := range f.files {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func taskFileName(variant string) string { return syncfs.SanitizeForFile(variant) + ".task.yaml" }
+
+func scriptBase(name, variant string) string {
+	base := syncfs.SanitizeForFile(name)
+	if v := syncfs.SanitizeForFile(variant); v != "" {
+		base += "-" + v
+	}
+	if base == "" {
+		base = "script"
+	}
+	return base
+}
+
+func scriptFileName(base string) string { return base + ".script.yaml" }
+
+// bodyFiles maps each script key (name/variant) to its body_file.
+func (f *wfFolder) bodyFiles() map[string]string {
+	out := make(map[string]string, len(f.scripts))
+	for _, sy := range f.scripts {
+		out[sy.Name+"/"+sy.Variant] = sy.BodyFile
+	}
+	return out
+}
+
+// loadRemoteWorkflow renders a workflow, its tasks, attached scripts and bodies from the DB.
+// Script bodies take the local body_file name when bodyFiles has one (<base>.sh otherwise).
+// A missing workflow yields an empty folder so folder->id can create it.
+func loadRemoteWorkflow(ctx context.Context, db *pgxpool.Pool, name string, bodyFiles map[string]string) (*wfFolder, error) {
+	f := newWFFolder()
+	w, err := pgdao.GetWorkflowByName(ctx, db, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	wy := &workflowYAML{Name: w.Name, Title: w.Title, Role: w.RoleName, Description: syncfs.OptString(w.Description.String, w.Description.Valid), Notes: syncfs.OptLiteral(w.Notes.String, w.Notes.Valid)}
+	f.workflow = wy
+	if err := f.addYAML(workflowFileName, "workflow", w.Name, wy); err != nil {
+		return nil, err
+	}
+	tasks, err := pgdao.ListWorkflowTasks(ctx, db, name)
+	if err != nil {
+		return nil, err
+	}
+	// script id -> base, so that shared scripts are rendered once
+	baseByScriptID := map[string]string{}
+	for _, t := range tasks {
+		ty := taskYAML{
+			Variant: t.Variant, Command: t.Command, Role: t.RoleName,
+			Title: syncfs.OptString(t.Title.String, t.Title.Valid), Description: syncfs.OptString(t.Description.String, t.Description.Valid), Motivation: syncfs.OptString(t.Motivation.String, t.Motivation.Valid),
+			Notes: syncfs.OptLiteral(t.Notes.String, t.Notes.Valid), Shell: syncfs.OptString(t.Shell.String, t.Shell.Valid), Timeout: syncfs.OptString(t.Timeout.String, t.Timeout.Valid), Level: syncfs.OptString(t.Level.String, t.Level.Valid),
+			ToolWorkspace: syncfs.OptString(t.ToolWorkspaceID.String, t.ToolWorkspaceID.Valid), Tags: t.Tags, Archived: t.Archived,
+		}
+		atts, err := pgdao.ListTaskScripts(ctx, db, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range atts {
+			base, ok := baseByScriptID[a.ScriptID]
+			if !ok {
+				s, err := pgdao.GetScriptByID(ctx, db, a.ScriptID)
+				if err != nil {
+					return nil, err
+				}
+				base = scriptBase(s.ComplexName.Name, s.ComplexName.Variant)
+				if other, taken := f.scriptIDs[base]; taken && other != s.ID {
+					base += "-" + s.ID[:8]
+				}
+				baseByScriptID[s.ID] = base
+				f.scriptIDs[base] = s.ID
+				body, err := pgdao.GetScriptContent(ctx, db, s.ScriptContentID)
+				if err != nil {
+					return nil, err
+				}
+				sy := remoteScriptYAML(*s, base, bodyFiles)
+				key := sy.Name + "/" + sy.Variant
+				fn := scriptFileName(base)
+				f.scripts[fn] = sy
+				if err := f.addYAML(fn, "script", key, sy); err != nil {
+					return nil, err
+				}
+				f.addBody(sy.BodyFile, key, body)
+			}
+			ty.Scripts = append(ty.Scripts, taskScriptYAML{Name: a.Name, Alias: syncfs.OptString(a.Alias.String, a.Alias.Valid), Script: base})
+		}
+		fn := taskFileName(t.Variant)
+		f.tasks[fn] = ty
+		f.taskIDs[fn] = t.ID
+		if err := f.addYAML(fn, "task", t.Variant, ty); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// remoteScriptYAML renders a stored script as <base>.script.yaml; the body
+// file keeps its local name from bodyFiles, else <base>.sh.
+func remoteScriptYAML(s pgdao.Script, base string, bodyFiles map[string]string) scriptYAML {
+	sy := scriptYAML{
+		Name: s.ComplexName.Name, Variant: s.ComplexName.Variant, Title: s.Title, Role: s.RoleName,
+		Description: syncfs.OptString(s.Description.String, s.Description.Valid), Motivation: syncfs.OptString(s.Motivation.String, s.Motivation.Valid), Notes: syncfs.OptLiteral(s.Notes.String, s.Notes.Valid),
+		Tags: s.Tags, Archived: s.Archived, BodyFile: base + ".sh",
+	}
+	if bf, ok := bodyFiles[sy.Name+"/"+sy.Variant]; ok {
+		sy.BodyFile = bf
+	}
+	return sy
+}
+
+// loadLocalWorkflow parses a workflow folder. A missing folder yields an empty result.
+func loadLocalWorkflow(dir string) (*wfFolder, error) {
+	f := newWFFolder()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("read folder: %w", err)
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		name := e.Name()
+		p := filepath.Join(dir, name)
+		switch {
+		case name == workflowFileName:
+			var wy workflowYAML
+			if err := syncfs.ReadYAML(p, &wy); err != nil {
+				return nil, err
+			}
+			f.workflow = &wy
+			if err := f.addYAML(name, "workflow", wy.Name, wy); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(name, ".task.yaml"):
+			var ty taskYAML
+			if err := syncfs.ReadYAML(p, &ty); err != nil {
+				return nil, err
+			}
+			f.tasks[name] = ty
+			if err := f.addYAML(name, "task", ty.Variant, ty); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(name, ".script.yaml"):
+			var sy scriptYAML
+			if err := syncfs.ReadYAML(p, &sy); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(sy.BodyFile) == "" || strings.ContainsAny(sy.BodyFile, `/\`) {
+				return nil, fmt.Errorf("%s: body_file must name a sibling file", p)
+			}
+			body, err := os.ReadFile(filepath.Join(dir, sy.BodyFile))
+			if err != nil {
+				return nil, fmt.Errorf("%s: read body_file: %w", p, err)
+			}
+			f.scripts[name] = sy
+			key := sy.Name + "/" + sy.Variant
+			if err := f.addYAML(name, "script", key, sy); err != nil {
+				return nil, err
+			}
+			f.addBody(sy.BodyFile, key, string(body))
+		}
+	}
+	return f, nil
+}
+
+// records groups the folder files into sync records: one per workflow and task,
+// and one per script carrying its body file as a sidecar.
+func (f *wfFolder) records() []syncfs.Record {
+	byKey := map[string]*syncfs.Record{}
+	var order []string
+	for _, pass := range []bool{false, true} {
+		for _, n := range f.sortedNames() {
+			wf := f.files[n]
+			if (wf.kind == "body") != pass {
+				continue
+			}
+			kind := wf.kind
+			if kind == "body" {
+				kind = "script"
+			}
+			k := kind + "\x00" + wf.key
+			r, ok := byKey[k]
+			if !ok {
+				r = &syncfs.Record{Kind: kind, Key: wf.key}
+				byKey[k] = r
+				order = append(order, k)
+			}
+			r.Files = append(r.Files, syncfs.File{Name: n, Data: wf.data})
+		}
+	}
+	out := make([]syncfs.Record, 0, len(order))
+	for _, k := range order {
+		out = append(out, *byKey[k])
+	}
+	return out
+}
+
+func sameFile(a, b wfFile) bool { return bytes.Equal(a.data, b.data) }
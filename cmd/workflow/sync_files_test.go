@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/syncfs"
+)
+
+func TestLoadLocalWorkflow_RoundTripsRenderedFiles(t *testing.T) {
+	notes := LiteralString("line one\nline two\n")
+	alias := "ut"
+	src := newWFFolder()
+	if err := src.addYAML(workflowFileName, "workflow", "ci", workflowYAML{Name: "ci", Title: "CI", Role: "user", Notes: &notes}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.addYAML(taskFileName("unit/go"), "task", "unit/go", taskYAML{
+		Variant: "unit/go", Command: "unit", Role: "user",
+		Tags:    map[string]any{"lang": "go"},
+		Scripts: []taskScriptYAML{{Name: "run", Alias: &alias, Script: scriptBase("run-tests", "go")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	base := scriptBase("run-tests", "go")
+	if err := src.addYAML(scriptFileName(base), "script", "run-tests/go", scriptYAML{Name: "run-tests", Variant: "go", Title: "Run", Role: "user", BodyFile: base + ".sh"}); err != nil {
+		t.Fatal(err)
+	}
+	src.addBody(base+".sh", "run-tests/go", "go test ./...   \r\n")
+
+	dir := t.TempDir()
+	for n, f := range src.files {
+		if err := os.WriteFile(filepath.Join(dir, n), f.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := loadLocalWorkflow(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.files) != len(src.files) {
+		t.Fatalf("expected %d files; got %d", len(src.files), len(got.files))
+	}
+	for n, f := range src.files {
+		if !sameFile(f, got.files[n]) {
+			t.Fatalf("%s differs after reload:\n%s\nvs\n%s", n, f.data, got.files[n].data)
+		}
+	}
+	if _, ok := got.tasks["unit-go.task.yaml"]; !ok {
+		t.Fatalf("expected task file unit-go.task.yaml; got %v", got.sortedNames())
+	}
+}
+
+func TestRemoteScriptYAML_KeepsLocalBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"lint.script.yaml": "name: lint\ntitle: Lint\nrole: user\narchived: false\nbody_file: lint.py\n",
+		"lint.py":          "print('lint')\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	local, err := loadLocalWorkflow(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := pgdao.Script{Title: "Lint", RoleName: "user", ComplexName: pgdao.ScriptComplexName{Name: "lint"}}
+	sy := remoteScriptYAML(s, "lint", local.bodyFiles())
+	remote := newWFFolder()
+	if err := remote.addYAML("lint.script.yaml", "script", "lint/", sy); err != nil {
+		t.Fatal(err)
+	}
+	remote.addBody(sy.BodyFile, "lint/", "print('lint')")
+	for _, c := range syncfs.Diff(remote.records(), local.records()) {
+		if c.Op != syncfs.OpSame {
+			t.Fatalf("expected no diff; got %+v", c)
+		}
+	}
+	if got := remoteScriptYAML(s, "lint", nil).BodyFile; got != "lint.sh" {
+		t.Fatalf("expected lint.sh without a local script; got %q", got)
+	}
+}
@@ -16,12 +16,13 @@ import (
 )
 
 var (
-	flagWSID    string
-	flagWSRole  string
-	flagWSDesc  string
-	flagWSProj  string
-	flagWSBuild string
-	flagWSTags  []string
+	flagWSID      string
+	flagWSRole    string
+	flagWSDesc    string
+	flagWSProj    string
+	flagWSBuild   string
+	flagWSTags    []string
+	flagWSIfMatch string
 )
 
 var setCmd = &cobra.Command{
@@ -31,6 +32,13 @@ var setCmd = &cobra.Command{
 		if strings.TrimSpace(flagWSRole) == "" {
 			return errors.New("--role is required")
 		}
+		ifMatch, err := pgdao.ParseIfMatch(flagWSIfMatch)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.IsZero() && strings.TrimSpace(flagWSID) == "" {
+			return errors.New("--if-match requires --id")
+		}
 		cfg, err := cfgpkg.Load()
 		if err != nil {
 			return err
@@ -43,7 +51,7 @@ var setCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		w := &pgdao.Workspace{ID: flagWSID, RoleName: flagWSRole}
+		w := &pgdao.Workspace{ID: flagWSID, RoleName: flagWSRole, IfMatch: ifMatch}
 		if flagWSDesc != "" {
 			w.Description = sql.NullString{String: flagWSDesc, Valid: true}
 		}
@@ -91,6 +99,7 @@ func init() {
 	setCmd.Flags().StringVar(&flagWSProj, "project", "", "Project name (must exist for role if provided)")
 	setCmd.Flags().StringVar(&flagWSBuild, "build-script", "", "Optional script UUID to run when building the workspace")
 	setCmd.Flags().StringSliceVar(&flagWSTags, "tags", nil, "Tags as key=value pairs (repeat or comma-separated). Plain values mapped to true")
+	setCmd.Flags().StringVar(&flagWSIfMatch, "if-match", "", "Only update if the stored updated timestamp still matches (RFC3339, as printed by set/get)")
 }
 
 // parseTags converts k=v pairs (or bare keys) into a map.
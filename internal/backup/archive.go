@@ -0,0 +1,462 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveFormat identifies the layout of exported snapshot archives.
+//
+// An archive is a zstd-compressed tar holding, in this order:
+//
+//	manifest.json              backup metadata, entity schemas, counts and checksums
+//	records/<entity>.ndjson    one pgdao.EntityRecord JSON document per line
+const ArchiveFormat = "rbc-snapshot/1"
+
+const manifestName = "manifest.json"
+
+// Manifest describes an exported snapshot.
+type Manifest struct {
+	Format     string           `json:"format"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Backup     pgdao.Backup     `json:"backup"`
+	Entities   []ManifestEntity `json:"entities"`
+}
+
+// ManifestEntity describes one entity file of an archive.
+type ManifestEntity struct {
+	Name      string              `json:"name"`
+	Table     string              `json:"table"`
+	PKColumns []string            `json:"pk_columns"`
+	Fields    []pgdao.EntityField `json:"fields"`
+	Records   int64               `json:"records"`
+	File      string              `json:"file"`
+	SHA256    string              `json:"sha256"`
+}
+
+// ExportArchive writes backup id of schema to outPath as a .tar.zst archive.
+// Records are spooled to a temporary directory first so that the manifest, with
+// checksums, can lead the archive. The file is written atomically.
+func ExportArchive(ctx context.Context, db *pgxpool.Pool, entities []BackupEntityConfig, schema, id, outPath string) (*Manifest, error) {
+	b, err := GetBackup(ctx, db, schema, id)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgdao.ListBackupEntities(ctx, db, schema, id)
+	if err != nil {
+		return nil, err
+	}
+	conf := map[string]BackupEntityConfig{}
+	for _, e := range entities {
+		conf[e.EntityName] = e
+	}
+	spool, err := os.MkdirTemp("", "rbc-snapshot-export-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(spool)
+
+	m := &Manifest{Format: ArchiveFormat, ExportedAt: time.Now().UTC(), Backup: *b}
+	files := map[string]string{}
+	for _, name := range names {
+		fields, err := pgdao.ListEntityFields(ctx, db, schema, id, name)
+		if err != nil {
+			return nil, err
+		}
+		me := ManifestEntity{Name: name, Table: name, Fields: fields, File: path.Join("records", name+".ndjson")}
+		if c, ok := conf[name]; ok {
+			me.Table, me.PKColumns = c.TableName, c.PKColumns
+		}
+		p := filepath.Join(spool, name+".ndjson")
+		if me.Records, me.SHA256, err = spoolRecords(ctx, db, schema, id, name, p); err != nil {
+			return nil, err
+		}
+		files[me.File] = p
+		m.Entities = append(m.Entities, me)
+	}
+
+	tmp := outPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeArchive(f, m, files); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return m, os.Rename(tmp, outPath)
+}
+
+func spoolRecords(ctx context.Context, db *pgxpool.Pool, schema, id, entity, p string) (int64, string, error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	enc := json.NewEncoder(w)
+	var n int64
+	err = pgdao.EachEntityRecord(ctx, db, schema, id, entity, func(r pgdao.EntityRecord) error {
+		n++
+		return enc.Encode(r)
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	if err := w.Flush(); err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeArchive writes the manifest followed by the spooled files (archive name -> local path).
+func writeArchive(w io.Writer, m *Manifest, files map[string]string) error {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+	mb, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0o644, Size: int64(len(mb)), ModTime: m.ExportedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(mb); err != nil {
+		return err
+	}
+	for _, e := range m.Entities {
+		if err := addFile(tw, e.File, files[e.File], m.ExportedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func addFile(tw *tar.Writer, name, local string, mod time.Time) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: fi.Size(), ModTime: mod}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// readArchive decodes the manifest, calls onManifest, then calls onEntity with a
+// reader over each entity file. After onEntity returns, the remainder of the file
+// is drained and its checksum compared with the manifest; every manifest entity
+// must be present exactly once.
+func readArchive(r io.Reader, onManifest func(*Manifest) error, onEntity func(*ManifestEntity, io.Reader) error) (*Manifest, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("not a snapshot archive: first entry is %q, want %s", hdr.Name, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestName, err)
+	}
+	if m.Format != ArchiveFormat {
+		return nil, fmt.Errorf("unsupported archive format %q (want %s)", m.Format, ArchiveFormat)
+	}
+	if onManifest != nil {
+		if err := onManifest(&m); err != nil {
+			return &m, err
+		}
+	}
+	byFile := map[string]*ManifestEntity{}
+	for i := range m.Entities {
+		byFile[m.Entities[i].File] = &m.Entities[i]
+	}
+	seen := map[string]bool{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return &m, fmt.Errorf("read archive: %w", err)
+		}
+		e, ok := byFile[hdr.Name]
+		if !ok {
+			return &m, fmt.Errorf("archive entry %q is not listed in the manifest", hdr.Name)
+		}
+		if seen[hdr.Name] {
+			return &m, fmt.Errorf("archive entry %q appears twice", hdr.Name)
+		}
+		seen[hdr.Name] = true
+		h := sha256.New()
+		body := io.TeeReader(tr, h)
+		if onEntity != nil {
+			if err := onEntity(e, body); err != nil {
+				return &m, err
+			}
+		}
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return &m, err
+		}
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != e.SHA256 {
+			return &m, fmt.Errorf("checksum mismatch for %s: archive %s, manifest %s", e.File, sum, e.SHA256)
+		}
+	}
+	for _, e := range m.Entities {
+		if !seen[e.File] {
+			return &m, fmt.Errorf("archive is missing %s", e.File)
+		}
+	}
+	return &m, nil
+}
+
+// SchemaIssue is a difference between an archived entity schema and the live table.
+type SchemaIssue struct {
+	Entity   string `json:"entity"`
+	Field    string `json:"field,omitempty"`
+	Problem  string `json:"problem"`
+	Blocking bool   `json:"blocking"`
+}
+
+// CheckLiveSchema compares the manifest's entity schemas with the live tables.
+// Blocking issues would make a later restore fail (missing table or key column,
+// changed type, new NOT NULL column without default); others are informational.
+func CheckLiveSchema(ctx context.Context, db *pgxpool.Pool, m *Manifest) ([]SchemaIssue, error) {
+	var issues []SchemaIssue
+	for _, e := range m.Entities {
+		live, err := fetchTableColumns(ctx, db, "public", e.Table)
+		if err != nil {
+			if errors.Is(err, ErrTableNotFound) {
+				issues = append(issues, SchemaIssue{Entity: e.Name, Problem: fmt.Sprintf("table %s does not exist", e.Table), Blocking: true})
+				continue
+			}
+			return nil, dbutil.ErrWrap("snapshot.schema_check", err, dbutil.ParamSummary("table", e.Table))
+		}
+		issues = append(issues, compareFields(e, live)...)
+	}
+	return issues, nil
+}
+
+func compareFields(e ManifestEntity, live []Column) []SchemaIssue {
+	var issues []SchemaIssue
+	liveBy := map[string]Column{}
+	for _, c := range live {
+		liveBy[c.ColumnName] = c
+	}
+	archived := map[string]bool{}
+	for _, f := range e.Fields {
+		archived[f.Name] = true
+		c, ok := liveBy[f.Name]
+		if !ok {
+			issues = append(issues, SchemaIssue{Entity: e.Name, Field: f.Name, Problem: "column dropped in live schema; values will be skipped"})
+			continue
+		}
+		if c.DataType != f.Type {
+			issues = append(issues, SchemaIssue{Entity: e.Name, Field: f.Name, Problem: fmt.Sprintf("type changed %s -> %s", f.Type, c.DataType), Blocking: true})
+		}
+	}
+	for _, k := range e.PKColumns {
+		if _, ok := liveBy[k]; !ok {
+			issues = append(issues, SchemaIssue{Entity: e.Name, Field: k, Problem: "primary key column missing in live schema", Blocking: true})
+		}
+	}
+	for _, c := range live {
+		if archived[c.ColumnName] {
+			continue
+		}
+		if !c.IsNullable && c.ColumnDefault == "" {
+			issues = append(issues, SchemaIssue{Entity: e.Name, Field: c.ColumnName, Problem: "new NOT NULL column without default", Blocking: true})
+		} else {
+			issues = append(issues, SchemaIssue{Entity: e.Name, Field: c.ColumnName, Problem: "new column; restored rows get its default"})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	return issues
+}
+
+// ImportOptions controls ImportArchive.
+type ImportOptions struct {
+	Schema string
+	// AllowDrift imports even when the live schema has blocking differences.
+	AllowDrift bool
+	// DryRun verifies checksums and schema without writing.
+	DryRun bool
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Manifest *Manifest     `json:"manifest"`
+	Issues   []SchemaIssue `json:"schema_issues"`
+	Records  int64         `json:"records"`
+	Imported bool          `json:"imported"`
+}
+
+// ImportArchive verifies an archive against the live schema and loads it into the
+// backup schema under its original id, in one transaction. Records are bulk-loaded
+// with COPY; a checksum or count mismatch rolls everything back. The imported
+// backup can then be inspected and restored like any other.
+func ImportArchive(ctx context.Context, db *pgxpool.Pool, r io.Reader, opt ImportOptions) (*ImportResult, error) {
+	schema := opt.Schema
+	if schema == "" {
+		schema = "backup"
+	}
+	res := &ImportResult{}
+	if !opt.DryRun {
+		if err := pgdao.EnsureBackupSchema(ctx, db, schema); err != nil {
+			return nil, dbutil.ErrWrap("snapshot.ensure_backup_schema", err, dbutil.ParamSummary("schema", schema))
+		}
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, dbutil.ErrWrap("snapshot.import.begin", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var backupID pgtype.UUID
+	onManifest := func(m *Manifest) error {
+		res.Manifest = m
+		issues, err := CheckLiveSchema(ctx, db, m)
+		if err != nil {
+			return err
+		}
+		res.Issues = issues
+		for _, is := range issues {
+			if is.Blocking && !opt.AllowDrift {
+				return fmt.Errorf("archive schema is incompatible with the live schema (%s.%s: %s); use --allow-drift to import anyway", is.Entity, is.Field, is.Problem)
+			}
+		}
+		if err := backupID.Scan(m.Backup.ID); err != nil {
+			return fmt.Errorf("invalid backup id %q in manifest: %w", m.Backup.ID, err)
+		}
+		if opt.DryRun {
+			return nil
+		}
+		exists, err := pgdao.BackupExists(ctx, tx, schema, m.Backup.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("backup %s already exists in schema %s; delete it first to re-import", m.Backup.ID, schema)
+		}
+		return pgdao.InsertBackupRow(ctx, tx, schema, m.Backup)
+	}
+	onEntity := func(e *ManifestEntity, body io.Reader) error {
+		src := &ndjsonSource{r: bufio.NewReader(body), backupID: backupID, entity: e.Name}
+		var n int64
+		if opt.DryRun {
+			for src.Next() {
+			}
+			n = src.n
+		} else {
+			if err := pgdao.InsertEntitySchemaBatch(ctx, tx, schema, res.Manifest.Backup.ID, e.Name, e.Fields); err != nil {
+				return err
+			}
+			if n, err = pgdao.CopyEntityRecords(ctx, tx, schema, e.Name, src); err != nil {
+				return err
+			}
+		}
+		if src.err != nil {
+			return fmt.Errorf("%s: %w", e.File, src.err)
+		}
+		if n != e.Records {
+			return fmt.Errorf("%s: %d records, manifest says %d", e.File, n, e.Records)
+		}
+		res.Records += n
+		return nil
+	}
+	if _, err := readArchive(r, onManifest, onEntity); err != nil {
+		return res, err
+	}
+	if opt.DryRun {
+		return res, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return res, dbutil.ErrWrap("snapshot.import.commit", err)
+	}
+	res.Imported = true
+	return res, nil
+}
+
+// ndjsonSource adapts an NDJSON records stream to pgx.CopyFromSource.
+type ndjsonSource struct {
+	r        *bufio.Reader
+	backupID pgtype.UUID
+	entity   string
+	cur      pgdao.EntityRecord
+	line     int
+	n        int64
+	err      error
+}
+
+func (s *ndjsonSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	for {
+		b, err := s.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(b)) > 0 {
+			s.line++
+			s.cur = pgdao.EntityRecord{}
+			if e := json.Unmarshal(b, &s.cur); e != nil {
+				s.err = fmt.Errorf("line %d: %w", s.line, e)
+				return false
+			}
+			s.n++
+			return true
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.err = err
+			}
+			return false
+		}
+	}
+}
+
+func (s *ndjsonSource) Values() ([]any, error) {
+	return []any{s.backupID, s.entity, s.cur.PK, s.cur.Record, s.cur.RoleName}, nil
+}
+
+func (s *ndjsonSource) Err() error { return s.err }
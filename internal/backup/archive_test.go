@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func testArchive(t *testing.T, records string, sum string) []byte {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "roles.ndjson")
+	if err := os.WriteFile(p, []byte(records), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{
+		Format: ArchiveFormat,
+		Backup: pgdao.Backup{ID: "7f1c2e64-0d3b-4a38-9a59-6a1f7d3c2b10"},
+		Entities: []ManifestEntity{{
+			Name: "roles", Table: "roles", PKColumns: []string{"name"},
+			Fields:  []pgdao.EntityField{{Name: "name", Type: "text"}},
+			Records: 2, File: "records/roles.ndjson", SHA256: sum,
+		}},
+	}
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, m, map[string]string{"records/roles.ndjson": p}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchive_RoundTripVerifiesChecksums(t *testing.T) {
+	records := `{"pk":{"name":"dev"},"record":{"name":"dev"}}` + "\n" + `{"pk":{"name":"ops"},"record":{"name":"ops"}}` + "\n"
+	h := sha256.Sum256([]byte(records))
+	data := testArchive(t, records, hex.EncodeToString(h[:]))
+
+	var lines int
+	m, err := readArchive(bytes.NewReader(data), nil, func(e *ManifestEntity, r io.Reader) error {
+		src := &ndjsonSource{r: bufio.NewReader(r), entity: e.Name}
+		for src.Next() {
+			lines++
+		}
+		return src.Err()
+	})
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	if lines != 2 || m.Backup.ID == "" || len(m.Entities) != 1 {
+		t.Fatalf("unexpected result: lines=%d manifest=%+v", lines, m)
+	}
+}
+
+func TestArchive_ChecksumMismatchFails(t *testing.T) {
+	data := testArchive(t, `{"pk":{"name":"dev"},"record":{"name":"dev"}}`+"\n", strings.Repeat("0", 64))
+	_, err := readArchive(bytes.NewReader(data), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestCompareFields_FlagsBlockingDrift(t *testing.T) {
+	e := ManifestEntity{Name: "tags", PKColumns: []string{"name"}, Fields: []pgdao.EntityField{
+		{Name: "name", Type: "text"}, {Name: "title", Type: "text"}, {Name: "legacy", Type: "text"},
+	}}
+	live := []Column{
+		{ColumnName: "name", DataType: "text"},
+		{ColumnName: "title", DataType: "integer"},
+		{ColumnName: "owner", DataType: "text"},
+		{ColumnName: "note", DataType: "text", IsNullable: true},
+	}
+	blocking := map[string]bool{}
+	for _, is := range compareFields(e, live) {
+		blocking[is.Field] = is.Blocking
+	}
+	want := map[string]bool{"title": true, "owner": true, "legacy": false, "note": false}
+	for f, b := range want {
+		got, ok := blocking[f]
+		if !ok || got != b {
+			t.Fatalf("field %s: got blocking=%v present=%v, want %v (all: %v)", f, got, ok, b, blocking)
+		}
+	}
+}
@@ -21,8 +21,10 @@ func DefaultEntities() []BackupEntityConfig {
 		{EntityName: "tasks", TableName: "tasks", PKColumns: []string{"id"}, HasRoleName: true, IncludeByDefault: true},
 		{EntityName: "workspaces", TableName: "workspaces", PKColumns: []string{"id"}, HasRoleName: true, IncludeByDefault: true},
 		{EntityName: "blackboards", TableName: "blackboards", PKColumns: []string{"id"}, HasRoleName: true, IncludeByDefault: true},
+		{EntityName: "blackboard_schemas", TableName: "blackboard_schemas", PKColumns: []string{"blackboard_id", "label"}, HasRoleName: false, IncludeByDefault: true},
 		{EntityName: "stickies", TableName: "stickies", PKColumns: []string{"id"}, HasRoleName: false, IncludeByDefault: true},
 		{EntityName: "stickie_relations", TableName: "stickie_relations", PKColumns: []string{"from_id", "to_id", "rel_type"}, HasRoleName: false, IncludeByDefault: true},
+		{EntityName: "stickie_revisions", TableName: "stickie_revisions", PKColumns: []string{"id"}, HasRoleName: false, IncludeByDefault: true},
 		{EntityName: "task_replaces", TableName: "task_replaces", PKColumns: []string{"new_task_id", "old_task_id"}, HasRoleName: false, IncludeByDefault: true},
 		{EntityName: "packages", TableName: "packages", PKColumns: []string{"id"}, HasRoleName: true, IncludeByDefault: true},
 		// Ephemeral by default (can be opted-in via --include)
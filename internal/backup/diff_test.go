@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func rec(pk string, fields map[string]any) (string, sideRecord) {
+	return pk, sideRecord{pk: json.RawMessage(pk), fields: fields}
+}
+
+func TestDiffRecords_ClassifiesByPrimaryKey(t *testing.T) {
+	a, b := map[string]sideRecord{}, map[string]sideRecord{}
+	k, r := rec(`{"id":"1"}`, map[string]any{"title": "keep"})
+	a[k], b[k] = r, r
+	k, r = rec(`{"id":"2"}`, map[string]any{"title": "old", "n": 1.0})
+	a[k] = r
+	_, r = rec(`{"id":"2"}`, map[string]any{"title": "new", "n": 1.0})
+	b[k] = r
+	k, r = rec(`{"id":"3"}`, map[string]any{"title": "gone"})
+	a[k] = r
+	k, r = rec(`{"id":"4"}`, map[string]any{"title": "fresh"})
+	b[k] = r
+
+	d := diffRecords(a, b)
+	if d.Added != 1 || d.Removed != 1 || d.Modified != 1 || d.Unchanged != 1 {
+		t.Fatalf("unexpected counts: %+v", d)
+	}
+	for _, r := range d.Records {
+		if r.Op == "~" {
+			if len(r.Fields) != 1 || r.Fields[0].Field != "title" || r.Fields[0].From != "old" || r.Fields[0].To != "new" {
+				t.Fatalf("unexpected field diff: %+v", r.Fields)
+			}
+		}
+	}
+}
+
+func TestCanonicalJSON_SortsKeys(t *testing.T) {
+	x, err := canonicalJSON([]byte(`{"role_name":"dev","name":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, _ := canonicalJSON([]byte(`{"name":"a", "role_name":"dev"}`))
+	if x != y {
+		t.Fatalf("expected identical keys, got %s and %s", x, y)
+	}
+}
@@ -0,0 +1,370 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Restore modes.
+const (
+	ModeAppend  = "append"  // insert missing primary keys only
+	ModeReplace = "replace" // truncate targets, then insert
+	ModeMerge   = "merge"   // insert missing rows and update changed ones
+)
+
+// Filter selects records by the text value of a record field. An empty Entity
+// applies the filter to every restored entity.
+type Filter struct {
+	Entity string
+	Field  string
+	Value  string
+}
+
+// ParseFilters parses --where values of the form field=value or entity.field=value.
+func ParseFilters(ss []string) ([]Filter, error) {
+	var out []Filter
+	for _, s := range ss {
+		k, v, ok := strings.Cut(s, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --where %q (want field=value or entity.field=value)", s)
+		}
+		f := Filter{Field: k, Value: strings.TrimSpace(v)}
+		if ent, field, qualified := strings.Cut(k, "."); qualified {
+			f.Entity, f.Field = ent, field
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// RestoreOptions defines restore behavior.
+type RestoreOptions struct {
+	Schema   string   // backup schema
+	Entities []string // subset to restore; empty = all
+	Mode     string   // append|replace|merge
+	Role     string   // only records with this role_name
+	Where    []Filter // only records matching every applicable filter
+}
+
+// RestoreStep is one entity of a restore plan, in application order.
+type RestoreStep struct {
+	Entity    string   `json:"entity"`
+	Table     string   `json:"table"`
+	Records   int64    `json:"records"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Missing lists parent tables that are referenced but not part of this restore;
+	// their rows must already exist live.
+	Missing []string `json:"missing_parents,omitempty"`
+
+	config BackupEntityConfig
+	filter pgdao.RecordFilter
+}
+
+// RestorePlan is the ordered list of entities a restore will write.
+type RestorePlan struct {
+	BackupID string        `json:"backup_id"`
+	Mode     string        `json:"mode"`
+	Steps    []RestoreStep `json:"steps"`
+	// Cycle lists tables whose foreign keys form a cycle; they are restored in name order.
+	Cycle []string `json:"cycle,omitempty"`
+}
+
+// PlanRestore resolves the entities to restore, counts matching records and orders
+// the entities so that referenced (parent) tables are written before the tables
+// that reference them, based on foreign keys from pg_constraint.
+func PlanRestore(ctx context.Context, db *pgxpool.Pool, entities []BackupEntityConfig, backupID string, opt RestoreOptions) (*RestorePlan, error) {
+	schema := opt.Schema
+	if schema == "" {
+		schema = "backup"
+	}
+	mode := strings.ToLower(strings.TrimSpace(opt.Mode))
+	if mode == "" {
+		mode = ModeAppend
+	}
+	if mode != ModeAppend && mode != ModeReplace && mode != ModeMerge {
+		return nil, fmt.Errorf("invalid mode %q, want append|replace|merge", opt.Mode)
+	}
+	if mode == ModeReplace && (opt.Role != "" || len(opt.Where) > 0) {
+		return nil, errors.New("replace mode truncates whole tables; use merge or append with --role/--where")
+	}
+	emap := map[string]BackupEntityConfig{}
+	for _, e := range entities {
+		emap[strings.ToLower(e.EntityName)] = e
+	}
+	names := opt.Entities
+	if len(names) == 0 {
+		var err error
+		if names, err = pgdao.ListBackupEntities(ctx, db, schema, backupID); err != nil {
+			return nil, err
+		}
+	}
+	byTable := map[string]BackupEntityConfig{}
+	for _, n := range names {
+		e, ok := emap[strings.ToLower(strings.TrimSpace(n))]
+		if !ok {
+			return nil, fmt.Errorf("unknown entity %q", n)
+		}
+		byTable[e.TableName] = e
+	}
+	for _, f := range opt.Where {
+		if f.Entity == "" {
+			continue
+		}
+		if _, ok := emap[strings.ToLower(f.Entity)]; !ok {
+			return nil, fmt.Errorf("--where refers to unknown entity %q", f.Entity)
+		}
+	}
+
+	fks, err := pgdao.ListForeignKeys(ctx, db, "public")
+	if err != nil {
+		return nil, err
+	}
+	parents := map[string][]string{}
+	for _, fk := range fks {
+		if fk.Table != fk.RefTable {
+			parents[fk.Table] = append(parents[fk.Table], fk.RefTable)
+		}
+	}
+	tables := make([]string, 0, len(byTable))
+	for t := range byTable {
+		tables = append(tables, t)
+	}
+	// TRUNCATE ... CASCADE also empties every table referencing a truncated one
+	if mode == ModeReplace {
+		if extra := cascadeTables(tables, parents); len(extra) > 0 {
+			return nil, fmt.Errorf("replace mode would also empty %s (referencing the restored tables); restore them too or use merge", strings.Join(extra, ", "))
+		}
+	}
+	order, cycle := orderByDependencies(tables, parents)
+
+	plan := &RestorePlan{BackupID: backupID, Mode: mode, Cycle: cycle}
+	for _, t := range order {
+		e := byTable[t]
+		step := RestoreStep{Entity: e.EntityName, Table: t, config: e, filter: recordFilter(e.EntityName, opt)}
+		for _, p := range parents[t] {
+			if _, ok := byTable[p]; ok {
+				step.DependsOn = append(step.DependsOn, p)
+			} else {
+				step.Missing = append(step.Missing, p)
+			}
+		}
+		if step.Records, err = pgdao.CountFilteredEntityRecords(ctx, db, schema, backupID, e.EntityName, step.filter); err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+func recordFilter(entity string, opt RestoreOptions) pgdao.RecordFilter {
+	var f pgdao.RecordFilter
+	if opt.Role != "" {
+		r := opt.Role
+		f.RoleName = &r
+	}
+	for _, w := range opt.Where {
+		if w.Entity != "" && !strings.EqualFold(w.Entity, entity) {
+			continue
+		}
+		if f.Fields == nil {
+			f.Fields = map[string]string{}
+		}
+		f.Fields[w.Field] = w.Value
+	}
+	return f
+}
+
+// cascadeTables returns, in name order, the tables outside tables that
+// reference them directly or through other referencing tables.
+func cascadeTables(tables []string, parents map[string][]string) []string {
+	children := map[string][]string{}
+	for t, ps := range parents {
+		for _, p := range ps {
+			children[p] = append(children[p], t)
+		}
+	}
+	seen := map[string]bool{}
+	for _, t := range tables {
+		seen[t] = true
+	}
+	queue := append([]string(nil), tables...)
+	var out []string
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, c := range children[t] {
+			if !seen[c] {
+				seen[c] = true
+				out = append(out, c)
+				queue = append(queue, c)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// orderByDependencies topologically sorts tables so that parents come first,
+// breaking ties by name. Tables caught in a cycle are appended in name order and
+// returned as cycle.
+func orderByDependencies(tables []string, parents map[string][]string) (order, cycle []string) {
+	in := map[string]bool{}
+	for _, t := range tables {
+		in[t] = true
+	}
+	pending := map[string]int{}
+	children := map[string][]string{}
+	for _, t := range tables {
+		seen := map[string]bool{}
+		for _, p := range parents[t] {
+			if in[p] && p != t && !seen[p] {
+				seen[p] = true
+				pending[t]++
+				children[p] = append(children[p], t)
+			}
+		}
+	}
+	var ready []string
+	for _, t := range tables {
+		if pending[t] == 0 {
+			ready = append(ready, t)
+		}
+	}
+	done := map[string]bool{}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		t := ready[0]
+		ready = ready[1:]
+		order = append(order, t)
+		done[t] = true
+		for _, c := range children[t] {
+			pending[c]--
+			if pending[c] == 0 {
+				ready = append(ready, c)
+			}
+		}
+	}
+	for _, t := range tables {
+		if !done[t] {
+			cycle = append(cycle, t)
+		}
+	}
+	sort.Strings(cycle)
+	return append(order, cycle...), cycle
+}
+
+// ApplyRestore executes a plan in a single transaction, in plan order. Only columns
+// present both in the backup and in the live table are written; other columns take
+// their defaults.
+func ApplyRestore(ctx context.Context, db *pgxpool.Pool, plan *RestorePlan, schema string) ([]EntityStats, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, dbutil.ErrWrap("snapshot.restore.begin", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if plan.Mode == ModeReplace && len(plan.Steps) > 0 {
+		tables := make([]string, 0, len(plan.Steps))
+		for _, s := range plan.Steps {
+			tables = append(tables, pgx.Identifier{"public", s.Table}.Sanitize())
+		}
+		if _, err := tx.Exec(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+			return nil, dbutil.ErrWrap("snapshot.restore.truncate", err, fmt.Sprintf("tables=%d", len(tables)))
+		}
+	}
+
+	var stats []EntityStats
+	for _, step := range plan.Steps {
+		started := time.Now()
+		e := step.config
+		cols, err := restoreColumns(ctx, tx, schema, plan.BackupID, e)
+		if err != nil {
+			return nil, err
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		onConflict, err := conflictClause(plan.Mode, e, cols)
+		if err != nil {
+			return nil, err
+		}
+		n, err := pgdao.RestoreEntityRecords(ctx, tx, schema, plan.BackupID, e.EntityName, e.TableName, cols, step.filter, onConflict)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, EntityStats{Entity: e.EntityName, Records: n, Duration: time.Since(started)})
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, dbutil.ErrWrap("snapshot.restore.commit", err, dbutil.ParamSummary("id", plan.BackupID))
+	}
+	return stats, nil
+}
+
+// restoreColumns returns the live columns, in table order, that the backup captured.
+func restoreColumns(ctx context.Context, db pgdao.Querier, schema, backupID string, e BackupEntityConfig) ([]string, error) {
+	liveCols, err := fetchTableColumns(ctx, db, "public", e.TableName)
+	if err != nil {
+		return nil, dbutil.ErrWrap("snapshot.restore.fetch_columns", err, dbutil.ParamSummary("table", e.TableName))
+	}
+	fields, err := pgdao.ListEntityFields(ctx, db, schema, backupID, e.EntityName)
+	if err != nil {
+		return nil, err
+	}
+	captured := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		captured[f.Name] = true
+	}
+	cols := make([]string, 0, len(liveCols))
+	for _, c := range liveCols {
+		if captured[c.ColumnName] && !c.Generated {
+			cols = append(cols, c.ColumnName)
+		}
+	}
+	return cols, nil
+}
+
+// conflictClause returns the ON CONFLICT clause for a mode. Merge only rewrites
+// rows whose restored columns differ, so unchanged rows are not counted.
+func conflictClause(mode string, e BackupEntityConfig, cols []string) (string, error) {
+	if mode == ModeReplace {
+		return "", nil
+	}
+	if len(e.PKColumns) == 0 {
+		return "", fmt.Errorf("%s mode requires PKColumns for %s", mode, e.EntityName)
+	}
+	pk := map[string]bool{}
+	var pkid []string
+	for _, p := range e.PKColumns {
+		pk[p] = true
+		pkid = append(pkid, pgx.Identifier{p}.Sanitize())
+	}
+	target := strings.Join(pkid, ", ")
+	var set, cur, exc []string
+	for _, c := range cols {
+		if pk[c] {
+			continue
+		}
+		id := pgx.Identifier{c}.Sanitize()
+		set = append(set, id+" = EXCLUDED."+id)
+		cur = append(cur, "cur."+id)
+		exc = append(exc, "EXCLUDED."+id)
+	}
+	if mode == ModeAppend || len(set) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", target), nil
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s WHERE (%s) IS DISTINCT FROM (%s)",
+		target, strings.Join(set, ", "), strings.Join(cur, ", "), strings.Join(exc, ", ")), nil
+}
@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderByDependencies_ParentsFirst(t *testing.T) {
+	parents := map[string][]string{
+		"tasks":         {"task_variants", "roles"},
+		"task_variants": {"workflows"},
+		"workflows":     {"roles"},
+		"stickies":      {"blackboards"},
+		"blackboards":   {"roles", "projects"},
+	}
+	order, cycle := orderByDependencies([]string{"tasks", "stickies", "workflows", "task_variants", "blackboards", "roles"}, parents)
+	want := []string{"roles", "blackboards", "stickies", "workflows", "task_variants", "tasks"}
+	if !reflect.DeepEqual(order, want) || len(cycle) != 0 {
+		t.Fatalf("order=%v cycle=%v, want %v", order, cycle, want)
+	}
+}
+
+func TestOrderByDependencies_ReportsCycle(t *testing.T) {
+	order, cycle := orderByDependencies([]string{"a", "b", "c"}, map[string][]string{"a": {"b"}, "b": {"a"}})
+	if !reflect.DeepEqual(order, []string{"c", "a", "b"}) || !reflect.DeepEqual(cycle, []string{"a", "b"}) {
+		t.Fatalf("order=%v cycle=%v", order, cycle)
+	}
+}
+
+func TestCascadeTables_FollowsReferencingTables(t *testing.T) {
+	parents := map[string][]string{
+		"stickies":          {"blackboards"},
+		"stickie_relations": {"stickies"},
+		"blackboards":       {"roles"},
+	}
+	got := cascadeTables([]string{"blackboards"}, parents)
+	if !reflect.DeepEqual(got, []string{"stickie_relations", "stickies"}) {
+		t.Fatalf("cascade=%v", got)
+	}
+	if got := cascadeTables([]string{"blackboards", "stickies", "stickie_relations"}, parents); len(got) != 0 {
+		t.Fatalf("closed set cascades to %v", got)
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	fs, err := ParseFilters([]string{"id=42", "stickies.blackboard_id=abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Filter{{Field: "id", Value: "42"}, {Entity: "stickies", Field: "blackboard_id", Value: "abc"}}
+	if !reflect.DeepEqual(fs, want) {
+		t.Fatalf("got %+v", fs)
+	}
+	if _, err := ParseFilters([]string{"nokey"}); err == nil {
+		t.Fatal("expected error for missing '='")
+	}
+}
+
+func TestConflictClause_MergeUpdatesChangedRowsOnly(t *testing.T) {
+	e := BackupEntityConfig{EntityName: "tags", PKColumns: []string{"name"}}
+	got, err := conflictClause(ModeMerge, e, []string{"name", "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `DO UPDATE SET "title" = EXCLUDED."title"`) || !strings.Contains(got, "IS DISTINCT FROM") {
+		t.Fatalf("unexpected clause: %s", got)
+	}
+	got, _ = conflictClause(ModeAppend, e, []string{"name", "title"})
+	if got != `ON CONFLICT ("name") DO NOTHING` {
+		t.Fatalf("unexpected append clause: %s", got)
+	}
+}
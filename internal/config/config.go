@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flarebyte/baldrick-rebec/internal/paths"
 	"gopkg.in/yaml.v3"
@@ -19,10 +22,12 @@ type ServerConfig struct {
 }
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Postgres PostgresConfig `yaml:"postgres"`
-	Graph    GraphConfig    `yaml:"graph"`
-	Vault    VaultConfig    `yaml:"vault"`
+	Server    ServerConfig    `yaml:"server"`
+	Postgres  PostgresConfig  `yaml:"postgres"`
+	Graph     GraphConfig     `yaml:"graph"`
+	Vault     VaultConfig     `yaml:"vault"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Messages  MessagesConfig  `yaml:"messages"`
 }
 
 func defaults() Config {
@@ -30,8 +35,9 @@ func defaults() Config {
 		Server: ServerConfig{Port: DefaultServerPort},
 		Postgres: PostgresConfig{Host: "127.0.0.1", Port: 5432, DBName: "rbc", SSLMode: "disable",
 			Admin: PGRole{User: "rbc_admin"}, App: PGRole{User: "rbc_app"}, Backup: PGRole{}},
-		Graph: GraphConfig{AllowFallback: false},
-		Vault: VaultConfig{Backend: "keychain"},
+		Graph:    GraphConfig{AllowFallback: false},
+		Vault:    VaultConfig{Backend: "keychain"},
+		Messages: MessagesConfig{Retention: MessageRetentionConfig{OlderThan: "30d", KeepTagged: []string{"pin"}, BatchSize: 500}},
 	}
 }
 
@@ -98,9 +104,73 @@ func Load() (Config, error) {
 	if fileCfg.Vault.Backend != "" {
 		cfg.Vault.Backend = fileCfg.Vault.Backend
 	}
+	// Scheduler jobs have no defaults; take them as written.
+	cfg.Scheduler = fileCfg.Scheduler
+	// Message retention overrides
+	if r := fileCfg.Messages.Retention; r.OlderThan != "" {
+		if _, err := ParseAge(r.OlderThan); err != nil {
+			return cfg, fmt.Errorf("messages.retention.older_than: %w", err)
+		}
+		cfg.Messages.Retention.OlderThan = r.OlderThan
+	}
+	if fileCfg.Messages.Retention.KeepTagged != nil {
+		cfg.Messages.Retention.KeepTagged = fileCfg.Messages.Retention.KeepTagged
+	}
+	if fileCfg.Messages.Retention.BatchSize > 0 {
+		cfg.Messages.Retention.BatchSize = fileCfg.Messages.Retention.BatchSize
+	}
 	return cfg, nil
 }
 
+// MessagesConfig holds settings for messages and their content.
+type MessagesConfig struct {
+	Retention MessageRetentionConfig `yaml:"retention"`
+}
+
+// MessageRetentionConfig gives the defaults of `rbc message prune`, e.g.:
+//
+//	messages:
+//	  retention:
+//	    older_than: 30d
+//	    keep_tagged: [pin, keep]
+//	    batch_size: 500
+//
+// An explicit empty keep_tagged list keeps nothing back.
+type MessageRetentionConfig struct {
+	OlderThan  string   `yaml:"older_than"`
+	KeepTagged []string `yaml:"keep_tagged"`
+	BatchSize  int      `yaml:"batch_size"`
+}
+
+// ParseAge reads an age such as 30d, 2w, 6mo, 1y or a Go duration (36h).
+// Months count as 30 days and years as 365.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 30d, 2w, 6mo, 1y or 36h)", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	day := 24 * time.Hour
+	switch s[i:] {
+	case "d":
+		return time.Duration(n) * day, nil
+	case "w":
+		return time.Duration(n) * 7 * day, nil
+	case "mo":
+		return time.Duration(n) * 30 * day, nil
+	case "y":
+		return time.Duration(n) * 365 * day, nil
+	}
+	return 0, fmt.Errorf("invalid age %q (units: d, w, mo, y)", s)
+}
+
 type PostgresConfig struct {
 	Host    string `yaml:"host"`
 	Port    int    `yaml:"port"`
@@ -127,3 +197,58 @@ type GraphConfig struct {
 type VaultConfig struct {
 	Backend string `yaml:"backend"`
 }
+
+// SchedulerConfig lists jobs run by the server's scheduler.
+type SchedulerConfig struct {
+	Snapshots    []SnapshotJobConfig     `yaml:"snapshots"`
+	BlackboardGC []BlackboardGCJobConfig `yaml:"blackboard_gc"`
+}
+
+// SnapshotJobConfig describes a recurring snapshot backup, e.g.:
+//
+//	scheduler:
+//	  snapshots:
+//	    - name: nightly
+//	      cron: "0 2 * * *"
+//	      exclude: [stickies]
+//	      retention: {daily: 7, weekly: 4, monthly: 12}
+type SnapshotJobConfig struct {
+	Name        string   `yaml:"name"`
+	Cron        string   `yaml:"cron"` // 5-field cron expression or @hourly/@daily/@weekly/@monthly
+	Schema      string   `yaml:"schema,omitempty"`
+	Include     []string `yaml:"include,omitempty"`
+	Exclude     []string `yaml:"exclude,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	// Role is recorded on the outcome messages (default "user").
+	Role      string          `yaml:"role,omitempty"`
+	Retention RetentionConfig `yaml:"retention,omitempty"`
+}
+
+// RetentionConfig is a grandfather-father-son policy: keep the newest backup of
+// each of the last Daily days, Weekly ISO weeks and Monthly months. All zero keeps everything.
+type RetentionConfig struct {
+	Daily   int `yaml:"daily,omitempty"`
+	Weekly  int `yaml:"weekly,omitempty"`
+	Monthly int `yaml:"monthly,omitempty"`
+}
+
+// BlackboardGCJobConfig runs `rbc blackboard gc` on a schedule, e.g.:
+//
+//	scheduler:
+//	  blackboard_gc:
+//	    - name: scratch-cleanup
+//	      cron: "@daily"
+//	      action: archive
+//	      rollup: true
+type BlackboardGCJobConfig struct {
+	Name       string `yaml:"name"`
+	Cron       string `yaml:"cron"`
+	Action     string `yaml:"action,omitempty"` // archive (default) or delete
+	BoardRole  string `yaml:"board_role,omitempty"`
+	Snapshot   bool   `yaml:"snapshot,omitempty"`
+	Schema     string `yaml:"schema,omitempty"` // backup schema for snapshot
+	Rollup     bool   `yaml:"rollup,omitempty"`
+	RollupInto string `yaml:"rollup_into,omitempty"`
+	// Role is recorded on the outcome messages (default "user").
+	Role string `yaml:"role,omitempty"`
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	day := 24 * time.Hour
+	cases := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"30d", 30 * day, true},
+		{"2w", 14 * day, true},
+		{"6mo", 180 * day, true},
+		{"1y", 365 * day, true},
+		{" 3D ", 3 * day, true},
+		{"36h", 36 * time.Hour, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"0d", 0, false},
+		{"0h", 0, false},
+		{"", 0, false},
+		{"d", 0, false},
+		{"10x", 0, false},
+		{"-3d", 0, false},
+		{"soon", 0, false},
+	}
+	for _, c := range cases {
+		got, err := ParseAge(c.in)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v ok=%v", c.in, got, err, c.want, c.ok)
+		}
+	}
+}
@@ -2,14 +2,37 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"strconv"
 )
 
+// Querier is satisfied by both *pgxpool.Pool and pgx.Tx so that snapshot writes
+// can run inside a single transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// EntityField describes one captured column of an entity (a row of entity_schema).
+type EntityField struct {
+	Name         string  `json:"field_name"`
+	Type         string  `json:"field_type"`
+	Nullable     bool    `json:"is_nullable"`
+	DefaultValue *string `json:"default_value,omitempty"`
+}
+
 // Backup represents a row in backup.backups.
 type Backup struct {
 	ID             string         `json:"id"`
@@ -21,7 +44,7 @@ type Backup struct {
 }
 
 // InsertBackup inserts a new backup entry and returns its ID.
-func InsertBackup(ctx context.Context, db *pgxpool.Pool, schema string, description *string, tags map[string]any, initiatedBy *string, retention *time.Time) (string, error) {
+func InsertBackup(ctx context.Context, db Querier, schema string, description *string, tags map[string]any, initiatedBy *string, retention *time.Time) (string, error) {
 	if schema == "" {
 		schema = "backup"
 	}
@@ -39,36 +62,293 @@ func InsertBackup(ctx context.Context, db *pgxpool.Pool, schema string, descript
 	return id, nil
 }
 
-// InsertEntitySchema inserts one entity schema row.
-func InsertEntitySchema(ctx context.Context, db *pgxpool.Pool, schema string, backupID string, entityName string, fieldName string, fieldType string, isNullable bool, defaultValue *string, metadata any) error {
+// InsertEntitySchemaBatch inserts all field rows of one entity in a single round trip.
+func InsertEntitySchemaBatch(ctx context.Context, db Querier, schema, backupID, entityName string, fields []EntityField) error {
 	if schema == "" {
 		schema = "backup"
 	}
-	q := `INSERT INTO ` + schema + `.entity_schema (backup_id, entity_name, field_name, field_type, is_nullable, default_value, metadata)
-          VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := db.Exec(ctx, q, backupID, entityName, fieldName, fieldType, isNullable, defaultValue, metadata)
-	return dbutil.ErrWrap("backup.entity_schema.insert", err,
-		dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName), dbutil.ParamSummary("field", fieldName))
+	q := `INSERT INTO ` + schema + `.entity_schema (backup_id, entity_name, field_name, field_type, is_nullable, default_value)
+          VALUES ($1, $2, $3, $4, $5, $6)`
+	b := &pgx.Batch{}
+	for _, f := range fields {
+		b.Queue(q, backupID, entityName, f.Name, f.Type, f.Nullable, f.DefaultValue)
+	}
+	err := db.SendBatch(ctx, b).Close()
+	return dbutil.ErrWrap("backup.entity_schema.insert_batch", err,
+		dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName), fmt.Sprintf("fields=%d", len(fields)))
 }
 
-// InsertEntityRecord inserts one entity record snapshot.
-func InsertEntityRecord(ctx context.Context, db *pgxpool.Pool, schema string, backupID string, entityName string, recordPK []byte, record []byte, roleName *string) error {
+// CaptureEntityRecords copies every row of a live table into entity_records with a
+// single INSERT ... SELECT, so records never leave the database server.
+// It returns the number of captured rows.
+func CaptureEntityRecords(ctx context.Context, db Querier, schema, backupID, entityName, table string, pkColumns []string, hasRole bool) (int64, error) {
 	if schema == "" {
 		schema = "backup"
 	}
-	q := `INSERT INTO ` + schema + `.entity_records (backup_id, entity_name, record_pk, record, role_name)
-          VALUES ($1, $2, $3::jsonb, $4::jsonb, $5)`
-	// Cast parameters to text for ::jsonb
-	var pkS, recS string
-	if recordPK != nil {
-		pkS = string(recordPK)
+	role := "NULL"
+	if hasRole {
+		role = "t.role_name"
+	}
+	q := fmt.Sprintf(`INSERT INTO %s (backup_id, entity_name, record_pk, record, role_name)
+          SELECT $1, $2, %s, to_jsonb(t), %s FROM %s AS t`,
+		pgx.Identifier{schema, "entity_records"}.Sanitize(), pkObjectSQL(pkColumns), role, pgx.Identifier{"public", table}.Sanitize())
+	ct, err := db.Exec(ctx, q, backupID, entityName)
+	if err != nil {
+		return 0, dbutil.ErrWrap("backup.entity_records.capture", err,
+			dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName), dbutil.ParamSummary("table", table))
+	}
+	return ct.RowsAffected(), nil
+}
+
+// ListEntityFields returns the captured columns of an entity in a backup.
+func ListEntityFields(ctx context.Context, db Querier, schema, backupID, entityName string) ([]EntityField, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	rows, err := db.Query(ctx, `SELECT field_name, field_type, is_nullable, default_value FROM `+schema+`.entity_schema
+          WHERE backup_id=$1 AND entity_name=$2 ORDER BY field_name`, backupID, entityName)
+	if err != nil {
+		return nil, dbutil.ErrWrap("backup.entity_schema.list", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName))
+	}
+	defer rows.Close()
+	var out []EntityField
+	for rows.Next() {
+		var f EntityField
+		if err := rows.Scan(&f.Name, &f.Type, &f.Nullable, &f.DefaultValue); err != nil {
+			return nil, dbutil.ErrWrap("backup.entity_schema.list.scan", err)
+		}
+		out = append(out, f)
 	}
-	if record != nil {
-		recS = string(record)
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("backup.entity_schema.list", err, dbutil.ParamSummary("entity", entityName))
 	}
-	_, err := db.Exec(ctx, q, backupID, entityName, pkS, recS, roleName)
-	return dbutil.ErrWrap("backup.entity_record.insert", err,
-		dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName), dbutil.ParamSummary("role", roleName))
+	return out, nil
+}
+
+// RecordFilter narrows the records of a backup entity. Fields compare the text
+// value of top-level record fields (record->>field = value); all must match.
+type RecordFilter struct {
+	RoleName *string
+	Fields   map[string]string
+}
+
+// sql returns the extra WHERE conditions over alias er, numbering parameters from next.
+func (f RecordFilter) sql(next int) (string, []any) {
+	var conds []string
+	var args []any
+	if f.RoleName != nil {
+		conds = append(conds, fmt.Sprintf("er.role_name = $%d", next))
+		args = append(args, *f.RoleName)
+		next++
+	}
+	keys := make([]string, 0, len(f.Fields))
+	for k := range f.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conds = append(conds, fmt.Sprintf("er.record->>$%d = $%d", next, next+1))
+		args = append(args, k, f.Fields[k])
+		next += 2
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conds, " AND "), args
+}
+
+// CountFilteredEntityRecords counts the records of an entity matching filter.
+func CountFilteredEntityRecords(ctx context.Context, db Querier, schema, backupID, entityName string, filter RecordFilter) (int64, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	where, args := filter.sql(3)
+	var n int64
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM `+pgx.Identifier{schema, "entity_records"}.Sanitize()+` AS er
+          WHERE er.backup_id=$1 AND er.entity_name=$2`+where, append([]any{backupID, entityName}, args...)...).Scan(&n)
+	if err != nil {
+		return 0, dbutil.ErrWrap("backup.entity.count_filtered", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName))
+	}
+	return n, nil
+}
+
+// RestoreEntityRecords writes the records of one backup entity matching filter into
+// its live table with a single INSERT ... SELECT over jsonb_populate_record. Only the
+// given columns are written. onConflict is appended verbatim and may refer to the
+// live row as "cur" (e.g. "ON CONFLICT (id) DO NOTHING"). It returns the number of
+// inserted or updated rows.
+func RestoreEntityRecords(ctx context.Context, db Querier, schema, backupID, entityName, table string, columns []string, filter RecordFilter, onConflict string) (int64, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	ids := make([]string, 0, len(columns))
+	sel := make([]string, 0, len(columns))
+	for _, c := range columns {
+		id := pgx.Identifier{c}.Sanitize()
+		ids = append(ids, id)
+		sel = append(sel, "r."+id)
+	}
+	live := pgx.Identifier{"public", table}.Sanitize()
+	where, args := filter.sql(3)
+	q := fmt.Sprintf(`INSERT INTO %s AS cur (%s)
+          SELECT %s FROM %s AS er, jsonb_populate_record(NULL::%s, er.record) AS r
+          WHERE er.backup_id=$1 AND er.entity_name=$2%s %s`,
+		live, strings.Join(ids, ", "), strings.Join(sel, ", "),
+		pgx.Identifier{schema, "entity_records"}.Sanitize(), live, where, onConflict)
+	ct, err := db.Exec(ctx, q, append([]any{backupID, entityName}, args...)...)
+	if err != nil {
+		return 0, dbutil.ErrWrap("backup.entity_records.restore", err,
+			dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName), dbutil.ParamSummary("table", table))
+	}
+	return ct.RowsAffected(), nil
+}
+
+// ForeignKey is a child -> parent table reference from pg_constraint.
+type ForeignKey struct {
+	Table    string
+	RefTable string
+}
+
+// ListForeignKeys returns the foreign keys between tables of a schema.
+func ListForeignKeys(ctx context.Context, db Querier, schema string) ([]ForeignKey, error) {
+	rows, err := db.Query(ctx, `SELECT DISTINCT cl.relname, rf.relname
+          FROM pg_constraint c
+          JOIN pg_class cl ON cl.oid = c.conrelid
+          JOIN pg_class rf ON rf.oid = c.confrelid
+          JOIN pg_namespace n ON n.oid = cl.relnamespace
+          WHERE c.contype = 'f' AND n.nspname = $1
+          ORDER BY 1, 2`, schema)
+	if err != nil {
+		return nil, dbutil.ErrWrap("schema.foreign_keys", err, dbutil.ParamSummary("schema", schema))
+	}
+	defer rows.Close()
+	var out []ForeignKey
+	for rows.Next() {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.Table, &fk.RefTable); err != nil {
+			return nil, dbutil.ErrWrap("schema.foreign_keys.scan", err)
+		}
+		out = append(out, fk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("schema.foreign_keys", err, dbutil.ParamSummary("schema", schema))
+	}
+	return out, nil
+}
+
+// EntityRecord is one captured row of entity_records.
+type EntityRecord struct {
+	PK       json.RawMessage `json:"pk"`
+	Record   json.RawMessage `json:"record"`
+	RoleName *string         `json:"role_name,omitempty"`
+}
+
+// GetBackup returns a backup by id; an unknown id fails with a wrapped
+// pgx.ErrNoRows.
+func GetBackup(ctx context.Context, db Querier, schema, id string) (*Backup, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	var b Backup
+	err := db.QueryRow(ctx, `SELECT id, created_at, description, tags, initiated_by, retention_until FROM `+schema+`.backups WHERE id=$1`, id).
+		Scan(&b.ID, &b.CreatedAt, &b.Description, &b.Tags, &b.InitiatedBy, &b.RetentionUntil)
+	if err != nil {
+		return nil, dbutil.ErrWrap("backup.get", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("id", id))
+	}
+	return &b, nil
+}
+
+// BackupExists reports whether a backup id is present.
+func BackupExists(ctx context.Context, db Querier, schema, id string) (bool, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	var ok bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM `+schema+`.backups WHERE id=$1)`, id).Scan(&ok); err != nil {
+		return false, dbutil.ErrWrap("backup.exists", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("id", id))
+	}
+	return ok, nil
+}
+
+// InsertBackupRow inserts a backup keeping its id and created_at (used by archive imports).
+func InsertBackupRow(ctx context.Context, db Querier, schema string, b Backup) error {
+	if schema == "" {
+		schema = "backup"
+	}
+	tags := b.Tags
+	if tags == nil {
+		tags = map[string]any{}
+	}
+	q := `INSERT INTO ` + schema + `.backups (id, created_at, description, tags, initiated_by, retention_until)
+          VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := db.Exec(ctx, q, b.ID, b.CreatedAt, b.Description, tags, b.InitiatedBy, b.RetentionUntil)
+	return dbutil.ErrWrap("backup.insert_row", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("id", b.ID))
+}
+
+// EachEntityRecord streams the records of one entity in primary-key order.
+func EachEntityRecord(ctx context.Context, db Querier, schema, backupID, entityName string, fn func(EntityRecord) error) error {
+	if schema == "" {
+		schema = "backup"
+	}
+	rows, err := db.Query(ctx, `SELECT record_pk, record, role_name FROM `+schema+`.entity_records
+          WHERE backup_id=$1 AND entity_name=$2 ORDER BY record_pk::text, id`, backupID, entityName)
+	if err != nil {
+		return dbutil.ErrWrap("backup.entity_records.read", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pk, rec []byte
+		var r EntityRecord
+		if err := rows.Scan(&pk, &rec, &r.RoleName); err != nil {
+			return dbutil.ErrWrap("backup.entity_records.read.scan", err, dbutil.ParamSummary("entity", entityName))
+		}
+		r.PK, r.Record = pk, rec
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dbutil.ErrWrap("backup.entity_records.read", err, dbutil.ParamSummary("entity", entityName))
+	}
+	return nil
+}
+
+// EachLiveRecord streams a live table as (primary key, row) JSON pairs, shaped like
+// the record_pk/record columns of entity_records.
+func EachLiveRecord(ctx context.Context, db Querier, table string, pkColumns []string, fn func(pk, record []byte) error) error {
+	q := fmt.Sprintf("SELECT %s, to_jsonb(t) FROM %s AS t", pkObjectSQL(pkColumns), pgx.Identifier{"public", table}.Sanitize())
+	rows, err := db.Query(ctx, q)
+	if err != nil {
+		return dbutil.ErrWrap("table.records", err, dbutil.ParamSummary("table", table))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pk, rec []byte
+		if err := rows.Scan(&pk, &rec); err != nil {
+			return dbutil.ErrWrap("table.records.scan", err, dbutil.ParamSummary("table", table))
+		}
+		if err := fn(pk, rec); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dbutil.ErrWrap("table.records", err, dbutil.ParamSummary("table", table))
+	}
+	return nil
+}
+
+// CopyEntityRecords bulk-loads records of one entity with COPY. src yields rows of
+// (backup_id, entity_name, record_pk, record, role_name).
+func CopyEntityRecords(ctx context.Context, db Querier, schema, entityName string, src pgx.CopyFromSource) (int64, error) {
+	if schema == "" {
+		schema = "backup"
+	}
+	n, err := db.CopyFrom(ctx, pgx.Identifier{schema, "entity_records"},
+		[]string{"backup_id", "entity_name", "record_pk", "record", "role_name"}, src)
+	if err != nil {
+		return n, dbutil.ErrWrap("backup.entity_records.copy", err, dbutil.ParamSummary("schema", schema), dbutil.ParamSummary("entity", entityName))
+	}
+	return n, nil
 }
 
 // ListBackups returns backups filtered by time range and limited.
@@ -126,7 +406,7 @@ func DeleteBackup(ctx context.Context, db *pgxpool.Pool, schema, id string) (int
 }
 
 // CountPerEntity returns record counts per entity for a backup.
-func CountPerEntity(ctx context.Context, db *pgxpool.Pool, schema, backupID string) (map[string]int64, error) {
+func CountPerEntity(ctx context.Context, db Querier, schema, backupID string) (map[string]int64, error) {
 	if schema == "" {
 		schema = "backup"
 	}
@@ -151,7 +431,7 @@ func CountPerEntity(ctx context.Context, db *pgxpool.Pool, schema, backupID stri
 }
 
 // ListBackupEntities returns the distinct entity names present for a backup.
-func ListBackupEntities(ctx context.Context, db *pgxpool.Pool, schema, backupID string) ([]string, error) {
+func ListBackupEntities(ctx context.Context, db Querier, schema, backupID string) ([]string, error) {
 	if schema == "" {
 		schema = "backup"
 	}
@@ -175,7 +455,7 @@ func ListBackupEntities(ctx context.Context, db *pgxpool.Pool, schema, backupID
 }
 
 // CountBackupEntityRecords returns the number of records for an entity in a backup.
-func CountBackupEntityRecords(ctx context.Context, db *pgxpool.Pool, schema, backupID, entity string) (int64, error) {
+func CountBackupEntityRecords(ctx context.Context, db Querier, schema, backupID, entity string) (int64, error) {
 	if schema == "" {
 		schema = "backup"
 	}
@@ -223,5 +503,14 @@ func CountBackupsOlderThan(ctx context.Context, db *pgxpool.Pool, schema string,
 	return n, nil
 }
 
+// pkObjectSQL builds jsonb_build_object('k1', t.k1, ...) over the primary key columns of alias t.
+func pkObjectSQL(pkColumns []string) string {
+	pairs := make([]string, 0, len(pkColumns))
+	for _, k := range pkColumns {
+		pairs = append(pairs, fmt.Sprintf("'%s', t.%s", strings.ReplaceAll(k, "'", "''"), pgx.Identifier{k}.Sanitize()))
+	}
+	return "jsonb_build_object(" + strings.Join(pairs, ", ") + ")"
+}
+
 // Helper: small int to string without fmt to avoid allocations here.
 func itoa(i int) string { return strconv.Itoa(i) }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// recordingQuerier captures the statements the set-based backup helpers send.
+type recordingQuerier struct {
+	Querier
+	sql   []string
+	args  [][]any
+	batch *pgx.Batch
+	tag   string
+}
+
+func (q *recordingQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.sql = append(q.sql, sql)
+	q.args = append(q.args, args)
+	return pgconn.NewCommandTag(q.tag), nil
+}
+
+func (q *recordingQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	q.batch = b
+	return closedBatch{}
+}
+
+type closedBatch struct{ pgx.BatchResults }
+
+func (closedBatch) Close() error { return nil }
+
+func TestCaptureEntityRecords_SingleInsertSelect(t *testing.T) {
+	q := &recordingQuerier{tag: "INSERT 0 3"}
+	n, err := CaptureEntityRecords(context.Background(), q, "", "b1", "tags", "tags", []string{"name"}, true)
+	if err != nil || n != 3 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if len(q.sql) != 1 {
+		t.Fatalf("expected one statement; got %d", len(q.sql))
+	}
+	for _, want := range []string{
+		`INSERT INTO "backup"."entity_records"`,
+		`jsonb_build_object('name', t."name")`,
+		"to_jsonb(t), t.role_name",
+		`FROM "public"."tags" AS t`,
+	} {
+		if !strings.Contains(q.sql[0], want) {
+			t.Fatalf("missing %q in:\n%s", want, q.sql[0])
+		}
+	}
+	if !reflect.DeepEqual(q.args[0], []any{"b1", "tags"}) {
+		t.Fatalf("args: %v", q.args[0])
+	}
+
+	q = &recordingQuerier{tag: "INSERT 0 0"}
+	if _, err := CaptureEntityRecords(context.Background(), q, "bk", "b1", "packages", "packages", []string{"role_name", "variant"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(q.sql[0], `to_jsonb(t), NULL`) || !strings.Contains(q.sql[0], `"bk"."entity_records"`) {
+		t.Fatalf("unexpected capture SQL:\n%s", q.sql[0])
+	}
+}
+
+func TestRestoreEntityRecords_FilteredInsertSelect(t *testing.T) {
+	q := &recordingQuerier{tag: "INSERT 0 2"}
+	role := "dev"
+	filter := RecordFilter{RoleName: &role, Fields: map[string]string{"name": "x", "id": "42"}}
+	n, err := RestoreEntityRecords(context.Background(), q, "", "b1", "tags", "tags", []string{"name", "title"}, filter, `ON CONFLICT ("name") DO NOTHING`)
+	if err != nil || n != 2 {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	got := q.sql[0]
+	for _, want := range []string{
+		`INSERT INTO "public"."tags" AS cur ("name", "title")`,
+		`SELECT r."name", r."title" FROM "backup"."entity_records" AS er, jsonb_populate_record(NULL::"public"."tags", er.record) AS r`,
+		"er.role_name = $3 AND er.record->>$4 = $5 AND er.record->>$6 = $7",
+		`ON CONFLICT ("name") DO NOTHING`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if want := []any{"b1", "tags", "dev", "id", "42", "name", "x"}; !reflect.DeepEqual(q.args[0], want) {
+		t.Fatalf("args: got %v want %v", q.args[0], want)
+	}
+}
+
+func TestInsertEntitySchemaBatch_OneRoundTrip(t *testing.T) {
+	q := &recordingQuerier{}
+	def := "now()"
+	fields := []EntityField{{Name: "id", Type: "uuid"}, {Name: "created", Type: "timestamptz", Nullable: true, DefaultValue: &def}}
+	if err := InsertEntitySchemaBatch(context.Background(), q, "", "b1", "tags", fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.sql) != 0 || q.batch == nil || q.batch.Len() != 2 {
+		t.Fatalf("expected one batch of 2 inserts; got exec=%d batch=%v", len(q.sql), q.batch)
+	}
+	if got := q.batch.QueuedQueries[1].Arguments; !reflect.DeepEqual(got, []any{"b1", "tags", "created", "timestamptz", true, &def}) {
+		t.Fatalf("args: %v", got)
+	}
+}
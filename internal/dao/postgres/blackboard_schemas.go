@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SchemaLabelAll is the label of a schema applying to every stickie of a board.
+const SchemaLabelAll = "*"
+
+// BlackboardSchema is a JSON Schema that stickie structured payloads of a
+// blackboard must satisfy, for all stickies (label "*") or those with Label.
+type BlackboardSchema struct {
+	BlackboardID string
+	Label        string
+	Schema       []byte
+	Description  sql.NullString
+	Created      sql.NullTime
+	Updated      sql.NullTime
+}
+
+// UpsertBlackboardSchema creates or replaces the schema of (blackboard, label).
+func UpsertBlackboardSchema(ctx context.Context, db *pgxpool.Pool, s *BlackboardSchema) error {
+	if s.Label == "" {
+		s.Label = SchemaLabelAll
+	}
+	q := `INSERT INTO blackboard_schemas (blackboard_id, label, schema, description)
+          VALUES ($1::uuid, $2, $3::jsonb, NULLIF($4,''))
+          ON CONFLICT (blackboard_id, label) DO UPDATE SET
+            schema = EXCLUDED.schema,
+            description = EXCLUDED.description,
+            updated = now()
+          RETURNING created, updated`
+	if err := db.QueryRow(ctx, q, s.BlackboardID, s.Label, s.Schema, stringOrEmpty(s.Description)).Scan(&s.Created, &s.Updated); err != nil {
+		return dbutil.ErrWrap("blackboard_schema.upsert", err, dbutil.ParamSummary("blackboard_id", s.BlackboardID), dbutil.ParamSummary("label", s.Label))
+	}
+	return nil
+}
+
+// ListBlackboardSchemas returns the schemas of a blackboard ordered by label.
+func ListBlackboardSchemas(ctx context.Context, db Querier, blackboardID string) ([]BlackboardSchema, error) {
+	rows, err := db.Query(ctx, `SELECT blackboard_id::text, label, schema, description, created, updated
+                                FROM blackboard_schemas WHERE blackboard_id=$1::uuid ORDER BY label`, blackboardID)
+	if err != nil {
+		return nil, dbutil.ErrWrap("blackboard_schema.list", err, dbutil.ParamSummary("blackboard_id", blackboardID))
+	}
+	defer rows.Close()
+	var out []BlackboardSchema
+	for rows.Next() {
+		var s BlackboardSchema
+		if err := rows.Scan(&s.BlackboardID, &s.Label, &s.Schema, &s.Description, &s.Created, &s.Updated); err != nil {
+			return nil, dbutil.ErrWrap("blackboard_schema.list.scan", err, dbutil.ParamSummary("blackboard_id", blackboardID))
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("blackboard_schema.list", err, dbutil.ParamSummary("blackboard_id", blackboardID))
+	}
+	return out, nil
+}
+
+// DeleteBlackboardSchema removes the schema of (blackboard, label).
+func DeleteBlackboardSchema(ctx context.Context, db *pgxpool.Pool, blackboardID, label string) (int64, error) {
+	ct, err := db.Exec(ctx, `DELETE FROM blackboard_schemas WHERE blackboard_id=$1::uuid AND label=$2`, blackboardID, label)
+	if err != nil {
+		return 0, dbutil.ErrWrap("blackboard_schema.delete", err, dbutil.ParamSummary("blackboard_id", blackboardID), dbutil.ParamSummary("label", label))
+	}
+	return ct.RowsAffected(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -21,6 +22,11 @@ type Blackboard struct {
 	Lifecycle      sql.NullString
 	Created        sql.NullTime
 	Updated        sql.NullTime
+	Archived       bool
+	ExpiresAt      sql.NullTime // derived from updated + lifecycle; NULL when permanent
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
 }
 
 // BlackboardWithRefs flattens blackboard plus selected display fields from joined tables.
@@ -37,6 +43,8 @@ type BlackboardWithRefs struct {
 	Lifecycle      sql.NullString
 	Created        sql.NullTime
 	Updated        sql.NullTime
+	Archived       bool
+	ExpiresAt      sql.NullTime
 
 	// Related display fields (all optional)
 	TaskVariant       sql.NullString // tasks.variant
@@ -60,11 +68,14 @@ func UpsertBlackboard(ctx context.Context, db *pgxpool.Pool, b *Blackboard) erro
                   updated=now()
               WHERE id=$1::uuid
               RETURNING created, updated`
-		if err := db.QueryRow(ctx, q,
-			b.ID, b.RoleName,
-			stringOrEmpty(b.ConversationID), stringOrEmpty(b.ProjectName), stringOrEmpty(b.TaskID),
-			stringOrEmpty(b.Background), stringOrEmpty(b.Guidelines), stringOrEmpty(b.Lifecycle),
-		).Scan(&b.Created, &b.Updated); err != nil {
+		check := &versionCheck{entity: "blackboard", key: b.ID, table: "blackboards", where: "id=$1::uuid", args: []any{b.ID}, p: b.IfMatch}
+		if err := withWrite(ctx, db, check, func(x Querier) error {
+			return x.QueryRow(ctx, q,
+				b.ID, b.RoleName,
+				stringOrEmpty(b.ConversationID), stringOrEmpty(b.ProjectName), stringOrEmpty(b.TaskID),
+				stringOrEmpty(b.Background), stringOrEmpty(b.Guidelines), stringOrEmpty(b.Lifecycle),
+			).Scan(&b.Created, &b.Updated)
+		}); err != nil {
 			return dbutil.ErrWrap("blackboard.upsert.update", err,
 				dbutil.ParamSummary("id", b.ID), dbutil.ParamSummary("role", b.RoleName))
 		}
@@ -84,26 +95,28 @@ func UpsertBlackboard(ctx context.Context, db *pgxpool.Pool, b *Blackboard) erro
 
 // GetBlackboardByID fetches a blackboard by UUID.
 func GetBlackboardByID(ctx context.Context, db *pgxpool.Pool, id string) (*Blackboard, error) {
-	q := `SELECT id::text, role_name, conversation_id::text, project_name, task_id::text, background, guidelines, lifecycle, created, updated
+	q := `SELECT id::text, role_name, conversation_id::text, project_name, task_id::text, background, guidelines, lifecycle, created, updated, archived, expires_at
           FROM blackboards WHERE id=$1::uuid`
 	var b Blackboard
-	if err := db.QueryRow(ctx, q, id).Scan(&b.ID, &b.RoleName, &b.ConversationID, &b.ProjectName, &b.TaskID, &b.Background, &b.Guidelines, &b.Lifecycle, &b.Created, &b.Updated); err != nil {
+	if err := db.QueryRow(ctx, q, id).Scan(&b.ID, &b.RoleName, &b.ConversationID, &b.ProjectName, &b.TaskID, &b.Background, &b.Guidelines, &b.Lifecycle, &b.Created, &b.Updated, &b.Archived, &b.ExpiresAt); err != nil {
 		return nil, dbutil.ErrWrap("blackboard.get", err, dbutil.ParamSummary("id", id))
 	}
 	return &b, nil
 }
 
-// ListBlackboards lists blackboards filtered by role.
-func ListBlackboards(ctx context.Context, db *pgxpool.Pool, roleName string, limit, offset int) ([]Blackboard, error) {
+// ListBlackboards lists blackboards filtered by role. Archived boards are skipped
+// unless includeArchived is set.
+func ListBlackboards(ctx context.Context, db *pgxpool.Pool, roleName string, includeArchived bool, limit, offset int) ([]Blackboard, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 	if offset < 0 {
 		offset = 0
 	}
-	q := `SELECT id::text, role_name, conversation_id::text, project_name, task_id::text, background, guidelines, lifecycle, created, updated
-          FROM blackboards WHERE role_name=$1 ORDER BY updated DESC, created DESC LIMIT $2 OFFSET $3`
-	rows, err := db.Query(ctx, q, roleName, limit, offset)
+	q := `SELECT id::text, role_name, conversation_id::text, project_name, task_id::text, background, guidelines, lifecycle, created, updated, archived, expires_at
+          FROM blackboards WHERE role_name=$1 AND ($4 OR NOT archived)
+          ORDER BY updated DESC, created DESC LIMIT $2 OFFSET $3`
+	rows, err := db.Query(ctx, q, roleName, limit, offset, includeArchived)
 	if err != nil {
 		return nil, dbutil.ErrWrap("blackboard.list", err,
 			dbutil.ParamSummary("role", roleName), fmt.Sprintf("limit=%d", limit), fmt.Sprintf("offset=%d", offset))
@@ -112,7 +125,7 @@ func ListBlackboards(ctx context.Context, db *pgxpool.Pool, roleName string, lim
 	var out []Blackboard
 	for rows.Next() {
 		var b Blackboard
-		if err := rows.Scan(&b.ID, &b.RoleName, &b.ConversationID, &b.ProjectName, &b.TaskID, &b.Background, &b.Guidelines, &b.Lifecycle, &b.Created, &b.Updated); err != nil {
+		if err := rows.Scan(&b.ID, &b.RoleName, &b.ConversationID, &b.ProjectName, &b.TaskID, &b.Background, &b.Guidelines, &b.Lifecycle, &b.Created, &b.Updated, &b.Archived, &b.ExpiresAt); err != nil {
 			return nil, dbutil.ErrWrap("blackboard.list.scan", err, dbutil.ParamSummary("role", roleName))
 		}
 		out = append(out, b)
@@ -137,7 +150,7 @@ func ListBlackboardsWithRefs(ctx context.Context, db *pgxpool.Pool, roleName str
 	base := `SELECT 
             b.id::text, b.role_name,
             b.conversation_id::text, b.project_name, b.task_id::text,
-            b.background, b.guidelines, b.lifecycle, b.created, b.updated,
+            b.background, b.guidelines, b.lifecycle, b.created, b.updated, b.archived, b.expires_at,
             t.variant, t.title,
             p.description, p.notes,
             c.title
@@ -173,7 +186,7 @@ func ListBlackboardsWithRefs(ctx context.Context, db *pgxpool.Pool, roleName str
 		if err := rows.Scan(
 			&r.ID, &r.RoleName,
 			&r.ConversationID, &r.ProjectName, &r.TaskID,
-			&r.Background, &r.Guidelines, &r.Lifecycle, &r.Created, &r.Updated,
+			&r.Background, &r.Guidelines, &r.Lifecycle, &r.Created, &r.Updated, &r.Archived, &r.ExpiresAt,
 			&r.TaskVariant, &r.TaskTitle,
 			&r.ProjectDesc, &r.ProjectNotes,
 			&r.ConversationTitle,
@@ -189,10 +202,48 @@ func ListBlackboardsWithRefs(ctx context.Context, db *pgxpool.Pool, roleName str
 }
 
 // DeleteBlackboard deletes by id.
-func DeleteBlackboard(ctx context.Context, db *pgxpool.Pool, id string) (int64, error) {
+func DeleteBlackboard(ctx context.Context, db Querier, id string) (int64, error) {
 	ct, err := db.Exec(ctx, `DELETE FROM blackboards WHERE id=$1::uuid`, id)
 	if err != nil {
 		return 0, dbutil.ErrWrap("blackboard.delete", err, dbutil.ParamSummary("id", id))
 	}
 	return ct.RowsAffected(), nil
 }
+
+// ListExpiredBlackboards returns unarchived boards whose expires_at is at or
+// before asOf, oldest expiry first. An empty roleName matches every role.
+func ListExpiredBlackboards(ctx context.Context, db *pgxpool.Pool, roleName string, asOf time.Time, limit int) ([]Blackboard, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+	q := `SELECT id::text, role_name, conversation_id::text, project_name, task_id::text, background, guidelines, lifecycle, created, updated, archived, expires_at
+          FROM blackboards
+          WHERE NOT archived AND expires_at <= $1 AND ($2 = '' OR role_name = $2)
+          ORDER BY expires_at, id LIMIT $3`
+	rows, err := db.Query(ctx, q, asOf, roleName, limit)
+	if err != nil {
+		return nil, dbutil.ErrWrap("blackboard.list_expired", err, dbutil.ParamSummary("role", roleName))
+	}
+	defer rows.Close()
+	var out []Blackboard
+	for rows.Next() {
+		var b Blackboard
+		if err := rows.Scan(&b.ID, &b.RoleName, &b.ConversationID, &b.ProjectName, &b.TaskID, &b.Background, &b.Guidelines, &b.Lifecycle, &b.Created, &b.Updated, &b.Archived, &b.ExpiresAt); err != nil {
+			return nil, dbutil.ErrWrap("blackboard.list_expired.scan", err, dbutil.ParamSummary("role", roleName))
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("blackboard.list_expired", err, dbutil.ParamSummary("role", roleName))
+	}
+	return out, nil
+}
+
+// SetBlackboardArchived flags or unflags a board as archived.
+func SetBlackboardArchived(ctx context.Context, db Querier, id string, archived bool) (int64, error) {
+	ct, err := db.Exec(ctx, `UPDATE blackboards SET archived=$2 WHERE id=$1::uuid`, id, archived)
+	if err != nil {
+		return 0, dbutil.ErrWrap("blackboard.set_archived", err, dbutil.ParamSummary("id", id))
+	}
+	return ct.RowsAffected(), nil
+}
@@ -20,7 +20,7 @@ type ContentRecord struct {
 }
 
 // InsertContent inserts a new content row and returns its numeric id.
-func InsertContent(ctx context.Context, db *pgxpool.Pool, text string, jsonPayload []byte) (string, error) {
+func InsertContent(ctx context.Context, db Querier, text string, jsonPayload []byte) (string, error) {
 	if strings.TrimSpace(text) == "" {
 		return "", errors.New("empty content")
 	}
@@ -21,6 +21,9 @@ type Conversation struct {
 	Tags        map[string]any
 	Created     sql.NullTime
 	Updated     sql.NullTime
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertConversation inserts a new conversation if ID==0, otherwise updates the existing one.
@@ -29,12 +32,20 @@ func UpsertConversation(ctx context.Context, db *pgxpool.Pool, c *Conversation)
 		q := `UPDATE conversations
               SET title=$2, description=NULLIF($3,''), project=NULLIF($4,''), role_name=$5, tags=$6::text[], notes=NULLIF($7,''), updated=now()
               WHERE id=$1::uuid RETURNING created, updated`
-		if err := db.QueryRow(ctx, q, c.ID, c.Title, stringOrEmpty(c.Description), stringOrEmpty(c.Project), c.RoleName, c.Tags, stringOrEmpty(c.Notes)).Scan(&c.Created, &c.Updated); err != nil {
+		check := &versionCheck{entity: "conversation", key: c.ID, table: "conversations", where: "id=$1::uuid", args: []any{c.ID}, p: c.IfMatch}
+		if err := withWrite(ctx, db, check, func(x Querier) error {
+			return x.QueryRow(ctx, q, c.ID, c.Title, stringOrEmpty(c.Description), stringOrEmpty(c.Project), c.RoleName, c.Tags, stringOrEmpty(c.Notes)).Scan(&c.Created, &c.Updated)
+		}); err != nil {
 			return dbutil.ErrWrap("conversation.upsert.update", err,
 				dbutil.ParamSummary("id", c.ID), dbutil.ParamSummary("title", c.Title))
 		}
 		return nil
 	}
+	return InsertConversation(ctx, db, c)
+}
+
+// InsertConversation creates c and fills in its id and timestamps.
+func InsertConversation(ctx context.Context, db Querier, c *Conversation) error {
 	q := `INSERT INTO conversations (title, description, project, role_name, tags, notes)
           VALUES ($1, NULLIF($2,''), NULLIF($3,''), $4, COALESCE($5,'{}'::jsonb), NULLIF($6,''))
           RETURNING id::text, created, updated`
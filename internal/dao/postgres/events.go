@@ -9,6 +9,7 @@ import (
 	"time"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,18 +23,39 @@ type MessageEvent struct {
 	Status       string
 	ErrorMessage sql.NullString
 	Tags         map[string]any
+	// ParentMessageID is the message this one replies to; ToRole the roles
+	// it is addressed to (empty: anyone in the conversation).
+	ParentMessageID sql.NullString
+	ToRole          []string
 }
 
-func InsertMessageEvent(ctx context.Context, db *pgxpool.Pool, ev *MessageEvent) (string, error) {
+// ReplyTo makes ev a reply to parent: it joins the parent's experiment and,
+// without recipients, is addressed to the parent's role. A reply filed under
+// another experiment than its parent's is rejected.
+func (ev *MessageEvent) ReplyTo(parent *MessageEvent) error {
+	if ev.ExperimentID.Valid && parent.ExperimentID.Valid && ev.ExperimentID.String != parent.ExperimentID.String {
+		return fmt.Errorf("message %s belongs to experiment %s, not %s", parent.ID, parent.ExperimentID.String, ev.ExperimentID.String)
+	}
+	ev.ParentMessageID = sql.NullString{String: parent.ID, Valid: true}
+	if !ev.ExperimentID.Valid {
+		ev.ExperimentID = parent.ExperimentID
+	}
+	if len(ev.ToRole) == 0 && parent.RoleName != "" {
+		ev.ToRole = []string{parent.RoleName}
+	}
+	return nil
+}
+
+func InsertMessageEvent(ctx context.Context, db Querier, ev *MessageEvent) (string, error) {
 	if ev == nil {
 		return "", errors.New("nil event")
 	}
 	q := `INSERT INTO messages (
             content_id, from_task_id, experiment_id, role_name,
-            status, error_message, tags, created
+            status, error_message, tags, created, parent_message_id, to_role
         ) VALUES (
             $1::uuid,$2,$3,$4,
-            $5,$6,COALESCE($7,'{}'::jsonb),COALESCE($8, now())
+            $5,$6,COALESCE($7,'{}'::jsonb),COALESCE($8, now()),$9,COALESCE($10::text[],'{}')
         ) RETURNING id::text`
 	var id string
 	var created any
@@ -48,11 +70,11 @@ func InsertMessageEvent(ctx context.Context, db *pgxpool.Pool, ev *MessageEvent)
 	}
 	err := db.QueryRow(ctx, q,
 		ev.ContentID, nullOrUUID(ev.FromTaskID), nullOrUUID(ev.ExperimentID), ev.RoleName,
-		ev.Status, nullOrString(ev.ErrorMessage), tagsJSON, created,
+		ev.Status, nullOrString(ev.ErrorMessage), tagsJSON, created, nullOrUUID(ev.ParentMessageID), pgTextArrayOrNil(ev.ToRole),
 	).Scan(&id)
 	if err != nil {
 		return "", dbutil.ErrWrap("message.insert", err,
-			dbutil.ParamSummary("content_id", ev.ContentID), dbutil.ParamSummary("from_task_id", ev.FromTaskID), dbutil.ParamSummary("experiment_id", ev.ExperimentID), dbutil.ParamSummary("status", ev.Status))
+			dbutil.ParamSummary("content_id", ev.ContentID), dbutil.ParamSummary("from_task_id", ev.FromTaskID), dbutil.ParamSummary("experiment_id", ev.ExperimentID), dbutil.ParamSummary("parent_message_id", ev.ParentMessageID), dbutil.ParamSummary("status", ev.Status))
 	}
 	ev.ID = id
 	return id, nil
@@ -87,7 +109,8 @@ func UpdateMessageEvent(ctx context.Context, db *pgxpool.Pool, id string, update
 func GetMessageEventByID(ctx context.Context, db *pgxpool.Pool, id string) (*MessageEvent, error) {
 	q := `SELECT id::text, content_id::text,
                  from_task_id, experiment_id,
-                 created, status, error_message, tags
+                 created, status, error_message, tags,
+                 role_name, parent_message_id, to_role
           FROM messages WHERE id=$1::uuid`
 	row := db.QueryRow(ctx, q, id)
 	var out MessageEvent
@@ -98,6 +121,7 @@ func GetMessageEventByID(ctx context.Context, db *pgxpool.Pool, id string) (*Mes
 		&out.ID, &out.ContentID,
 		&taskID, &expID,
 		&out.Created, &out.Status, &out.ErrorMessage, &tagsJSON,
+		&out.RoleName, &out.ParentMessageID, &out.ToRole,
 	)
 	if err != nil {
 		return nil, dbutil.ErrWrap("message.get", err, dbutil.ParamSummary("id", id))
@@ -111,6 +135,8 @@ func GetMessageEventByID(ctx context.Context, db *pgxpool.Pool, id string) (*Mes
 	return &out, nil
 }
 
+const messageListColumns = `id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags, role_name, parent_message_id, to_role`
+
 // ListMessages lists messages with optional filters.
 func ListMessages(ctx context.Context, db *pgxpool.Pool, roleName, experimentID, taskID string, status string, limit, offset int) ([]MessageEvent, error) {
 	if limit <= 0 {
@@ -124,35 +150,35 @@ func ListMessages(ctx context.Context, db *pgxpool.Pool, roleName, experimentID,
 	var err error
 	switch {
 	case stringsTrim(experimentID) != "" && stringsTrim(taskID) != "" && status != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND experiment_id=$2::uuid AND from_task_id=$3::uuid AND status=$4
                                    ORDER BY created DESC LIMIT $5 OFFSET $6`, roleName, experimentID, taskID, status, limit, offset)
 	case stringsTrim(experimentID) != "" && stringsTrim(taskID) != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND experiment_id=$2::uuid AND from_task_id=$3::uuid
                                    ORDER BY created DESC LIMIT $4 OFFSET $5`, roleName, experimentID, taskID, limit, offset)
 	case stringsTrim(experimentID) != "" && status != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND experiment_id=$2::uuid AND status=$3
                                    ORDER BY created DESC LIMIT $4 OFFSET $5`, roleName, experimentID, status, limit, offset)
 	case stringsTrim(taskID) != "" && status != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND from_task_id=$2::uuid AND status=$3
                                    ORDER BY created DESC LIMIT $4 OFFSET $5`, roleName, taskID, status, limit, offset)
 	case stringsTrim(experimentID) != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND experiment_id=$2::uuid
                                    ORDER BY created DESC LIMIT $3 OFFSET $4`, roleName, experimentID, limit, offset)
 	case stringsTrim(taskID) != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND from_task_id=$2::uuid
                                    ORDER BY created DESC LIMIT $3 OFFSET $4`, roleName, taskID, limit, offset)
 	case status != "":
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 AND status=$2
                                    ORDER BY created DESC LIMIT $3 OFFSET $4`, roleName, status, limit, offset)
 	default:
-		rows, err = db.Query(ctx, `SELECT id::text, content_id::text, from_task_id, experiment_id, created, status, error_message, tags
+		rows, err = db.Query(ctx, `SELECT `+messageListColumns+`
                                    FROM messages WHERE role_name=$1 ORDER BY created DESC LIMIT $2 OFFSET $3`, roleName, limit, offset)
 	}
 	if err != nil {
@@ -163,7 +189,7 @@ func ListMessages(ctx context.Context, db *pgxpool.Pool, roleName, experimentID,
 	for rows.Next() {
 		var r MessageEvent
 		var tagsJSON []byte
-		if err := rows.Scan(&r.ID, &r.ContentID, &r.FromTaskID, &r.ExperimentID, &r.Created, &r.Status, &r.ErrorMessage, &tagsJSON); err != nil {
+		if err := rows.Scan(&r.ID, &r.ContentID, &r.FromTaskID, &r.ExperimentID, &r.Created, &r.Status, &r.ErrorMessage, &tagsJSON, &r.RoleName, &r.ParentMessageID, &r.ToRole); err != nil {
 			return nil, dbutil.ErrWrap("message.list.scan", err)
 		}
 		if len(tagsJSON) > 0 {
@@ -177,6 +203,127 @@ func ListMessages(ctx context.Context, db *pgxpool.Pool, roleName, experimentID,
 	return out, nil
 }
 
+// ListMessageThread lists the whole thread the message id belongs to: its
+// root and every reply below it, oldest first, whatever their role.
+func ListMessageThread(ctx context.Context, db *pgxpool.Pool, id string) ([]MessageEvent, error) {
+	q := `WITH RECURSIVE up AS (
+              SELECT id, parent_message_id, 0 AS hops FROM messages WHERE id=$1::uuid
+              UNION ALL
+              SELECT m.id, m.parent_message_id, up.hops+1 FROM messages m JOIN up ON m.id = up.parent_message_id
+              WHERE up.hops < $2
+          ), root AS (
+              SELECT id FROM up ORDER BY hops DESC LIMIT 1
+          ), down AS (
+              SELECT id, 0 AS depth FROM root
+              UNION ALL
+              SELECT m.id, down.depth+1 FROM messages m JOIN down ON m.parent_message_id = down.id
+              WHERE down.depth < $2
+          )
+          SELECT ` + messageListColumns + ` FROM messages WHERE id IN (SELECT id FROM down) ORDER BY created, id`
+	rows, err := db.Query(ctx, q, id, maxThreadDepth)
+	if err != nil {
+		return nil, dbutil.ErrWrap("message.thread", err, dbutil.ParamSummary("id", id))
+	}
+	defer rows.Close()
+	var out []MessageEvent
+	for rows.Next() {
+		var r MessageEvent
+		var tagsJSON []byte
+		if err := rows.Scan(&r.ID, &r.ContentID, &r.FromTaskID, &r.ExperimentID, &r.Created, &r.Status, &r.ErrorMessage, &tagsJSON, &r.RoleName, &r.ParentMessageID, &r.ToRole); err != nil {
+			return nil, dbutil.ErrWrap("message.thread.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &r.Tags)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("message.thread", err, dbutil.ParamSummary("id", id))
+	}
+	if len(out) == 0 {
+		return nil, dbutil.ErrWrap("message.thread", pgx.ErrNoRows, dbutil.ParamSummary("id", id))
+	}
+	return out, nil
+}
+
+// maxThreadDepth bounds thread walks so a corrupted parent chain cannot loop.
+const maxThreadDepth = 1000
+
+// ExperimentMessage is a message of an experiment with the JSON metadata of
+// its content (nil when the content has none).
+type ExperimentMessage struct {
+	MessageEvent
+	ContentJSON []byte
+}
+
+// ListExperimentMessages lists every message of an experiment, whatever its
+// role, oldest first, joined with its content metadata.
+func ListExperimentMessages(ctx context.Context, db *pgxpool.Pool, experimentID string) ([]ExperimentMessage, error) {
+	rows, err := db.Query(ctx, `SELECT m.id::text, m.content_id::text, m.from_task_id, m.experiment_id, m.role_name, m.created, m.status, m.error_message, m.tags, m.parent_message_id, m.to_role, c.json_content
+                                FROM messages m JOIN messages_content c ON c.id = m.content_id
+                                WHERE m.experiment_id=$1::uuid ORDER BY m.created, m.id`, experimentID)
+	if err != nil {
+		return nil, dbutil.ErrWrap("message.list_experiment", err, dbutil.ParamSummary("experiment_id", experimentID))
+	}
+	defer rows.Close()
+	var out []ExperimentMessage
+	for rows.Next() {
+		var r ExperimentMessage
+		var tagsJSON []byte
+		if err := rows.Scan(&r.ID, &r.ContentID, &r.FromTaskID, &r.ExperimentID, &r.RoleName, &r.Created, &r.Status, &r.ErrorMessage, &tagsJSON, &r.ParentMessageID, &r.ToRole, &r.ContentJSON); err != nil {
+			return nil, dbutil.ErrWrap("message.list_experiment.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &r.Tags)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("message.list_experiment", err)
+	}
+	return out, nil
+}
+
+// TranscriptMessage is a message with its full content and, for task runs,
+// the variant of the task that produced it.
+type TranscriptMessage struct {
+	MessageEvent
+	Text        string
+	ContentJSON []byte
+	TaskVariant sql.NullString
+}
+
+// ListExperimentTranscript lists the messages of an experiment oldest first,
+// with their text and JSON content.
+func ListExperimentTranscript(ctx context.Context, db *pgxpool.Pool, experimentID string) ([]TranscriptMessage, error) {
+	rows, err := db.Query(ctx, `SELECT m.id::text, m.content_id::text, m.from_task_id, m.experiment_id, m.role_name, m.created, m.status, m.error_message, m.tags,
+                                       m.parent_message_id, m.to_role, c.text_content, c.json_content, t.variant
+                                FROM messages m
+                                JOIN messages_content c ON c.id = m.content_id
+                                LEFT JOIN tasks t ON t.id = m.from_task_id
+                                WHERE m.experiment_id=$1::uuid ORDER BY m.created, m.id`, experimentID)
+	if err != nil {
+		return nil, dbutil.ErrWrap("message.transcript", err, dbutil.ParamSummary("experiment_id", experimentID))
+	}
+	defer rows.Close()
+	var out []TranscriptMessage
+	for rows.Next() {
+		var r TranscriptMessage
+		var tagsJSON []byte
+		if err := rows.Scan(&r.ID, &r.ContentID, &r.FromTaskID, &r.ExperimentID, &r.RoleName, &r.Created, &r.Status, &r.ErrorMessage, &tagsJSON, &r.ParentMessageID, &r.ToRole, &r.Text, &r.ContentJSON, &r.TaskVariant); err != nil {
+			return nil, dbutil.ErrWrap("message.transcript.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &r.Tags)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("message.transcript", err)
+	}
+	return out, nil
+}
+
 // DeleteMessage deletes a message by id.
 func DeleteMessage(ctx context.Context, db *pgxpool.Pool, id string) (int64, error) {
 	ct, err := db.Exec(ctx, `DELETE FROM messages WHERE id=$1::uuid`, id)
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestMessageEventReplyTo(t *testing.T) {
+	parent := &MessageEvent{ID: "m1", RoleName: "planner", ExperimentID: sql.NullString{String: "e1", Valid: true}}
+
+	ev := &MessageEvent{}
+	if err := ev.ReplyTo(parent); err != nil {
+		t.Fatal(err)
+	}
+	if ev.ParentMessageID.String != "m1" || ev.ExperimentID.String != "e1" || !reflect.DeepEqual(ev.ToRole, []string{"planner"}) {
+		t.Fatalf("reply not linked: %+v", ev)
+	}
+
+	ev = &MessageEvent{ExperimentID: sql.NullString{String: "e1", Valid: true}, ToRole: []string{"coder"}}
+	if err := ev.ReplyTo(parent); err != nil || !reflect.DeepEqual(ev.ToRole, []string{"coder"}) {
+		t.Fatalf("same experiment rejected or recipients replaced: %+v %v", ev, err)
+	}
+
+	ev = &MessageEvent{ExperimentID: sql.NullString{String: "e2", Valid: true}}
+	if err := ev.ReplyTo(parent); err == nil || ev.ParentMessageID.Valid {
+		t.Fatalf("reply across experiments accepted: %+v", ev)
+	}
+}
@@ -2,48 +2,98 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Experiment lifecycle statuses.
+const (
+	ExperimentRunning   = "running"
+	ExperimentSucceeded = "succeeded"
+	ExperimentFailed    = "failed"
+	ExperimentAborted   = "aborted"
+)
+
+// ValidExperimentStatus reports whether s is a lifecycle status.
+func ValidExperimentStatus(s string) bool {
+	switch s {
+	case ExperimentRunning, ExperimentSucceeded, ExperimentFailed, ExperimentAborted:
+		return true
+	}
+	return false
+}
+
 type Experiment struct {
 	ID             string
 	ConversationID string
 	Created        string // RFC3339 timestamp as string for simplicity
+	Name           sql.NullString
+	Params         map[string]any     // what varied: model, temperature, task versions...
+	Status         string             // running, succeeded, failed or aborted
+	Started        sql.NullTime       // set on creation
+	Finished       sql.NullTime       // set when the status leaves running
+	Metrics        map[string]float64 // numeric outcomes
+}
+
+const experimentColumns = `id::text, conversation_id::text, created, name, params, status, started, finished, metrics`
+
+func scanExperiment(row interface{ Scan(...any) error }, e *Experiment) error {
+	var created time.Time
+	var params, metrics []byte
+	if err := row.Scan(&e.ID, &e.ConversationID, &created, &e.Name, &params, &e.Status, &e.Started, &e.Finished, &metrics); err != nil {
+		return err
+	}
+	e.Created = created.Format(time.RFC3339)
+	e.Params, e.Metrics = nil, nil
+	if len(params) > 0 {
+		_ = json.Unmarshal(params, &e.Params)
+	}
+	if len(metrics) > 0 {
+		_ = json.Unmarshal(metrics, &e.Metrics)
+	}
+	return nil
 }
 
 func CreateExperiment(ctx context.Context, db *pgxpool.Pool, conversationID string) (*Experiment, error) {
-	q := `INSERT INTO experiments (conversation_id) VALUES ($1::uuid) RETURNING id::text, created`
-	var e Experiment
-	e.ConversationID = conversationID
-	var createdTS any
-	if err := db.QueryRow(ctx, q, conversationID).Scan(&e.ID, &createdTS); err != nil {
-		return nil, dbutil.ErrWrap("experiment.insert", err, dbutil.ParamSummary("conversation_id", conversationID))
-	}
-	// pgx encodes time as time.Time; format to RFC3339
-	switch t := createdTS.(type) {
-	case string:
-		e.Created = t
-	default:
-		// Best effort
-		// let JSON marshalling handle types elsewhere if needed
+	e := &Experiment{ConversationID: conversationID}
+	if err := InsertExperiment(ctx, db, e); err != nil {
+		return nil, err
 	}
-	return &e, nil
+	return e, nil
+}
+
+// InsertExperiment creates an experiment with its name, params, status
+// (running when empty) and metrics, and fills in the stored row. Started and
+// Finished, when valid, are kept (imports); otherwise they default to now.
+func InsertExperiment(ctx context.Context, db Querier, e *Experiment) error {
+	if e.Status == "" {
+		e.Status = ExperimentRunning
+	}
+	if !ValidExperimentStatus(e.Status) {
+		return fmt.Errorf("invalid experiment status %q", e.Status)
+	}
+	params, _ := json.Marshal(orEmptyMap(e.Params))
+	metrics, _ := json.Marshal(orEmptyMetrics(e.Metrics))
+	q := `INSERT INTO experiments (conversation_id, name, params, status, metrics, created, started, finished)
+          VALUES ($1::uuid, NULLIF($2,''), $3::jsonb, $4, $5::jsonb, COALESCE($6::timestamptz, now()), COALESCE($6::timestamptz, now()),
+                  CASE WHEN $4='running' THEN NULL ELSE COALESCE($7::timestamptz, now()) END)
+          RETURNING ` + experimentColumns
+	if err := scanExperiment(db.QueryRow(ctx, q, e.ConversationID, stringOrEmpty(e.Name), params, e.Status, metrics, nullOrTime(e.Started), nullOrTime(e.Finished)), e); err != nil {
+		return dbutil.ErrWrap("experiment.insert", err, dbutil.ParamSummary("conversation_id", e.ConversationID))
+	}
+	return nil
 }
 
 func GetExperimentByID(ctx context.Context, db *pgxpool.Pool, id string) (*Experiment, error) {
-	q := `SELECT id::text, conversation_id::text, created FROM experiments WHERE id=$1::uuid`
 	var e Experiment
-	var createdTS any
-	if err := db.QueryRow(ctx, q, id).Scan(&e.ID, &e.ConversationID, &createdTS); err != nil {
+	if err := scanExperiment(db.QueryRow(ctx, `SELECT `+experimentColumns+` FROM experiments WHERE id=$1::uuid`, id), &e); err != nil {
 		return nil, dbutil.ErrWrap("experiment.get", err, dbutil.ParamSummary("id", id))
 	}
-	switch t := createdTS.(type) {
-	case string:
-		e.Created = t
-	}
 	return &e, nil
 }
 
@@ -57,9 +107,9 @@ func ListExperiments(ctx context.Context, db *pgxpool.Pool, conversationID strin
 	var rows pgxRows
 	var err error
 	if stringsTrim(conversationID) != "" {
-		rows, err = db.Query(ctx, `SELECT id::text, conversation_id::text, created FROM experiments WHERE conversation_id=$1::uuid ORDER BY created DESC LIMIT $2 OFFSET $3`, conversationID, limit, offset)
+		rows, err = db.Query(ctx, `SELECT `+experimentColumns+` FROM experiments WHERE conversation_id=$1::uuid ORDER BY created DESC LIMIT $2 OFFSET $3`, conversationID, limit, offset)
 	} else {
-		rows, err = db.Query(ctx, `SELECT id::text, conversation_id::text, created FROM experiments ORDER BY created DESC LIMIT $1 OFFSET $2`, limit, offset)
+		rows, err = db.Query(ctx, `SELECT `+experimentColumns+` FROM experiments ORDER BY created DESC LIMIT $1 OFFSET $2`, limit, offset)
 	}
 	if err != nil {
 		return nil, dbutil.ErrWrap("experiment.list", err, dbutil.ParamSummary("conversation_id", conversationID), fmt.Sprintf("limit=%d", limit), fmt.Sprintf("offset=%d", offset))
@@ -68,13 +118,9 @@ func ListExperiments(ctx context.Context, db *pgxpool.Pool, conversationID strin
 	var out []Experiment
 	for rows.Next() {
 		var e Experiment
-		var createdTS any
-		if err := rows.Scan(&e.ID, &e.ConversationID, &createdTS); err != nil {
+		if err := scanExperiment(rows, &e); err != nil {
 			return nil, dbutil.ErrWrap("experiment.list.scan", err)
 		}
-		if s, ok := createdTS.(string); ok {
-			e.Created = s
-		}
 		out = append(out, e)
 	}
 	if err := rows.Err(); err != nil {
@@ -83,6 +129,118 @@ func ListExperiments(ctx context.Context, db *pgxpool.Pool, conversationID strin
 	return out, nil
 }
 
+// ExperimentUpdate changes part of an experiment; zero fields keep the
+// stored values.
+type ExperimentUpdate struct {
+	Name       string
+	Status     string
+	Outcome    string             // run outcome folded into the status (NextExperimentStatus)
+	Params     map[string]any     // merged into params, nested objects included
+	Metrics    map[string]float64 // replaces these metrics
+	AddMetrics map[string]float64 // added to these metrics (counters, token totals)
+}
+
+// UpdateExperiment applies u in one transaction, with the row locked so an
+// Outcome folds into the status concurrent runs left. Leaving running stamps
+// finished; going back to running clears it.
+func UpdateExperiment(ctx context.Context, db *pgxpool.Pool, id string, u ExperimentUpdate) (*Experiment, error) {
+	if u.Status != "" && !ValidExperimentStatus(u.Status) {
+		return nil, fmt.Errorf("invalid experiment status %q", u.Status)
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, dbutil.ErrWrap("experiment.update.begin", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var cur Experiment
+	if err := scanExperiment(tx.QueryRow(ctx, `SELECT `+experimentColumns+` FROM experiments WHERE id=$1::uuid FOR UPDATE`, id), &cur); err != nil {
+		return nil, dbutil.ErrWrap("experiment.update.get", err, dbutil.ParamSummary("id", id))
+	}
+	params := mergeParams(orEmptyMap(cur.Params), u.Params)
+	metrics := orEmptyMetrics(cur.Metrics)
+	for k, v := range u.Metrics {
+		metrics[k] = v
+	}
+	for k, v := range u.AddMetrics {
+		metrics[k] += v
+	}
+	status := u.nextStatus(cur.Status)
+	pj, _ := json.Marshal(params)
+	mj, _ := json.Marshal(metrics)
+	q := `UPDATE experiments
+          SET name=COALESCE(NULLIF($2,''), name), params=$3::jsonb, metrics=$4::jsonb, status=$5,
+              finished=CASE WHEN $5='running' THEN NULL
+                            WHEN status<>$5 OR finished IS NULL THEN now()
+                            ELSE finished END
+          WHERE id=$1::uuid
+          RETURNING ` + experimentColumns
+	var out Experiment
+	if err := scanExperiment(tx.QueryRow(ctx, q, id, u.Name, pj, mj, status), &out); err != nil {
+		return nil, dbutil.ErrWrap("experiment.update", err, dbutil.ParamSummary("id", id), dbutil.ParamSummary("status", status))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, dbutil.ErrWrap("experiment.update.commit", err, dbutil.ParamSummary("id", id))
+	}
+	return &out, nil
+}
+
+// nextStatus is the status u leaves on an experiment currently at current.
+func (u ExperimentUpdate) nextStatus(current string) string {
+	switch {
+	case u.Status != "":
+		return u.Status
+	case u.Outcome != "":
+		return NextExperimentStatus(current, u.Outcome)
+	}
+	return current
+}
+
+// NextExperimentStatus folds the outcome of one run (succeeded, failed,
+// timeout...) into an experiment's status: a failed or aborted experiment
+// stays so, anything else takes the run's outcome.
+func NextExperimentStatus(current, outcome string) string {
+	switch current {
+	case ExperimentFailed, ExperimentAborted:
+		return current
+	}
+	switch outcome {
+	case ExperimentSucceeded, ExperimentRunning:
+		return outcome
+	case ExperimentAborted:
+		return ExperimentAborted
+	}
+	return ExperimentFailed
+}
+
+// mergeParams merges patch into base; objects present on both sides are
+// merged recursively, anything else is replaced.
+func mergeParams(base, patch map[string]any) map[string]any {
+	for k, v := range patch {
+		pm, ok := v.(map[string]any)
+		bm, ok2 := base[k].(map[string]any)
+		if ok && ok2 {
+			base[k] = mergeParams(bm, pm)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
+
+func orEmptyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
+	}
+	return m
+}
+
+func orEmptyMetrics(m map[string]float64) map[string]float64 {
+	if m == nil {
+		return map[string]float64{}
+	}
+	return m
+}
+
 func DeleteExperiment(ctx context.Context, db *pgxpool.Pool, id string) (int64, error) {
 	ct, err := db.Exec(ctx, `DELETE FROM experiments WHERE id=$1::uuid`, id)
 	if err != nil {
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextExperimentStatus(t *testing.T) {
+	cases := []struct{ cur, outcome, want string }{
+		{ExperimentRunning, "succeeded", ExperimentSucceeded},
+		{ExperimentSucceeded, "running", ExperimentRunning},
+		{ExperimentSucceeded, "timeout", ExperimentFailed},
+		{ExperimentFailed, "succeeded", ExperimentFailed},
+		{ExperimentAborted, "running", ExperimentAborted},
+		{ExperimentRunning, "aborted", ExperimentAborted},
+	}
+	for _, c := range cases {
+		if got := NextExperimentStatus(c.cur, c.outcome); got != c.want {
+			t.Errorf("NextExperimentStatus(%q, %q) = %q, want %q", c.cur, c.outcome, got, c.want)
+		}
+	}
+}
+
+func TestExperimentUpdateNextStatus(t *testing.T) {
+	cases := []struct {
+		u    ExperimentUpdate
+		cur  string
+		want string
+	}{
+		{ExperimentUpdate{}, ExperimentRunning, ExperimentRunning},
+		{ExperimentUpdate{Outcome: "succeeded"}, ExperimentRunning, ExperimentSucceeded},
+		{ExperimentUpdate{Outcome: "succeeded"}, ExperimentFailed, ExperimentFailed},
+		{ExperimentUpdate{Status: ExperimentRunning, Outcome: "failed"}, ExperimentFailed, ExperimentRunning},
+	}
+	for _, c := range cases {
+		if got := c.u.nextStatus(c.cur); got != c.want {
+			t.Errorf("%+v.nextStatus(%q) = %q, want %q", c.u, c.cur, got, c.want)
+		}
+	}
+}
+
+func TestMergeParams(t *testing.T) {
+	base := map[string]any{"model": "a", "tasks": map[string]any{"unit/go": "1"}}
+	got := mergeParams(base, map[string]any{"model": "b", "tasks": map[string]any{"lint/go": "2"}})
+	want := map[string]any{"model": "b", "tasks": map[string]any{"unit/go": "1", "lint/go": "2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeParams = %v, want %v", got, want)
+	}
+}
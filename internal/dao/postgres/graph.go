@@ -2,6 +2,9 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -33,7 +36,7 @@ func normalizeStickieRelType(t string) string {
 }
 
 // CreateStickieEdge stores/updates relation in SQL mirror table.
-func CreateStickieEdge(ctx context.Context, db *pgxpool.Pool, fromID, toID, relType string, labels []string) error {
+func CreateStickieEdge(ctx context.Context, db *pgxpool.Pool, fromID, toID, relType string, labels []string, ifMatch Precondition) error {
 	rt := normalizeStickieRelType(relType)
 	if rt == "" {
 		return fmt.Errorf("invalid relation type: %s", relType)
@@ -41,7 +44,7 @@ func CreateStickieEdge(ctx context.Context, db *pgxpool.Pool, fromID, toID, relT
 	if strings.TrimSpace(fromID) == "" || strings.TrimSpace(toID) == "" {
 		return fmt.Errorf("from/to ids required")
 	}
-	return UpsertStickieRelation(ctx, db, StickieRelation{FromID: fromID, ToID: toID, RelType: rt, Labels: labels})
+	return UpsertStickieRelation(ctx, db, StickieRelation{FromID: fromID, ToID: toID, RelType: rt, Labels: labels, IfMatch: ifMatch})
 }
 
 type StickieEdge struct {
@@ -105,8 +108,9 @@ func CreateTaskReplacesEdgeSQL(ctx context.Context, db *pgxpool.Pool, newTaskID,
 	return CreateTaskReplacesEdge(ctx, db, newTaskID, oldTaskID, level, comment, createdISO)
 }
 
-// CreateTaskReplacesEdge stores a replacement relation in SQL.
-func CreateTaskReplacesEdge(ctx context.Context, db *pgxpool.Pool, newTaskID, oldTaskID, level, comment, createdISO string) error {
+// CreateTaskReplacesEdge stores a replacement relation in SQL, without a cycle
+// check; see ReplaceTask.
+func CreateTaskReplacesEdge(ctx context.Context, db Querier, newTaskID, oldTaskID, level, comment, createdISO string) error {
 	if strings.TrimSpace(newTaskID) == "" || strings.TrimSpace(oldTaskID) == "" {
 		return fmt.Errorf("task ids required")
 	}
@@ -181,3 +185,141 @@ func FindLatestFrom(ctx context.Context, db *pgxpool.Pool, currentID string) (st
 	}
 	return id, nil
 }
+
+// FindLatestFromLevels returns the newest task reachable from current following only
+// replacement edges whose level is in levels. The deepest successor wins (most recent
+// on ties); current itself is returned when nothing replaces it.
+func FindLatestFromLevels(ctx context.Context, db *pgxpool.Pool, currentID string, levels []string) (string, error) {
+	q := `WITH RECURSIVE succ(id, depth) AS (
+              SELECT $1::uuid, 0
+            UNION
+              SELECT tr.new_task_id, s.depth+1
+              FROM task_replaces tr JOIN succ s ON tr.old_task_id=s.id
+              WHERE tr.level = ANY($2::text[]) AND s.depth < 1000
+          )
+          SELECT s.id::text
+          FROM succ s JOIN tasks t ON t.id=s.id
+          ORDER BY s.depth DESC, t.created DESC, s.id
+          LIMIT 1`
+	var id string
+	if err := db.QueryRow(ctx, q, currentID, levels).Scan(&id); err != nil {
+		return "", fmt.Errorf("task latest by levels failed: %w; sql=%s", err, q)
+	}
+	return id, nil
+}
+
+// TaskReplace is a stored replacement relation: NewTaskID REPLACES OldTaskID.
+type TaskReplace struct {
+	NewTaskID string
+	OldTaskID string
+	Level     string
+	Comment   sql.NullString
+	Created   sql.NullTime
+}
+
+// ErrTaskReplacesCycle is returned by ReplaceTask for an edge that would close
+// a cycle.
+var ErrTaskReplacesCycle = errors.New("task replacement would create a cycle")
+
+// ReplaceTask stores newTaskID REPLACES oldTaskID unless it would close a cycle
+// (ErrTaskReplacesCycle). The tasks of both lineages are locked before the
+// check, in the insert's transaction, so concurrent replacements cannot close
+// a cycle between them.
+func ReplaceTask(ctx context.Context, db *pgxpool.Pool, newTaskID, oldTaskID, level, comment, createdISO string) error {
+	return InTx(ctx, db, func(tx Querier) error {
+		q := `WITH RECURSIVE comp(id) AS (
+                  SELECT unnest(ARRAY[$1::uuid, $2::uuid])
+                UNION
+                  SELECT CASE WHEN tr.new_task_id=c.id THEN tr.old_task_id ELSE tr.new_task_id END
+                  FROM task_replaces tr JOIN comp c ON tr.new_task_id=c.id OR tr.old_task_id=c.id
+              )
+              SELECT t.id FROM tasks t WHERE t.id IN (SELECT id FROM comp) ORDER BY t.id FOR UPDATE`
+		if _, err := tx.Exec(ctx, q, newTaskID, oldTaskID); err != nil {
+			return fmt.Errorf("task replaces lock failed: %w", err)
+		}
+		cycle, err := TaskReplacesWouldCycle(ctx, tx, newTaskID, oldTaskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrTaskReplacesCycle
+		}
+		return CreateTaskReplacesEdge(ctx, tx, newTaskID, oldTaskID, level, comment, createdISO)
+	})
+}
+
+// TaskReplacesWouldCycle reports whether storing newTaskID REPLACES oldTaskID would
+// close a cycle, i.e. oldTaskID already (transitively) replaces newTaskID.
+func TaskReplacesWouldCycle(ctx context.Context, db Querier, newTaskID, oldTaskID string) (bool, error) {
+	if strings.TrimSpace(newTaskID) == strings.TrimSpace(oldTaskID) {
+		return true, nil
+	}
+	q := `WITH RECURSIVE succ(id) AS (
+              SELECT new_task_id FROM task_replaces WHERE old_task_id=$1::uuid
+            UNION
+              SELECT tr.new_task_id FROM task_replaces tr JOIN succ s ON tr.old_task_id=s.id
+          )
+          SELECT EXISTS (SELECT 1 FROM succ WHERE id=$2::uuid)`
+	var found bool
+	if err := db.QueryRow(ctx, q, newTaskID, oldTaskID).Scan(&found); err != nil {
+		return false, fmt.Errorf("task replaces cycle check failed: %w; sql=%s", err, q)
+	}
+	return found, nil
+}
+
+// ListTaskLineage returns every task connected to id through task_replaces (in either
+// direction) together with the replacement edges between them.
+func ListTaskLineage(ctx context.Context, db *pgxpool.Pool, id string) ([]Task, []TaskReplace, error) {
+	comp := `WITH RECURSIVE comp(id) AS (
+                 SELECT $1::uuid
+               UNION
+                 SELECT CASE WHEN tr.new_task_id=c.id THEN tr.old_task_id ELSE tr.new_task_id END
+                 FROM task_replaces tr JOIN comp c ON tr.new_task_id=c.id OR tr.old_task_id=c.id
+             )`
+	rows, err := db.Query(ctx, comp+`
+             SELECT t.id::text, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
+                    t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+             FROM comp c
+             JOIN tasks t ON t.id=c.id
+             LEFT JOIN task_variants tv ON tv.variant = t.variant
+             ORDER BY t.created ASC, t.id ASC`, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("task lineage tasks failed: %w; id=%s", err, id)
+	}
+	var tasks []Task
+	for rows.Next() {
+		var t Task
+		var tagsJSON []byte
+		if err := rows.Scan(&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
+			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &t.Tags)
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	erows, err := db.Query(ctx, comp+`
+             SELECT tr.new_task_id::text, tr.old_task_id::text, tr.level, tr.comment, tr.created
+             FROM task_replaces tr
+             WHERE tr.new_task_id IN (SELECT id FROM comp)
+             ORDER BY tr.created ASC, tr.new_task_id ASC`, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("task lineage edges failed: %w; id=%s", err, id)
+	}
+	defer erows.Close()
+	var edges []TaskReplace
+	for erows.Next() {
+		var e TaskReplace
+		if err := erows.Scan(&e.NewTaskID, &e.OldTaskID, &e.Level, &e.Comment, &e.Created); err != nil {
+			return nil, nil, err
+		}
+		edges = append(edges, e)
+	}
+	return tasks, edges, erows.Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,28 +14,68 @@ type Package struct {
 	ID       string
 	RoleName string
 	TaskID   string
-	Created  sql.NullTime
-	Updated  sql.NullTime
+	// Constraint is exact|patch|minor|major: how far task_replaces may move the pin.
+	Constraint string
+	Created    sql.NullTime
+	Updated    sql.NullTime
+}
+
+// PackageConstraintLevels returns the replacement levels a constraint may follow.
+// exact follows none; patch follows patch; minor follows patch+minor; major follows all.
+func PackageConstraintLevels(constraint string) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(constraint)) {
+	case "", "exact":
+		return nil, nil
+	case "patch":
+		return []string{"patch"}, nil
+	case "minor":
+		return []string{"patch", "minor"}, nil
+	case "major":
+		return []string{"patch", "minor", "major"}, nil
+	default:
+		return nil, fmt.Errorf("invalid version constraint: %s (want exact|patch|minor|major)", constraint)
+	}
+}
+
+// NarrowerPackageConstraint returns whichever of a and b follows fewer levels.
+func NarrowerPackageConstraint(a, b string) string {
+	la, _ := PackageConstraintLevels(a)
+	lb, _ := PackageConstraintLevels(b)
+	if len(lb) < len(la) {
+		return b
+	}
+	return a
 }
 
 // UpsertStarredTask binds a role to a specific (variant, version) by referencing the task row.
 // Enforces uniqueness on (role, variant) so later calls update the chosen version.
-func UpsertPackage(ctx context.Context, db *pgxpool.Pool, roleName, variant string) (*Package, error) {
+// An empty constraint keeps the stored one (exact for new packages). A non-zero
+// ifMatch makes the write conditional on the updated timestamp of the role's
+// package for variant.
+func UpsertPackage(ctx context.Context, db *pgxpool.Pool, roleName, variant, constraint string, ifMatch Precondition) (*Package, error) {
+	if _, err := PackageConstraintLevels(constraint); err != nil {
+		return nil, err
+	}
 	// Resolve the task id for integrity by variant
 	t, err := GetTaskByVariant(ctx, db, variant)
 	if err != nil {
 		return nil, dbutil.ErrWrap("package.resolve_task", err, dbutil.ParamSummary("variant", variant))
 	}
-	q := `INSERT INTO packages (role_name, task_id)
-          VALUES ($1,$2::uuid)
+	q := `INSERT INTO packages (role_name, task_id, version_constraint)
+          VALUES ($1,$2::uuid,COALESCE(NULLIF($3,''),'exact'))
           ON CONFLICT (role_name, task_id) DO UPDATE SET
             task_id = EXCLUDED.task_id,
+            version_constraint = COALESCE(NULLIF($3,''), packages.version_constraint),
             updated = now()
-          RETURNING id::text, created, updated`
+          RETURNING id::text, version_constraint, created, updated`
 	var p Package
 	p.RoleName = roleName
 	p.TaskID = t.ID
-	if err := db.QueryRow(ctx, q, roleName, t.ID).Scan(&p.ID, &p.Created, &p.Updated); err != nil {
+	check := &versionCheck{entity: "package", key: roleName + "/" + variant, table: "packages",
+		where: "role_name=$1 AND task_id IN (SELECT id FROM tasks WHERE variant=$2)", args: []any{roleName, variant}, p: ifMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q, roleName, t.ID, strings.ToLower(strings.TrimSpace(constraint))).Scan(&p.ID, &p.Constraint, &p.Created, &p.Updated)
+	}); err != nil {
 		return nil, dbutil.ErrWrap("package.upsert", err, dbutil.ParamSummary("role", roleName), dbutil.ParamSummary("task_id", t.ID))
 	}
 	return &p, nil
@@ -42,9 +83,9 @@ func UpsertPackage(ctx context.Context, db *pgxpool.Pool, roleName, variant stri
 
 // GetStarredTaskByID fetches a starred task by id.
 func GetPackageByID(ctx context.Context, db *pgxpool.Pool, id string) (*Package, error) {
-	q := `SELECT id::text, role_name, task_id::text, created, updated FROM packages WHERE id=$1::uuid`
+	q := `SELECT id::text, role_name, task_id::text, version_constraint, created, updated FROM packages WHERE id=$1::uuid`
 	var p Package
-	if err := db.QueryRow(ctx, q, id).Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Created, &p.Updated); err != nil {
+	if err := db.QueryRow(ctx, q, id).Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Constraint, &p.Created, &p.Updated); err != nil {
 		return nil, dbutil.ErrWrap("package.get", err, dbutil.ParamSummary("id", id))
 	}
 	return &p, nil
@@ -53,12 +94,12 @@ func GetPackageByID(ctx context.Context, db *pgxpool.Pool, id string) (*Package,
 // GetStarredTaskByKey fetches a starred task by (role, variant).
 func GetPackageByKey(ctx context.Context, db *pgxpool.Pool, roleName, variant string) (*Package, error) {
 	// Join with tasks to filter by variant
-	q := `SELECT p.id::text, p.role_name, p.task_id::text, p.created, p.updated
+	q := `SELECT p.id::text, p.role_name, p.task_id::text, p.version_constraint, p.created, p.updated
           FROM packages p
           JOIN tasks t ON t.id = p.task_id
           WHERE p.role_name=$1 AND t.variant=$2`
 	var p Package
-	if err := db.QueryRow(ctx, q, roleName, variant).Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Created, &p.Updated); err != nil {
+	if err := db.QueryRow(ctx, q, roleName, variant).Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Constraint, &p.Created, &p.Updated); err != nil {
 		return nil, dbutil.ErrWrap("package.get", err, dbutil.ParamSummary("role", roleName), dbutil.ParamSummary("variant", variant))
 	}
 	return &p, nil
@@ -75,22 +116,22 @@ func ListPackages(ctx context.Context, db *pgxpool.Pool, roleName, variant strin
 	var rows pgxRows
 	var err error
 	if stringsTrim(roleName) != "" && stringsTrim(variant) != "" {
-		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.created, p.updated
+		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.version_constraint, p.created, p.updated
                                     FROM packages p JOIN tasks t ON t.id = p.task_id
                                     WHERE p.role_name=$1 AND t.variant=$2
                                     ORDER BY t.variant ASC LIMIT $3 OFFSET $4`, roleName, variant, limit, offset)
 	} else if stringsTrim(roleName) != "" {
-		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.created, p.updated
+		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.version_constraint, p.created, p.updated
                                     FROM packages p JOIN tasks t ON t.id = p.task_id
                                     WHERE p.role_name=$1
                                     ORDER BY t.variant ASC LIMIT $2 OFFSET $3`, roleName, limit, offset)
 	} else if stringsTrim(variant) != "" {
-		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.created, p.updated
+		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.version_constraint, p.created, p.updated
                                     FROM packages p JOIN tasks t ON t.id = p.task_id
                                     WHERE t.variant=$1
                                     ORDER BY t.variant ASC LIMIT $2 OFFSET $3`, variant, limit, offset)
 	} else {
-		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.created, p.updated
+		rows, err = db.Query(ctx, `SELECT p.id::text, p.role_name, p.task_id::text, p.version_constraint, p.created, p.updated
                                     FROM packages p JOIN tasks t ON t.id = p.task_id
                                     ORDER BY t.variant ASC LIMIT $1 OFFSET $2`, limit, offset)
 	}
@@ -101,7 +142,7 @@ func ListPackages(ctx context.Context, db *pgxpool.Pool, roleName, variant strin
 	var out []Package
 	for rows.Next() {
 		var p Package
-		if err := rows.Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Created, &p.Updated); err != nil {
+		if err := rows.Scan(&p.ID, &p.RoleName, &p.TaskID, &p.Constraint, &p.Created, &p.Updated); err != nil {
 			return nil, dbutil.ErrWrap("package.list.scan", err)
 		}
 		out = append(out, p)
@@ -129,3 +170,28 @@ func DeletePackageByKey(ctx context.Context, db *pgxpool.Pool, roleName, variant
 	}
 	return ct.RowsAffected(), nil
 }
+
+// ResolvePackageTask returns the task id a package currently points to: the latest
+// task reachable from its pinned task_id following only the levels allowed by constraint.
+func ResolvePackageTask(ctx context.Context, db *pgxpool.Pool, p *Package, constraint string) (string, error) {
+	levels, err := PackageConstraintLevels(constraint)
+	if err != nil {
+		return "", err
+	}
+	if len(levels) == 0 {
+		return p.TaskID, nil
+	}
+	id, err := FindLatestFromLevels(ctx, db, p.TaskID, levels)
+	if err != nil {
+		return "", dbutil.ErrWrap("package.resolve", err, dbutil.ParamSummary("id", p.ID), dbutil.ParamSummary("constraint", constraint))
+	}
+	return id, nil
+}
+
+// UpdatePackageTask moves a package pin to another task id.
+func UpdatePackageTask(ctx context.Context, db *pgxpool.Pool, id, taskID string) error {
+	if _, err := db.Exec(ctx, `UPDATE packages SET task_id=$2::uuid WHERE id=$1::uuid`, id, taskID); err != nil {
+		return dbutil.ErrWrap("package.update_task", err, dbutil.ParamSummary("id", id), dbutil.ParamSummary("task_id", taskID))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPackageConstraintLevels(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"exact", ""},
+		{"patch", "patch"},
+		{" Minor ", "patch,minor"},
+		{"MAJOR", "patch,minor,major"},
+	}
+	for _, c := range cases {
+		got, err := PackageConstraintLevels(c.in)
+		if err != nil {
+			t.Fatalf("%q: %v", c.in, err)
+		}
+		if strings.Join(got, ",") != c.want {
+			t.Errorf("%q: got %v, want %s", c.in, got, c.want)
+		}
+	}
+	if _, err := PackageConstraintLevels("latest"); err == nil {
+		t.Fatal("latest: expected error")
+	}
+}
+
+func TestNarrowerPackageConstraint(t *testing.T) {
+	cases := []struct{ a, b, want string }{
+		{"major", "minor", "minor"},
+		{"minor", "major", "minor"},
+		{"patch", "major", "patch"},
+		{"major", "exact", "exact"},
+		{"minor", "", ""}, // unset stored constraint means exact
+		{"minor", "minor", "minor"},
+		{"patch", "bogus", "bogus"}, // kept so resolving it reports the error
+	}
+	for _, c := range cases {
+		if got := NarrowerPackageConstraint(c.a, c.b); got != c.want {
+			t.Errorf("Narrower(%q, %q) = %q, want %q", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestResolvePackageTaskWithoutLevels(t *testing.T) {
+	// exact resolves to the pin without touching the database.
+	p := &Package{ID: "p1", TaskID: "t1"}
+	id, err := ResolvePackageTask(context.Background(), nil, p, "exact")
+	if err != nil || id != "t1" {
+		t.Fatalf("exact: got %q, %v", id, err)
+	}
+	if _, err := ResolvePackageTask(context.Background(), nil, p, "newest"); err == nil {
+		t.Fatal("invalid constraint: expected error")
+	}
+}
@@ -18,6 +18,9 @@ type Project struct {
 	Tags        map[string]any
 	Created     sql.NullTime
 	Updated     sql.NullTime
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertProject inserts or updates a project identified by (name, role_name).
@@ -34,9 +37,12 @@ func UpsertProject(ctx context.Context, db *pgxpool.Pool, p *Project) error {
 	if p.Tags != nil {
 		tagsJSON, _ = json.Marshal(p.Tags)
 	}
-	if err := db.QueryRow(ctx, q,
-		p.Name, p.RoleName, stringOrEmpty(p.Description), stringOrEmpty(p.Notes), tagsJSON,
-	).Scan(&p.Created, &p.Updated); err != nil {
+	check := &versionCheck{entity: "project", key: p.Name, table: "projects", where: "name=$1 AND role_name=$2", args: []any{p.Name, p.RoleName}, p: p.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q,
+			p.Name, p.RoleName, stringOrEmpty(p.Description), stringOrEmpty(p.Notes), tagsJSON,
+		).Scan(&p.Created, &p.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("project.upsert", err, dbutil.ParamSummary("name", p.Name), dbutil.ParamSummary("role", p.RoleName))
 	}
 	return nil
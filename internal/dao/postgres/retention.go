@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ContentGCGrace is how old unreferenced content must be before it is
+// collected: content is written just before the message or script that
+// points at it, so fresh rows may simply not be referenced yet.
+const ContentGCGrace = time.Hour
+
+// MessagePruneFilter selects messages for deletion: created before Cutoff,
+// optionally with this Status and RoleName, and without any KeepTagged tag key.
+type MessagePruneFilter struct {
+	Cutoff     time.Time
+	Status     string
+	RoleName   string
+	KeepTagged []string
+}
+
+// pruneWhere is the fixed-shape filter over messages for $1..$4.
+const pruneWhere = `created < $1 AND ($2 = '' OR status = $2) AND ($3 = '' OR role_name = $3)
+          AND NOT (COALESCE(tags, '{}'::jsonb) ?| $4::text[])`
+
+func (f MessagePruneFilter) args() []any {
+	keep := f.KeepTagged
+	if keep == nil {
+		keep = []string{}
+	}
+	return []any{f.Cutoff, f.Status, f.RoleName, keep}
+}
+
+func (f MessagePruneFilter) summary() []string {
+	return []string{dbutil.ParamSummary("cutoff", f.Cutoff.Format(time.RFC3339)), dbutil.ParamSummary("status", f.Status), dbutil.ParamSummary("role", f.RoleName)}
+}
+
+// MessagePrunePlan is what a prune would remove, for --dry-run.
+type MessagePrunePlan struct {
+	Messages       int64 `json:"messages"`
+	Contents       int64 `json:"contents"`        // message content left unreferenced
+	ContentBytes   int64 `json:"content_bytes"`   // stored size of those contents
+	Scripts        int64 `json:"scripts"`         // unreferenced script content
+	ScriptBytes    int64 `json:"script_bytes"`    // stored size of that script content
+	ReclaimedBytes int64 `json:"reclaimed_bytes"` // ContentBytes + ScriptBytes
+}
+
+// PlanMessagePrune counts the messages f selects and the content that would
+// be collected afterwards: content no other message uses and script content
+// no script uses, both older than ContentGCGrace.
+func PlanMessagePrune(ctx context.Context, db *pgxpool.Pool, f MessagePruneFilter) (MessagePrunePlan, error) {
+	var p MessagePrunePlan
+	q := `WITH doomed AS (SELECT id, content_id FROM messages WHERE ` + pruneWhere + `),
+               freed AS (
+                   SELECT c.id, pg_column_size(c.text_content) + COALESCE(pg_column_size(c.json_content), 0) AS sz
+                   FROM messages_content c
+                   WHERE c.created_at < $5
+                     AND NOT EXISTS (SELECT 1 FROM messages m WHERE m.content_id = c.id AND m.id NOT IN (SELECT id FROM doomed))
+               )
+          SELECT (SELECT count(*) FROM doomed), (SELECT count(*) FROM freed), (SELECT COALESCE(sum(sz), 0) FROM freed)`
+	args := append(f.args(), time.Now().Add(-ContentGCGrace))
+	if err := db.QueryRow(ctx, q, args...).Scan(&p.Messages, &p.Contents, &p.ContentBytes); err != nil {
+		return p, dbutil.ErrWrap("message.prune.plan", err, f.summary()...)
+	}
+	q = `SELECT count(*), COALESCE(sum(pg_column_size(sc.script_content)), 0) FROM scripts_content sc
+         WHERE sc.created_at < $1 AND NOT EXISTS (SELECT 1 FROM scripts s WHERE s.script_content_id = sc.id)`
+	if err := db.QueryRow(ctx, q, time.Now().Add(-ContentGCGrace)).Scan(&p.Scripts, &p.ScriptBytes); err != nil {
+		return p, dbutil.ErrWrap("script_content.gc.plan", err)
+	}
+	p.ReclaimedBytes = p.ContentBytes + p.ScriptBytes
+	return p, nil
+}
+
+// DeleteMessagesBatch deletes up to limit of the oldest messages f selects
+// and returns how many were deleted.
+func DeleteMessagesBatch(ctx context.Context, db *pgxpool.Pool, f MessagePruneFilter, limit int) (int64, error) {
+	q := `DELETE FROM messages WHERE id IN (
+              SELECT id FROM messages WHERE ` + pruneWhere + ` ORDER BY created LIMIT $5)`
+	ct, err := db.Exec(ctx, q, append(f.args(), limit)...)
+	if err != nil {
+		return 0, dbutil.ErrWrap("message.prune", err, append(f.summary(), fmt.Sprintf("limit=%d", limit))...)
+	}
+	return ct.RowsAffected(), nil
+}
+
+// GCMessageContentBatch deletes up to limit messages_content rows no message
+// references (and older than ContentGCGrace); it returns the rows deleted and
+// their stored size in bytes.
+func GCMessageContentBatch(ctx context.Context, db *pgxpool.Pool, limit int) (int64, int64, error) {
+	q := `WITH del AS (
+              DELETE FROM messages_content c WHERE c.id IN (
+                  SELECT id FROM messages_content x
+                  WHERE x.created_at < $1 AND NOT EXISTS (SELECT 1 FROM messages m WHERE m.content_id = x.id)
+                  LIMIT $2)
+              RETURNING pg_column_size(c.text_content) + COALESCE(pg_column_size(c.json_content), 0) AS sz)
+          SELECT count(*), COALESCE(sum(sz), 0) FROM del`
+	var n, bytes int64
+	if err := db.QueryRow(ctx, q, time.Now().Add(-ContentGCGrace), limit).Scan(&n, &bytes); err != nil {
+		return 0, 0, dbutil.ErrWrap("message_content.gc", err, fmt.Sprintf("limit=%d", limit))
+	}
+	return n, bytes, nil
+}
+
+// GCScriptContentBatch deletes up to limit scripts_content rows no script
+// references (and older than ContentGCGrace); it returns the rows deleted and
+// their stored size in bytes.
+func GCScriptContentBatch(ctx context.Context, db *pgxpool.Pool, limit int) (int64, int64, error) {
+	q := `WITH del AS (
+              DELETE FROM scripts_content c WHERE c.id IN (
+                  SELECT id FROM scripts_content x
+                  WHERE x.created_at < $1 AND NOT EXISTS (SELECT 1 FROM scripts s WHERE s.script_content_id = x.id)
+                  LIMIT $2)
+              RETURNING pg_column_size(c.script_content) AS sz)
+          SELECT count(*), COALESCE(sum(sz), 0) FROM del`
+	var n, bytes int64
+	if err := db.QueryRow(ctx, q, time.Now().Add(-ContentGCGrace), limit).Scan(&n, &bytes); err != nil {
+		return 0, 0, dbutil.ErrWrap("script_content.gc", err, fmt.Sprintf("limit=%d", limit))
+	}
+	return n, bytes, nil
+}
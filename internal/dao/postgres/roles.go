@@ -18,6 +18,9 @@ type Role struct {
 	Tags        map[string]any
 	Created     sql.NullTime
 	Updated     sql.NullTime
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertRole creates or updates a role by name.
@@ -35,9 +38,12 @@ func UpsertRole(ctx context.Context, db *pgxpool.Pool, r *Role) error {
 	if r.Tags != nil {
 		tagsJSON, _ = json.Marshal(r.Tags)
 	}
-	if err := db.QueryRow(ctx, q,
-		r.Name, r.Title, stringOrEmpty(r.Description), stringOrEmpty(r.Notes), tagsJSON,
-	).Scan(&r.Created, &r.Updated); err != nil {
+	check := &versionCheck{entity: "role", key: r.Name, table: "roles", where: "name=$1", args: []any{r.Name}, p: r.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q,
+			r.Name, r.Title, stringOrEmpty(r.Description), stringOrEmpty(r.Notes), tagsJSON,
+		).Scan(&r.Created, &r.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("role.upsert", err, dbutil.ParamSummary("name", r.Name))
 	}
 	return nil
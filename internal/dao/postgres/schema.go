@@ -259,6 +259,17 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
             created TIMESTAMPTZ NOT NULL DEFAULT now()
         )`,
 		`CREATE INDEX IF NOT EXISTS idx_experiments_conversation ON experiments(conversation_id)`,
+		// Experiment metadata: what varied (params), lifecycle and numeric outcomes
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS name TEXT`,
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS params JSONB NOT NULL DEFAULT '{}'::jsonb`,
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'running'
+            CHECK (status IN ('running','succeeded','failed','aborted'))`,
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS started TIMESTAMPTZ`,
+		`UPDATE experiments SET started = created WHERE started IS NULL`,
+		`ALTER TABLE experiments ALTER COLUMN started SET DEFAULT now()`,
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS finished TIMESTAMPTZ`,
+		`ALTER TABLE experiments ADD COLUMN IF NOT EXISTS metrics JSONB NOT NULL DEFAULT '{}'::jsonb`,
+		`CREATE INDEX IF NOT EXISTS idx_experiments_status ON experiments(status)`,
 		// Task variants registry: one workflow per selector (variant)
 		`CREATE TABLE IF NOT EXISTS task_variants (
             variant TEXT PRIMARY KEY,
@@ -290,6 +301,8 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
 		`ALTER TABLE tasks DROP COLUMN IF EXISTS run_script_id`,
 		// Ensure archived column exists for tasks
 		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE`,
+		// Last write time, checked by --if-match on task set
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS updated TIMESTAMPTZ NOT NULL DEFAULT now()`,
 		// Task-Script attachments: associate scripts to tasks under logical names and optional aliases
 		`CREATE TABLE IF NOT EXISTS task_scripts (
             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -349,6 +362,10 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
             created TIMESTAMPTZ NOT NULL DEFAULT now(),
             UNIQUE (content_id, status, created)
         )`,
+		// Threading: the message this one replies to, and the roles it is addressed to
+		`ALTER TABLE messages ADD COLUMN IF NOT EXISTS parent_message_id UUID REFERENCES messages(id) ON DELETE SET NULL`,
+		`ALTER TABLE messages ADD COLUMN IF NOT EXISTS to_role TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_parent ON messages(parent_message_id)`,
 		// Packages per role_name: bind a role (e.g., user, admin) to a specific
 		// task (by id). Unique per (role_name, task_id).
 		`CREATE TABLE IF NOT EXISTS packages (
@@ -361,6 +378,9 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
         )`,
 		`CREATE INDEX IF NOT EXISTS idx_packages_role_name ON packages(role_name)`,
 		`CREATE INDEX IF NOT EXISTS idx_packages_task ON packages(task_id)`,
+		// Version constraint resolved through task_replaces levels (exact keeps task_id as-is)
+		`ALTER TABLE packages ADD COLUMN IF NOT EXISTS version_constraint TEXT NOT NULL DEFAULT 'exact'
+            CHECK (version_constraint IN ('exact','patch','minor','major'))`,
 		// Queue of work items
 		`CREATE TABLE IF NOT EXISTS queues (
             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -430,6 +450,30 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
             END IF;
         END $$;`,
 		`CREATE INDEX IF NOT EXISTS idx_blackboards_role_name ON blackboards(role_name)`,
+		// Lifecycle: archived flag and expiry derived from updated plus the lifecycle period
+		// (NULL for permanent). Arithmetic is done in UTC so the expression stays immutable.
+		`ALTER TABLE blackboards ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE blackboards ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ GENERATED ALWAYS AS (
+            CASE lifecycle
+                WHEN 'daily' THEN ((updated AT TIME ZONE 'UTC') + interval '1 day') AT TIME ZONE 'UTC'
+                WHEN 'weekly' THEN ((updated AT TIME ZONE 'UTC') + interval '7 days') AT TIME ZONE 'UTC'
+                WHEN 'monthly' THEN ((updated AT TIME ZONE 'UTC') + interval '1 month') AT TIME ZONE 'UTC'
+                WHEN 'quarterly' THEN ((updated AT TIME ZONE 'UTC') + interval '3 months') AT TIME ZONE 'UTC'
+                WHEN 'yearly' THEN ((updated AT TIME ZONE 'UTC') + interval '1 year') AT TIME ZONE 'UTC'
+            END
+        ) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_blackboards_expires_at ON blackboards(expires_at) WHERE archived = FALSE`,
+		// JSON Schemas for stickie structured payloads: label '*' applies to every
+		// stickie of the board, any other label to stickies carrying that label.
+		`CREATE TABLE IF NOT EXISTS blackboard_schemas (
+            blackboard_id UUID NOT NULL REFERENCES blackboards(id) ON DELETE CASCADE,
+            label TEXT NOT NULL DEFAULT '*' CHECK (label <> ''),
+            schema JSONB NOT NULL,
+            description TEXT,
+            created TIMESTAMPTZ NOT NULL DEFAULT now(),
+            updated TIMESTAMPTZ NOT NULL DEFAULT now(),
+            PRIMARY KEY (blackboard_id, label)
+        )`,
 		// Topics removed; use tags/labels instead
 		// Stickies: notes attached to blackboards, optionally associated to topics
 		`CREATE TABLE IF NOT EXISTS stickies (
@@ -497,6 +541,58 @@ func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
         )`,
 		`CREATE INDEX IF NOT EXISTS idx_stickie_relations_from ON stickie_relations(from_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_stickie_relations_to ON stickie_relations(to_id)`,
+		// updated backs --if-match on stickie-rel set
+		`ALTER TABLE stickie_relations ADD COLUMN IF NOT EXISTS updated TIMESTAMPTZ NOT NULL DEFAULT now()`,
+		// Stickie revisions: each content-changing update stores the prior version
+		// (rev = its edit_count) with who replaced it. The role comes from the
+		// transaction setting rbc.actor_role, else the board's role.
+		`CREATE TABLE IF NOT EXISTS stickie_revisions (
+            id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+            stickie_id UUID NOT NULL REFERENCES stickies(id) ON DELETE CASCADE,
+            rev INT NOT NULL,
+            blackboard_id UUID NOT NULL,
+            note TEXT,
+            code TEXT,
+            structured JSONB,
+            labels TEXT[],
+            priority_level TEXT,
+            score DOUBLE PRECISION,
+            name TEXT,
+            archived BOOLEAN NOT NULL DEFAULT FALSE,
+            valid_from TIMESTAMPTZ NOT NULL,
+            changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+            changed_by_task_id UUID,
+            changed_by_role TEXT,
+            UNIQUE (stickie_id, rev)
+        )`,
+		`CREATE OR REPLACE FUNCTION record_stickie_revision()
+         RETURNS TRIGGER AS $$
+         BEGIN
+            IF (OLD.blackboard_id, OLD.note, OLD.code, OLD.structured, OLD.labels, OLD.priority_level, OLD.score, OLD.name, OLD.archived)
+               IS DISTINCT FROM
+               (NEW.blackboard_id, NEW.note, NEW.code, NEW.structured, NEW.labels, NEW.priority_level, NEW.score, NEW.name, NEW.archived) THEN
+                INSERT INTO stickie_revisions (
+                    stickie_id, rev, blackboard_id, note, code, structured, labels, priority_level, score, name, archived,
+                    valid_from, changed_at, changed_by_task_id, changed_by_role
+                ) VALUES (
+                    OLD.id, OLD.edit_count, OLD.blackboard_id, OLD.note, OLD.code, OLD.structured, OLD.labels, OLD.priority_level, OLD.score, OLD.name, OLD.archived,
+                    OLD.updated, NEW.updated, NEW.created_by_task_id,
+                    COALESCE(NULLIF(current_setting('rbc.actor_role', true), ''), (SELECT role_name FROM blackboards WHERE id = NEW.blackboard_id))
+                ) ON CONFLICT (stickie_id, rev) DO NOTHING;
+            END IF;
+            RETURN NEW;
+         END;
+         $$ LANGUAGE plpgsql;`,
+		`DO $$ BEGIN
+            IF NOT EXISTS (
+                SELECT 1 FROM pg_trigger WHERE tgname = 'stickies_record_revision'
+            ) THEN
+                CREATE TRIGGER stickies_record_revision
+                AFTER UPDATE ON stickies
+                FOR EACH ROW
+                EXECUTE PROCEDURE record_stickie_revision();
+            END IF;
+        END $$;`,
 	}
 	for _, s := range stmts {
 		if _, err := db.Exec(ctx, s); err != nil {
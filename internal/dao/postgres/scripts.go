@@ -27,6 +27,9 @@ type Script struct {
 	Archived        bool
 	Created         sql.NullTime
 	Updated         sql.NullTime
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
 }
 
 type ScriptComplexName struct {
@@ -86,8 +89,11 @@ func UpsertScript(ctx context.Context, db *pgxpool.Pool, s *Script) error {
               updated=now()
           WHERE id=$1::uuid
           RETURNING created, updated`
-	if err := db.QueryRow(ctx, q, s.ID, s.Title, stringOrEmpty(s.Description), stringOrEmpty(s.Motivation), stringOrEmpty(s.Notes), s.ScriptContentID, s.RoleName, tagsJSON, string(cnJSON), s.Archived).
-		Scan(&s.Created, &s.Updated); err != nil {
+	check := &versionCheck{entity: "script", key: s.ID, table: "scripts", where: "id=$1::uuid", args: []any{s.ID}, p: s.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q, s.ID, s.Title, stringOrEmpty(s.Description), stringOrEmpty(s.Motivation), stringOrEmpty(s.Notes), s.ScriptContentID, s.RoleName, tagsJSON, string(cnJSON), s.Archived).
+			Scan(&s.Created, &s.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("script.upsert.update", err, dbutil.ParamSummary("id", s.ID), dbutil.ParamSummary("title", s.Title))
 	}
 	return nil
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,13 +13,22 @@ type StickieRelation struct {
 	ToID    string
 	RelType string
 	Labels  []string
+	Updated sql.NullTime
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 func UpsertStickieRelation(ctx context.Context, db *pgxpool.Pool, r StickieRelation) error {
 	q := `INSERT INTO stickie_relations (from_id, to_id, rel_type, labels)
           VALUES ($1::uuid,$2::uuid,$3,COALESCE($4, ARRAY[]::text[]))
-          ON CONFLICT (from_id,to_id,rel_type) DO UPDATE SET labels=EXCLUDED.labels`
-	_, err := db.Exec(ctx, q, r.FromID, r.ToID, r.RelType, pgTextArrayOrNil(r.Labels))
+          ON CONFLICT (from_id,to_id,rel_type) DO UPDATE SET labels=EXCLUDED.labels, updated=now()`
+	check := &versionCheck{entity: "stickie relation", key: r.FromID + "-" + r.RelType + "->" + r.ToID, table: "stickie_relations",
+		where: "from_id=$1::uuid AND to_id=$2::uuid AND rel_type=$3", args: []any{r.FromID, r.ToID, r.RelType}, p: r.IfMatch}
+	err := withWrite(ctx, db, check, func(x Querier) error {
+		_, err := x.Exec(ctx, q, r.FromID, r.ToID, r.RelType, pgTextArrayOrNil(r.Labels))
+		return err
+	})
 	return dbutil.ErrWrap("stickie_rel.upsert", err, dbutil.ParamSummary("from", r.FromID), dbutil.ParamSummary("to", r.ToID), dbutil.ParamSummary("type", r.RelType))
 }
 
@@ -31,9 +41,9 @@ func DeleteStickieRelation(ctx context.Context, db *pgxpool.Pool, fromID, toID,
 }
 
 func GetStickieRelation(ctx context.Context, db *pgxpool.Pool, fromID, toID, relType string) (*StickieRelation, error) {
-	q := `SELECT from_id::text, to_id::text, rel_type, labels FROM stickie_relations WHERE from_id=$1::uuid AND to_id=$2::uuid AND rel_type=$3`
+	q := `SELECT from_id::text, to_id::text, rel_type, labels, updated FROM stickie_relations WHERE from_id=$1::uuid AND to_id=$2::uuid AND rel_type=$3`
 	var r StickieRelation
-	if err := db.QueryRow(ctx, q, fromID, toID, relType).Scan(&r.FromID, &r.ToID, &r.RelType, &r.Labels); err != nil {
+	if err := db.QueryRow(ctx, q, fromID, toID, relType).Scan(&r.FromID, &r.ToID, &r.RelType, &r.Labels, &r.Updated); err != nil {
 		return nil, dbutil.ErrWrap("stickie_rel.get", err, dbutil.ParamSummary("from", fromID), dbutil.ParamSummary("to", toID), dbutil.ParamSummary("type", relType))
 	}
 	return &r, nil
@@ -69,3 +79,30 @@ func ListStickieRelations(ctx context.Context, db *pgxpool.Pool, id, dir string)
 	}
 	return out, nil
 }
+
+// ListBlackboardRelations returns the relations touching any stickie of a
+// blackboard (either endpoint), ordered by from, type, to.
+func ListBlackboardRelations(ctx context.Context, db *pgxpool.Pool, blackboardID string) ([]StickieRelation, error) {
+	q := `SELECT r.from_id::text, r.to_id::text, r.rel_type, r.labels
+          FROM stickie_relations r
+          WHERE r.from_id IN (SELECT id FROM stickies WHERE blackboard_id=$1::uuid)
+             OR r.to_id IN (SELECT id FROM stickies WHERE blackboard_id=$1::uuid)
+          ORDER BY r.from_id, r.rel_type, r.to_id`
+	rows, err := db.Query(ctx, q, blackboardID)
+	if err != nil {
+		return nil, dbutil.ErrWrap("stickie_rel.list_board", err, dbutil.ParamSummary("blackboard_id", blackboardID))
+	}
+	defer rows.Close()
+	out := []StickieRelation{}
+	for rows.Next() {
+		var r StickieRelation
+		if err := rows.Scan(&r.FromID, &r.ToID, &r.RelType, &r.Labels); err != nil {
+			return nil, dbutil.ErrWrap("stickie_rel.list_board.scan", err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("stickie_rel.list_board", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StickieRevision is a prior version of a stickie, stored by trigger when an
+// update changes its content. Rev is the edit_count the version had; ChangedAt,
+// ChangedByTaskID and ChangedByRole describe the update that replaced it.
+type StickieRevision struct {
+	ID              string
+	StickieID       string
+	Rev             int
+	BlackboardID    string
+	Note            sql.NullString
+	Code            sql.NullString
+	Structured      []byte
+	Labels          []string
+	PriorityLevel   sql.NullString
+	Score           sql.NullFloat64
+	Name            sql.NullString
+	Archived        bool
+	ValidFrom       time.Time
+	ChangedAt       time.Time
+	ChangedByTaskID sql.NullString
+	ChangedByRole   sql.NullString
+}
+
+type actorRoleKey struct{}
+
+// WithActorRole returns a context whose stickie updates are attributed to role
+// in the revision history.
+func WithActorRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, actorRoleKey{}, strings.TrimSpace(role))
+}
+
+func actorRole(ctx context.Context) string {
+	v, _ := ctx.Value(actorRoleKey{}).(string)
+	return v
+}
+
+const stickieRevisionCols = `id::text, stickie_id::text, rev, blackboard_id::text, note, code, structured, labels, priority_level, score, name, archived,
+       valid_from, changed_at, changed_by_task_id::text, changed_by_role`
+
+func scanStickieRevision(row interface{ Scan(...any) error }, r *StickieRevision) error {
+	return row.Scan(&r.ID, &r.StickieID, &r.Rev, &r.BlackboardID, &r.Note, &r.Code, &r.Structured, &r.Labels, &r.PriorityLevel, &r.Score, &r.Name, &r.Archived,
+		&r.ValidFrom, &r.ChangedAt, &r.ChangedByTaskID, &r.ChangedByRole)
+}
+
+// ListStickieRevisions returns stored prior versions of a stickie, newest first.
+func ListStickieRevisions(ctx context.Context, db *pgxpool.Pool, stickieID string, limit int) ([]StickieRevision, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := db.Query(ctx, `SELECT `+stickieRevisionCols+`
+                                FROM stickie_revisions WHERE stickie_id=$1::uuid
+                                ORDER BY rev DESC LIMIT $2`, stickieID, limit)
+	if err != nil {
+		return nil, dbutil.ErrWrap("stickie_revision.list", err, dbutil.ParamSummary("stickie_id", stickieID))
+	}
+	defer rows.Close()
+	var out []StickieRevision
+	for rows.Next() {
+		var r StickieRevision
+		if err := scanStickieRevision(rows, &r); err != nil {
+			return nil, dbutil.ErrWrap("stickie_revision.list.scan", err, dbutil.ParamSummary("stickie_id", stickieID))
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("stickie_revision.list", err, dbutil.ParamSummary("stickie_id", stickieID))
+	}
+	return out, nil
+}
+
+// GetStickieRevision fetches one stored version of a stickie by rev.
+func GetStickieRevision(ctx context.Context, db *pgxpool.Pool, stickieID string, rev int) (*StickieRevision, error) {
+	var r StickieRevision
+	row := db.QueryRow(ctx, `SELECT `+stickieRevisionCols+` FROM stickie_revisions WHERE stickie_id=$1::uuid AND rev=$2`, stickieID, rev)
+	if err := scanStickieRevision(row, &r); err != nil {
+		return nil, dbutil.ErrWrap("stickie_revision.get", err, dbutil.ParamSummary("stickie_id", stickieID), fmt.Sprintf("rev=%d", rev))
+	}
+	return &r, nil
+}
+
+// RevertStickie restores the content of revision rev onto the stickie. The
+// stickie keeps its board and creator; the replaced content becomes a new
+// revision, so a revert can itself be reverted. Returns the new edit_count.
+// A non-zero ifMatch makes the revert conditional on the current version; a
+// non-nil validate checks the restored content against the board's current
+// schemas before it is written.
+func RevertStickie(ctx context.Context, db *pgxpool.Pool, stickieID string, rev int, ifMatch Precondition, validate StickieValidator) (int, error) {
+	var editCount int
+	check := stickieCheck(&Stickie{ID: stickieID, IfMatch: ifMatch})
+	if validate != nil {
+		check.then = func(ctx context.Context, x Querier) error {
+			var board string
+			var labels []string
+			var structured []byte
+			if err := x.QueryRow(ctx, `SELECT s.blackboard_id::text, r.labels, r.structured
+                  FROM stickies s JOIN stickie_revisions r ON r.stickie_id=s.id AND r.rev=$2
+                  WHERE s.id=$1::uuid FOR UPDATE OF s`, stickieID, rev).Scan(&board, &labels, &structured); err != nil {
+				return err
+			}
+			return validateStickie(ctx, x, validate, fmt.Sprintf("stickie %s rev %d", stickieID, rev), board, labels, structured)
+		}
+	}
+	err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, `UPDATE stickies s
+              SET note=r.note, code=r.code, structured=r.structured, labels=r.labels,
+                  priority_level=r.priority_level, score=r.score, name=r.name, archived=r.archived
+              FROM stickie_revisions r
+              WHERE s.id=$1::uuid AND r.stickie_id=s.id AND r.rev=$2
+              RETURNING s.edit_count`, stickieID, rev).Scan(&editCount)
+	})
+	if err != nil {
+		return 0, dbutil.ErrWrap("stickie.revert", err, dbutil.ParamSummary("id", stickieID), fmt.Sprintf("rev=%d", rev))
+	}
+	return editCount, nil
+}
@@ -24,8 +24,19 @@ type Stickie struct {
 	Score           sql.NullFloat64
 	Name            sql.NullString
 	Archived        bool
+	Structured      []byte // raw JSON; nil on update keeps the stored payload
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
+	// Validate, when set, checks the labels and payload the write leaves
+	// against the board's schemas, read in the write transaction.
+	Validate StickieValidator
 }
 
+// StickieValidator checks the labels and structured payload a stickie will
+// have against its blackboard's schemas. subject names the stickie in errors.
+type StickieValidator func(subject string, schemas []BlackboardSchema, labels []string, structured []byte) error
+
 // UpsertStickie inserts a new stickie if ID is empty, otherwise updates it.
 func UpsertStickie(ctx context.Context, db *pgxpool.Pool, s *Stickie) error {
 	if s.ID != "" {
@@ -38,42 +49,140 @@ func UpsertStickie(ctx context.Context, db *pgxpool.Pool, s *Stickie) error {
                   priority_level=COALESCE(NULLIF($7,''), priority_level),
                   name=COALESCE(NULLIF($8,''), name),
                   archived=$9,
-                  score=COALESCE($10::double precision, score)
+                  score=COALESCE($10::double precision, score),
+                  structured=COALESCE($11::jsonb, structured)
               WHERE id=$1::uuid
               RETURNING created, updated, edit_count`
-		if err := db.QueryRow(ctx, q,
-			s.ID, s.BlackboardID, nullOrString(s.Note), stringOrEmpty(s.Code), pgTextArrayOrNil(s.Labels), nullOrUUID(s.CreatedByTaskID), nullOrString(s.PriorityLevel), nullOrString(s.Name), s.Archived, nullOrFloat64(s.Score),
-		).Scan(&s.Created, &s.Updated, &s.EditCount); err != nil {
+		check := stickieCheck(s)
+		if s.Validate != nil {
+			check.then = func(ctx context.Context, x Querier) error { return validateStickieUpdate(ctx, x, s) }
+		}
+		if err := withWrite(ctx, db, check, func(x Querier) error {
+			return x.QueryRow(ctx, q,
+				s.ID, s.BlackboardID, nullOrString(s.Note), stringOrEmpty(s.Code), pgTextArrayOrNil(s.Labels), nullOrUUID(s.CreatedByTaskID), nullOrString(s.PriorityLevel), nullOrString(s.Name), s.Archived, nullOrFloat64(s.Score), jsonOrNil(s.Structured),
+			).Scan(&s.Created, &s.Updated, &s.EditCount)
+		}); err != nil {
 			return dbutil.ErrWrap("stickie.upsert.update", err,
 				dbutil.ParamSummary("id", s.ID), dbutil.ParamSummary("blackboard_id", s.BlackboardID))
 		}
 		return nil
 	}
-	q := `INSERT INTO stickies (blackboard_id, note, code, labels, created_by_task_id, priority_level, name, archived, score)
-          VALUES ($1::uuid, NULLIF($2,''), NULLIF($3,''), COALESCE($4,ARRAY[]::text[]), CASE WHEN $5='' THEN NULL ELSE $5::uuid END, NULLIF($6,''), NULLIF($7,''), COALESCE($8,false), $9::double precision)
-          RETURNING id::text, created, updated, edit_count`
-	if err := db.QueryRow(ctx, q,
-		s.BlackboardID, stringOrEmpty(s.Note), stringOrEmpty(s.Code), pgTextArrayOrNil(s.Labels), stringOrEmpty(s.CreatedByTaskID), stringOrEmpty(s.PriorityLevel), stringOrEmpty(s.Name), s.Archived, nullOrFloat64(s.Score),
-	).Scan(&s.ID, &s.Created, &s.Updated, &s.EditCount); err != nil {
+	check := &versionCheck{}
+	if s.Validate != nil {
+		check.then = func(ctx context.Context, x Querier) error {
+			return validateStickie(ctx, x, s.Validate, "new stickie", s.BlackboardID, s.Labels, s.Structured)
+		}
+	}
+	if err := withWrite(ctx, db, check, func(x Querier) error { return insertStickie(ctx, x, s) }); err != nil {
 		return dbutil.ErrWrap("stickie.upsert.insert", err,
 			dbutil.ParamSummary("blackboard_id", s.BlackboardID))
 	}
 	return nil
 }
 
+// InsertStickie inserts s as a new stickie on db, e.g. inside a caller's
+// transaction. s.Validate and s.IfMatch are ignored.
+func InsertStickie(ctx context.Context, db Querier, s *Stickie) error {
+	if err := insertStickie(ctx, db, s); err != nil {
+		return dbutil.ErrWrap("stickie.insert", err, dbutil.ParamSummary("blackboard_id", s.BlackboardID))
+	}
+	return nil
+}
+
+func insertStickie(ctx context.Context, x Querier, s *Stickie) error {
+	q := `INSERT INTO stickies (blackboard_id, note, code, labels, created_by_task_id, priority_level, name, archived, score, structured)
+          VALUES ($1::uuid, NULLIF($2,''), NULLIF($3,''), COALESCE($4,ARRAY[]::text[]), CASE WHEN $5='' THEN NULL ELSE $5::uuid END, NULLIF($6,''), NULLIF($7,''), COALESCE($8,false), $9::double precision, $10::jsonb)
+          RETURNING id::text, created, updated, edit_count`
+	return x.QueryRow(ctx, q,
+		s.BlackboardID, stringOrEmpty(s.Note), stringOrEmpty(s.Code), pgTextArrayOrNil(s.Labels), stringOrEmpty(s.CreatedByTaskID), stringOrEmpty(s.PriorityLevel), stringOrEmpty(s.Name), s.Archived, nullOrFloat64(s.Score), jsonOrNil(s.Structured),
+	).Scan(&s.ID, &s.Created, &s.Updated, &s.EditCount)
+}
+
+// validateStickieUpdate locks the stickie and validates the content UpsertStickie
+// would leave: fields unset on s keep their stored values.
+func validateStickieUpdate(ctx context.Context, x Querier, s *Stickie) error {
+	var board string
+	var labels []string
+	var structured []byte
+	if err := x.QueryRow(ctx, `SELECT blackboard_id::text, labels, structured FROM stickies WHERE id=$1::uuid FOR UPDATE`, s.ID).Scan(&board, &labels, &structured); err != nil {
+		return err
+	}
+	if s.BlackboardID != "" {
+		board = s.BlackboardID
+	}
+	if s.Labels != nil {
+		labels = s.Labels
+	}
+	if s.Structured != nil {
+		structured = s.Structured
+	}
+	return validateStickie(ctx, x, s.Validate, "stickie "+s.ID, board, labels, structured)
+}
+
+// validateStickie runs validate with the schemas of board as seen by x.
+func validateStickie(ctx context.Context, x Querier, validate StickieValidator, subject, board string, labels []string, structured []byte) error {
+	schemas, err := ListBlackboardSchemas(ctx, x, board)
+	if err != nil {
+		return err
+	}
+	return validate(subject, schemas, labels, structured)
+}
+
+func stickieCheck(s *Stickie) *versionCheck {
+	return &versionCheck{entity: "stickie", key: s.ID, table: "stickies", where: "id=$1::uuid", args: []any{s.ID}, hasEditCount: true, p: s.IfMatch}
+}
+
+// ReplaceStickieContent overwrites every content column of an existing stickie,
+// clearing fields that are empty (unlike UpsertStickie which keeps old values).
+// With s.Validate set, the new content is checked in the write transaction.
+func ReplaceStickieContent(ctx context.Context, db *pgxpool.Pool, s *Stickie) error {
+	q := `UPDATE stickies
+          SET note=NULLIF($2,''),
+              code=NULLIF($3,''),
+              labels=COALESCE($4, ARRAY[]::text[]),
+              created_by_task_id=CASE WHEN $5='' THEN NULL ELSE $5::uuid END,
+              priority_level=NULLIF($6,''),
+              name=NULLIF($7,''),
+              archived=$8,
+              score=$9::double precision,
+              structured=$10::jsonb,
+              updated=now()
+          WHERE id=$1::uuid
+          RETURNING created, updated, edit_count`
+	check := stickieCheck(s)
+	if s.Validate != nil {
+		check.then = func(ctx context.Context, x Querier) error {
+			// The content is replaced as a whole; only the board is kept.
+			var board string
+			if err := x.QueryRow(ctx, `SELECT blackboard_id::text FROM stickies WHERE id=$1::uuid FOR UPDATE`, s.ID).Scan(&board); err != nil {
+				return err
+			}
+			return validateStickie(ctx, x, s.Validate, "stickie "+s.ID, board, s.Labels, s.Structured)
+		}
+	}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q,
+			s.ID, stringOrEmpty(s.Note), stringOrEmpty(s.Code), pgTextArrayOrNil(s.Labels), stringOrEmpty(s.CreatedByTaskID), stringOrEmpty(s.PriorityLevel), stringOrEmpty(s.Name), s.Archived, nullOrFloat64(s.Score), jsonOrNil(s.Structured),
+		).Scan(&s.Created, &s.Updated, &s.EditCount)
+	}); err != nil {
+		return dbutil.ErrWrap("stickie.replace", err, dbutil.ParamSummary("id", s.ID))
+	}
+	return nil
+}
+
 // GetStickieByID fetches a stickie by UUID.
 func GetStickieByID(ctx context.Context, db *pgxpool.Pool, id string) (*Stickie, error) {
-	q := `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived
+	q := `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
           FROM stickies WHERE id=$1::uuid`
 	var s Stickie
-	if err := db.QueryRow(ctx, q, id).Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels, &s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived); err != nil {
+	if err := db.QueryRow(ctx, q, id).Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels, &s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived, &s.Structured); err != nil {
 		return nil, dbutil.ErrWrap("stickie.get", err, dbutil.ParamSummary("id", id))
 	}
 	return &s, nil
 }
 
 // ListStickies lists stickies with optional filters.
-func ListStickies(ctx context.Context, db *pgxpool.Pool, blackboardID string, limit, offset int) ([]Stickie, error) {
+func ListStickies(ctx context.Context, db Querier, blackboardID string, limit, offset int) ([]Stickie, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -84,11 +193,11 @@ func ListStickies(ctx context.Context, db *pgxpool.Pool, blackboardID string, li
 	var err error
 	switch {
 	case stringsTrim(blackboardID) != "":
-		rows, err = db.Query(ctx, `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived
+		rows, err = db.Query(ctx, `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
                                    FROM stickies WHERE blackboard_id=$1::uuid
                                    ORDER BY updated DESC, created DESC LIMIT $2 OFFSET $3`, blackboardID, limit, offset)
 	default:
-		rows, err = db.Query(ctx, `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived
+		rows, err = db.Query(ctx, `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
                                    FROM stickies ORDER BY updated DESC, created DESC LIMIT $1 OFFSET $2`, limit, offset)
 	}
 	if err != nil {
@@ -99,7 +208,7 @@ func ListStickies(ctx context.Context, db *pgxpool.Pool, blackboardID string, li
 	var out []Stickie
 	for rows.Next() {
 		var s Stickie
-		if err := rows.Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels, &s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived); err != nil {
+		if err := rows.Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels, &s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived, &s.Structured); err != nil {
 			return nil, dbutil.ErrWrap("stickie.list.scan", err, dbutil.ParamSummary("blackboard_id", blackboardID))
 		}
 		out = append(out, s)
@@ -123,7 +232,7 @@ func DeleteStickie(ctx context.Context, db *pgxpool.Pool, id string) (int64, err
 func GetStickieByName(ctx context.Context, db *pgxpool.Pool, name string, archived bool) (*Stickie, error) {
 	const q = `
         SELECT id::text, blackboard_id::text, note, code, labels,
-               created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived
+               created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
         FROM stickies
         WHERE name = $1
           AND archived = $2
@@ -132,7 +241,7 @@ func GetStickieByName(ctx context.Context, db *pgxpool.Pool, name string, archiv
 	var s Stickie
 	if err := db.QueryRow(ctx, q, name, archived).
 		Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels,
-			&s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived); err != nil {
+			&s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived, &s.Structured); err != nil {
 		return nil, dbutil.ErrWrap("stickie.get_by_name", err, dbutil.ParamSummary("name", name), dbutil.ParamSummary("archived", archived))
 	}
 	return &s, nil
@@ -142,7 +251,7 @@ func GetStickieByName(ctx context.Context, db *pgxpool.Pool, name string, archiv
 func GetStickieByNameInBlackboard(ctx context.Context, db *pgxpool.Pool, name string, archived bool, blackboardID string) (*Stickie, error) {
 	const q = `
         SELECT id::text, blackboard_id::text, note, code, labels,
-               created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived
+               created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
         FROM stickies
         WHERE blackboard_id = $1::uuid
           AND name = $2
@@ -152,8 +261,66 @@ func GetStickieByNameInBlackboard(ctx context.Context, db *pgxpool.Pool, name st
 	var s Stickie
 	if err := db.QueryRow(ctx, q, blackboardID, name, archived).
 		Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels,
-			&s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived); err != nil {
+			&s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived, &s.Structured); err != nil {
 		return nil, dbutil.ErrWrap("stickie.get_by_name_board", err, dbutil.ParamSummary("board", blackboardID), dbutil.ParamSummary("name", name), dbutil.ParamSummary("archived", archived))
 	}
 	return &s, nil
 }
+
+// ArchiveBlackboardStickies archives every unarchived stickie of a blackboard
+// except keepID (e.g. a summary that replaces them).
+func ArchiveBlackboardStickies(ctx context.Context, db Querier, blackboardID, keepID string) (int64, error) {
+	ct, err := db.Exec(ctx, `UPDATE stickies SET archived=TRUE
+                             WHERE blackboard_id=$1::uuid AND NOT archived AND id::text <> $2`, blackboardID, keepID)
+	if err != nil {
+		return 0, dbutil.ErrWrap("stickie.archive_blackboard", err, dbutil.ParamSummary("blackboard_id", blackboardID))
+	}
+	return ct.RowsAffected(), nil
+}
+
+// StickieQuery filters stickies by a SQL/JSON path over their structured payload.
+type StickieQuery struct {
+	BlackboardID    string   // optional board scope
+	JSONPath        string   // jsonb_path_exists semantics, e.g. $.status ? (@ == "done")
+	Labels          []string // stickies must carry all of these labels
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+// QueryStickies returns stickies whose structured payload matches q.JSONPath,
+// newest first. Stickies without a payload never match.
+func QueryStickies(ctx context.Context, db *pgxpool.Pool, q StickieQuery) ([]Stickie, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	rows, err := db.Query(ctx, `SELECT id::text, blackboard_id::text, note, code, labels, created, updated, created_by_task_id::text, edit_count, priority_level, score, name, archived, structured
+                                FROM stickies
+                                WHERE structured IS NOT NULL
+                                  AND jsonb_path_exists(structured, $1::jsonpath)
+                                  AND ($2 = '' OR blackboard_id = $2::uuid)
+                                  AND ($3::text[] IS NULL OR labels @> $3::text[])
+                                  AND ($4 OR NOT archived)
+                                ORDER BY updated DESC, created DESC LIMIT $5 OFFSET $6`,
+		q.JSONPath, q.BlackboardID, pgTextArrayOrNil(q.Labels), q.IncludeArchived, q.Limit, q.Offset)
+	if err != nil {
+		return nil, dbutil.ErrWrap("stickie.query", err, dbutil.ParamSummary("jsonpath", q.JSONPath),
+			dbutil.ParamSummary("blackboard_id", q.BlackboardID), fmt.Sprintf("limit=%d", q.Limit))
+	}
+	defer rows.Close()
+	var out []Stickie
+	for rows.Next() {
+		var s Stickie
+		if err := rows.Scan(&s.ID, &s.BlackboardID, &s.Note, &s.Code, &s.Labels, &s.Created, &s.Updated, &s.CreatedByTaskID, &s.EditCount, &s.PriorityLevel, &s.Score, &s.Name, &s.Archived, &s.Structured); err != nil {
+			return nil, dbutil.ErrWrap("stickie.query.scan", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("stickie.query", err)
+	}
+	return out, nil
+}
@@ -17,6 +17,9 @@ type Tag struct {
 	RoleName    string
 	Created     sql.NullTime
 	Updated     sql.NullTime
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertTag inserts or updates a tag by name.
@@ -30,7 +33,10 @@ func UpsertTag(ctx context.Context, db *pgxpool.Pool, t *Tag) error {
             role_name = EXCLUDED.role_name,
             updated = now()
           RETURNING created, updated`
-	if err := db.QueryRow(ctx, q, t.Name, t.Title, stringOrEmpty(t.Description), stringOrEmpty(t.Notes), t.RoleName).Scan(&t.Created, &t.Updated); err != nil {
+	check := &versionCheck{entity: "tag", key: t.Name, table: "tags", where: "name=$1", args: []any{t.Name}, p: t.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q, t.Name, t.Title, stringOrEmpty(t.Description), stringOrEmpty(t.Notes), t.RoleName).Scan(&t.Created, &t.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("tag.upsert", err, dbutil.ParamSummary("name", t.Name))
 	}
 	return nil
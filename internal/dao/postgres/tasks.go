@@ -21,6 +21,7 @@ type Task struct {
 	Description     sql.NullString
 	Motivation      sql.NullString
 	Created         sql.NullTime
+	Updated         sql.NullTime
 	Notes           sql.NullString
 	Shell           sql.NullString
 	Timeout         sql.NullString // textual interval
@@ -28,6 +29,9 @@ type Task struct {
 	Tags            map[string]any
 	Level           sql.NullString // h1..h6
 	Archived        bool
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertTask inserts or updates a task identified by (workflow_id, name, version).
@@ -94,22 +98,27 @@ func UpsertTask(ctx context.Context, db *pgxpool.Pool, t *Task) error {
             tags = EXCLUDED.tags,
             level = EXCLUDED.level,
             role_name = EXCLUDED.role_name,
-            archived = EXCLUDED.archived
-          RETURNING id, created`
+            archived = EXCLUDED.archived,
+            updated = now()
+          RETURNING id, created, updated`
 	var id string
-	var created sql.NullTime
+	var created, updated sql.NullTime
 	var tagsJSON []byte
 	if t.Tags != nil {
 		tagsJSON, _ = json.Marshal(t.Tags)
 	}
-	if err := db.QueryRow(ctx, q,
-		t.Command, t.Variant, t.RoleName, stringOrEmpty(t.Title), stringOrEmpty(t.Description), stringOrEmpty(t.Motivation),
-		stringOrEmpty(t.Notes), stringOrEmpty(t.Shell), stringOrEmpty(t.Timeout), stringOrEmpty(t.ToolWorkspaceID), tagsJSON, stringOrEmpty(t.Level), t.Archived,
-	).Scan(&id, &created); err != nil {
+	check := &versionCheck{entity: "task", key: t.Variant, table: "tasks", where: "variant=$1", args: []any{t.Variant}, p: t.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q,
+			t.Command, t.Variant, t.RoleName, stringOrEmpty(t.Title), stringOrEmpty(t.Description), stringOrEmpty(t.Motivation),
+			stringOrEmpty(t.Notes), stringOrEmpty(t.Shell), stringOrEmpty(t.Timeout), stringOrEmpty(t.ToolWorkspaceID), tagsJSON, stringOrEmpty(t.Level), t.Archived,
+		).Scan(&id, &created, &updated)
+	}); err != nil {
 		return fmt.Errorf("upsert task: write failed: %w; %s", err, summarize(t))
 	}
 	t.ID = id
 	t.Created = created
+	t.Updated = updated
 	// Ensure a Task vertex exists/updated in AGE graph
 	// Best-effort: AGE graph writes are optional in this deployment. Avoid failing the upsert
 	// if the graph is unavailable or lacks privileges; diagnostics are available via age-status.
@@ -120,7 +129,7 @@ func UpsertTask(ctx context.Context, db *pgxpool.Pool, t *Task) error {
 // GetTaskByID fetches a task by numeric id.
 func GetTaskByID(ctx context.Context, db *pgxpool.Pool, id string) (*Task, error) {
 	q := `SELECT t.id::text, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                 t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                 t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
           FROM tasks t
           LEFT JOIN task_variants tv ON tv.variant = t.variant
           WHERE t.id=$1::uuid`
@@ -128,7 +137,7 @@ func GetTaskByID(ctx context.Context, db *pgxpool.Pool, id string) (*Task, error
 	var tagsJSON []byte
 	if err := db.QueryRow(ctx, q, id).Scan(
 		&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
-		&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created,
+		&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated,
 	); err != nil {
 		return nil, dbutil.ErrWrap("task.get", err, dbutil.ParamSummary("id", id))
 	}
@@ -139,9 +148,9 @@ func GetTaskByID(ctx context.Context, db *pgxpool.Pool, id string) (*Task, error
 }
 
 // GetTaskByVariant fetches a task by variant.
-func GetTaskByVariant(ctx context.Context, db *pgxpool.Pool, variant string) (*Task, error) {
+func GetTaskByVariant(ctx context.Context, db Querier, variant string) (*Task, error) {
 	q := `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                 t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                 t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
           FROM tasks t
           LEFT JOIN task_variants tv ON tv.variant = t.variant
           WHERE t.variant=$1`
@@ -149,7 +158,7 @@ func GetTaskByVariant(ctx context.Context, db *pgxpool.Pool, variant string) (*T
 	var tagsJSON []byte
 	if err := db.QueryRow(ctx, q, variant).Scan(
 		&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
-		&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created,
+		&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated,
 	); err != nil {
 		return nil, dbutil.ErrWrap("task.get", err, dbutil.ParamSummary("variant", variant))
 	}
@@ -171,14 +180,14 @@ func ListTasks(ctx context.Context, db *pgxpool.Pool, workflow, roleName string,
 	var err error
 	if stringsTrim(workflow) == "" {
 		rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                    FROM tasks t
                                    LEFT JOIN task_variants tv ON tv.variant = t.variant
                                    WHERE t.role_name=$1
                                    ORDER BY t.variant ASC LIMIT $2 OFFSET $3`, roleName, limit, offset)
 	} else {
 		rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                    FROM tasks t
                                    LEFT JOIN task_variants tv ON tv.variant = t.variant
                                    WHERE tv.workflow_id=$1 AND t.role_name=$2
@@ -193,7 +202,7 @@ func ListTasks(ctx context.Context, db *pgxpool.Pool, workflow, roleName string,
 		var t Task
 		var tagsJSON []byte
 		if err := rows.Scan(&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
-			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created); err != nil {
+			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated); err != nil {
 			return nil, dbutil.ErrWrap("task.list.scan", err)
 		}
 		if len(tagsJSON) > 0 {
@@ -217,7 +226,7 @@ func ListTasksSearch(ctx context.Context, db *pgxpool.Pool, roleName, search str
 	}
 	like := "%" + strings.TrimSpace(search) + "%"
 	rows, err := db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                    FROM tasks t
                                    LEFT JOIN task_variants tv ON tv.variant = t.variant
                                    WHERE t.role_name=$1 AND (
@@ -233,7 +242,7 @@ func ListTasksSearch(ctx context.Context, db *pgxpool.Pool, roleName, search str
 		var t Task
 		var tagsJSON []byte
 		if err := rows.Scan(&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
-			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created); err != nil {
+			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated); err != nil {
 			return nil, dbutil.ErrWrap("task.search.scan", err)
 		}
 		if len(tagsJSON) > 0 {
@@ -266,21 +275,21 @@ func ListTasksWithArchived(ctx context.Context, db *pgxpool.Pool, workflow, role
 		switch {
 		case activeOnly:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE t.role_name=$1 AND t.archived=false
                                        ORDER BY t.variant ASC LIMIT $2 OFFSET $3`, roleName, limit, offset)
 		case archivedOnly:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE t.role_name=$1 AND t.archived=true
                                        ORDER BY t.variant ASC LIMIT $2 OFFSET $3`, roleName, limit, offset)
 		default:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE t.role_name=$1
@@ -290,21 +299,21 @@ func ListTasksWithArchived(ctx context.Context, db *pgxpool.Pool, workflow, role
 		switch {
 		case activeOnly:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE tv.workflow_id=$1 AND t.role_name=$2 AND t.archived=false
                                        ORDER BY t.variant ASC LIMIT $3 OFFSET $4`, workflow, roleName, limit, offset)
 		case archivedOnly:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE tv.workflow_id=$1 AND t.role_name=$2 AND t.archived=true
                                        ORDER BY t.variant ASC LIMIT $3 OFFSET $4`, workflow, roleName, limit, offset)
 		default:
 			rows, err = db.Query(ctx, `SELECT t.id, tv.workflow_id, t.command, t.variant, t.title, t.description, t.motivation,
-                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created
+                                            t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
                                        FROM tasks t
                                        LEFT JOIN task_variants tv ON tv.variant = t.variant
                                        WHERE tv.workflow_id=$1 AND t.role_name=$2
@@ -320,7 +329,7 @@ func ListTasksWithArchived(ctx context.Context, db *pgxpool.Pool, workflow, role
 		var t Task
 		var tagsJSON []byte
 		if err := rows.Scan(&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.Title, &t.Description, &t.Motivation,
-			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created); err != nil {
+			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated); err != nil {
 			return nil, dbutil.ErrWrap("task.list.scan", err)
 		}
 		if len(tagsJSON) > 0 {
@@ -334,6 +343,37 @@ func ListTasksWithArchived(ctx context.Context, db *pgxpool.Pool, workflow, role
 	return out, nil
 }
 
+// ListWorkflowTasks lists every task bound to a workflow (all roles, archived included).
+func ListWorkflowTasks(ctx context.Context, db *pgxpool.Pool, workflow string) ([]Task, error) {
+	rows, err := db.Query(ctx, `SELECT t.id::text, tv.workflow_id, t.command, t.variant, t.role_name, t.title, t.description, t.motivation,
+                                        t.notes, t.shell, t.timeout::text, t.tool_workspace_id::text, t.tags, t.level, t.archived, t.created, t.updated
+                                   FROM tasks t
+                                   JOIN task_variants tv ON tv.variant = t.variant
+                                   WHERE tv.workflow_id=$1
+                                   ORDER BY t.variant ASC`, workflow)
+	if err != nil {
+		return nil, dbutil.ErrWrap("task.list_workflow", err, dbutil.ParamSummary("workflow", workflow))
+	}
+	defer rows.Close()
+	var out []Task
+	for rows.Next() {
+		var t Task
+		var tagsJSON []byte
+		if err := rows.Scan(&t.ID, &t.WorkflowID, &t.Command, &t.Variant, &t.RoleName, &t.Title, &t.Description, &t.Motivation,
+			&t.Notes, &t.Shell, &t.Timeout, &t.ToolWorkspaceID, &tagsJSON, &t.Level, &t.Archived, &t.Created, &t.Updated); err != nil {
+			return nil, dbutil.ErrWrap("task.list_workflow.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &t.Tags)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("task.list_workflow", err)
+	}
+	return out, nil
+}
+
 // DeleteTaskByID deletes a task by id.
 func DeleteTaskByID(ctx context.Context, db *pgxpool.Pool, id string) (int64, error) {
 	ct, err := db.Exec(ctx, `DELETE FROM tasks WHERE id=$1::uuid`, id)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -29,6 +30,29 @@ type Testcase struct {
 }
 
 func InsertTestcase(ctx context.Context, db *pgxpool.Pool, t *Testcase) error {
+	return insertTestcase(ctx, db, t)
+}
+
+// InsertTestcases inserts all testcases in one transaction: either every row
+// is stored or none is.
+func InsertTestcases(ctx context.Context, db *pgxpool.Pool, ts []*Testcase) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return dbutil.ErrWrap("testcase.insert_many.begin", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	for i, t := range ts {
+		if err := insertTestcase(ctx, tx, t); err != nil {
+			return fmt.Errorf("testcase %d of %d: %w", i+1, len(ts), err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return dbutil.ErrWrap("testcase.insert_many.commit", err, fmt.Sprintf("count=%d", len(ts)))
+	}
+	return nil
+}
+
+func insertTestcase(ctx context.Context, db Querier, t *Testcase) error {
 	q := `INSERT INTO testcases (
             name, package, classname, title, experiment_id, role_name, status, error_message, tags, level, file, line, execution_time
           ) VALUES (
@@ -95,6 +119,33 @@ func ListTestcases(ctx context.Context, db *pgxpool.Pool, roleName, experimentID
 	return out, nil
 }
 
+// ListExperimentTestcases lists every testcase of an experiment, whatever its
+// role, in recording order.
+func ListExperimentTestcases(ctx context.Context, db *pgxpool.Pool, experimentID string) ([]Testcase, error) {
+	rows, err := db.Query(ctx, `SELECT id::text, name, package, classname, title, experiment_id::text, role_name, status, error_message, tags, level, created, file, line, execution_time
+                                FROM testcases WHERE experiment_id=$1::uuid ORDER BY created, id`, experimentID)
+	if err != nil {
+		return nil, dbutil.ErrWrap("testcase.list_experiment", err, dbutil.ParamSummary("experiment_id", experimentID))
+	}
+	defer rows.Close()
+	var out []Testcase
+	for rows.Next() {
+		var t Testcase
+		var tagsJSON []byte
+		if err := rows.Scan(&t.ID, &t.Name, &t.Package, &t.Classname, &t.Title, &t.ExperimentID, &t.RoleName, &t.Status, &t.ErrorMessage, &tagsJSON, &t.Level, &t.Created, &t.File, &t.Line, &t.ExecutionTime); err != nil {
+			return nil, dbutil.ErrWrap("testcase.list_experiment.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &t.Tags)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("testcase.list_experiment", err)
+	}
+	return out, nil
+}
+
 func DeleteTestcase(ctx context.Context, db *pgxpool.Pool, id string) (int64, error) {
 	ct, err := db.Exec(ctx, `DELETE FROM testcases WHERE id=$1::uuid`, id)
 	if err != nil {
@@ -130,3 +181,47 @@ func nullOrFloat(f sql.NullFloat64) any {
 	}
 	return nil
 }
+
+// TestcaseRun is a testcase together with the creation time of its experiment,
+// which orders runs of the same test.
+type TestcaseRun struct {
+	Testcase
+	ExperimentCreated time.Time
+}
+
+// ListTestcaseRuns lists the testcases recorded under experiments, filtered by
+// conversation and/or role, oldest experiment first. A zero since keeps all.
+func ListTestcaseRuns(ctx context.Context, db *pgxpool.Pool, conversationID, roleName string, since time.Time) ([]TestcaseRun, error) {
+	q := `SELECT t.id::text, t.name, t.package, t.classname, t.title, t.experiment_id::text, t.role_name, t.status, t.error_message, t.tags, t.level, t.created, t.file, t.line, t.execution_time, e.created
+          FROM testcases t JOIN experiments e ON e.id = t.experiment_id
+          WHERE ($1 = '' OR e.conversation_id = NULLIF($1,'')::uuid)
+            AND ($2 = '' OR t.role_name = $2)
+            AND ($3::timestamptz IS NULL OR e.created >= $3::timestamptz)
+          ORDER BY e.created, e.id, t.created`
+	var sinceArg any
+	if !since.IsZero() {
+		sinceArg = since
+	}
+	rows, err := db.Query(ctx, q, stringsTrim(conversationID), stringsTrim(roleName), sinceArg)
+	if err != nil {
+		return nil, dbutil.ErrWrap("testcase.runs", err, dbutil.ParamSummary("conversation_id", conversationID), dbutil.ParamSummary("role", roleName))
+	}
+	defer rows.Close()
+	var out []TestcaseRun
+	for rows.Next() {
+		var r TestcaseRun
+		var tagsJSON []byte
+		t := &r.Testcase
+		if err := rows.Scan(&t.ID, &t.Name, &t.Package, &t.Classname, &t.Title, &t.ExperimentID, &t.RoleName, &t.Status, &t.ErrorMessage, &tagsJSON, &t.Level, &t.Created, &t.File, &t.Line, &t.ExecutionTime, &r.ExperimentCreated); err != nil {
+			return nil, dbutil.ErrWrap("testcase.runs.scan", err)
+		}
+		if len(tagsJSON) > 0 {
+			_ = json.Unmarshal(tagsJSON, &t.Tags)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbutil.ErrWrap("testcase.runs", err)
+	}
+	return out, nil
+}
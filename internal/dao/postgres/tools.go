@@ -21,6 +21,9 @@ type Tool struct {
 	Tags        map[string]any
 	Settings    map[string]any
 	ToolType    sql.NullString
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertTool inserts or updates a tool identified by name (role-scoped via role_name column).
@@ -56,9 +59,12 @@ func UpsertTool(ctx context.Context, db *pgxpool.Pool, t *Tool) error {
 	} else {
 		settingsParam = nil // allow COALESCE to apply '{}'
 	}
-	if err := db.QueryRow(ctx, q,
-		t.Name, t.Title, stringOrEmpty(t.Description), t.RoleName, stringOrEmpty(t.Notes), tagsParam, settingsParam, stringOrEmpty(t.ToolType),
-	).Scan(&t.Created, &t.Updated); err != nil {
+	check := &versionCheck{entity: "tool", key: t.Name, table: "tools", where: "name=$1", args: []any{t.Name}, p: t.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q,
+			t.Name, t.Title, stringOrEmpty(t.Description), t.RoleName, stringOrEmpty(t.Notes), tagsParam, settingsParam, stringOrEmpty(t.ToolType),
+		).Scan(&t.Created, &t.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("tool.upsert", err, dbutil.ParamSummary("name", t.Name), dbutil.ParamSummary("role", t.RoleName))
 	}
 	return nil
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Precondition is the version a writer expects to replace. A zero value means
+// an unconditional write; otherwise the row must exist and match every field set.
+type Precondition struct {
+	Updated   *time.Time
+	EditCount *int
+}
+
+// IsZero reports whether no expectation is set.
+func (p Precondition) IsZero() bool { return p.Updated == nil && p.EditCount == nil }
+
+func (p Precondition) String() string {
+	switch {
+	case p.EditCount != nil:
+		return "edit_count=" + strconv.Itoa(*p.EditCount)
+	case p.Updated != nil:
+		return "updated=" + p.Updated.UTC().Format(time.RFC3339Nano)
+	}
+	return "any"
+}
+
+// ParseIfMatch parses an --if-match value: an RFC3339 timestamp is compared to
+// the row's updated column, an integer to its edit_count (stickies only).
+func ParseIfMatch(s string) (Precondition, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Precondition{}, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return Precondition{}, fmt.Errorf("invalid --if-match %q: edit_count must not be negative", s)
+		}
+		return Precondition{EditCount: &n}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return Precondition{}, fmt.Errorf("invalid --if-match %q (want an RFC3339 updated timestamp or an edit_count)", s)
+	}
+	// Postgres keeps microseconds; drop anything finer so echoes compare equal.
+	t = t.Truncate(time.Microsecond)
+	return Precondition{Updated: &t}, nil
+}
+
+// Version is the current version of a row, reported on conflicts.
+type Version struct {
+	Exists    bool       `json:"exists"`
+	Updated   *time.Time `json:"updated,omitempty"`
+	EditCount *int       `json:"edit_count,omitempty"`
+}
+
+func (v Version) String() string {
+	if !v.Exists {
+		return "missing"
+	}
+	var parts []string
+	if v.Updated != nil {
+		parts = append(parts, "updated="+v.Updated.UTC().Format(time.RFC3339Nano))
+	}
+	if v.EditCount != nil {
+		parts = append(parts, "edit_count="+strconv.Itoa(*v.EditCount))
+	}
+	return strings.Join(parts, " ")
+}
+
+// VersionConflictExitCode is the process exit status for rejected --if-match writes.
+const VersionConflictExitCode = 3
+
+// VersionConflictError reports a write rejected because the row changed (or
+// disappeared) since the caller read it. Current holds the version to re-read.
+type VersionConflictError struct {
+	Entity   string
+	Key      string
+	Expected Precondition
+	Current  Version
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("version conflict on %s %s: expected %s, current %s", e.Entity, e.Key, e.Expected, e.Current)
+}
+
+func (e *VersionConflictError) ExitCode() int { return VersionConflictExitCode }
+
+// AsVersionConflict unwraps a VersionConflictError from err.
+func AsVersionConflict(err error) (*VersionConflictError, bool) {
+	var vc *VersionConflictError
+	if errors.As(err, &vc) {
+		return vc, true
+	}
+	return nil, false
+}
+
+// versionCheck locks the row selected by where and compares it with p. It runs
+// inside the write transaction so the version cannot change before the update.
+type versionCheck struct {
+	entity       string
+	key          string
+	table        string
+	where        string
+	args         []any
+	hasEditCount bool
+	p            Precondition
+	// then runs after the version check, inside the same transaction.
+	then func(context.Context, Querier) error
+}
+
+func (c *versionCheck) run(ctx context.Context, x Querier) error {
+	if c.p.EditCount != nil && !c.hasEditCount {
+		return fmt.Errorf("%s has no edit_count; use an updated timestamp with --if-match", c.entity)
+	}
+	cols := "updated, NULL::int"
+	if c.hasEditCount {
+		cols = "updated, edit_count"
+	}
+	var updated *time.Time
+	var editCount *int
+	err := x.QueryRow(ctx, `SELECT `+cols+` FROM `+c.table+` WHERE `+c.where+` FOR UPDATE`, c.args...).Scan(&updated, &editCount)
+	cur := Version{Exists: err == nil, Updated: updated, EditCount: editCount}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	ok := cur.Exists
+	if ok && c.p.Updated != nil {
+		ok = updated != nil && updated.Equal(*c.p.Updated)
+	}
+	if ok && c.p.EditCount != nil {
+		ok = editCount != nil && *editCount == *c.p.EditCount
+	}
+	if !ok {
+		return &VersionConflictError{Entity: c.entity, Key: c.key, Expected: c.p, Current: cur}
+	}
+	return nil
+}
+
+// withWrite runs fn directly on db, or inside a transaction when ctx carries an
+// actor role (set as rbc.actor_role for the revision trigger) or a version check
+// or validation must hold until the write commits.
+func withWrite(ctx context.Context, db *pgxpool.Pool, check *versionCheck, fn func(Querier) error) error {
+	if actorRole(ctx) == "" && (check == nil || (check.p.IsZero() && check.then == nil)) {
+		return fn(db)
+	}
+	return InTx(ctx, db, func(tx Querier) error {
+		if check != nil && !check.p.IsZero() {
+			if err := check.run(ctx, tx); err != nil {
+				return err
+			}
+		}
+		if check != nil && check.then != nil {
+			if err := check.then(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// InTx runs fn in one transaction, with the actor role of ctx set as for any
+// other write, and commits when fn succeeds.
+func InTx(ctx context.Context, db *pgxpool.Pool, fn func(Querier) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if role := actorRole(ctx); role != "" {
+		if _, err := tx.Exec(ctx, `SELECT set_config('rbc.actor_role', $1, true)`, role); err != nil {
+			return err
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
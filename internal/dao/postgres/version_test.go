@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	dbutil "github.com/flarebyte/baldrick-rebec/internal/dao/dbutil"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	p, err := ParseIfMatch("")
+	if err != nil || !p.IsZero() {
+		t.Fatalf("empty: got %+v, %v", p, err)
+	}
+	p, err = ParseIfMatch(" 7 ")
+	if err != nil || p.EditCount == nil || *p.EditCount != 7 || p.Updated != nil {
+		t.Fatalf("edit_count: got %+v, %v", p, err)
+	}
+	p, err = ParseIfMatch("2026-03-01T10:00:00.123456789Z")
+	if err != nil || p.Updated == nil {
+		t.Fatalf("timestamp: got %+v, %v", p, err)
+	}
+	if want := time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC); !p.Updated.Equal(want) {
+		t.Fatalf("timestamp not truncated to µs: %v", p.Updated)
+	}
+	for _, bad := range []string{"-1", "yesterday"} {
+		if _, err := ParseIfMatch(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestVersionConflictError(t *testing.T) {
+	n, cur := 3, 5
+	err := error(&VersionConflictError{Entity: "stickie", Key: "abc", Expected: Precondition{EditCount: &n}, Current: Version{Exists: true, EditCount: &cur}})
+	if !strings.Contains(err.Error(), "expected edit_count=3, current edit_count=5") {
+		t.Fatalf("message: %s", err)
+	}
+	if vc, ok := AsVersionConflict(dbutil.ErrWrap("stickie.upsert.update", err)); !ok || vc.ExitCode() != VersionConflictExitCode {
+		t.Fatalf("wrapped conflict not found")
+	}
+	missing := &VersionConflictError{Entity: "role", Key: "x", Expected: Precondition{EditCount: &n}}
+	if !strings.HasSuffix(missing.Error(), "current missing") {
+		t.Fatalf("missing: %s", missing)
+	}
+}
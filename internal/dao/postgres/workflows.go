@@ -17,6 +17,9 @@ type Workflow struct {
 	Notes       sql.NullString
 	Created     sql.NullTime
 	Updated     sql.NullTime
+
+	// IfMatch, when set, makes the write conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertWorkflow inserts or updates a workflow by name.
@@ -30,7 +33,10 @@ func UpsertWorkflow(ctx context.Context, db *pgxpool.Pool, w *Workflow) error {
             notes = EXCLUDED.notes,
             updated = now()
           RETURNING created, updated`
-	if err := db.QueryRow(ctx, q, w.Name, w.Title, stringOrEmpty(w.Description), w.RoleName, stringOrEmpty(w.Notes)).Scan(&w.Created, &w.Updated); err != nil {
+	check := &versionCheck{entity: "workflow", key: w.Name, table: "workflows", where: "name=$1", args: []any{w.Name}, p: w.IfMatch}
+	if err := withWrite(ctx, db, check, func(x Querier) error {
+		return x.QueryRow(ctx, q, w.Name, w.Title, stringOrEmpty(w.Description), w.RoleName, stringOrEmpty(w.Notes)).Scan(&w.Created, &w.Updated)
+	}); err != nil {
 		return dbutil.ErrWrap("workflow.upsert", err, dbutil.ParamSummary("name", w.Name))
 	}
 	return nil
@@ -38,9 +44,9 @@ func UpsertWorkflow(ctx context.Context, db *pgxpool.Pool, w *Workflow) error {
 
 // GetWorkflowByName fetches a workflow by its unique name.
 func GetWorkflowByName(ctx context.Context, db *pgxpool.Pool, name string) (*Workflow, error) {
-	q := `SELECT name, title, description, notes, created, updated FROM workflows WHERE name=$1`
+	q := `SELECT name, title, description, role_name, notes, created, updated FROM workflows WHERE name=$1`
 	var w Workflow
-	if err := db.QueryRow(ctx, q, name).Scan(&w.Name, &w.Title, &w.Description, &w.Notes, &w.Created, &w.Updated); err != nil {
+	if err := db.QueryRow(ctx, q, name).Scan(&w.Name, &w.Title, &w.Description, &w.RoleName, &w.Notes, &w.Created, &w.Updated); err != nil {
 		return nil, dbutil.ErrWrap("workflow.get", err, dbutil.ParamSummary("name", name))
 	}
 	return &w, nil
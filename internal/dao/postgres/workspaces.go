@@ -19,6 +19,9 @@ type Workspace struct {
 	Tags          map[string]any
 	Created       sql.NullTime
 	Updated       sql.NullTime
+
+	// IfMatch, when set, makes updates conditional on the stored version.
+	IfMatch Precondition
 }
 
 // UpsertWorkspace inserts a new workspace if ID is empty, otherwise updates it.
@@ -32,7 +35,10 @@ func UpsertWorkspace(ctx context.Context, db *pgxpool.Pool, w *Workspace) error
 		if w.Tags != nil {
 			tagsJSON, _ = json.Marshal(w.Tags)
 		}
-		if err := db.QueryRow(ctx, q, w.ID, stringOrEmpty(w.Description), w.RoleName, stringOrEmpty(w.ProjectName), tagsJSON, stringOrEmpty(w.BuildScriptID)).Scan(&w.Created, &w.Updated); err != nil {
+		check := &versionCheck{entity: "workspace", key: w.ID, table: "workspaces", where: "id=$1::uuid", args: []any{w.ID}, p: w.IfMatch}
+		if err := withWrite(ctx, db, check, func(x Querier) error {
+			return x.QueryRow(ctx, q, w.ID, stringOrEmpty(w.Description), w.RoleName, stringOrEmpty(w.ProjectName), tagsJSON, stringOrEmpty(w.BuildScriptID)).Scan(&w.Created, &w.Updated)
+		}); err != nil {
 			return dbutil.ErrWrap("workspace.upsert.update", err, dbutil.ParamSummary("id", w.ID), dbutil.ParamSummary("role", w.RoleName))
 		}
 		return nil
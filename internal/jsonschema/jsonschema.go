@@ -0,0 +1,626 @@
+// Package jsonschema validates JSON documents against the commonly used subset
+// of JSON Schema (draft 2020-12): type, enum/const, object, array, number and
+// string constraints, the applicators allOf/anyOf/oneOf/not/if-then-else, and
+// local $ref into $defs/definitions. Assertion keywords outside that subset
+// (prefixItems, contains, propertyNames, ...) are rejected at compile time so
+// a schema never silently validates less than it says. Other unknown keywords
+// (format, title, ...) are annotations and ignored. Violations carry JSON
+// Pointer paths.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled schema.
+type Schema struct {
+	always *bool // boolean schema
+
+	types    []string
+	enum     []any
+	constVal any
+	hasConst bool
+
+	properties    map[string]*Schema
+	patternProps  []patternSchema
+	additional    *Schema
+	required      []string
+	minProperties *int
+	maxProperties *int
+
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+	ifS, thenS, elseS   *Schema
+
+	ref *Schema
+}
+
+type patternSchema struct {
+	re *regexp.Regexp
+	s  *Schema
+}
+
+// Violation is one failed constraint at a JSON Pointer path ("" is the root).
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Schema  string `json:"schema,omitempty"` // which schema, when several apply
+}
+
+func (v Violation) String() string {
+	p := v.Path
+	if p == "" {
+		p = "/"
+	}
+	if v.Schema != "" {
+		return fmt.Sprintf("%s: %s (schema %s)", p, v.Message, v.Schema)
+	}
+	return p + ": " + v.Message
+}
+
+// ValidationError lists every violation of a document.
+type ValidationError struct {
+	Subject    string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	subj := e.Subject
+	if subj == "" {
+		subj = "document"
+	}
+	fmt.Fprintf(&sb, "%s failed schema validation (%d violations)", subj, len(e.Violations))
+	for _, v := range e.Violations {
+		sb.WriteString("\n  " + v.String())
+	}
+	return sb.String()
+}
+
+// Compile parses and compiles a schema document.
+func Compile(raw []byte) (*Schema, error) {
+	doc, err := decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	c := &compiler{root: doc, refs: map[string]*Schema{}}
+	return c.compile(doc, "#")
+}
+
+// Validate checks a JSON document and returns its violations (nil when valid).
+func (s *Schema) Validate(raw []byte) ([]Violation, error) {
+	doc, err := decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return s.ValidateValue(doc), nil
+}
+
+// ValidateValue checks an already decoded value (as produced by decode or
+// encoding/json with UseNumber).
+func (s *Schema) ValidateValue(v any) []Violation {
+	var out []Violation
+	s.validate(v, "", &out)
+	return out
+}
+
+func decode(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// unsupported lists the assertion and applicator keywords this package does
+// not implement; ignoring them would accept documents the schema rejects.
+var unsupported = []string{
+	"prefixItems", "additionalItems", "contains", "minContains", "maxContains",
+	"propertyNames", "dependentRequired", "dependentSchemas", "dependencies",
+	"unevaluatedProperties", "unevaluatedItems", "$dynamicRef", "$recursiveRef",
+}
+
+type compiler struct {
+	root any
+	refs map[string]*Schema
+}
+
+func (c *compiler) compile(node any, loc string) (*Schema, error) {
+	if b, ok := node.(bool); ok {
+		return &Schema{always: &b}, nil
+	}
+	m, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or boolean", loc)
+	}
+	for _, kw := range unsupported {
+		if _, ok := m[kw]; ok {
+			return nil, fmt.Errorf("%s: unsupported keyword %q", loc, kw)
+		}
+	}
+	s := &Schema{}
+	var err error
+	if r, ok := m["$ref"]; ok {
+		ref, _ := r.(string)
+		if s.ref, err = c.resolve(ref); err != nil {
+			return nil, fmt.Errorf("%s: %w", loc, err)
+		}
+	}
+	switch t := m["type"].(type) {
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, x := range t {
+			if str, ok := x.(string); ok {
+				s.types = append(s.types, str)
+			}
+		}
+	}
+	if e, ok := m["enum"].([]any); ok {
+		s.enum = e
+	}
+	if cv, ok := m["const"]; ok {
+		s.constVal, s.hasConst = cv, true
+	}
+	if props, ok := m["properties"].(map[string]any); ok {
+		s.properties = map[string]*Schema{}
+		for k, v := range props {
+			if s.properties[k], err = c.compile(v, loc+"/properties/"+escape(k)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if pp, ok := m["patternProperties"].(map[string]any); ok {
+		keys := sortedKeys(pp)
+		for _, k := range keys {
+			re, err := regexp.Compile(k)
+			if err != nil {
+				return nil, fmt.Errorf("%s/patternProperties: invalid pattern %q: %w", loc, k, err)
+			}
+			sub, err := c.compile(pp[k], loc+"/patternProperties/"+escape(k))
+			if err != nil {
+				return nil, err
+			}
+			s.patternProps = append(s.patternProps, patternSchema{re: re, s: sub})
+		}
+	}
+	if a, ok := m["additionalProperties"]; ok {
+		if s.additional, err = c.compile(a, loc+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if req, ok := m["required"].([]any); ok {
+		for _, r := range req {
+			if str, ok := r.(string); ok {
+				s.required = append(s.required, str)
+			}
+		}
+	}
+	s.minProperties, s.maxProperties = intKw(m, "minProperties"), intKw(m, "maxProperties")
+	if it, ok := m["items"]; ok {
+		if s.items, err = c.compile(it, loc+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	s.minItems, s.maxItems = intKw(m, "minItems"), intKw(m, "maxItems")
+	s.uniqueItems, _ = m["uniqueItems"].(bool)
+	s.minimum, s.maximum = numKw(m, "minimum"), numKw(m, "maximum")
+	s.exclusiveMinimum, s.exclusiveMaximum = numKw(m, "exclusiveMinimum"), numKw(m, "exclusiveMaximum")
+	s.multipleOf = numKw(m, "multipleOf")
+	s.minLength, s.maxLength = intKw(m, "minLength"), intKw(m, "maxLength")
+	if p, ok := m["pattern"].(string); ok {
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("%s/pattern: invalid pattern %q: %w", loc, p, err)
+		}
+	}
+	for _, kw := range []struct {
+		name string
+		dst  *[]*Schema
+	}{{"allOf", &s.allOf}, {"anyOf", &s.anyOf}, {"oneOf", &s.oneOf}} {
+		list, ok := m[kw.name].([]any)
+		if !ok {
+			continue
+		}
+		for i, sub := range list {
+			cs, err := c.compile(sub, fmt.Sprintf("%s/%s/%d", loc, kw.name, i))
+			if err != nil {
+				return nil, err
+			}
+			*kw.dst = append(*kw.dst, cs)
+		}
+	}
+	for _, kw := range []struct {
+		name string
+		dst  **Schema
+	}{{"not", &s.not}, {"if", &s.ifS}, {"then", &s.thenS}, {"else", &s.elseS}} {
+		if sub, ok := m[kw.name]; ok {
+			if *kw.dst, err = c.compile(sub, loc+"/"+kw.name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// resolve compiles the target of a local reference ("#", "#/$defs/x", ...).
+// The placeholder is registered first so recursive schemas terminate.
+func (c *compiler) resolve(ref string) (*Schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q (only local references are supported)", ref)
+	}
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+	node := c.root
+	ptr := strings.TrimPrefix(ref, "#")
+	if ptr != "" {
+		for _, tok := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+			tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+			switch n := node.(type) {
+			case map[string]any:
+				v, ok := n[tok]
+				if !ok {
+					return nil, fmt.Errorf("$ref %q not found", ref)
+				}
+				node = v
+			case []any:
+				i, err := strconv.Atoi(tok)
+				if err != nil || i < 0 || i >= len(n) {
+					return nil, fmt.Errorf("$ref %q not found", ref)
+				}
+				node = n[i]
+			default:
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+		}
+	}
+	placeholder := &Schema{}
+	c.refs[ref] = placeholder
+	s, err := c.compile(node, ref)
+	if err != nil {
+		return nil, err
+	}
+	*placeholder = *s
+	return placeholder, nil
+}
+
+func (s *Schema) validate(v any, path string, out *[]Violation) {
+	add := func(format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.always != nil {
+		if !*s.always {
+			add("no value is allowed here")
+		}
+		return
+	}
+	if s.ref != nil {
+		s.ref.validate(v, path, out)
+	}
+	if len(s.types) > 0 {
+		ok := false
+		for _, t := range s.types {
+			if hasType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			add("expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+			return
+		}
+	}
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if equal(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			add("must be one of %s", render(s.enum))
+		}
+	}
+	if s.hasConst && !equal(v, s.constVal) {
+		add("must equal %s", render(s.constVal))
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		s.validateObject(x, path, out, add)
+	case []any:
+		s.validateArray(x, path, out, add)
+	case json.Number:
+		f, _ := x.Float64()
+		s.validateNumber(f, add)
+	case string:
+		n := utf8.RuneCountInString(x)
+		if s.minLength != nil && n < *s.minLength {
+			add("length must be >= %d", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			add("length must be <= %d", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(x) {
+			add("does not match pattern %q", s.pattern.String())
+		}
+	}
+	for _, sub := range s.allOf {
+		sub.validate(v, path, out)
+	}
+	if len(s.anyOf) > 0 {
+		ok := false
+		for _, sub := range s.anyOf {
+			if sub.valid(v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			add("must match at least one schema in anyOf")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		n := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(v) {
+				n++
+			}
+		}
+		if n != 1 {
+			add("must match exactly one schema in oneOf (matched %d)", n)
+		}
+	}
+	if s.not != nil && s.not.valid(v) {
+		add("must not match the schema in not")
+	}
+	if s.ifS != nil {
+		if s.ifS.valid(v) {
+			if s.thenS != nil {
+				s.thenS.validate(v, path, out)
+			}
+		} else if s.elseS != nil {
+			s.elseS.validate(v, path, out)
+		}
+	}
+}
+
+func (s *Schema) validateObject(x map[string]any, path string, out *[]Violation, add func(string, ...any)) {
+	for _, r := range s.required {
+		if _, ok := x[r]; !ok {
+			add("missing required property %q", r)
+		}
+	}
+	if s.minProperties != nil && len(x) < *s.minProperties {
+		add("must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(x) > *s.maxProperties {
+		add("must have at most %d properties", *s.maxProperties)
+	}
+	for _, k := range sortedKeys(x) {
+		child := path + "/" + escape(k)
+		matched := false
+		if ps, ok := s.properties[k]; ok {
+			matched = true
+			ps.validate(x[k], child, out)
+		}
+		for _, pp := range s.patternProps {
+			if pp.re.MatchString(k) {
+				matched = true
+				pp.s.validate(x[k], child, out)
+			}
+		}
+		if !matched && s.additional != nil {
+			if s.additional.always != nil && !*s.additional.always {
+				add("additional property %q is not allowed", k)
+				continue
+			}
+			s.additional.validate(x[k], child, out)
+		}
+	}
+}
+
+func (s *Schema) validateArray(x []any, path string, out *[]Violation, add func(string, ...any)) {
+	if s.minItems != nil && len(x) < *s.minItems {
+		add("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(x) > *s.maxItems {
+		add("must have at most %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+	outer:
+		for i := range x {
+			for j := 0; j < i; j++ {
+				if equal(x[i], x[j]) {
+					add("items %d and %d are equal (uniqueItems)", j, i)
+					break outer
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, it := range x {
+			s.items.validate(it, path+"/"+strconv.Itoa(i), out)
+		}
+	}
+}
+
+func (s *Schema) validateNumber(f float64, add func(string, ...any)) {
+	if s.minimum != nil && f < *s.minimum {
+		add("must be >= %s", fmtNum(*s.minimum))
+	}
+	if s.maximum != nil && f > *s.maximum {
+		add("must be <= %s", fmtNum(*s.maximum))
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		add("must be > %s", fmtNum(*s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		add("must be < %s", fmtNum(*s.exclusiveMaximum))
+	}
+	if s.multipleOf != nil && *s.multipleOf > 0 {
+		q := f / *s.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			add("must be a multiple of %s", fmtNum(*s.multipleOf))
+		}
+	}
+}
+
+func (s *Schema) valid(v any) bool {
+	var out []Violation
+	s.validate(v, "", &out)
+	return len(out) == 0
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if hasType(x, "integer") {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// equal compares JSON values, treating numbers by value (1 == 1.0).
+func equal(a, b any) bool {
+	na, aok := a.(json.Number)
+	nb, bok := b.(json.Number)
+	if aok && bok {
+		fa, _ := na.Float64()
+		fb, _ := nb.Float64()
+		return fa == fb
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func intKw(m map[string]any, k string) *int {
+	if n, ok := m[k].(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			v := int(i)
+			return &v
+		}
+	}
+	return nil
+}
+
+func numKw(m map[string]any, k string) *float64 {
+	if n, ok := m[k].(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return &f
+		}
+	}
+	return nil
+}
+
+func fmtNum(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+
+func render(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func escape(tok string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
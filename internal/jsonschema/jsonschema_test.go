@@ -0,0 +1,80 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const decisionSchema = `{
+  "type": "object",
+  "required": ["status", "owner"],
+  "additionalProperties": false,
+  "properties": {
+    "status": {"enum": ["proposed", "accepted", "rejected"]},
+    "owner": {"type": "string", "minLength": 1},
+    "points": {"type": "integer", "minimum": 0, "maximum": 13},
+    "steps": {"type": "array", "items": {"$ref": "#/$defs/step"}, "uniqueItems": true}
+  },
+  "$defs": {
+    "step": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "pattern": "^[a-z-]+$"}}}
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(decisionSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, err := s.Validate([]byte(`{"status":"accepted","owner":"ops","points":5,"steps":[{"name":"build"}]}`))
+	if err != nil || len(vs) != 0 {
+		t.Fatalf("valid document rejected: %v %v", vs, err)
+	}
+	vs, _ = s.Validate([]byte(`{"status":"done","points":2.5,"steps":[{"name":"Build"},{}],"extra":1}`))
+	got := map[string]bool{}
+	for _, v := range vs {
+		got[v.String()] = true
+	}
+	for _, want := range []string{
+		`/: missing required property "owner"`,
+		`/status: must be one of ["proposed","accepted","rejected"]`,
+		`/points: expected integer, got number`,
+		`/steps/0/name: does not match pattern "^[a-z-]+$"`,
+		`/steps/1: missing required property "name"`,
+		`/: additional property "extra" is not allowed`,
+	} {
+		if !got[want] {
+			t.Errorf("missing violation %q in %v", want, vs)
+		}
+	}
+	if len(vs) != 6 {
+		t.Errorf("want 6 violations, got %d: %v", len(vs), vs)
+	}
+}
+
+func TestApplicators(t *testing.T) {
+	s, err := Compile([]byte(`{"oneOf":[{"type":"string"},{"type":"number","exclusiveMinimum":0}],"not":{"const":"x"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for doc, ok := range map[string]bool{`"a"`: true, `3`: true, `-1`: false, `"x"`: false, `null`: false} {
+		vs, _ := s.Validate([]byte(doc))
+		if (len(vs) == 0) != ok {
+			t.Errorf("%s: valid=%v, violations %v", doc, !ok, vs)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, raw := range []string{`{"$ref":"other.json"}`, `{"pattern":"("}`, `{"$ref":"#/$defs/missing"}`, `[1]`,
+		`{"prefixItems":[{"type":"string"}]}`, `{"properties":{"a":{"contains":{"const":1}}}}`,
+		`{"propertyNames":{"pattern":"^a"}}`, `{"dependentRequired":{"a":["b"]}}`,
+		`{"items":{"unevaluatedProperties":false}}`} {
+		if _, err := Compile([]byte(raw)); err == nil {
+			t.Errorf("%s: expected compile error", raw)
+		}
+	}
+	err := (&ValidationError{Subject: "stickie s1", Violations: []Violation{{Path: "/a", Message: "bad", Schema: "decision"}}}).Error()
+	if !strings.Contains(err, "/a: bad (schema decision)") {
+		t.Errorf("error text: %s", err)
+	}
+}
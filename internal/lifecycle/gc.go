@@ -0,0 +1,283 @@
+// Package lifecycle enforces blackboard lifecycles: boards whose expires_at has
+// passed are archived or deleted, optionally after a snapshot and with their
+// stickies rolled into a single summary stickie.
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	bkp "github.com/flarebyte/baldrick-rebec/internal/backup"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ActionArchive = "archive"
+	ActionDelete  = "delete"
+)
+
+// SummaryLabel marks stickies created by a rollup.
+const SummaryLabel = "lifecycle-summary"
+
+// Options controls a garbage collection run.
+type Options struct {
+	Role       string    // only boards of this role (default all roles)
+	Action     string    // archive (default) or delete
+	AsOf       time.Time // expiry cut-off (default now)
+	Rollup     bool      // roll stickies into a summary stickie
+	RollupInto string    // board receiving summaries; required with delete, defaults to the board itself on archive
+	Limit      int       // max boards per run (default 1000)
+}
+
+func (o *Options) normalize() error {
+	o.Action = strings.ToLower(strings.TrimSpace(o.Action))
+	if o.Action == "" {
+		o.Action = ActionArchive
+	}
+	if o.Action != ActionArchive && o.Action != ActionDelete {
+		return fmt.Errorf("invalid action %q (want archive|delete)", o.Action)
+	}
+	if o.Action == ActionDelete && o.Rollup && strings.TrimSpace(o.RollupInto) == "" {
+		return fmt.Errorf("rollup with delete requires a target board (rollup-into)")
+	}
+	if o.AsOf.IsZero() {
+		o.AsOf = time.Now()
+	}
+	return nil
+}
+
+// BoardResult describes what happened (or would happen) to one expired board.
+type BoardResult struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	Lifecycle string    `json:"lifecycle"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Action    string    `json:"action"`
+	Stickies  int       `json:"stickies"`
+	SummaryID string    `json:"summary_id,omitempty"`
+}
+
+// Plan is the set of expired boards found for a run.
+type Plan struct {
+	AsOf   time.Time     `json:"as_of"`
+	Action string        `json:"action"`
+	Boards []BoardResult `json:"boards"`
+	boards []pgdao.Blackboard
+}
+
+// FindExpired lists expired, unarchived boards and counts their live stickies.
+func FindExpired(ctx context.Context, db *pgxpool.Pool, opt Options) (*Plan, error) {
+	if err := opt.normalize(); err != nil {
+		return nil, err
+	}
+	bb, err := pgdao.ListExpiredBlackboards(ctx, db, strings.TrimSpace(opt.Role), opt.AsOf, opt.Limit)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{AsOf: opt.AsOf, Action: opt.Action}
+	for _, b := range bb {
+		if b.ID == strings.TrimSpace(opt.RollupInto) {
+			continue // never collect the board receiving summaries
+		}
+		p.boards = append(p.boards, b)
+		st, err := liveStickies(ctx, db, b.ID)
+		if err != nil {
+			return nil, err
+		}
+		p.Boards = append(p.Boards, BoardResult{
+			ID: b.ID, Role: b.RoleName, Lifecycle: b.Lifecycle.String,
+			ExpiresAt: b.ExpiresAt.Time, Action: opt.Action, Stickies: len(st),
+		})
+	}
+	return p, nil
+}
+
+// Snapshot captures blackboards and stickies before a destructive run and
+// returns the backup id.
+func Snapshot(ctx context.Context, backupDB *pgxpool.Pool, schema string, p *Plan) (string, error) {
+	res, err := bkp.CreateBackup(ctx, backupDB, bkp.DefaultEntities(), bkp.BackupOptions{
+		Schema:      schema,
+		Description: fmt.Sprintf("blackboard gc: %d expired boards", len(p.Boards)),
+		Tags:        map[string]any{"gc": "blackboard", "boards": len(p.Boards)},
+		InitiatedBy: "blackboard-gc",
+		Include:     []string{"blackboards", "blackboard_schemas", "stickies", "stickie_relations", "stickie_revisions"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Apply archives or deletes every board in the plan, rolling up stickies first
+// when requested. Each board is handled in its own transaction, so a failure
+// leaves that board untouched and a rerun picks it up again; the returned
+// results cover the boards already handled.
+func Apply(ctx context.Context, db *pgxpool.Pool, p *Plan, opt Options) ([]BoardResult, error) {
+	return apply(ctx, func(ctx context.Context, fn func(store) error) error {
+		return pgdao.InTx(ctx, db, func(tx pgdao.Querier) error { return fn(pgStore{tx}) })
+	}, p, opt)
+}
+
+// store is the part of the DAO a board collection needs, bound to one transaction.
+type store interface {
+	liveStickies(ctx context.Context, blackboardID string) ([]pgdao.Stickie, error)
+	insertStickie(ctx context.Context, s *pgdao.Stickie) error
+	archiveStickies(ctx context.Context, blackboardID, keepID string) error
+	deleteBoard(ctx context.Context, id string) error
+	archiveBoard(ctx context.Context, id string) error
+}
+
+// inTx runs fn against a store whose writes commit together or not at all.
+type inTx func(ctx context.Context, fn func(store) error) error
+
+func apply(ctx context.Context, tx inTx, p *Plan, opt Options) ([]BoardResult, error) {
+	if err := opt.normalize(); err != nil {
+		return nil, err
+	}
+	var done []BoardResult
+	for i, b := range p.boards {
+		r := p.Boards[i]
+		err := tx(ctx, func(st store) error {
+			r.SummaryID = ""
+			if opt.Rollup {
+				id, err := rollup(ctx, st, b, opt)
+				if err != nil {
+					return err
+				}
+				r.SummaryID = id
+			}
+			if opt.Action == ActionDelete {
+				return st.deleteBoard(ctx, b.ID)
+			}
+			return st.archiveBoard(ctx, b.ID)
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, r)
+	}
+	return done, nil
+}
+
+// rollup writes the summary stickie of b and, when it stays on b, archives the
+// stickies it replaces. It returns the summary id, or "" without live stickies.
+func rollup(ctx context.Context, st store, b pgdao.Blackboard, opt Options) (string, error) {
+	live, err := st.liveStickies(ctx, b.ID)
+	if err != nil || len(live) == 0 {
+		return "", err
+	}
+	target := strings.TrimSpace(opt.RollupInto)
+	if target == "" {
+		target = b.ID
+	}
+	sum := &pgdao.Stickie{
+		BlackboardID: target,
+		Name:         sql.NullString{String: "summary " + b.ID, Valid: true},
+		Note:         sql.NullString{String: SummaryNote(b, live, opt.AsOf), Valid: true},
+		Labels:       []string{SummaryLabel, "lifecycle-" + b.Lifecycle.String},
+	}
+	if err := st.insertStickie(ctx, sum); err != nil {
+		return "", err
+	}
+	if target == b.ID {
+		if err := st.archiveStickies(ctx, b.ID, sum.ID); err != nil {
+			return "", err
+		}
+	}
+	return sum.ID, nil
+}
+
+// pgStore is the store of a Postgres transaction.
+type pgStore struct{ q pgdao.Querier }
+
+func (s pgStore) liveStickies(ctx context.Context, blackboardID string) ([]pgdao.Stickie, error) {
+	return liveStickies(ctx, s.q, blackboardID)
+}
+
+func (s pgStore) insertStickie(ctx context.Context, st *pgdao.Stickie) error {
+	return pgdao.InsertStickie(ctx, s.q, st)
+}
+
+func (s pgStore) archiveStickies(ctx context.Context, blackboardID, keepID string) error {
+	_, err := pgdao.ArchiveBlackboardStickies(ctx, s.q, blackboardID, keepID)
+	return err
+}
+
+func (s pgStore) deleteBoard(ctx context.Context, id string) error {
+	_, err := pgdao.DeleteBlackboard(ctx, s.q, id)
+	return err
+}
+
+func (s pgStore) archiveBoard(ctx context.Context, id string) error {
+	_, err := pgdao.SetBlackboardArchived(ctx, s.q, id, true)
+	return err
+}
+
+// SummaryNote renders the markdown note of a rollup stickie: a header naming the
+// board and one bullet per stickie, oldest first.
+func SummaryNote(b pgdao.Blackboard, stickies []pgdao.Stickie, asOf time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Summary of blackboard %s\n\n", b.ID)
+	fmt.Fprintf(&sb, "Lifecycle %s", b.Lifecycle.String)
+	if b.ExpiresAt.Valid {
+		fmt.Fprintf(&sb, ", expired %s", b.ExpiresAt.Time.UTC().Format("2006-01-02"))
+	}
+	fmt.Fprintf(&sb, "; rolled up %s from %d stickies.\n", asOf.UTC().Format("2006-01-02"), len(stickies))
+	if b.Background.Valid && strings.TrimSpace(b.Background.String) != "" {
+		fmt.Fprintf(&sb, "\n%s\n", strings.TrimSpace(b.Background.String))
+	}
+	sb.WriteString("\n")
+	for i := len(stickies) - 1; i >= 0; i-- {
+		s := stickies[i]
+		title := strings.TrimSpace(s.Name.String)
+		note := firstLine(s.Note.String)
+		line := "- "
+		switch {
+		case title != "" && note != "":
+			line += "**" + title + "**: " + note
+		case title != "":
+			line += "**" + title + "**"
+		case note != "":
+			line += note
+		default:
+			line += "(empty)"
+		}
+		if len(s.Labels) > 0 {
+			line += " [" + strings.Join(s.Labels, ", ") + "]"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i]) + " …"
+	}
+	return s
+}
+
+// liveStickies returns the unarchived stickies of a board, newest first.
+func liveStickies(ctx context.Context, db pgdao.Querier, blackboardID string) ([]pgdao.Stickie, error) {
+	var out []pgdao.Stickie
+	const page = 500
+	for offset := 0; ; offset += page {
+		st, err := pgdao.ListStickies(ctx, db, blackboardID, page, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range st {
+			if !s.Archived {
+				out = append(out, s)
+			}
+		}
+		if len(st) < page {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func TestSummaryNote_ListsStickiesOldestFirst(t *testing.T) {
+	expired := time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)
+	b := pgdao.Blackboard{
+		ID:        "bb-1",
+		Lifecycle: sql.NullString{String: "daily", Valid: true},
+		ExpiresAt: sql.NullTime{Time: expired, Valid: true},
+	}
+	// ListStickies returns newest first
+	st := []pgdao.Stickie{
+		{Note: sql.NullString{String: "ship it\nmore detail", Valid: true}, Labels: []string{"todo"}},
+		{Name: sql.NullString{String: "plan", Valid: true}, Note: sql.NullString{String: "draft the plan", Valid: true}},
+	}
+	got := SummaryNote(b, st, expired.Add(time.Hour))
+	want := []string{
+		"# Summary of blackboard bb-1",
+		"Lifecycle daily, expired 2025-03-02; rolled up 2025-03-02 from 2 stickies.",
+		"- **plan**: draft the plan\n- ship it … [todo]\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Fatalf("summary missing %q:\n%s", w, got)
+		}
+	}
+}
+
+func TestOptions_DeleteRollupNeedsTarget(t *testing.T) {
+	o := Options{Action: "delete", Rollup: true}
+	if err := o.normalize(); err == nil {
+		t.Fatal("expected error for delete + rollup without target")
+	}
+	o = Options{}
+	if err := o.normalize(); err != nil || o.Action != ActionArchive || o.AsOf.IsZero() {
+		t.Fatalf("defaults not applied: %+v err=%v", o, err)
+	}
+}
+
+// memStore is an in-memory store; memTx commits a copy of it only when the
+// board's function succeeds, like a rolled-back transaction would.
+type memStore struct {
+	stickies []pgdao.Stickie
+	archived map[string]bool
+	failOn   string // archiveBoard of this id fails once
+}
+
+func (m *memStore) clone() *memStore {
+	c := &memStore{stickies: append([]pgdao.Stickie(nil), m.stickies...), archived: map[string]bool{}, failOn: m.failOn}
+	for k, v := range m.archived {
+		c.archived[k] = v
+	}
+	return c
+}
+
+func (m *memStore) liveStickies(_ context.Context, id string) ([]pgdao.Stickie, error) {
+	var out []pgdao.Stickie
+	for _, s := range m.stickies {
+		if s.BlackboardID == id && !s.Archived {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) insertStickie(_ context.Context, s *pgdao.Stickie) error {
+	s.ID = fmt.Sprintf("s%d", len(m.stickies)+1)
+	m.stickies = append(m.stickies, *s)
+	return nil
+}
+
+func (m *memStore) archiveStickies(_ context.Context, id, keep string) error {
+	for i, s := range m.stickies {
+		if s.BlackboardID == id && s.ID != keep {
+			m.stickies[i].Archived = true
+		}
+	}
+	return nil
+}
+
+func (m *memStore) deleteBoard(context.Context, string) error { return errors.New("unexpected delete") }
+
+func (m *memStore) archiveBoard(_ context.Context, id string) error {
+	if id == m.failOn {
+		return errors.New("connection lost")
+	}
+	m.archived[id] = true
+	return nil
+}
+
+func TestApply_RerunAfterFailureRollsUpOnce(t *testing.T) {
+	db := &memStore{archived: map[string]bool{}, failOn: "bb-1", stickies: []pgdao.Stickie{
+		{ID: "a", BlackboardID: "bb-1", Note: sql.NullString{String: "first", Valid: true}},
+		{ID: "b", BlackboardID: "bb-1", Note: sql.NullString{String: "second", Valid: true}},
+	}}
+	tx := func(ctx context.Context, fn func(store) error) error {
+		c := db.clone()
+		if err := fn(c); err != nil {
+			return err
+		}
+		*db = *c
+		return nil
+	}
+	p := &Plan{Boards: []BoardResult{{ID: "bb-1"}}, boards: []pgdao.Blackboard{{ID: "bb-1"}}}
+	opt := Options{Rollup: true}
+	if done, err := apply(context.Background(), tx, p, opt); err == nil || len(done) != 0 {
+		t.Fatalf("expected failure, got %v %v", done, err)
+	}
+	if len(db.stickies) != 2 || db.stickies[0].Archived {
+		t.Fatalf("failed run left changes: %+v", db.stickies)
+	}
+	db.failOn = ""
+	done, err := apply(context.Background(), tx, p, opt)
+	if err != nil || len(done) != 1 || done[0].SummaryID == "" {
+		t.Fatalf("rerun: %v %v", done, err)
+	}
+	live, _ := db.liveStickies(context.Background(), "bb-1")
+	if len(live) != 1 || live[0].ID != done[0].SummaryID || !db.archived["bb-1"] {
+		t.Fatalf("expected one live summary on an archived board: %+v %v", live, db.archived)
+	}
+}
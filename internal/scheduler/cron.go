@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed 5-field cron expression: minute hour day-of-month month day-of-week.
+// Fields accept *, numbers, ranges (a-b), steps (*/n, a-b/n) and comma lists.
+// Day-of-week is 0-6 with 0 (or 7) for Sunday. As in classic cron, when both
+// day-of-month and day-of-week are restricted, a day matching either is selected.
+type Schedule struct {
+	expr                     string
+	minute, hour, dom, month uint64
+	dow                      uint64
+	domAny, dowAny           bool
+}
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+// ParseCron parses a cron expression or one of the @hourly/@daily/@weekly/@monthly/@yearly aliases.
+func ParseCron(expr string) (*Schedule, error) {
+	s := strings.TrimSpace(expr)
+	if a, ok := cronAliases[strings.ToLower(s)]; ok {
+		s = a
+	}
+	f := strings.Fields(s)
+	if len(f) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday)", expr)
+	}
+	sc := &Schedule{expr: strings.TrimSpace(expr)}
+	var err error
+	if sc.minute, _, err = parseField(f[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q minute: %w", expr, err)
+	}
+	if sc.hour, _, err = parseField(f[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q hour: %w", expr, err)
+	}
+	if sc.dom, sc.domAny, err = parseField(f[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q day-of-month: %w", expr, err)
+	}
+	if sc.month, _, err = parseField(f[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q month: %w", expr, err)
+	}
+	if sc.dow, sc.dowAny, err = parseField(f[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q day-of-week: %w", expr, err)
+	}
+	if sc.dow&(1<<7) != 0 {
+		sc.dow |= 1 // 7 is also Sunday
+	}
+	return sc, nil
+}
+
+// parseField returns the bit set of allowed values and whether the field was "*".
+func parseField(field string, lo, hi int) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if start, err = strconv.Atoi(a); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", a)
+			}
+			if end, err = strconv.Atoi(b); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", b)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", rng)
+			}
+			start = n
+			if !hasStep {
+				end = n
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, false, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, field == "*", nil
+}
+
+func (s *Schedule) String() string { return s.expr }
+
+func has(bits uint64, v int) bool { return bits&(1<<uint(v)) != 0 }
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first matching minute strictly after t, in t's location.
+// It returns the zero time when nothing matches within five years (e.g. "0 0 31 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
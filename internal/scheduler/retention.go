@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flarebyte/baldrick-rebec/internal/config"
+)
+
+// Snapshot is the minimal view of a backup needed to apply retention.
+type Snapshot struct {
+	ID      string
+	Created time.Time
+}
+
+// ApplyGFS splits snapshots into those kept and those to delete under a
+// grandfather-father-son policy: the newest snapshot of each of the most recent
+// Daily days, Weekly ISO weeks and Monthly months is kept. A snapshot kept by any
+// class is kept. A zero policy keeps everything.
+func ApplyGFS(snaps []Snapshot, p config.RetentionConfig) (keep, drop []Snapshot) {
+	if p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0 {
+		return snaps, nil
+	}
+	sorted := append([]Snapshot(nil), snaps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Created.After(sorted[j].Created) })
+
+	kept := map[string]bool{}
+	class := func(limit int, bucket func(time.Time) string) {
+		if limit <= 0 {
+			return
+		}
+		seen := map[string]bool{}
+		for _, s := range sorted {
+			b := bucket(s.Created)
+			if seen[b] {
+				continue
+			}
+			if len(seen) == limit {
+				return
+			}
+			seen[b] = true
+			kept[s.ID] = true
+		}
+	}
+	class(p.Daily, func(t time.Time) string { return t.Format("2006-01-02") })
+	class(p.Weekly, func(t time.Time) string {
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	})
+	class(p.Monthly, func(t time.Time) string { return t.Format("2006-01") })
+
+	for _, s := range sorted {
+		if kept[s.ID] {
+			keep = append(keep, s)
+		} else {
+			drop = append(drop, s)
+		}
+	}
+	return keep, drop
+}
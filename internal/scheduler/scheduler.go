@@ -0,0 +1,468 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bkp "github.com/flarebyte/baldrick-rebec/internal/backup"
+	"github.com/flarebyte/baldrick-rebec/internal/config"
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/lifecycle"
+	"github.com/flarebyte/baldrick-rebec/internal/paths"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagSchedule is the backup tag holding the name of the job that created it.
+// Retention only ever prunes backups carrying its own job's tag.
+const TagSchedule = "schedule"
+
+const jobTimeout = 30 * time.Minute
+
+// Job kinds.
+const (
+	KindSnapshot     = "snapshot"
+	KindBlackboardGC = "blackboard_gc"
+)
+
+// Job is a configured job with its parsed schedule. Exactly one of Snapshot or
+// BlackboardGC is set, according to Kind.
+type Job struct {
+	Name         string
+	Kind         string
+	Schedule     *Schedule
+	Snapshot     *config.SnapshotJobConfig
+	BlackboardGC *config.BlackboardGCJobConfig
+}
+
+// Jobs validates the scheduler configuration and parses each cron expression.
+// Job names must be unique across kinds.
+func Jobs(cfg config.SchedulerConfig) ([]Job, error) {
+	seen := map[string]bool{}
+	var out []Job
+	add := func(section string, i int, name, cron string) (Job, error) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return Job{}, fmt.Errorf("scheduler.%s[%d]: name is required", section, i)
+		}
+		if seen[name] {
+			return Job{}, fmt.Errorf("scheduler.%s: duplicate job name %q", section, name)
+		}
+		seen[name] = true
+		sc, err := ParseCron(cron)
+		if err != nil {
+			return Job{}, fmt.Errorf("scheduler.%s[%s]: %w", section, name, err)
+		}
+		return Job{Name: name, Schedule: sc}, nil
+	}
+	for i := range cfg.Snapshots {
+		j := cfg.Snapshots[i]
+		job, err := add("snapshots", i, j.Name, j.Cron)
+		if err != nil {
+			return nil, err
+		}
+		j.Name = job.Name
+		job.Kind, job.Snapshot = KindSnapshot, &j
+		out = append(out, job)
+	}
+	for i := range cfg.BlackboardGC {
+		j := cfg.BlackboardGC[i]
+		job, err := add("blackboard_gc", i, j.Name, j.Cron)
+		if err != nil {
+			return nil, err
+		}
+		if j.Action == lifecycle.ActionDelete && j.Rollup && strings.TrimSpace(j.RollupInto) == "" {
+			return nil, fmt.Errorf("scheduler.blackboard_gc[%s]: rollup with delete requires rollup_into", job.Name)
+		}
+		j.Name = job.Name
+		job.Kind, job.BlackboardGC = KindBlackboardGC, &j
+		out = append(out, job)
+	}
+	return out, nil
+}
+
+// JobState is the persisted view of a job, read by `rbc server status`.
+type JobState struct {
+	Name         string     `json:"name"`
+	Cron         string     `json:"cron"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastStatus   string     `json:"last_status,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastBackupID string     `json:"last_backup_id,omitempty"`
+	LastPruned   int        `json:"last_pruned,omitempty"`
+}
+
+// State is the scheduler state file written by the running server.
+type State struct {
+	Updated time.Time  `json:"updated"`
+	Jobs    []JobState `json:"jobs"`
+}
+
+// DefaultStatePath returns the scheduler state file under the rbc home directory.
+func DefaultStatePath() string {
+	return filepath.Join(paths.Home(), "scheduler.json")
+}
+
+// LoadState reads a state file; a missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("scheduler state %s: %w", path, err)
+	}
+	return &st, nil
+}
+
+func saveState(path string, st *State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Scheduler runs configured jobs on their cron schedules inside the server process.
+type Scheduler struct {
+	statePath string
+
+	mu     sync.Mutex
+	cfg    config.Config
+	jobs   []Job
+	states map[string]*JobState
+	reload chan struct{}
+}
+
+// New creates a scheduler persisting its state at statePath. Previous last-run
+// details are carried over from an existing state file.
+func New(statePath string) *Scheduler {
+	s := &Scheduler{statePath: statePath, states: map[string]*JobState{}, reload: make(chan struct{}, 1)}
+	if st, err := LoadState(statePath); err == nil {
+		for i := range st.Jobs {
+			js := st.Jobs[i]
+			s.states[js.Name] = &js
+		}
+	}
+	return s
+}
+
+// Configure replaces the job set from cfg and recomputes next run times.
+// It is safe to call while Run is active (e.g. on SIGHUP).
+func (s *Scheduler) Configure(cfg config.Config) error {
+	jobs, err := Jobs(cfg.Scheduler)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	s.mu.Lock()
+	s.cfg = cfg
+	s.jobs = jobs
+	states := map[string]*JobState{}
+	for _, j := range jobs {
+		js := s.states[j.Name]
+		if js == nil {
+			js = &JobState{Name: j.Name}
+		}
+		js.Cron = j.Schedule.String()
+		js.NextRun = timePtr(j.Schedule.Next(now))
+		states[j.Name] = js
+	}
+	s.states = states
+	err = s.saveLocked()
+	s.mu.Unlock()
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Run blocks until ctx is cancelled, running each job when it is due. Jobs run
+// one at a time; a run that overlaps the next slot simply starts late.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.soonest()
+		var fire <-chan time.Time
+		var timer *time.Timer
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-s.reload:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-fire:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) soonest() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, js := range s.states {
+		if js.NextRun != nil && (next.IsZero() || js.NextRun.Before(next)) {
+			next = *js.NextRun
+		}
+	}
+	return next
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := time.Now()
+	s.mu.Lock()
+	cfg := s.cfg
+	var due []Job
+	for _, j := range s.jobs {
+		if js := s.states[j.Name]; js != nil && js.NextRun != nil && !js.NextRun.After(now) {
+			due = append(due, j)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, j := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		started := time.Now()
+		out := RunJob(ctx, cfg, j)
+		s.mu.Lock()
+		if js := s.states[j.Name]; js != nil {
+			js.LastRun = &started
+			js.LastBackupID = out.BackupID
+			js.LastPruned = out.Pruned
+			js.LastStatus, js.LastError = "success", ""
+			if out.Err != nil {
+				js.LastStatus, js.LastError = "failed", out.Err.Error()
+			}
+			js.NextRun = timePtr(j.Schedule.Next(time.Now()))
+		}
+		if err := s.saveLocked(); err != nil {
+			fmt.Fprintf(os.Stderr, "scheduler: save state: %v\n", err)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) saveLocked() error {
+	st := &State{Updated: time.Now()}
+	for _, js := range s.states {
+		st.Jobs = append(st.Jobs, *js)
+	}
+	sort.Slice(st.Jobs, func(i, j int) bool { return st.Jobs[i].Name < st.Jobs[j].Name })
+	return saveState(s.statePath, st)
+}
+
+// Outcome summarises a single job run.
+type Outcome struct {
+	BackupID string
+	Records  int64 // snapshot: records captured
+	Pruned   int   // snapshot: backups deleted by retention
+	Boards   int   // blackboard gc: boards archived or deleted
+	Err      error
+}
+
+// RunJob executes a job and records its outcome as a message. Failures are
+// reported in Outcome.Err.
+func RunJob(ctx context.Context, cfg config.Config, j Job) Outcome {
+	ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+	started := time.Now()
+	var out Outcome
+	var role, summary string
+	switch j.Kind {
+	case KindSnapshot:
+		role = j.Snapshot.Role
+		out = runSnapshot(ctx, cfg, *j.Snapshot)
+		summary = fmt.Sprintf("backup %s, %d records, %d pruned", out.BackupID, out.Records, out.Pruned)
+	case KindBlackboardGC:
+		role = j.BlackboardGC.Role
+		out = runBlackboardGC(ctx, cfg, *j.BlackboardGC)
+		summary = fmt.Sprintf("%d blackboards %sd", out.Boards, gcAction(*j.BlackboardGC))
+		if out.BackupID != "" {
+			summary += ", snapshot " + out.BackupID
+		}
+	default:
+		out.Err = fmt.Errorf("unknown job kind %q", j.Kind)
+	}
+	took := time.Since(started)
+	if out.Err != nil {
+		fmt.Fprintf(os.Stderr, "scheduler: %s failed: %v\n", j.Name, out.Err)
+	} else {
+		fmt.Fprintf(os.Stderr, "scheduler: %s %s in %s\n", j.Name, summary, took.Round(time.Millisecond))
+	}
+	if err := recordOutcome(ctx, cfg, j, role, summary, out, took); err != nil {
+		fmt.Fprintf(os.Stderr, "scheduler: %s record outcome: %v\n", j.Name, err)
+	}
+	return out
+}
+
+func runSnapshot(ctx context.Context, cfg config.Config, job config.SnapshotJobConfig) Outcome {
+	var out Outcome
+	db, err := pgdao.OpenBackup(ctx, cfg)
+	if err != nil {
+		out.Err = err
+		return out
+	}
+	defer db.Close()
+	desc := job.Description
+	if strings.TrimSpace(desc) == "" {
+		desc = "scheduled snapshot " + job.Name
+	}
+	res, err := bkp.CreateBackup(ctx, db, bkp.DefaultEntities(), bkp.BackupOptions{
+		Schema:      job.Schema,
+		Description: desc,
+		Tags:        map[string]any{TagSchedule: job.Name},
+		InitiatedBy: "scheduler",
+		Include:     job.Include,
+		Exclude:     job.Exclude,
+	})
+	if err != nil {
+		out.Err = err
+		return out
+	}
+	out.BackupID, out.Records = res.ID, res.Records
+	out.Pruned, out.Err = enforceRetention(ctx, db, job)
+	return out
+}
+
+// enforceRetention deletes this job's backups that fall outside its GFS policy.
+func enforceRetention(ctx context.Context, db *pgxpool.Pool, job config.SnapshotJobConfig) (int, error) {
+	p := job.Retention
+	if p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0 {
+		return 0, nil
+	}
+	all, err := pgdao.ListBackups(ctx, db, job.Schema, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	var mine []Snapshot
+	for _, b := range all {
+		if name, _ := b.Tags[TagSchedule].(string); name == job.Name {
+			mine = append(mine, Snapshot{ID: b.ID, Created: b.CreatedAt})
+		}
+	}
+	_, drop := ApplyGFS(mine, p)
+	pruned := 0
+	for _, d := range drop {
+		if _, err := pgdao.DeleteBackup(ctx, db, job.Schema, d.ID); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+func gcAction(job config.BlackboardGCJobConfig) string {
+	if job.Action == "" {
+		return lifecycle.ActionArchive
+	}
+	return job.Action
+}
+
+func runBlackboardGC(ctx context.Context, cfg config.Config, job config.BlackboardGCJobConfig) Outcome {
+	var out Outcome
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		out.Err = err
+		return out
+	}
+	defer db.Close()
+	opt := lifecycle.Options{Role: job.BoardRole, Action: job.Action, Rollup: job.Rollup, RollupInto: job.RollupInto}
+	plan, err := lifecycle.FindExpired(ctx, db, opt)
+	if err != nil || len(plan.Boards) == 0 {
+		out.Err = err
+		return out
+	}
+	if job.Snapshot {
+		bdb, err := pgdao.OpenBackup(ctx, cfg)
+		if err != nil {
+			out.Err = err
+			return out
+		}
+		out.BackupID, err = lifecycle.Snapshot(ctx, bdb, job.Schema, plan)
+		bdb.Close()
+		if err != nil {
+			out.Err = err
+			return out
+		}
+	}
+	done, err := lifecycle.Apply(ctx, db, plan, opt)
+	out.Boards, out.Err = len(done), err
+	return out
+}
+
+func recordOutcome(ctx context.Context, cfg config.Config, j Job, role, summary string, out Outcome, took time.Duration) error {
+	db, err := pgdao.OpenApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	status := "success"
+	text := fmt.Sprintf("scheduled %s %s: %s", j.Kind, j.Name, summary)
+	if out.Err != nil {
+		status = "failed"
+		text = fmt.Sprintf("scheduled %s %s failed: %v", j.Kind, j.Name, out.Err)
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"job": j.Name, "kind": j.Kind, "cron": j.Schedule.String(), "backup_id": out.BackupID,
+		"records": out.Records, "pruned": out.Pruned, "boards": out.Boards, "duration_ms": took.Milliseconds(),
+	})
+	cid, err := pgdao.InsertContent(ctx, db, text, payload)
+	if err != nil {
+		return err
+	}
+	role = strings.TrimSpace(role)
+	if role == "" {
+		role = "user"
+	}
+	ev := &pgdao.MessageEvent{
+		ContentID: cid,
+		RoleName:  role,
+		Status:    status,
+		Tags:      map[string]any{"scheduler": j.Kind, TagSchedule: j.Name},
+	}
+	if out.BackupID != "" {
+		ev.Tags["backup_id"] = out.BackupID
+	}
+	if out.Err != nil {
+		ev.ErrorMessage = sql.NullString{String: out.Err.Error(), Valid: true}
+	}
+	_, err = pgdao.InsertMessageEvent(ctx, db, ev)
+	return err
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
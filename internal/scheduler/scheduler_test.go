@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flarebyte/baldrick-rebec/internal/config"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2025, 3, 14, 10, 17, 30, 0, time.UTC) // Friday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2025, 3, 15, 2, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"30 9 1 * *", time.Date(2025, 4, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 8-18/5 * * 1-5", time.Date(2025, 3, 14, 13, 0, 0, 0, time.UTC)},
+		// day-of-month OR day-of-week when both are restricted
+		{"0 0 20 * 1", time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(c.want) {
+			t.Errorf("%s: next=%s want %s", c.expr, got, c.want)
+		}
+	}
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestApplyGFS_KeepsNewestPerBucket(t *testing.T) {
+	day := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.UTC) }
+	snaps := []Snapshot{
+		{ID: "a", Created: day(2025, 3, 14, 2)},
+		{ID: "b", Created: day(2025, 3, 13, 2)},
+		{ID: "b2", Created: day(2025, 3, 13, 1)}, // same day as b, older
+		{ID: "c", Created: day(2025, 3, 9, 2)},   // previous ISO week
+		{ID: "d", Created: day(2025, 2, 20, 2)},  // previous month
+		{ID: "e", Created: day(2025, 1, 5, 2)},   // beyond monthly window
+	}
+	keep, drop := ApplyGFS(snaps, config.RetentionConfig{Daily: 2, Weekly: 2, Monthly: 2})
+	got := map[string]bool{}
+	for _, s := range keep {
+		got[s.ID] = true
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if !got[id] {
+			t.Errorf("expected %s kept", id)
+		}
+	}
+	if len(drop) != 2 {
+		t.Fatalf("expected b2 and e dropped, got %+v", drop)
+	}
+	if _, drop := ApplyGFS(snaps, config.RetentionConfig{}); len(drop) != 0 {
+		t.Fatalf("zero policy must keep everything, dropped %+v", drop)
+	}
+}
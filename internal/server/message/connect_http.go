@@ -0,0 +1,61 @@
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Connect-style JSON handler that routes by path.
+func (s *Service) ConnectHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Path {
+		case "/message.v1.MessageService/Send":
+			var in SendMessageRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				writeErr(w, "invalid_argument", "invalid JSON")
+				return
+			}
+			out, err := s.Send(r.Context(), &in)
+			writeResult(w, out, err)
+		case "/message.v1.MessageService/List":
+			var in ListMessagesRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				writeErr(w, "invalid_argument", "invalid JSON")
+				return
+			}
+			out, err := s.List(r.Context(), &in)
+			writeResult(w, out, err)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func writeResult(w http.ResponseWriter, v any, err error) {
+	switch {
+	case errors.Is(err, errInvalid):
+		writeErr(w, "invalid_argument", err.Error())
+	case err != nil:
+		writeErr(w, "internal", err.Error())
+	default:
+		writeOK(w, v)
+	}
+}
+
+func writeOK(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/connect+json")
+	w.Header().Set("Connect-Protocol-Version", "1")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErr(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/connect+json")
+	w.Header().Set("Connect-Protocol-Version", "1")
+	w.Header().Set("Connect-Error-Code", code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": code, "message": msg}})
+}
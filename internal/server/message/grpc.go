@@ -0,0 +1,137 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	grpcjson "github.com/flarebyte/baldrick-rebec/internal/transport/grpcjson"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+)
+
+// errInvalid marks request validation errors (invalid_argument over Connect).
+var errInvalid = errors.New("invalid argument")
+
+// Service exposes message send and list via gRPC JSON codec.
+type Service struct {
+	DB *pgxpool.Pool
+}
+
+// MessageServiceServer defines the interface used by gRPC registration.
+type MessageServiceServer interface {
+	Send(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
+	List(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error)
+}
+
+// Register registers the service with the provided gRPC server using a JSON codec.
+func (s *Service) Register(gs *grpc.Server) {
+	grpcjson.Register()
+	gs.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "message.v1.MessageService",
+		HandlerType: (*MessageServiceServer)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Send", Handler: s.handleSend},
+			{MethodName: "List", Handler: s.handleList},
+		},
+		Streams:  []grpc.StreamDesc{},
+		Metadata: "proto/message/v1/message.proto",
+	}, s)
+}
+
+func (s *Service) handleSend(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (any, error) {
+	var in SendMessageRequest
+	if err := dec(&in); err != nil {
+		return nil, err
+	}
+	return s.Send(ctx, &in)
+}
+
+func (s *Service) handleList(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (any, error) {
+	var in ListMessagesRequest
+	if err := dec(&in); err != nil {
+		return nil, err
+	}
+	return s.List(ctx, &in)
+}
+
+// Send stores the text as content and records a message for it. A reply
+// (parent_message_id) joins its parent's experiment and, without to_role,
+// is addressed to the parent's role, as with 'rbc message set --reply-to'; it
+// cannot name another experiment than its parent's.
+func (s *Service) Send(ctx context.Context, in *SendMessageRequest) (*SendMessageResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("message service not initialized")
+	}
+	if strings.TrimSpace(in.Text) == "" {
+		return nil, fmt.Errorf("%w: text is required", errInvalid)
+	}
+	ev := &pgdao.MessageEvent{RoleName: orDefault(in.Role, "user"), Status: orDefault(in.Status, "ingested"), Tags: in.Tags, ToRole: in.ToRole}
+	if in.Experiment != "" {
+		ev.ExperimentID = sqlString(in.Experiment)
+	}
+	if in.FromTask != "" {
+		ev.FromTaskID = sqlString(in.FromTask)
+	}
+	if in.ParentMessageID != "" {
+		parent, err := pgdao.GetMessageEventByID(ctx, s.DB, in.ParentMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parent_message_id: %v", errInvalid, err)
+		}
+		if err := ev.ReplyTo(parent); err != nil {
+			return nil, fmt.Errorf("%w: parent_message_id: %v", errInvalid, err)
+		}
+	}
+	cid, err := pgdao.InsertContent(ctx, s.DB, in.Text, in.JSON)
+	if err != nil {
+		return nil, err
+	}
+	ev.ContentID = cid
+	id, err := pgdao.InsertMessageEvent(ctx, s.DB, ev)
+	if err != nil {
+		return nil, err
+	}
+	return &SendMessageResponse{ID: id, ContentID: cid, Status: ev.Status, ExperimentID: ev.ExperimentID.String, ParentMessageID: ev.ParentMessageID.String, ToRole: ev.ToRole}, nil
+}
+
+// List lists messages of a role, or the whole thread of a message.
+func (s *Service) List(ctx context.Context, in *ListMessagesRequest) (*ListMessagesResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("message service not initialized")
+	}
+	var rows []pgdao.MessageEvent
+	var err error
+	switch {
+	case in.Thread != "":
+		rows, err = pgdao.ListMessageThread(ctx, s.DB, in.Thread)
+	case in.Role != "":
+		rows, err = pgdao.ListMessages(ctx, s.DB, in.Role, in.Experiment, in.Task, in.Status, int(in.Limit), int(in.Offset))
+	default:
+		return nil, fmt.Errorf("%w: role or thread is required", errInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListMessagesResponse{Items: make([]MessageItem, 0, len(rows))}
+	for _, m := range rows {
+		resp.Items = append(resp.Items, MessageItem{
+			ID: m.ID, ContentID: m.ContentID, Role: m.RoleName, Status: m.Status, Created: m.Created.Format(time.RFC3339Nano),
+			ExperimentID: m.ExperimentID.String, FromTaskID: m.FromTaskID.String, ParentMessageID: m.ParentMessageID.String,
+			ToRole: m.ToRole, Error: m.ErrorMessage.String, Tags: m.Tags,
+		})
+	}
+	return resp, nil
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func sqlString(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
@@ -0,0 +1,56 @@
+package message
+
+import "encoding/json"
+
+// JSON-friendly request/response types for gRPC JSON codec.
+
+type SendMessageRequest struct {
+	Role            string          `json:"role"`
+	Text            string          `json:"text"`
+	JSON            json.RawMessage `json:"json,omitempty"`
+	Status          string          `json:"status,omitempty"`
+	Experiment      string          `json:"experiment,omitempty"`
+	FromTask        string          `json:"from_task,omitempty"`
+	Tags            map[string]any  `json:"tags,omitempty"`
+	ParentMessageID string          `json:"parent_message_id,omitempty"`
+	ToRole          []string        `json:"to_role,omitempty"`
+}
+
+type SendMessageResponse struct {
+	ID              string   `json:"id"`
+	ContentID       string   `json:"content_id"`
+	Status          string   `json:"status"`
+	ExperimentID    string   `json:"experiment_id,omitempty"`
+	ParentMessageID string   `json:"parent_message_id,omitempty"`
+	ToRole          []string `json:"to_role,omitempty"`
+}
+
+// ListMessagesRequest lists by role and filters, or a whole reply thread
+// when Thread (a message id) is set.
+type ListMessagesRequest struct {
+	Role       string `json:"role,omitempty"`
+	Experiment string `json:"experiment,omitempty"`
+	Task       string `json:"task,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Thread     string `json:"thread,omitempty"`
+	Limit      int32  `json:"limit,omitempty"`
+	Offset     int32  `json:"offset,omitempty"`
+}
+
+type MessageItem struct {
+	ID              string         `json:"id"`
+	ContentID       string         `json:"content_id"`
+	Role            string         `json:"role,omitempty"`
+	Status          string         `json:"status"`
+	Created         string         `json:"created,omitempty"`
+	ExperimentID    string         `json:"experiment_id,omitempty"`
+	FromTaskID      string         `json:"from_task_id,omitempty"`
+	ParentMessageID string         `json:"parent_message_id,omitempty"`
+	ToRole          []string       `json:"to_role,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	Tags            map[string]any `json:"tags,omitempty"`
+}
+
+type ListMessagesResponse struct {
+	Items []MessageItem `json:"items"`
+}
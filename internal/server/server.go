@@ -15,6 +15,8 @@ import (
 	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
 	toolingdao "github.com/flarebyte/baldrick-rebec/internal/dao/tooling"
 	"github.com/flarebyte/baldrick-rebec/internal/paths"
+	"github.com/flarebyte/baldrick-rebec/internal/scheduler"
+	messagesvc "github.com/flarebyte/baldrick-rebec/internal/server/message"
 	promptsvc "github.com/flarebyte/baldrick-rebec/internal/server/prompt"
 	testcasesvc "github.com/flarebyte/baldrick-rebec/internal/server/testcase"
 	responsesvc "github.com/flarebyte/baldrick-rebec/internal/service/responses"
@@ -51,8 +53,16 @@ func RunForeground(addr, pidPath string) error {
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Scheduler runs configured jobs until shutdown; SIGHUP reloads its jobs.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sched := scheduler.New(scheduler.DefaultStatePath())
+
 	// Register services backed by DAOs and services
 	if cfg, err := config.Load(); err == nil {
+		if err := sched.Configure(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "server: scheduler disabled: %v\n", err)
+		}
 		// Open DB with default timeout
 		// Note: keep pool for process lifetime; server will close on shutdown.
 		if db, e := pgdao.OpenApp(context.Background(), cfg); e == nil {
@@ -70,8 +80,14 @@ func RunForeground(addr, pidPath string) error {
 			tsvc.Register(gs)
 			// Mount Connect-style JSON HTTP handlers for testcases as well
 			mux.Handle("/testcase.v1.TestcaseService/", tsvc.ConnectHandler())
+
+			// Message gRPC JSON service (send, list, threads)
+			msvc := &messagesvc.Service{DB: db}
+			msvc.Register(gs)
+			mux.Handle("/message.v1.MessageService/", msvc.ConnectHandler())
 		}
 	}
+	go sched.Run(ctx)
 
 	// Graceful shutdown on SIGTERM/SIGINT and config reload on SIGHUP
 	sigCh := make(chan os.Signal, 1)
@@ -81,12 +97,15 @@ func RunForeground(addr, pidPath string) error {
 			sig := <-sigCh
 			switch sig {
 			case syscall.SIGTERM, syscall.SIGINT:
+				cancel()
 				gs.GracefulStop()
 				return
 			case syscall.SIGHUP:
 				// Reload config; dynamic settings (like ports) require restart; we just refresh values.
-				if _, err := config.Load(); err != nil {
+				if cfg, err := config.Load(); err != nil {
 					fmt.Fprintf(os.Stderr, "server: reload-config failed: %v\n", err)
+				} else if err := sched.Configure(cfg); err != nil {
+					fmt.Fprintf(os.Stderr, "server: config reloaded; scheduler unchanged: %v\n", err)
 				} else {
 					fmt.Fprintln(os.Stderr, "server: config reloaded")
 				}
@@ -0,0 +1,110 @@
+// Package stickieschema checks stickie structured payloads against the JSON
+// Schemas declared by their blackboard. A schema labelled "*" applies to every
+// stickie that has a payload; a schema for label L applies to stickies carrying
+// L and makes the payload mandatory for them.
+package stickieschema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/jsonschema"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Set is the compiled schemas of one blackboard keyed by label.
+type Set struct {
+	schemas map[string]*jsonschema.Schema
+}
+
+// Compile builds a set from raw schemas keyed by label ("" is treated as "*").
+func Compile(raw map[string][]byte) (*Set, error) {
+	s := &Set{schemas: map[string]*jsonschema.Schema{}}
+	for label, doc := range raw {
+		if label == "" {
+			label = pgdao.SchemaLabelAll
+		}
+		cs, err := jsonschema.Compile(doc)
+		if err != nil {
+			return nil, fmt.Errorf("schema for label %q: %w", label, err)
+		}
+		s.schemas[label] = cs
+	}
+	return s, nil
+}
+
+// Load compiles the schemas stored for a blackboard.
+func Load(ctx context.Context, db *pgxpool.Pool, blackboardID string) (*Set, error) {
+	rows, err := pgdao.ListBlackboardSchemas(ctx, db, blackboardID)
+	if err != nil {
+		return nil, err
+	}
+	return fromRows(rows)
+}
+
+// Validate compiles stored schema rows and checks a stickie against them. It
+// is a pgdao.StickieValidator, so writes can validate inside their transaction.
+func Validate(subject string, rows []pgdao.BlackboardSchema, labels []string, structured []byte) error {
+	set, err := fromRows(rows)
+	if err != nil {
+		return err
+	}
+	return set.Check(subject, labels, structured)
+}
+
+var _ pgdao.StickieValidator = Validate
+
+func fromRows(rows []pgdao.BlackboardSchema) (*Set, error) {
+	raw := make(map[string][]byte, len(rows))
+	for _, r := range rows {
+		raw[r.Label] = r.Schema
+	}
+	return Compile(raw)
+}
+
+// Empty reports whether the set declares no schema.
+func (s *Set) Empty() bool { return s == nil || len(s.schemas) == 0 }
+
+// Check validates a payload (nil or empty when absent) for a stickie with the
+// given labels. subject names the stickie in the error. It returns a
+// *jsonschema.ValidationError listing every violation, or nil.
+func (s *Set) Check(subject string, labels []string, structured []byte) error {
+	if s.Empty() {
+		return nil
+	}
+	var applicable []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		if _, ok := s.schemas[l]; ok && l != pgdao.SchemaLabelAll && !seen[l] {
+			seen[l] = true
+			applicable = append(applicable, l)
+		}
+	}
+	sort.Strings(applicable)
+	if _, ok := s.schemas[pgdao.SchemaLabelAll]; ok {
+		applicable = append([]string{pgdao.SchemaLabelAll}, applicable...)
+	}
+	var out []jsonschema.Violation
+	for _, label := range applicable {
+		if len(structured) == 0 {
+			if label != pgdao.SchemaLabelAll {
+				out = append(out, jsonschema.Violation{Message: "structured payload is required", Schema: label})
+			}
+			continue
+		}
+		vs, err := s.schemas[label].Validate(structured)
+		if err != nil {
+			return fmt.Errorf("%s: %w", subject, err)
+		}
+		for _, v := range vs {
+			v.Schema = label
+			out = append(out, v)
+		}
+	}
+	if len(out) > 0 {
+		return &jsonschema.ValidationError{Subject: subject, Violations: out}
+	}
+	return nil
+}
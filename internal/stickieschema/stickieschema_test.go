@@ -0,0 +1,49 @@
+package stickieschema
+
+import (
+	"errors"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+	"github.com/flarebyte/baldrick-rebec/internal/jsonschema"
+)
+
+func TestCheckLabelsAndRequiredPayload(t *testing.T) {
+	set, err := Compile(map[string][]byte{
+		"":         []byte(`{"type":"object"}`),
+		"decision": []byte(`{"type":"object","required":["status"]}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Check("s1", []string{"idea"}, nil); err != nil {
+		t.Fatalf("unlabelled stickie without payload rejected: %v", err)
+	}
+	if err := set.Check("s2", []string{"idea"}, []byte(`[1]`)); err == nil {
+		t.Fatal("expected '*' schema to reject an array payload")
+	}
+	err = set.Check("s3", []string{"decision"}, nil)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) || len(ve.Violations) != 1 || ve.Violations[0].Message != "structured payload is required" {
+		t.Fatalf("expected missing payload violation; got %v", err)
+	}
+	err = set.Check("s4", []string{"decision"}, []byte(`{"owner":"ops"}`))
+	if !errors.As(err, &ve) || ve.Violations[0].Schema != "decision" {
+		t.Fatalf("expected decision schema violation; got %v", err)
+	}
+}
+
+func TestValidateStoredRows(t *testing.T) {
+	rows := []pgdao.BlackboardSchema{{Label: "decision", Schema: []byte(`{"type":"object","required":["status"]}`)}}
+	if err := Validate("s1", rows, []string{"decision"}, []byte(`{"status":"accepted"}`)); err != nil {
+		t.Fatalf("valid payload rejected: %v", err)
+	}
+	var ve *jsonschema.ValidationError
+	if err := Validate("s1 rev 2", rows, []string{"decision"}, []byte(`{}`)); !errors.As(err, &ve) || ve.Subject != "s1 rev 2" {
+		t.Fatalf("expected violation for s1 rev 2; got %v", err)
+	}
+	rows = append(rows, pgdao.BlackboardSchema{Label: "*", Schema: []byte(`{"contains":{}}`)})
+	if err := Validate("s1", rows, nil, nil); err == nil {
+		t.Fatal("expected unsupported keyword to fail compilation")
+	}
+}
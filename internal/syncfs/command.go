@@ -0,0 +1,183 @@
+package syncfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Opener builds an adapter for the scope named by an id:<scope> endpoint.
+// The returned func releases resources (e.g. closes the DB pool).
+type Opener func(ctx context.Context, scope string) (Adapter, func(), error)
+
+// CommandSpec describes the sync/diff/import commands generated for one entity type.
+type CommandSpec struct {
+	Noun      string // entity name used in help, e.g. "project"
+	ScopeHelp string // what id:<scope> selects, e.g. "role name"
+	Open      Opener
+}
+
+// Commands returns `sync`, `diff` and `import` subcommands sharing the same endpoints and flags:
+//
+//	sync   id:<scope> folder:<path> | folder:<path> id:<scope>  [--dry-run] [--delete] [--force-write]
+//	diff   id:<scope> folder:<path>                              [--detailed] [--output text|json]
+//	import folder:<path> id:<scope>                              [--dry-run]
+func Commands(spec CommandSpec) []*cobra.Command {
+	return []*cobra.Command{syncCommand(spec), diffCommand(spec), importCommand(spec)}
+}
+
+func parsePair(args []string) (id, folder Endpoint, idFirst bool, err error) {
+	a, err := ParseEndpoint(args[0])
+	if err != nil {
+		return
+	}
+	b, err := ParseEndpoint(args[1])
+	if err != nil {
+		return
+	}
+	id, folder, idFirst, err = SplitPair(a, b)
+	if err != nil {
+		return
+	}
+	if id.Value == "_" {
+		err = errors.New("id:_ shortcut is not supported here; name the scope explicitly")
+		return
+	}
+	folder.Value, err = CleanFolder(folder.Value)
+	return
+}
+
+func run(spec CommandSpec, scope string, timeout time.Duration, fn func(ctx context.Context, a Adapter) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	a, closeFn, err := spec.Open(ctx, scope)
+	if err != nil {
+		return err
+	}
+	if closeFn != nil {
+		defer closeFn()
+	}
+	return fn(ctx, a)
+}
+
+func syncCommand(spec CommandSpec) *cobra.Command {
+	var del, dry, force bool
+	cmd := &cobra.Command{
+		Use:   "sync <source> <target>",
+		Short: fmt.Sprintf("Sync %ss between id and folder", spec.Noun),
+		Long: fmt.Sprintf("Sync %ss between id:<%s> and folder:RELATIVE_PATH (one *.%s.yaml per %s). Supports id->folder and folder->id.",
+			spec.Noun, spec.ScopeHelp, spec.Noun, spec.Noun),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, folder, idFirst, err := parsePair(args)
+			if err != nil {
+				return err
+			}
+			opts := Options{Delete: del, DryRun: dry, ForceWrite: force}
+			return run(spec, id.Value, 60*time.Second, func(ctx context.Context, a Adapter) error {
+				if idFirst {
+					s, err := Pull(ctx, a, folder.Value, opts)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(os.Stderr, "%s sync id->folder scope=%q folder=%s written=%d deleted=%d unchanged=%d\n", spec.Noun, id.Value, folder.Value, s.Written, s.Deleted, s.Unchanged)
+					return nil
+				}
+				s, err := Push(ctx, a, folder.Value, opts)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "%s sync folder->id scope=%q folder=%s applied=%d deleted=%d unchanged=%d\n", spec.Noun, id.Value, folder.Value, s.Applied, s.Deleted, s.Unchanged)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&del, "delete", false, "Delete destination items that are not present at source")
+	cmd.Flags().BoolVar(&dry, "dry-run", false, "Show what would change without writing or deleting")
+	cmd.Flags().BoolVar(&force, "force-write", false, "Force rewrite files even if destination appears up-to-date")
+	return cmd
+}
+
+func diffCommand(spec CommandSpec) *cobra.Command {
+	var detailed bool
+	var output string
+	cmd := &cobra.Command{
+		Use:   "diff <left> <right>",
+		Short: fmt.Sprintf("Diff %ss (id) against a folder", spec.Noun),
+		Long:  fmt.Sprintf("Compare %ss in id:<%s> with folder:RELATIVE_PATH and display concise, detailed or JSON differences.", spec.Noun, spec.ScopeHelp),
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := ParseDiffOutput(output)
+			if err != nil {
+				return err
+			}
+			id, folder, _, err := parsePair(args)
+			if err != nil {
+				return err
+			}
+			return run(spec, id.Value, 45*time.Second, func(ctx context.Context, a Adapter) error {
+				local, err := a.LoadLocal(folder.Value)
+				if err != nil {
+					return err
+				}
+				remote, err := a.LoadRemote(ctx)
+				if err != nil {
+					return err
+				}
+				return PrintChanges(os.Stdout, Diff(remote, local), detailed, asJSON)
+			})
+		},
+	}
+	AddDiffFlags(cmd, &detailed, &output)
+	return cmd
+}
+
+// AddDiffFlags registers the --detailed and --output flags shared by all diff commands.
+func AddDiffFlags(cmd *cobra.Command, detailed *bool, output *string) {
+	cmd.Flags().BoolVar(detailed, "detailed", false, "Show detailed differences (default: concise)")
+	cmd.Flags().StringVar(output, "output", "text", "Output format: text or json")
+}
+
+// ParseDiffOutput validates a diff --output value and reports whether JSON was requested.
+func ParseDiffOutput(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid --output %q (use text or json)", s)
+	}
+}
+
+func importCommand(spec CommandSpec) *cobra.Command {
+	var dry bool
+	cmd := &cobra.Command{
+		Use:          "import <folder> <id>",
+		Short:        fmt.Sprintf("Import %ss from a folder (create only)", spec.Noun),
+		Long:         fmt.Sprintf("Create every *.%s.yaml of folder:RELATIVE_PATH in id:<%s>. Fails without writing when any of them already exists; use sync to update.", spec.Noun, spec.ScopeHelp),
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, folder, _, err := parsePair(args)
+			if err != nil {
+				return err
+			}
+			return run(spec, id.Value, 60*time.Second, func(ctx context.Context, a Adapter) error {
+				s, err := Import(ctx, a, folder.Value, Options{DryRun: dry})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "%s import scope=%q folder=%s imported=%d\n", spec.Noun, id.Value, folder.Value, s.Applied)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&dry, "dry-run", false, "Show what would be imported without writing")
+	return cmd
+}
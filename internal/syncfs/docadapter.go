@@ -0,0 +1,160 @@
+package syncfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// DocAdapter adapts entities stored as one YAML document per record, in files named
+// <sanitized-key>.<kind>.yaml. T is the YAML struct; it is marshalled on both sides
+// so that remote and local records normalize identically.
+type DocAdapter[T any] struct {
+	Noun   string
+	List   func(ctx context.Context) ([]T, error)
+	KeyOf  func(T) string
+	Put    func(ctx context.Context, v T) error
+	Remove func(ctx context.Context, v T) error
+	// Keep optionally restricts which local documents belong to the scope.
+	Keep func(T) bool
+}
+
+func (d *DocAdapter[T]) Kind() string { return d.Noun }
+
+func (d *DocAdapter[T]) suffix() string { return "." + d.Noun + ".yaml" }
+
+// FileName returns the file name used for a key.
+func (d *DocAdapter[T]) FileName(key string) string {
+	base := SanitizeForFile(key)
+	if base == "" {
+		base = d.Noun
+	}
+	return base + d.suffix()
+}
+
+func (d *DocAdapter[T]) record(v T) (Record, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return Record{}, err
+	}
+	key := d.KeyOf(v)
+	return Record{Kind: d.Noun, Key: key, Files: []File{{Name: d.FileName(key), Data: b}}, Value: v}, nil
+}
+
+func (d *DocAdapter[T]) LoadRemote(ctx context.Context) ([]Record, error) {
+	vs, err := d.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Record, 0, len(vs))
+	for _, v := range vs {
+		r, err := d.record(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (d *DocAdapter[T]) LoadLocal(dir string) ([]Record, error) {
+	var out []Record
+	seen := map[string]string{}
+	err := EachFile(dir, d.suffix(), func(name string, data []byte) error {
+		var v T
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		if d.Keep != nil && !d.Keep(v) {
+			return nil
+		}
+		key := d.KeyOf(v)
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%s: missing %s key", name, d.Noun)
+		}
+		if prev, dup := seen[key]; dup {
+			return fmt.Errorf("%s %q declared twice (%s and %s)", d.Noun, key, prev, name)
+		}
+		seen[key] = name
+		r, err := d.record(v)
+		if err != nil {
+			return err
+		}
+		// keep the actual file name so --delete and diffs point at what is on disk
+		r.Files[0].Name = name
+		out = append(out, r)
+		return nil
+	})
+	return out, err
+}
+
+func (d *DocAdapter[T]) Apply(ctx context.Context, r Record) error {
+	v, ok := r.Value.(T)
+	if !ok {
+		return fmt.Errorf("%s %q: unexpected record payload", d.Noun, r.Key)
+	}
+	return d.Put(ctx, v)
+}
+
+func (d *DocAdapter[T]) Delete(ctx context.Context, r Record) error {
+	if d.Remove == nil {
+		return fmt.Errorf("%s: delete not supported", d.Noun)
+	}
+	v, ok := r.Value.(T)
+	if !ok {
+		return fmt.Errorf("%s %q: unexpected record payload", d.Noun, r.Key)
+	}
+	return d.Remove(ctx, v)
+}
+
+// EachFile calls fn for every regular file in dir whose name ends with suffix,
+// in name order. A missing dir is treated as empty.
+func EachFile(dir, suffix string, fn func(name string, data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read folder: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), suffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		b, err := os.ReadFile(filepath.Join(dir, n))
+		if err != nil {
+			return err
+		}
+		if err := fn(n, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Paged collects every row of a limit/offset listing.
+func Paged[T any](page func(limit, offset int) ([]T, error)) ([]T, error) {
+	const size = 1000
+	var out []T
+	for off := 0; ; off += size {
+		rows, err := page(size, off)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rows...)
+		if len(rows) < size {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package syncfs holds the shared machinery behind the `sync`, `diff` and
+// `import` commands that mirror database entities to and from YAML folders.
+package syncfs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Kind tells which side of a sync an endpoint refers to.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindID
+	KindFolder
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindID:
+		return "id"
+	case KindFolder:
+		return "folder"
+	default:
+		return "unknown"
+	}
+}
+
+// Endpoint is a parsed `id:<value>` or `folder:<relpath>` argument.
+type Endpoint struct {
+	Kind  Kind
+	Value string
+}
+
+// ParseEndpoint parses id:<value> and folder:<relative-path>. Folder paths must be relative.
+func ParseEndpoint(s string) (Endpoint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Endpoint{}, errors.New("empty endpoint")
+	}
+	if strings.HasPrefix(s, "id:") {
+		v := strings.TrimSpace(strings.TrimPrefix(s, "id:"))
+		if v == "" {
+			return Endpoint{}, errors.New("id endpoint missing value")
+		}
+		return Endpoint{Kind: KindID, Value: v}, nil
+	}
+	if strings.HasPrefix(s, "folder:") {
+		v := strings.TrimSpace(strings.TrimPrefix(s, "folder:"))
+		if v == "" {
+			return Endpoint{}, errors.New("folder endpoint missing path")
+		}
+		if filepath.IsAbs(v) {
+			return Endpoint{}, errors.New("folder path must be relative")
+		}
+		return Endpoint{Kind: KindFolder, Value: v}, nil
+	}
+	return Endpoint{}, fmt.Errorf("unsupported endpoint %q (use id:<value> or folder:<path>)", s)
+}
+
+// SplitPair orders two endpoints as (id, folder) and reports whether the
+// id endpoint came first. Same-kind pairs are rejected.
+func SplitPair(a, b Endpoint) (id, folder Endpoint, idFirst bool, err error) {
+	if a.Kind == b.Kind {
+		return Endpoint{}, Endpoint{}, false, fmt.Errorf("cannot pair %s with %s; use id:<value> and folder:<path>", a.Kind, b.Kind)
+	}
+	if a.Kind == KindID {
+		return a, b, true, nil
+	}
+	return b, a, false, nil
+}
+
+// CleanFolder cleans a relative folder path and rejects paths escaping the working directory.
+func CleanFolder(rel string) (string, error) {
+	if strings.TrimSpace(rel) == "" {
+		return "", errors.New("folder path is empty")
+	}
+	dir := filepath.Clean(rel)
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "..") {
+		return "", errors.New("folder path must not escape current directory")
+	}
+	return dir, nil
+}
@@ -0,0 +1,533 @@
+package syncfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// File is one file of a record inside a folder, in normalized form.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Record is one entity as seen from one side of a sync. Files[0] is the primary
+// YAML document; further files are sidecars (e.g. a script body). Remote and local
+// records are matched by Key and compared byte-wise, so both sides must normalize
+// the same way (typically by marshalling the same YAML struct).
+type Record struct {
+	Kind  string
+	Key   string
+	Files []File
+	Value any // adapter-specific payload (e.g. the parsed YAML struct)
+	// Content optionally replaces the primary document when comparing records, so
+	// that bookkeeping fields (timestamps, edit counts) do not count as changes.
+	// Pull still rewrites files whose bytes differ, keeping that bookkeeping fresh.
+	Content []byte
+}
+
+// Primary returns the primary file name.
+func (r Record) Primary() string {
+	if len(r.Files) == 0 {
+		return ""
+	}
+	return r.Files[0].Name
+}
+
+func (r Record) file(name string) (File, bool) {
+	for _, f := range r.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// content returns what the primary document is compared by.
+func (r Record) content() []byte {
+	if r.Content != nil {
+		return r.Content
+	}
+	if len(r.Files) == 0 {
+		return nil
+	}
+	return r.Files[0].Data
+}
+
+// Equal reports whether both records carry the same content. Primary documents are
+// compared regardless of file name (a local file may be named freely); sidecars by name.
+func (r Record) Equal(o Record) bool {
+	return bytes.Equal(r.content(), o.content()) && r.sameSidecars(o)
+}
+
+// sameFiles is Equal on the file bytes alone, bookkeeping included.
+func (r Record) sameFiles(o Record) bool {
+	if len(r.Files) > 0 && len(o.Files) > 0 && !bytes.Equal(r.Files[0].Data, o.Files[0].Data) {
+		return false
+	}
+	return r.sameSidecars(o)
+}
+
+func (r Record) sameSidecars(o Record) bool {
+	if len(r.Files) != len(o.Files) {
+		return false
+	}
+	if len(r.Files) == 0 {
+		return true
+	}
+	for _, f := range r.Files[1:] {
+		g, ok := o.file(f.Name)
+		if !ok || !bytes.Equal(f.Data, g.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// Adapter connects one entity type to the engine. The engine always calls
+// LoadLocal before LoadRemote, so an adapter may render remote records with
+// the names the folder already uses (e.g. a script's body file).
+type Adapter interface {
+	// Kind names the entity type (e.g. "project"); used in output.
+	Kind() string
+	// LoadRemote renders every record of the scope from the database.
+	LoadRemote(ctx context.Context) ([]Record, error)
+	// LoadLocal parses the records of this entity type found in dir (missing dir: none).
+	LoadLocal(dir string) ([]Record, error)
+	// Apply creates or updates the remote entity from a local record.
+	Apply(ctx context.Context, r Record) error
+	// Delete removes the remote entity of a remote record.
+	Delete(ctx context.Context, r Record) error
+}
+
+// Op is the diff marker: '=' unchanged, '~' changed, '+' remote-only, '-' local-only.
+type Op string
+
+const (
+	OpSame       Op = "="
+	OpChanged    Op = "~"
+	OpRemoteOnly Op = "+"
+	OpLocalOnly  Op = "-"
+)
+
+// FieldChange describes one differing field (or sidecar file) of a changed record.
+type FieldChange struct {
+	Name   string `json:"name"`
+	Remote string `json:"remote"`
+	Local  string `json:"local"`
+}
+
+// Change is the diff outcome for one record key.
+type Change struct {
+	Op     Op            `json:"op"`
+	Kind   string        `json:"kind"`
+	Key    string        `json:"key"`
+	File   string        `json:"file,omitempty"`
+	Note   string        `json:"note,omitempty"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// Diff matches remote and local records by key and returns changes ordered by kind then key.
+func Diff(remote, local []Record) []Change {
+	rm := indexRecords(remote)
+	lm := indexRecords(local)
+	keys := make(map[string]Record, len(rm)+len(lm))
+	for k, r := range rm {
+		keys[k] = r
+	}
+	for k, r := range lm {
+		if _, ok := keys[k]; !ok {
+			keys[k] = r
+		}
+	}
+	ordered := make([]string, 0, len(keys))
+	for k := range keys {
+		ordered = append(ordered, k)
+	}
+	sort.Strings(ordered)
+	out := make([]Change, 0, len(ordered))
+	for _, k := range ordered {
+		r, inRemote := rm[k]
+		l, inLocal := lm[k]
+		switch {
+		case inRemote && !inLocal:
+			out = append(out, Change{Op: OpRemoteOnly, Kind: r.Kind, Key: r.Key, File: r.Primary(), Note: "remote-only"})
+		case !inRemote && inLocal:
+			out = append(out, Change{Op: OpLocalOnly, Kind: l.Kind, Key: l.Key, File: l.Primary(), Note: "local-only"})
+		case r.Equal(l):
+			out = append(out, Change{Op: OpSame, Kind: r.Kind, Key: r.Key, File: l.Primary()})
+		default:
+			out = append(out, Change{Op: OpChanged, Kind: r.Kind, Key: r.Key, File: l.Primary(), Fields: RecordFields(r, l)})
+		}
+	}
+	return out
+}
+
+func indexRecords(rs []Record) map[string]Record {
+	m := make(map[string]Record, len(rs))
+	for _, r := range rs {
+		m[r.Kind+"\x00"+r.Key] = r
+	}
+	return m
+}
+
+// RecordFields lists the differing top-level YAML fields of the primary documents
+// (or of their Content),
+// then the differing sidecar files (named "file:<name>", compared by short hash).
+func RecordFields(remote, local Record) []FieldChange {
+	var out []FieldChange
+	if rc, lc := remote.content(), local.content(); !bytes.Equal(rc, lc) {
+		out = append(out, YAMLFields(rc, lc)...)
+	}
+	names := map[string]struct{}{}
+	for _, f := range remote.Files[min(1, len(remote.Files)):] {
+		names[f.Name] = struct{}{}
+	}
+	for _, f := range local.Files[min(1, len(local.Files)):] {
+		names[f.Name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+	for _, n := range sorted {
+		rf, _ := remote.file(n)
+		lf, _ := local.file(n)
+		if !bytes.Equal(rf.Data, lf.Data) {
+			out = append(out, FieldChange{Name: "file:" + n, Remote: ShortHash(string(rf.Data)), Local: ShortHash(string(lf.Data))})
+		}
+	}
+	return out
+}
+
+// YAMLFields compares two YAML mappings key by key and returns the differing keys.
+func YAMLFields(remote, local []byte) []FieldChange {
+	var rm, lm map[string]any
+	_ = yaml.Unmarshal(remote, &rm)
+	_ = yaml.Unmarshal(local, &lm)
+	keys := map[string]struct{}{}
+	for k := range rm {
+		keys[k] = struct{}{}
+	}
+	for k := range lm {
+		keys[k] = struct{}{}
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var out []FieldChange
+	for _, k := range names {
+		if reflect.DeepEqual(rm[k], lm[k]) {
+			continue
+		}
+		out = append(out, FieldChange{Name: k, Remote: renderValue(rm[k]), Local: renderValue(lm[k])})
+	}
+	return out
+}
+
+func renderValue(v any) string {
+	if v == nil {
+		return "(nil)"
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// PrintChanges writes changes as a JSON array, or as one line per change:
+// concise lines list changed field names, detailed lines show remote/local values.
+func PrintChanges(w io.Writer, changes []Change, detailed, asJSON bool) error {
+	if asJSON {
+		if changes == nil {
+			changes = []Change{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+	for _, c := range changes {
+		head := fmt.Sprintf("%s %s %q", c.Op, c.Kind, c.Key)
+		if c.File != "" {
+			head += " file=" + c.File
+		}
+		switch {
+		case c.Op != OpChanged:
+			if c.Note != "" {
+				head += " (" + c.Note + ")"
+			}
+			fmt.Fprintln(w, head)
+		case detailed:
+			fmt.Fprintf(w, "%s:%s\n", head, FormatDetailed(c.Fields))
+		default:
+			names := make([]string, 0, len(c.Fields))
+			for _, f := range c.Fields {
+				names = append(names, f.Name)
+			}
+			fmt.Fprintf(w, "%s fields:%s\n", head, strings.Join(names, ","))
+		}
+	}
+	return nil
+}
+
+// FormatDetailed renders " name[remote=\"..\" local=\"..\"]" for each field.
+func FormatDetailed(fields []FieldChange) string {
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(" ")
+		b.WriteString(f.Name)
+		b.WriteString("[remote=")
+		b.WriteString(QuoteShort(f.Remote))
+		b.WriteString(" local=")
+		b.WriteString(QuoteShort(f.Local))
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
+// Options controls Pull, Push and Import.
+type Options struct {
+	Delete     bool      // remove destination records absent at the source
+	DryRun     bool      // print intended actions only
+	ForceWrite bool      // Pull: rewrite files even when up-to-date
+	Log        io.Writer // progress lines; defaults to os.Stderr
+}
+
+func (o Options) log() io.Writer {
+	if o.Log == nil {
+		return os.Stderr
+	}
+	return o.Log
+}
+
+// Summary counts what a run did (or would do in dry-run).
+type Summary struct {
+	Written   int `json:"written,omitempty"`
+	Applied   int `json:"applied,omitempty"`
+	Deleted   int `json:"deleted,omitempty"`
+	Unchanged int `json:"unchanged"`
+	Conflicts int `json:"conflicts,omitempty"`
+}
+
+// ErrConflict is wrapped by Apply errors for records that changed remotely since
+// the local copy was taken. Push reports such records and carries on; the run then
+// fails with a ConflictError.
+var ErrConflict = errors.New("changed remotely since the local copy was taken")
+
+// Pull mirrors remote records into dir (id -> folder).
+func Pull(ctx context.Context, a Adapter, dir string, opts Options) (Summary, error) {
+	var sum Summary
+	local, err := a.LoadLocal(dir)
+	if err != nil {
+		return sum, err
+	}
+	remote, err := a.LoadRemote(ctx)
+	if err != nil {
+		return sum, err
+	}
+	lm := indexRecords(local)
+	if !opts.DryRun {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return sum, fmt.Errorf("create dest folder: %w", err)
+		}
+	}
+	w := opts.log()
+	for _, r := range sortRecords(remote) {
+		l, ok := lm[r.Kind+"\x00"+r.Key]
+		if ok && r.sameFiles(l) && !opts.ForceWrite {
+			sum.Unchanged++
+			continue
+		}
+		for _, f := range r.Files {
+			p := filepath.Join(dir, f.Name)
+			if opts.DryRun {
+				fmt.Fprintf(w, "[dry-run] write %s\n", p)
+				continue
+			}
+			if err := WriteFileAtomic(p, f.Data); err != nil {
+				return sum, fmt.Errorf("write %s: %w", p, err)
+			}
+			fmt.Fprintf(w, "wrote %s\n", p)
+		}
+		sum.Written++
+		// The same key under other file names would load twice next time: drop stale names.
+		if ok {
+			for _, f := range l.Files {
+				if _, keep := r.file(f.Name); keep {
+					continue
+				}
+				p := filepath.Join(dir, f.Name)
+				if opts.DryRun {
+					fmt.Fprintf(w, "[dry-run] delete %s\n", p)
+					continue
+				}
+				if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return sum, err
+				}
+				fmt.Fprintf(w, "deleted %s\n", p)
+			}
+		}
+	}
+	if opts.Delete {
+		rm := indexRecords(remote)
+		for _, l := range sortRecords(local) {
+			if _, ok := rm[l.Kind+"\x00"+l.Key]; ok {
+				continue
+			}
+			for _, f := range l.Files {
+				p := filepath.Join(dir, f.Name)
+				if opts.DryRun {
+					fmt.Fprintf(w, "[dry-run] delete %s\n", p)
+					continue
+				}
+				if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return sum, err
+				}
+				fmt.Fprintf(w, "deleted %s\n", p)
+			}
+			sum.Deleted++
+		}
+	}
+	return sum, nil
+}
+
+// Push applies local records to the database (folder -> id). Unchanged records are skipped.
+func Push(ctx context.Context, a Adapter, dir string, opts Options) (Summary, error) {
+	var sum Summary
+	local, err := a.LoadLocal(dir)
+	if err != nil {
+		return sum, err
+	}
+	remote, err := a.LoadRemote(ctx)
+	if err != nil {
+		return sum, err
+	}
+	rm := indexRecords(remote)
+	w := opts.log()
+	for _, l := range sortRecords(local) {
+		if r, ok := rm[l.Kind+"\x00"+l.Key]; ok && r.Equal(l) {
+			sum.Unchanged++
+			continue
+		}
+		if opts.DryRun {
+			fmt.Fprintf(w, "[dry-run] apply %s %q from %s\n", l.Kind, l.Key, l.Primary())
+		} else {
+			if err := a.Apply(ctx, l); err != nil {
+				if errors.Is(err, ErrConflict) {
+					fmt.Fprintf(w, "! %s %q %s (%v)\n", l.Kind, l.Key, l.Primary(), err)
+					sum.Conflicts++
+					continue
+				}
+				return sum, fmt.Errorf("apply %s %q from %s: %w", l.Kind, l.Key, l.Primary(), err)
+			}
+			fmt.Fprintf(w, "applied %s %q\n", l.Kind, l.Key)
+		}
+		sum.Applied++
+	}
+	if opts.Delete {
+		lm := indexRecords(local)
+		for _, r := range sortRecords(remote) {
+			if _, ok := lm[r.Kind+"\x00"+r.Key]; ok {
+				continue
+			}
+			if opts.DryRun {
+				fmt.Fprintf(w, "[dry-run] delete %s %q\n", r.Kind, r.Key)
+			} else {
+				if err := a.Delete(ctx, r); err != nil {
+					return sum, fmt.Errorf("delete %s %q: %w", r.Kind, r.Key, err)
+				}
+				fmt.Fprintf(w, "deleted %s %q\n", r.Kind, r.Key)
+			}
+			sum.Deleted++
+		}
+	}
+	if sum.Conflicts > 0 {
+		return sum, &ConflictError{Count: sum.Conflicts}
+	}
+	return sum, nil
+}
+
+// Import creates every local record in the database. Like `blackboard import` it refuses
+// to overwrite: it fails before writing anything when any record already exists remotely.
+func Import(ctx context.Context, a Adapter, dir string, opts Options) (Summary, error) {
+	var sum Summary
+	local, err := a.LoadLocal(dir)
+	if err != nil {
+		return sum, err
+	}
+	if len(local) == 0 {
+		return sum, fmt.Errorf("no %s files found in %s", a.Kind(), dir)
+	}
+	remote, err := a.LoadRemote(ctx)
+	if err != nil {
+		return sum, err
+	}
+	rm := indexRecords(remote)
+	var clash []string
+	for _, l := range sortRecords(local) {
+		if _, ok := rm[l.Kind+"\x00"+l.Key]; ok {
+			clash = append(clash, fmt.Sprintf("%s %q (%s)", l.Kind, l.Key, l.Primary()))
+		}
+	}
+	if len(clash) > 0 {
+		return sum, fmt.Errorf("already exists: %s; use sync folder:%s id:<scope> to update", strings.Join(clash, ", "), dir)
+	}
+	w := opts.log()
+	for _, l := range sortRecords(local) {
+		if opts.DryRun {
+			fmt.Fprintf(w, "[dry-run] import %s %q from %s\n", l.Kind, l.Key, l.Primary())
+		} else {
+			if err := a.Apply(ctx, l); err != nil {
+				return sum, fmt.Errorf("import %s %q from %s: %w", l.Kind, l.Key, l.Primary(), err)
+			}
+			fmt.Fprintf(w, "imported %s %q\n", l.Kind, l.Key)
+		}
+		sum.Applied++
+	}
+	return sum, nil
+}
+
+func sortRecords(rs []Record) []Record {
+	out := append([]Record(nil), rs...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// ConflictExitCode is the process exit status for syncs that stopped on conflicts.
+const ConflictExitCode = 3
+
+// ConflictError reports changes that could not be merged automatically.
+// main exits with ExitCode so that CI can gate on unresolved conflicts.
+type ConflictError struct {
+	Count int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d conflict(s) need manual resolution", e.Count)
+}
+
+func (e *ConflictError) ExitCode() int { return ConflictExitCode }
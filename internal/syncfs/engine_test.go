@@ -0,0 +1,142 @@
+package syncfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type item struct {
+	Name  string `yaml:"name"`
+	Title string `yaml:"title"`
+}
+
+// memAdapter backs a DocAdapter with an in-memory "remote".
+func memAdapter(remote map[string]item) *DocAdapter[item] {
+	return &DocAdapter[item]{
+		Noun:  "item",
+		KeyOf: func(i item) string { return i.Name },
+		List: func(ctx context.Context) ([]item, error) {
+			out := make([]item, 0, len(remote))
+			for _, v := range remote {
+				out = append(out, v)
+			}
+			return out, nil
+		},
+		Put:    func(ctx context.Context, i item) error { remote[i.Name] = i; return nil },
+		Remove: func(ctx context.Context, i item) error { delete(remote, i.Name); return nil },
+	}
+}
+
+func TestDiff_ReportsOpsAndFields(t *testing.T) {
+	remote := map[string]item{"a": {"a", "A"}, "b": {"b", "B"}, "r": {"r", "R"}}
+	a := memAdapter(remote)
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"a.item.yaml": "name: a\ntitle: A\n",
+		"b.item.yaml": "name: b\ntitle: changed\n",
+		"l.item.yaml": "name: l\ntitle: L\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rs, _ := a.LoadRemote(context.Background())
+	ls, err := a.LoadLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Change{}
+	for _, c := range Diff(rs, ls) {
+		got[c.Key] = c
+	}
+	want := map[string]Op{"a": OpSame, "b": OpChanged, "r": OpRemoteOnly, "l": OpLocalOnly}
+	for k, op := range want {
+		if got[k].Op != op {
+			t.Fatalf("key %s: expected %s, got %+v", k, op, got[k])
+		}
+	}
+	if f := got["b"].Fields; len(f) != 1 || f[0].Name != "title" || f[0].Remote != "B" || f[0].Local != "changed" {
+		t.Fatalf("expected title change on b; got %+v", f)
+	}
+}
+
+func TestPullThenPush_RoundTrips(t *testing.T) {
+	remote := map[string]item{"a": {"a", "A"}, "b": {"b", "B"}}
+	a := memAdapter(remote)
+	dir := filepath.Join(t.TempDir(), "items")
+	ctx := context.Background()
+	quiet := Options{Log: io.Discard}
+	if s, err := Pull(ctx, a, dir, quiet); err != nil || s.Written != 2 {
+		t.Fatalf("pull: %+v %v", s, err)
+	}
+	if s, err := Push(ctx, a, dir, quiet); err != nil || s.Applied != 0 || s.Unchanged != 2 {
+		t.Fatalf("expected clean push after pull; got %+v %v", s, err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b.item.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Push(ctx, a, dir, Options{Delete: true, DryRun: true, Log: io.Discard}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := remote["b"]; !ok {
+		t.Fatalf("dry-run must not delete")
+	}
+	if s, err := Push(ctx, a, dir, Options{Delete: true, Log: io.Discard}); err != nil || s.Deleted != 1 {
+		t.Fatalf("expected one delete; got %+v %v", s, err)
+	}
+	if _, ok := remote["b"]; ok {
+		t.Fatalf("expected b deleted remotely")
+	}
+	if _, err := Import(ctx, a, dir, quiet); err == nil {
+		t.Fatalf("import must refuse existing records")
+	}
+}
+
+func TestPush_ReportsConflictsAndContinues(t *testing.T) {
+	remote := map[string]item{"a": {"a", "A"}, "b": {"b", "B"}}
+	a := memAdapter(remote)
+	put := a.Put
+	a.Put = func(ctx context.Context, i item) error {
+		if i.Name == "a" {
+			return fmt.Errorf("%w: stale copy", ErrConflict)
+		}
+		return put(ctx, i)
+	}
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"a.item.yaml": "name: a\ntitle: A2\n",
+		"b.item.yaml": "name: b\ntitle: B2\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := Push(context.Background(), a, dir, Options{Log: io.Discard})
+	var ce *ConflictError
+	if !errors.As(err, &ce) || ce.Count != 1 || s.Conflicts != 1 || s.Applied != 1 {
+		t.Fatalf("expected one conflict and one apply; got %+v %v", s, err)
+	}
+	if remote["b"].Title != "B2" || remote["a"].Title != "A" {
+		t.Fatalf("expected b applied and a untouched; got %+v", remote)
+	}
+}
+
+func TestRecordContent_IgnoresBookkeeping(t *testing.T) {
+	r := Record{Kind: "item", Key: "a", Files: []File{{Name: "a.item.yaml", Data: []byte("title: A\nupdated: 1\n")}}, Content: []byte("title: A\n")}
+	l := Record{Kind: "item", Key: "a", Files: []File{{Name: "a.item.yaml", Data: []byte("title: A\nupdated: 2\n")}}, Content: []byte("title: A\n")}
+	if !r.Equal(l) {
+		t.Fatalf("records with the same content must be equal")
+	}
+	if r.sameFiles(l) {
+		t.Fatalf("pull must still see differing bookkeeping")
+	}
+	l.Content = []byte("title: B\n")
+	if f := RecordFields(r, l); len(f) != 1 || f[0].Name != "title" {
+		t.Fatalf("expected a title change; got %+v", f)
+	}
+}
@@ -0,0 +1,187 @@
+package syncfs
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// FoldedString renders as a YAML folded block scalar (>), suitable for prose paragraphs.
+type FoldedString string
+
+func (s FoldedString) MarshalYAML() (any, error) {
+	n := yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.FoldedStyle, Value: string(s)}
+	return &n, nil
+}
+
+// LiteralString renders as a YAML literal block scalar (|), preserving newlines exactly.
+type LiteralString string
+
+func (s LiteralString) MarshalYAML() (any, error) {
+	n := yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.LiteralStyle, Value: string(s)}
+	return &n, nil
+}
+
+// WrapAt inserts newlines so that lines are at most width runes, breaking at spaces.
+// Existing newlines are preserved; multiple spaces are treated as breakable.
+func WrapAt(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	var out []string
+	for _, line := range lines {
+		words := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })
+		if len(words) == 0 {
+			out = append(out, "")
+			continue
+		}
+		cur := words[0]
+		for _, w := range words[1:] {
+			if len([]rune(cur))+1+len([]rune(w)) <= width {
+				cur += " " + w
+			} else {
+				out = append(out, cur)
+				cur = w
+			}
+		}
+		out = append(out, cur)
+	}
+	return strings.Join(out, "\n")
+}
+
+// SanitizeForFile replaces any rune that is not alphanumeric, '_' or '-' with '-'.
+// It also lowercases the result and collapses consecutive '-' and trims leading/trailing '-'.
+func SanitizeForFile(s string) string {
+	s = strings.ToLower(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	prevDash := false
+	for _, r := range s {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-') {
+			r = '-'
+		}
+		if r == '-' {
+			if prevDash {
+				continue
+			}
+			prevDash = true
+			b.WriteRune(r)
+			continue
+		}
+		prevDash = false
+		b.WriteRune(r)
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// WriteFileAtomic writes via temp + rename to reduce partial writes.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// WriteYAML marshals v and writes it atomically.
+func WriteYAML(path string, v any) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, b)
+}
+
+// ReadYAML reads and unmarshals a YAML file.
+func ReadYAML(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// HashMaterial returns the hex sha256 of the JSON encoding of v.
+func HashMaterial(v any) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// ShortHash returns the first 8 hex characters of the sha256 of s ("" for empty input).
+func ShortHash(s string) string {
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// QuoteShort trims, truncates to 80 characters and quotes s for one-line diff output.
+func QuoteShort(s string) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:77]) + "..."
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// OptString returns a pointer to s, or nil when s is blank; used for omitempty YAML fields.
+func OptString(s string, valid bool) *string {
+	if !valid || strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return &s
+}
+
+// OptLiteral is OptString for literal block scalars.
+func OptLiteral(s string, valid bool) *LiteralString {
+	if !valid || strings.TrimSpace(s) == "" {
+		return nil
+	}
+	v := LiteralString(s)
+	return &v
+}
+
+// NullString is the inverse of OptString: nil or blank becomes NULL.
+func NullString(p *string) sql.NullString {
+	if p == nil || strings.TrimSpace(*p) == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *p, Valid: true}
+}
+
+// NullLiteral is NullString for literal block scalars.
+func NullLiteral(p *LiteralString) sql.NullString {
+	if p == nil || strings.TrimSpace(string(*p)) == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(*p), Valid: true}
+}
+
+// Deref returns the pointed-to string or "".
+func Deref(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// DerefLiteral returns the pointed-to literal as a string or "".
+func DerefLiteral(p *LiteralString) string {
+	if p == nil {
+		return ""
+	}
+	return string(*p)
+}
@@ -0,0 +1,21 @@
+package syncfs
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestQuoteShort_TruncatesByRune(t *testing.T) {
+	s := strings.Repeat("é", 100)
+	got := QuoteShort(s)
+	if !utf8.ValidString(got) || strings.Contains(got, `\x`) {
+		t.Fatalf("split a multi-byte rune: %s", got)
+	}
+	if want := `"` + strings.Repeat("é", 77) + `..."`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if got := QuoteShort("  short  "); got != `"short"` {
+		t.Fatalf("short: %s", got)
+	}
+}
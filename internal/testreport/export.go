@@ -0,0 +1,373 @@
+package testreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// noPackage names the suite of testcases recorded without a package.
+const noPackage = "(no package)"
+
+// Suite is the testcases of one package, as exported to a report.
+type Suite struct {
+	Name     string
+	Cases    []ExportCase
+	Tests    int
+	Failures int
+	Errors   int
+	Skipped  int
+	Time     float64
+}
+
+// ExportCase is one testcase row prepared for export.
+type ExportCase struct {
+	Name      string
+	Classname string
+	Status    string
+	Message   string
+	File      string
+	Line      int
+	Time      float64
+	Tags      []Tag
+}
+
+// Tag is one key/value of a testcase's tags, rendered as text.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Location is file:line, the file alone, or empty.
+func (c ExportCase) Location() string {
+	if c.File == "" {
+		return ""
+	}
+	if c.Line > 0 {
+		return c.File + ":" + strconv.Itoa(c.Line)
+	}
+	return c.File
+}
+
+// Failed reports whether the case failed or errored.
+func (c ExportCase) Failed() bool { return failing(c.Status) }
+
+// Suites groups testcases by package; suites and their cases are sorted by
+// name so exports of the same experiment are stable.
+func Suites(rows []pgdao.Testcase) []Suite {
+	idx := map[string]int{}
+	var out []Suite
+	for _, t := range rows {
+		pkg := t.Package.String
+		if pkg == "" {
+			pkg = noPackage
+		}
+		i, ok := idx[pkg]
+		if !ok {
+			i = len(out)
+			idx[pkg] = i
+			out = append(out, Suite{Name: pkg})
+		}
+		c := ExportCase{Name: t.Name.String, Classname: t.Classname.String, Status: strings.ToUpper(t.Status), Message: t.ErrorMessage.String, File: t.File.String, Line: int(t.Line.Int64), Time: t.ExecutionTime.Float64, Tags: tagList(t.Tags)}
+		if c.Name == "" {
+			c.Name = t.Title
+		}
+		s := &out[i]
+		s.Cases = append(s.Cases, c)
+		s.Tests++
+		s.Time += c.Time
+		switch c.Status {
+		case StatusOK:
+		case StatusError:
+			s.Errors++
+		case StatusSkip, "TODO":
+			s.Skipped++
+		default:
+			s.Failures++
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	for _, s := range out {
+		sort.SliceStable(s.Cases, func(i, j int) bool {
+			if s.Cases[i].Classname != s.Cases[j].Classname {
+				return s.Cases[i].Classname < s.Cases[j].Classname
+			}
+			return s.Cases[i].Name < s.Cases[j].Name
+		})
+	}
+	return out
+}
+
+func tagList(tags map[string]any) []Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make([]Tag, 0, len(tags))
+	for k, v := range tags {
+		var s string
+		switch x := v.(type) {
+		case string:
+			s = x
+		default:
+			b, _ := json.Marshal(x)
+			s = string(b)
+		}
+		out = append(out, Tag{Key: k, Value: s})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ExportTotals sums the counts of all suites.
+func ExportTotals(suites []Suite) Suite {
+	var t Suite
+	for _, s := range suites {
+		t.Tests += s.Tests
+		t.Failures += s.Failures
+		t.Errors += s.Errors
+		t.Skipped += s.Skipped
+		t.Time += s.Time
+	}
+	return t
+}
+
+type xmlSuites struct {
+	XMLName  xml.Name   `xml:"testsuites"`
+	Name     string     `xml:"name,attr,omitempty"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Errors   int        `xml:"errors,attr"`
+	Skipped  int        `xml:"skipped,attr"`
+	Time     string     `xml:"time,attr"`
+	Suites   []xmlSuite `xml:"testsuite"`
+}
+
+type xmlSuite struct {
+	Name       string         `xml:"name,attr"`
+	Tests      int            `xml:"tests,attr"`
+	Failures   int            `xml:"failures,attr"`
+	Errors     int            `xml:"errors,attr"`
+	Skipped    int            `xml:"skipped,attr"`
+	Time       string         `xml:"time,attr"`
+	Properties *xmlProperties `xml:"properties"`
+	Cases      []xmlCase      `xml:"testcase"`
+}
+
+type xmlProperties struct {
+	Items []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlCase struct {
+	Name       string         `xml:"name,attr"`
+	Classname  string         `xml:"classname,attr"`
+	Time       string         `xml:"time,attr"`
+	File       string         `xml:"file,attr,omitempty"`
+	Line       string         `xml:"line,attr,omitempty"`
+	Properties *xmlProperties `xml:"properties"`
+	Failure    *junitDetail   `xml:"failure"`
+	Error      *junitDetail   `xml:"error"`
+	Skipped    *junitDetail   `xml:"skipped"`
+}
+
+func seconds(f float64) string { return strconv.FormatFloat(f, 'f', 3, 64) }
+
+// WriteJUnit writes suites as a JUnit XML <testsuites> document. Tags become
+// testcase properties and the experiment a suite property.
+func WriteJUnit(w io.Writer, experimentID string, suites []Suite) error {
+	tot := ExportTotals(suites)
+	doc := xmlSuites{Name: experimentID, Tests: tot.Tests, Failures: tot.Failures, Errors: tot.Errors, Skipped: tot.Skipped, Time: seconds(tot.Time)}
+	for _, s := range suites {
+		xs := xmlSuite{Name: s.Name, Tests: s.Tests, Failures: s.Failures, Errors: s.Errors, Skipped: s.Skipped, Time: seconds(s.Time)}
+		if experimentID != "" {
+			xs.Properties = &xmlProperties{Items: []xmlProperty{{Name: "experiment_id", Value: experimentID}}}
+		}
+		for _, c := range s.Cases {
+			xc := xmlCase{Name: c.Name, Classname: c.Classname, Time: seconds(c.Time), File: c.File}
+			if xc.Classname == "" {
+				xc.Classname = s.Name
+			}
+			if c.Line > 0 {
+				xc.Line = strconv.Itoa(c.Line)
+			}
+			if len(c.Tags) > 0 {
+				xc.Properties = &xmlProperties{}
+				for _, t := range c.Tags {
+					xc.Properties.Items = append(xc.Properties.Items, xmlProperty{Name: t.Key, Value: t.Value})
+				}
+			}
+			detail := &junitDetail{Message: firstLine(c.Message), Text: c.Message}
+			if loc := c.Location(); loc != "" && c.Message != "" {
+				detail.Text = loc + ": " + c.Message
+			}
+			switch c.Status {
+			case StatusOK:
+			case StatusError:
+				detail.Type = "error"
+				xc.Error = detail
+			case StatusSkip, "TODO":
+				if c.Status == "TODO" && detail.Message == "" {
+					detail.Message = "TODO"
+				}
+				detail.Text = ""
+				xc.Skipped = detail
+			default:
+				detail.Type = "failure"
+				xc.Failure = detail
+			}
+			xs.Cases = append(xs.Cases, xc)
+		}
+		doc.Suites = append(doc.Suites, xs)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteMarkdown writes a summary suited to PR comments: totals, the failures
+// with their messages, then one table per suite.
+func WriteMarkdown(w io.Writer, experimentID string, suites []Suite) error {
+	bw := bufio.NewWriter(w)
+	p := func(format string, args ...any) { fmt.Fprintf(bw, format, args...) }
+	tot := ExportTotals(suites)
+	icon := "✅"
+	if tot.Failures+tot.Errors > 0 {
+		icon = "❌"
+	}
+	p("# %s Test report\n\n", icon)
+	if experimentID != "" {
+		p("- **experiment**: `%s`\n", experimentID)
+	}
+	p("- **tests**: %d · **failed**: %d · **errored**: %d · **skipped**: %d · **time**: %ss\n\n", tot.Tests, tot.Failures, tot.Errors, tot.Skipped, seconds(tot.Time))
+	first := true
+	for _, s := range suites {
+		for _, c := range s.Cases {
+			if !c.Failed() {
+				continue
+			}
+			if first {
+				p("## Failures\n\n")
+				first = false
+			}
+			p("<details><summary><b>%s</b> %s › %s", c.Status, mdCell(s.Name), mdCell(c.Name))
+			if loc := c.Location(); loc != "" {
+				p(" <code>%s</code>", template.HTMLEscapeString(loc))
+			}
+			p("</summary>\n\n")
+			if c.Message != "" {
+				f := fence(c.Message)
+				p("%s\n%s\n%s\n", f, c.Message, f)
+			}
+			p("\n</details>\n\n")
+		}
+	}
+	for _, s := range suites {
+		p("## %s\n\n", mdCell(s.Name))
+		p("%d tests, %d failed, %d errored, %d skipped in %ss\n\n", s.Tests, s.Failures, s.Errors, s.Skipped, seconds(s.Time))
+		p("| Status | Test | Time (s) | Location | Tags |\n| --- | --- | ---: | --- | --- |\n")
+		for _, c := range s.Cases {
+			name := c.Name
+			if c.Classname != "" && c.Classname != s.Name {
+				name = c.Classname + " › " + name
+			}
+			loc := ""
+			if l := c.Location(); l != "" {
+				loc = "`" + l + "`"
+			}
+			tags := make([]string, 0, len(c.Tags))
+			for _, t := range c.Tags {
+				tags = append(tags, t.Key+"="+t.Value)
+			}
+			p("| %s | %s | %s | %s | %s |\n", c.Status, mdCell(name), seconds(c.Time), loc, mdCell(strings.Join(tags, ", ")))
+		}
+		p("\n")
+	}
+	return bw.Flush()
+}
+
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// fence returns a code fence longer than any backtick run in s.
+func fence(s string) string {
+	n, run := 3, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			if run >= n {
+				n = run + 1
+			}
+			continue
+		}
+		run = 0
+	}
+	return strings.Repeat("`", n)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{"seconds": seconds}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Test report{{with .Experiment}} {{.}}{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #ddd; padding: .3rem .5rem; text-align: left; vertical-align: top; }
+td.num { text-align: right; }
+.OK { color: #1a7f37; } .KO, .ERROR { color: #cf222e; font-weight: bold; } .SKIP, .TODO { color: #9a6700; }
+pre { background: #f6f8fa; padding: .5rem; overflow-x: auto; margin: .3rem 0 0; }
+code, .tag { font-size: .85em; }
+.tag { background: #eef; border-radius: 3px; padding: 0 .3rem; margin-right: .2rem; }
+</style>
+</head>
+<body>
+<h1>Test report</h1>
+<p>{{with .Experiment}}Experiment <code>{{.}}</code> · {{end}}{{.Totals.Tests}} tests, {{.Totals.Failures}} failed, {{.Totals.Errors}} errored, {{.Totals.Skipped}} skipped in {{seconds .Totals.Time}}s</p>
+{{range .Suites}}<h2>{{.Name}}</h2>
+<p>{{.Tests}} tests, {{.Failures}} failed, {{.Errors}} errored, {{.Skipped}} skipped in {{seconds .Time}}s</p>
+<table>
+<tr><th>Status</th><th>Test</th><th>Time (s)</th><th>Tags</th></tr>
+{{range .Cases}}<tr>
+<td class="{{.Status}}">{{.Status}}</td>
+<td>{{with .Classname}}{{.}} › {{end}}{{.Name}}{{with .Location}}<br><code>{{.}}</code>{{end}}{{if and .Message (ne .Status "OK")}}<pre>{{.Message}}</pre>{{end}}</td>
+<td class="num">{{seconds .Time}}</td>
+<td>{{range .Tags}}<span class="tag">{{.Key}}={{.Value}}</span>{{end}}</td>
+</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes suites as a standalone HTML page.
+func WriteHTML(w io.Writer, experimentID string, suites []Suite) error {
+	return htmlReport.Execute(w, map[string]any{"Experiment": experimentID, "Suites": suites, "Totals": ExportTotals(suites)})
+}
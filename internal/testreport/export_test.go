@@ -0,0 +1,84 @@
+package testreport
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func exportRows() []pgdao.Testcase {
+	ns := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	return []pgdao.Testcase{
+		{Title: "TestPut", Name: ns("TestPut"), Package: ns("app/store"), Status: "KO", ErrorMessage: ns("want 2, got 3\nmore"), File: ns("store_test.go"), Line: sql.NullInt64{Int64: 42, Valid: true}, ExecutionTime: sql.NullFloat64{Float64: 0.5, Valid: true}, Tags: map[string]any{"source": "gotest-json", "retries": 2}},
+		{Title: "TestGet", Name: ns("TestGet"), Package: ns("app/store"), Status: "OK", ExecutionTime: sql.NullFloat64{Float64: 0.25, Valid: true}},
+		{Title: "go vet", Status: "SKIP", ErrorMessage: ns("not on CI")},
+		{Title: "TestBoom", Name: ns("TestBoom"), Package: ns("app/api"), Status: "ERROR", ErrorMessage: ns("panic")},
+	}
+}
+
+func TestSuites(t *testing.T) {
+	suites := Suites(exportRows())
+	if len(suites) != 3 || suites[0].Name != noPackage || suites[1].Name != "app/api" || suites[2].Name != "app/store" {
+		t.Fatalf("suites = %+v", suites)
+	}
+	s := suites[2]
+	if s.Tests != 2 || s.Failures != 1 || s.Time != 0.75 || s.Cases[0].Name != "TestGet" {
+		t.Fatalf("store suite = %+v", s)
+	}
+	if tags := s.Cases[1].Tags; len(tags) != 2 || tags[0] != (Tag{"retries", "2"}) || tags[1] != (Tag{"source", "gotest-json"}) {
+		t.Fatalf("tags = %+v", tags)
+	}
+	tot := ExportTotals(suites)
+	if tot.Tests != 4 || tot.Failures != 1 || tot.Errors != 1 || tot.Skipped != 1 {
+		t.Fatalf("totals = %+v", tot)
+	}
+}
+
+func TestWriteJUnitRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "exp-1", Suites(exportRows())); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`<testsuites name="exp-1" tests="4" failures="1" errors="1" skipped="1"`, `<property name="source" value="gotest-json">`, `file="store_test.go" line="42"`, `<failure message="want 2, got 3" type="failure">store_test.go:42: want 2, got 3`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+	cases, err := ParseJUnit(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := byName(cases)
+	if c := got["TestPut"]; c.Result != Failed || c.Package != "app/store" || c.File != "store_test.go" || c.Line != 42 || c.Time != 0.5 {
+		t.Fatalf("TestPut = %+v", c)
+	}
+	if got["TestBoom"].Result != Errored || got["go vet"].Result != Skipped || got["TestGet"].Result != Passed {
+		t.Fatalf("cases = %+v", cases)
+	}
+}
+
+func TestWriteMarkdownAndHTML(t *testing.T) {
+	suites := Suites(exportRows())
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, "exp-1", suites); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# ❌ Test report", "## Failures", "<b>KO</b> app/store › TestPut <code>store_test.go:42</code>", "| OK | TestGet | 0.250 |", "retries=2, source=gotest-json"} {
+		if !strings.Contains(md.String(), want) {
+			t.Fatalf("markdown missing %q in\n%s", want, md.String())
+		}
+	}
+	var h bytes.Buffer
+	if err := WriteHTML(&h, "exp-1", suites); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h2>app/store</h2>", `<td class="KO">KO</td>`, "<pre>want 2, got 3\nmore</pre>", "<code>store_test.go:42</code>"} {
+		if !strings.Contains(h.String(), want) {
+			t.Fatalf("html missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// goTestEvent is one line of `go test -json` (cmd/test2json) output.
+type goTestEvent struct {
+	Action     string
+	Package    string
+	Test       string
+	Elapsed    float64
+	Output     string
+	ImportPath string
+	// FailedBuild names the package whose build failure failed this one.
+	FailedBuild string
+}
+
+// goOutputLocRe matches the location prefix testing.T adds to log lines.
+var goOutputLocRe = regexp.MustCompile(`^\s+([\w./-]+\.go):(\d+):`)
+
+// maxMessage bounds the output kept for one failing case.
+const maxMessage = 16 * 1024
+
+// GoTestStream incrementally converts `go test -json` events into cases, so a
+// runner can report results while the test binary is still running.
+type GoTestStream struct {
+	cases    []Case
+	output   map[string]*strings.Builder
+	failed   map[string]bool // packages with at least one failed test
+	building map[string]*strings.Builder
+}
+
+// NewGoTestStream returns an empty stream.
+func NewGoTestStream() *GoTestStream {
+	return &GoTestStream{output: map[string]*strings.Builder{}, failed: map[string]bool{}, building: map[string]*strings.Builder{}}
+}
+
+// Feed consumes one output line; lines that are not JSON events are ignored.
+// It returns the case completed by the line, if any.
+func (g *GoTestStream) Feed(line []byte) (*Case, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil, nil
+	}
+	var ev goTestEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return nil, fmt.Errorf("gotest-json: %w", err)
+	}
+	key := ev.Package + "\x00" + ev.Test
+	switch ev.Action {
+	case "build-output":
+		appendBounded(g.builder(g.building, ev.ImportPath), ev.Output)
+	case "build-fail":
+		msg := ""
+		if b := g.building[ev.ImportPath]; b != nil {
+			msg = strings.TrimSpace(b.String())
+		}
+		// Test variants are reported as "pkg [pkg.test]"
+		pkg, _, _ := strings.Cut(ev.ImportPath, " [")
+		return g.add(Case{Name: "(build)", Package: pkg, Result: Errored, Message: msg}), nil
+	case "output":
+		appendBounded(g.builder(g.output, key), ev.Output)
+	case "pass", "fail", "skip":
+		if ev.Test == "" {
+			// A package failing without a failed test did not build or crashed;
+			// build failures were already reported by their build-fail event
+			if ev.Action == "fail" && !g.failed[ev.Package] && ev.FailedBuild == "" {
+				msg := ""
+				if b := g.output[key]; b != nil {
+					msg = strings.TrimSpace(b.String())
+				}
+				return g.add(Case{Name: "(package)", Package: ev.Package, Time: ev.Elapsed, Result: Errored, Message: msg}), nil
+			}
+			return nil, nil
+		}
+		c := Case{Name: ev.Test, Package: ev.Package, Time: ev.Elapsed, Result: Passed}
+		out := ""
+		if b := g.output[key]; b != nil {
+			out = b.String()
+			delete(g.output, key)
+		}
+		switch ev.Action {
+		case "fail":
+			c.Result = Failed
+			g.failed[ev.Package] = true
+			c.Message = goTestMessage(out)
+		case "skip":
+			c.Result = Skipped
+			c.Message = goTestMessage(out)
+		}
+		if c.Message != "" {
+			for _, l := range strings.Split(out, "\n") {
+				if m := goOutputLocRe.FindStringSubmatch(l); m != nil {
+					c.File, c.Line = fileLine(m[1] + ":" + m[2])
+					break
+				}
+			}
+		}
+		return g.add(c), nil
+	}
+	return nil, nil
+}
+
+// Cases returns the cases completed so far.
+func (g *GoTestStream) Cases() []Case { return g.cases }
+
+func (g *GoTestStream) add(c Case) *Case {
+	g.cases = append(g.cases, c)
+	return &c
+}
+
+func (g *GoTestStream) builder(m map[string]*strings.Builder, key string) *strings.Builder {
+	b := m[key]
+	if b == nil {
+		b = &strings.Builder{}
+		m[key] = b
+	}
+	return b
+}
+
+func appendBounded(b *strings.Builder, s string) {
+	if b.Len() < maxMessage {
+		b.WriteString(s)
+	}
+}
+
+// goTestMessage drops the framing lines (=== RUN, --- FAIL...) of test output.
+func goTestMessage(out string) string {
+	var keep []string
+	for _, l := range strings.Split(out, "\n") {
+		t := strings.TrimSpace(l)
+		if t == "" || strings.HasPrefix(t, "=== ") || strings.HasPrefix(t, "--- ") {
+			continue
+		}
+		keep = append(keep, strings.TrimRight(l, " \t"))
+	}
+	return strings.TrimSpace(unindent(strings.Join(keep, "\n")))
+}
+
+// ParseGoTestJSON reads the complete output of `go test -json`.
+func ParseGoTestJSON(r io.Reader) ([]Case, error) {
+	g := NewGoTestStream()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if _, err := g.Feed(sc.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("gotest-json: %w", err)
+	}
+	return g.Cases(), nil
+}
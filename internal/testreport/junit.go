@@ -0,0 +1,110 @@
+package testreport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type junitSuite struct {
+	Name    string       `xml:"name,attr"`
+	Package string       `xml:"package,attr"`
+	File    string       `xml:"file,attr"`
+	Suites  []junitSuite `xml:"testsuite"`
+	Cases   []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      string        `xml:"line,attr"`
+	Time      string        `xml:"time,attr"`
+	Failures  []junitDetail `xml:"failure"`
+	Errors    []junitDetail `xml:"error"`
+	Skipped   *junitDetail  `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+}
+
+type junitDetail struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (d junitDetail) String() string {
+	msg := strings.TrimSpace(d.Message)
+	text := strings.TrimSpace(d.Text)
+	switch {
+	case text == "" || text == msg:
+		return msg
+	case msg == "":
+		return text
+	default:
+		return msg + "\n" + text
+	}
+}
+
+// ParseJUnit reads a JUnit XML report; the root may be <testsuites> or a
+// single <testsuite>, and suites may nest. A case takes its package from the
+// innermost suite declaring a package, else from the suite name.
+func ParseJUnit(r io.Reader) ([]Case, error) {
+	var root junitSuite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("junit: %w", err)
+	}
+	var out []Case
+	var walk func(s junitSuite, pkg, file string)
+	walk = func(s junitSuite, pkg, file string) {
+		if s.Package != "" {
+			pkg = s.Package
+		} else if s.Name != "" {
+			pkg = s.Name
+		}
+		if s.File != "" {
+			file = s.File
+		}
+		for _, jc := range s.Cases {
+			c := Case{Name: jc.Name, Package: pkg, Classname: jc.Classname, File: jc.File, Time: parseSeconds(jc.Time), Result: Passed}
+			if c.File == "" {
+				c.File = file
+			}
+			if n, err := strconv.Atoi(strings.TrimSpace(jc.Line)); err == nil {
+				c.Line = n
+			}
+			switch {
+			case len(jc.Errors) > 0:
+				c.Result, c.Message = Errored, jc.Errors[0].String()
+			case len(jc.Failures) > 0:
+				c.Result, c.Message = Failed, jc.Failures[0].String()
+			case jc.Skipped != nil:
+				c.Result, c.Message = Skipped, jc.Skipped.String()
+			}
+			if c.Line == 0 && c.Message != "" {
+				if f, n := fileLine(c.Message); n > 0 {
+					switch {
+					case c.File == "":
+						c.File, c.Line = f, n
+					case strings.HasSuffix(c.File, f), strings.HasSuffix(f, c.File):
+						c.Line = n
+					}
+				}
+			}
+			out = append(out, c)
+		}
+		for _, child := range s.Suites {
+			walk(child, pkg, file)
+		}
+	}
+	// The root's own name is the run name when it is <testsuites>
+	if len(root.Cases) == 0 {
+		for _, s := range root.Suites {
+			walk(s, root.Package, root.File)
+		}
+	} else {
+		walk(root, "", "")
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package testreport
+
+import (
+	"database/sql"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// Rows maps parsed cases to testcases rows of a role and optional experiment.
+// Every row gets its own copy of tags.
+func Rows(cases []Case, role, experimentID string, tags map[string]any) []*pgdao.Testcase {
+	out := make([]*pgdao.Testcase, 0, len(cases))
+	ns := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	for _, c := range cases {
+		tc := &pgdao.Testcase{Title: c.Title(), RoleName: role, Status: c.Result.Status()}
+		tc.Name = ns(c.Name)
+		tc.Package = ns(c.Package)
+		tc.Classname = ns(c.Classname)
+		tc.ExperimentID = ns(experimentID)
+		tc.ErrorMessage = ns(c.Message)
+		tc.File = ns(c.File)
+		if c.Line > 0 {
+			tc.Line = sql.NullInt64{Int64: int64(c.Line), Valid: true}
+		}
+		if c.Time > 0 {
+			tc.ExecutionTime = sql.NullFloat64{Float64: c.Time, Valid: true}
+		}
+		if len(tags) > 0 {
+			tc.Tags = make(map[string]any, len(tags))
+			for k, v := range tags {
+				tc.Tags[k] = v
+			}
+		}
+		out = append(out, tc)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package testreport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var tapLineRe = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\w+)\b\s*(.*))?$`)
+
+// ParseTAP reads a TAP (version 12/13/14) stream. Only top-level test points
+// are read; indented subtests are summarized by their parent point. A TODO
+// directive counts as skipped, "Bail out!" as an errored case, and the YAML
+// diagnostics block of a failure provides its message and location.
+func ParseTAP(r io.Reader) ([]Case, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	var out []Case
+	var diag []string
+	inDiag := false
+	flushDiag := func() {
+		if len(out) == 0 || len(diag) == 0 {
+			diag = nil
+			return
+		}
+		applyTAPDiagnostics(&out[len(out)-1], strings.Join(diag, "\n"))
+		diag = nil
+	}
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if inDiag {
+			if strings.TrimSpace(line) == "..." {
+				inDiag = false
+				flushDiag()
+				continue
+			}
+			diag = append(diag, line)
+			continue
+		}
+		if strings.TrimSpace(line) == "---" && len(out) > 0 && strings.HasPrefix(line, " ") {
+			inDiag = true
+			continue
+		}
+		if strings.HasPrefix(line, "Bail out!") {
+			out = append(out, Case{Name: "Bail out!", Result: Errored, Message: strings.TrimSpace(strings.TrimPrefix(line, "Bail out!"))})
+			break
+		}
+		m := tapLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		c := Case{Name: strings.TrimSpace(m[3]), Result: Passed}
+		if c.Name == "" {
+			c.Name = "test " + m[2]
+		}
+		if m[1] == "not ok" {
+			c.Result = Failed
+		}
+		switch strings.ToUpper(m[4]) {
+		case "SKIP":
+			c.Result, c.Message = Skipped, strings.TrimSpace(m[5])
+		case "TODO":
+			c.Result, c.Message = Skipped, strings.TrimSpace("TODO "+m[5])
+		}
+		out = append(out, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("tap: %w", err)
+	}
+	if inDiag {
+		flushDiag()
+	}
+	return out, nil
+}
+
+type tapDiagnostics struct {
+	Message  string  `yaml:"message"`
+	Severity string  `yaml:"severity"`
+	File     string  `yaml:"file"`
+	Line     int     `yaml:"line"`
+	Duration float64 `yaml:"duration_ms"`
+	At       any     `yaml:"at"`
+}
+
+func applyTAPDiagnostics(c *Case, block string) {
+	var d tapDiagnostics
+	if err := yaml.Unmarshal([]byte(unindent(block)), &d); err != nil {
+		if c.Result != Passed {
+			c.Message = strings.TrimSpace(block)
+		}
+		return
+	}
+	if c.Result != Passed && c.Message == "" {
+		c.Message = strings.TrimSpace(d.Message)
+		if c.Message == "" {
+			c.Message = strings.TrimSpace(unindent(block))
+		}
+	}
+	if c.Result == Failed && strings.EqualFold(d.Severity, "error") {
+		c.Result = Errored
+	}
+	if d.Duration > 0 {
+		c.Time = d.Duration / 1000
+	}
+	c.File, c.Line = d.File, d.Line
+	if at, ok := d.At.(map[string]any); ok {
+		if f, ok := at["file"].(string); ok {
+			c.File = f
+		}
+		if n, ok := at["line"].(int); ok {
+			c.Line = n
+		}
+	} else if s, ok := d.At.(string); ok {
+		c.File, c.Line = fileLine(s)
+	}
+}
+
+// unindent removes the common leading indentation of a diagnostics block.
+func unindent(block string) string {
+	lines := strings.Split(block, "\n")
+	min := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " "))
+		if min < 0 || n < min {
+			min = n
+		}
+	}
+	if min <= 0 {
+		return block
+	}
+	for i, l := range lines {
+		if len(l) >= min {
+			lines[i] = l[min:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
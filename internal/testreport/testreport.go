@@ -0,0 +1,204 @@
+// Package testreport parses test runner reports (JUnit XML, TAP, go test -json)
+// into flat test cases. Parsers are registered by format name so commands can
+// accept new runners without knowing their formats.
+package testreport
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Result is the normalized outcome of a test case.
+type Result string
+
+const (
+	Passed  Result = "passed"
+	Failed  Result = "failed"
+	Errored Result = "errored"
+	Skipped Result = "skipped"
+)
+
+// Testcase statuses stored for each result.
+const (
+	StatusOK    = "OK"
+	StatusKO    = "KO"
+	StatusError = "ERROR"
+	StatusSkip  = "SKIP"
+)
+
+// Status maps a result to the testcases.status vocabulary.
+func (r Result) Status() string {
+	switch r {
+	case Passed:
+		return StatusOK
+	case Errored:
+		return StatusError
+	case Skipped:
+		return StatusSkip
+	default:
+		return StatusKO
+	}
+}
+
+// Case is one test case read from a report.
+type Case struct {
+	Name      string
+	Package   string
+	Classname string
+	File      string
+	Line      int
+	Time      float64 // seconds; 0 when unknown
+	Result    Result
+	Message   string // failure, error or skip detail
+}
+
+// Title is a display title: the name, else the classname.
+func (c Case) Title() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	if c.Classname != "" {
+		return c.Classname
+	}
+	return "(unnamed)"
+}
+
+// Parser reads every case of a report.
+type Parser func(r io.Reader) ([]Case, error)
+
+var (
+	mu      sync.RWMutex
+	parsers = map[string]Parser{}
+)
+
+// Register makes a parser available under a format name.
+func Register(format string, p Parser) {
+	mu.Lock()
+	defer mu.Unlock()
+	parsers[strings.ToLower(format)] = p
+}
+
+// Lookup returns the parser of a format.
+func Lookup(format string) (Parser, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := parsers[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(formatsLocked(), ", "))
+	}
+	return p, nil
+}
+
+// Formats lists the registered format names.
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return formatsLocked()
+}
+
+func formatsLocked() []string {
+	out := make([]string, 0, len(parsers))
+	for f := range parsers {
+		out = append(out, f)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Stream parses a report line by line while the runner is still writing it.
+type Stream interface {
+	// Feed consumes one line and returns the case it completed, if any.
+	Feed(line []byte) (*Case, error)
+	// Cases returns the cases completed so far.
+	Cases() []Case
+}
+
+var streams = map[string]func() Stream{}
+
+// RegisterStream makes a line-oriented parser available under a format name.
+func RegisterStream(format string, f func() Stream) {
+	mu.Lock()
+	defer mu.Unlock()
+	streams[strings.ToLower(format)] = f
+}
+
+// NewStream returns a streaming parser for the format, if one is registered.
+func NewStream(format string) (Stream, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := streams[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+func init() {
+	Register("junit", ParseJUnit)
+	Register("tap", ParseTAP)
+	Register("gotest-json", ParseGoTestJSON)
+	RegisterStream("gotest-json", func() Stream { return NewGoTestStream() })
+}
+
+// Summary counts cases per result.
+type Summary struct {
+	Total   int     `json:"total"`
+	Passed  int     `json:"passed"`
+	Failed  int     `json:"failed"`
+	Errored int     `json:"errored"`
+	Skipped int     `json:"skipped"`
+	Time    float64 `json:"execution_time"`
+}
+
+// OK reports whether no case failed or errored.
+func (s Summary) OK() bool { return s.Failed == 0 && s.Errored == 0 }
+
+func (s Summary) String() string {
+	return fmt.Sprintf("total=%d passed=%d failed=%d errored=%d skipped=%d time=%.3fs", s.Total, s.Passed, s.Failed, s.Errored, s.Skipped, s.Time)
+}
+
+// Summarize counts cases per result and sums their time.
+func Summarize(cases []Case) Summary {
+	var s Summary
+	for _, c := range cases {
+		s.Total++
+		s.Time += c.Time
+		switch c.Result {
+		case Passed:
+			s.Passed++
+		case Errored:
+			s.Errored++
+		case Skipped:
+			s.Skipped++
+		default:
+			s.Failed++
+		}
+	}
+	return s
+}
+
+var fileLineRe = regexp.MustCompile(`([\w./\\-]+\.\w+):(\d+)`)
+
+// fileLine extracts the first file:line reference of a message.
+func fileLine(msg string) (string, int) {
+	m := fileLineRe.FindStringSubmatch(msg)
+	if m == nil {
+		return "", 0
+	}
+	n, _ := strconv.Atoi(m[2])
+	return m[1], n
+}
+
+// parseSeconds reads a duration in seconds, tolerating thousands separators.
+func parseSeconds(s string) float64 {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil || f < 0 {
+		return 0
+	}
+	return f
+}
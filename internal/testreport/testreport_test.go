@@ -0,0 +1,106 @@
+package testreport
+
+import (
+	"strings"
+	"testing"
+)
+
+func byName(cases []Case) map[string]Case {
+	m := map[string]Case{}
+	for _, c := range cases {
+		m[c.Name] = c
+	}
+	return m
+}
+
+func TestParseJUnit(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<testsuites name="ci">
+  <testsuite name="github.com/acme/app/store" tests="4">
+    <testcase name="TestGet" classname="store" time="0.012"/>
+    <testcase name="TestPut" classname="store" time="1,002.5">
+      <failure message="want 2, got 3">store_test.go:42: want 2, got 3</failure>
+    </testcase>
+    <testcase name="TestBoom" classname="store" file="store_test.go" line="7"><error message="panic"/></testcase>
+    <testcase name="TestLater" classname="store"><skipped message="flaky on CI"/></testcase>
+  </testsuite>
+</testsuites>`
+	cases, err := ParseJUnit(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := byName(cases)
+	if len(cases) != 4 || m["TestGet"].Result != Passed || m["TestGet"].Package != "github.com/acme/app/store" || m["TestGet"].Time != 0.012 {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+	put := m["TestPut"]
+	if put.Result != Failed || put.File != "store_test.go" || put.Line != 42 || put.Time != 1002.5 || !strings.HasPrefix(put.Message, "want 2, got 3") {
+		t.Fatalf("failure not mapped: %+v", put)
+	}
+	if m["TestBoom"].Result != Errored || m["TestBoom"].Line != 7 || m["TestLater"].Result != Skipped || m["TestLater"].Message != "flaky on CI" {
+		t.Fatalf("error/skip not mapped: %+v", cases)
+	}
+}
+
+func TestParseTAP(t *testing.T) {
+	const doc = `TAP version 13
+1..4
+ok 1 - parses config
+not ok 2 - writes file
+  ---
+  message: permission denied
+  at:
+    file: test/write.js
+    line: 12
+  ...
+ok 3 - network # SKIP offline
+not ok 4 - new api # TODO not implemented
+`
+	cases, err := ParseTAP(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := byName(cases)
+	if len(cases) != 4 || m["parses config"].Result != Passed {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+	w := m["writes file"]
+	if w.Result != Failed || w.Message != "permission denied" || w.File != "test/write.js" || w.Line != 12 {
+		t.Fatalf("diagnostics not applied: %+v", w)
+	}
+	if m["network"].Result != Skipped || m["new api"].Result != Skipped {
+		t.Fatalf("directives not mapped: %+v", cases)
+	}
+}
+
+func TestParseGoTestJSON(t *testing.T) {
+	const doc = `{"Action":"run","Package":"example.com/p","Test":"TestA"}
+{"Action":"output","Package":"example.com/p","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"example.com/p","Test":"TestA","Output":"    a_test.go:9: boom\n"}
+{"Action":"output","Package":"example.com/p","Test":"TestA","Output":"--- FAIL: TestA (0.01s)\n"}
+{"Action":"fail","Package":"example.com/p","Test":"TestA","Elapsed":0.01}
+{"Action":"pass","Package":"example.com/p","Test":"TestB","Elapsed":0.2}
+{"Action":"skip","Package":"example.com/p","Test":"TestC","Elapsed":0}
+{"Action":"fail","Package":"example.com/p","Elapsed":0.3}
+not json: ignored
+{"ImportPath":"example.com/q [example.com/q.test]","Action":"build-output","Output":"q.go:3:1: syntax error\n"}
+{"ImportPath":"example.com/q [example.com/q.test]","Action":"build-fail"}
+{"Action":"fail","Package":"example.com/q","Elapsed":0,"FailedBuild":"example.com/q [example.com/q.test]"}
+`
+	cases, err := ParseGoTestJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := byName(cases)
+	a := m["TestA"]
+	if a.Result != Failed || a.Message != "a_test.go:9: boom" || a.File != "a_test.go" || a.Line != 9 {
+		t.Fatalf("failed test not mapped: %+v", a)
+	}
+	if m["TestB"].Result != Passed || m["TestC"].Result != Skipped || m["(build)"].Result != Errored || m["(build)"].Package != "example.com/q" || m["(package)"].Name != "" {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+	s := Summarize(cases)
+	if s.Total != 4 || s.Failed != 1 || s.Errored != 1 || s.OK() {
+		t.Fatalf("summary: %s", s)
+	}
+}
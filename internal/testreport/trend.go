@@ -0,0 +1,219 @@
+package testreport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+// TestKey identifies the same test across experiments.
+type TestKey struct {
+	Package   string `json:"package,omitempty"`
+	Classname string `json:"classname,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (k TestKey) String() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{k.Package, k.Classname, k.Name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " › ")
+}
+
+// TrendOptions tunes what counts as flaky or slower.
+type TrendOptions struct {
+	FlakyFlips int     // pass/fail flips needed to flag a test as flaky
+	SlowerPct  float64 // percent above the baseline time flagged as a regression
+	MinTime    float64 // seconds; faster baselines are too noisy to compare
+}
+
+// TestTrend is the history of one test over the analysed experiments.
+type TestTrend struct {
+	TestKey
+	Runs         int     `json:"runs"`
+	Failures     int     `json:"failures"`
+	Flips        int     `json:"flips"`
+	Latest       string  `json:"latest_status,omitempty"`
+	Previous     string  `json:"previous_status,omitempty"`
+	BaselineTime float64 `json:"baseline_time,omitempty"`
+	LatestTime   float64 `json:"latest_time,omitempty"`
+	SlowdownPct  float64 `json:"slowdown_pct,omitempty"`
+	Message      string  `json:"error_message,omitempty"`
+	File         string  `json:"file,omitempty"`
+	Line         int     `json:"line,omitempty"`
+}
+
+// TrendReport lists the tests that got worse (or better) across experiments.
+// Latest and Previous are the last two experiments of the window.
+type TrendReport struct {
+	Experiments []string    `json:"experiments"`
+	Latest      string      `json:"latest_experiment,omitempty"`
+	Previous    string      `json:"previous_experiment,omitempty"`
+	Tests       int         `json:"tests"`
+	Flaky       []TestTrend `json:"flaky"`
+	NewFailures []TestTrend `json:"new_failures"`
+	Fixed       []TestTrend `json:"fixed"`
+	Slower      []TestTrend `json:"slower"`
+}
+
+type trendRun struct {
+	status string
+	time   float64 // 0 when unknown
+	row    pgdao.Testcase
+}
+
+// AnalyzeTrends compares runs of the same tests, oldest experiment first.
+// When a test is recorded twice in one experiment the last row wins.
+func AnalyzeTrends(runs []pgdao.TestcaseRun, opts TrendOptions) TrendReport {
+	if opts.FlakyFlips <= 0 {
+		opts.FlakyFlips = 2
+	}
+	created := map[string]int64{}
+	byTest := map[TestKey]map[string]trendRun{}
+	for _, r := range runs {
+		if !r.ExperimentID.Valid {
+			continue
+		}
+		exp := r.ExperimentID.String
+		if _, ok := created[exp]; !ok {
+			created[exp] = r.ExperimentCreated.UnixNano()
+		}
+		k := KeyOf(r.Testcase)
+		if byTest[k] == nil {
+			byTest[k] = map[string]trendRun{}
+		}
+		tr := trendRun{status: strings.ToUpper(r.Status), row: r.Testcase}
+		if r.ExecutionTime.Valid {
+			tr.time = r.ExecutionTime.Float64
+		}
+		byTest[k][exp] = tr
+	}
+	rep := TrendReport{Tests: len(byTest), Flaky: []TestTrend{}, NewFailures: []TestTrend{}, Fixed: []TestTrend{}, Slower: []TestTrend{}}
+	for exp := range created {
+		rep.Experiments = append(rep.Experiments, exp)
+	}
+	sort.Slice(rep.Experiments, func(i, j int) bool {
+		a, b := rep.Experiments[i], rep.Experiments[j]
+		if created[a] != created[b] {
+			return created[a] < created[b]
+		}
+		return a < b
+	})
+	if n := len(rep.Experiments); n > 0 {
+		rep.Latest = rep.Experiments[n-1]
+		if n > 1 {
+			rep.Previous = rep.Experiments[n-2]
+		}
+	}
+	for k, perExp := range byTest {
+		t := TestTrend{TestKey: k}
+		var times []float64
+		last := ""
+		for _, exp := range rep.Experiments {
+			r, ok := perExp[exp]
+			if !ok {
+				continue
+			}
+			t.Runs++
+			if failing(r.status) {
+				t.Failures++
+			}
+			if outcome := outcomeOf(r.status); outcome != "" {
+				if last != "" && outcome != last {
+					t.Flips++
+				}
+				last = outcome
+			}
+			if exp != rep.Latest && r.time > 0 {
+				times = append(times, r.time)
+			}
+		}
+		latest, inLatest := perExp[rep.Latest]
+		if inLatest {
+			t.Latest = latest.status
+			t.LatestTime = latest.time
+			t.Message = latest.row.ErrorMessage.String
+			t.File = latest.row.File.String
+			t.Line = int(latest.row.Line.Int64)
+		}
+		if prev, ok := perExp[rep.Previous]; ok {
+			t.Previous = prev.status
+		}
+		if t.Flips >= opts.FlakyFlips {
+			rep.Flaky = append(rep.Flaky, t)
+		}
+		if !inLatest || rep.Previous == "" {
+			continue
+		}
+		switch {
+		case failing(t.Latest) && !failing(t.Previous):
+			rep.NewFailures = append(rep.NewFailures, t)
+		case t.Latest == StatusOK && failing(t.Previous):
+			rep.Fixed = append(rep.Fixed, t)
+		}
+		if base := median(times); base > 0 && base >= opts.MinTime && t.LatestTime > base*(1+opts.SlowerPct/100) {
+			s := t
+			s.BaselineTime = base
+			s.SlowdownPct = (t.LatestTime/base - 1) * 100
+			rep.Slower = append(rep.Slower, s)
+		}
+	}
+	for _, l := range [][]TestTrend{rep.Flaky, rep.NewFailures, rep.Fixed} {
+		sort.Slice(l, func(i, j int) bool { return l[i].String() < l[j].String() })
+	}
+	sort.Slice(rep.Slower, func(i, j int) bool {
+		if rep.Slower[i].SlowdownPct != rep.Slower[j].SlowdownPct {
+			return rep.Slower[i].SlowdownPct > rep.Slower[j].SlowdownPct
+		}
+		return rep.Slower[i].String() < rep.Slower[j].String()
+	})
+	return rep
+}
+
+// Summary is a one-line count of the findings.
+func (r TrendReport) Summary() string {
+	return fmt.Sprintf("experiments=%d tests=%d flaky=%d new_failures=%d fixed=%d slower=%d",
+		len(r.Experiments), r.Tests, len(r.Flaky), len(r.NewFailures), len(r.Fixed), len(r.Slower))
+}
+
+// KeyOf identifies a row by package, classname and name; rows created by hand
+// without a name fall back to their title.
+func KeyOf(t pgdao.Testcase) TestKey {
+	k := TestKey{Package: t.Package.String, Classname: t.Classname.String, Name: t.Name.String}
+	if k.Name == "" {
+		k.Name = t.Title
+	}
+	return k
+}
+
+func failing(status string) bool { return status == StatusKO || status == StatusError }
+
+// outcomeOf reduces a status to pass or fail; skipped and TODO runs do not
+// count towards flips.
+func outcomeOf(status string) string {
+	switch {
+	case status == StatusOK:
+		return "pass"
+	case failing(status):
+		return "fail"
+	}
+	return ""
+}
+
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	m := len(s) / 2
+	if len(s)%2 == 1 {
+		return s[m]
+	}
+	return (s[m-1] + s[m]) / 2
+}
@@ -0,0 +1,81 @@
+package testreport
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	pgdao "github.com/flarebyte/baldrick-rebec/internal/dao/postgres"
+)
+
+func run(exp string, at int, name, status string, secs float64) pgdao.TestcaseRun {
+	r := pgdao.TestcaseRun{ExperimentCreated: time.Unix(int64(at), 0)}
+	r.ExperimentID = sql.NullString{String: exp, Valid: true}
+	r.Package = sql.NullString{String: "app", Valid: true}
+	r.Name = sql.NullString{String: name, Valid: true}
+	r.Title = name
+	r.Status = status
+	if secs > 0 {
+		r.ExecutionTime = sql.NullFloat64{Float64: secs, Valid: true}
+	}
+	return r
+}
+
+func names(ts []TestTrend) []string {
+	out := make([]string, 0, len(ts))
+	for _, t := range ts {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestAnalyzeTrends(t *testing.T) {
+	runs := []pgdao.TestcaseRun{
+		run("e1", 1, "TestStable", "OK", 0.1),
+		run("e1", 1, "TestFlaky", "OK", 0),
+		run("e1", 1, "TestBroke", "OK", 0),
+		run("e1", 1, "TestHealed", "OK", 0),
+		run("e2", 2, "TestStable", "OK", 0.1),
+		run("e2", 2, "TestFlaky", "KO", 0),
+		run("e2", 2, "TestBroke", "OK", 0),
+		run("e2", 2, "TestHealed", "ERROR", 0),
+		// e3 is listed first but created last
+		run("e3", 3, "TestStable", "OK", 0.4),
+		run("e3", 3, "TestFlaky", "OK", 0),
+		run("e3", 3, "TestBroke", "KO", 0),
+		run("e3", 3, "TestHealed", "OK", 0),
+		run("e3", 3, "TestNew", "KO", 0),
+		run("e3", 3, "TestSkip", "SKIP", 0),
+	}
+	runs = append(runs[8:], runs[:8]...)
+	rep := AnalyzeTrends(runs, TrendOptions{FlakyFlips: 2, SlowerPct: 50, MinTime: 0.05})
+	if rep.Latest != "e3" || rep.Previous != "e2" || len(rep.Experiments) != 3 {
+		t.Fatalf("experiments = %v latest=%s previous=%s", rep.Experiments, rep.Latest, rep.Previous)
+	}
+	check := func(what string, got []TestTrend, want ...string) {
+		t.Helper()
+		g := names(got)
+		if len(g) != len(want) {
+			t.Fatalf("%s = %v, want %v", what, g, want)
+		}
+		for i := range want {
+			if g[i] != want[i] {
+				t.Fatalf("%s = %v, want %v", what, g, want)
+			}
+		}
+	}
+	check("flaky", rep.Flaky, "TestFlaky", "TestHealed")
+	check("new failures", rep.NewFailures, "TestBroke", "TestNew")
+	check("fixed", rep.Fixed, "TestFlaky", "TestHealed")
+	check("slower", rep.Slower, "TestStable")
+	if s := rep.Slower[0]; s.BaselineTime != 0.1 || s.SlowdownPct < 299 || s.SlowdownPct > 301 {
+		t.Fatalf("slower = %+v", s)
+	}
+}
+
+func TestAnalyzeTrendsSingleExperiment(t *testing.T) {
+	rep := AnalyzeTrends([]pgdao.TestcaseRun{run("e1", 1, "TestA", "KO", 1)}, TrendOptions{})
+	if rep.Previous != "" || len(rep.NewFailures) != 0 || len(rep.Slower) != 0 {
+		t.Fatalf("single experiment should have nothing to compare: %+v", rep)
+	}
+}
@@ -0,0 +1,124 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown writes t as a readable transcript: one section per
+// experiment, one entry per message with its role, status and time. Task run
+// output and JSON-only content are fenced; other text is kept as written.
+func WriteMarkdown(w io.Writer, t *Transcript) error {
+	bw := bufio.NewWriter(w)
+	p := func(format string, args ...any) { fmt.Fprintf(bw, format, args...) }
+	c := t.Conversation
+	p("# %s\n\n", oneLine(c.Title))
+	p("- **conversation**: `%s`\n", c.ID)
+	if c.Project != "" {
+		p("- **project**: %s\n", oneLine(c.Project))
+	}
+	p("- **role**: %s\n", c.Role)
+	if c.Created != nil {
+		p("- **created**: %s\n", c.Created.Format(time.RFC3339))
+	}
+	exps, msgs := t.Counts()
+	p("- **experiments**: %d · **messages**: %d\n\n", exps, msgs)
+	if c.Description != "" {
+		p("%s\n\n", strings.TrimSpace(c.Description))
+	}
+	for _, e := range t.Experiments {
+		title := e.Name
+		if title == "" {
+			title = e.ID
+		}
+		p("## Experiment %s — %s\n\n", oneLine(title), e.Status)
+		p("- **id**: `%s`\n", e.ID)
+		if e.Started != nil {
+			p("- **started**: %s", e.Started.Format(time.RFC3339))
+			if e.Finished != nil {
+				p(" · **duration**: %s", e.Finished.Sub(*e.Started).Round(time.Millisecond))
+			}
+			p("\n")
+		}
+		if len(e.Params) > 0 {
+			b, _ := json.Marshal(e.Params)
+			p("- **params**: `%s`\n", b)
+		}
+		if len(e.Metrics) > 0 {
+			p("- **metrics**: %s\n", metricsLine(e.Metrics))
+		}
+		p("\n")
+		for _, m := range e.Messages {
+			p("### %s · %s · %s", m.Created.Format("2006-01-02 15:04:05"), m.Role, m.Status)
+			if len(m.ToRole) > 0 {
+				p(" → %s", strings.Join(m.ToRole, ", "))
+			}
+			if m.Task != nil && m.Task.Variant != "" {
+				p(" · task `%s`", m.Task.Variant)
+			}
+			p("\n\n")
+			if m.ReplyTo != "" {
+				p("_in reply to `%s`_\n\n", m.ReplyTo)
+			}
+			if m.Error != "" {
+				p("> **error**: %s\n\n", oneLine(m.Error))
+			}
+			text := strings.TrimRight(m.Text, "\n")
+			switch {
+			case m.Task != nil || isTaskRun(m.Tags):
+				f := fence(text)
+				p("%s\n%s\n%s\n\n", f, text, f)
+			case strings.TrimSpace(text) != "":
+				p("%s\n\n", text)
+			case len(m.JSON) > 0:
+				f := fence(string(m.JSON))
+				p("%sjson\n%s\n%s\n\n", f, m.JSON, f)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func isTaskRun(tags map[string]any) bool {
+	v, _ := tags["task"].(bool)
+	return v
+}
+
+func metricsLine(m map[string]float64) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strconv.FormatFloat(m[k], 'g', 6, 64))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// fence returns a backtick fence longer than any backtick run in s.
+func fence(s string) string {
+	n, run := 3, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			if run >= n {
+				n = run + 1
+			}
+			continue
+		}
+		run = 0
+	}
+	return strings.Repeat("`", n)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
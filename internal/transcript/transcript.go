@@ -0,0 +1,205 @@
+// Package transcript renders a conversation, its experiments and their
+// messages as a readable or portable transcript, and reads the portable
+// JSONL form back for import.
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format identifies the layout of exported transcripts.
+//
+// The JSON form is one Transcript document. The JSONL form is one record per
+// line, in this order: a header, the conversation, then each experiment
+// followed by its messages oldest first.
+const Format = "rbc-transcript/1"
+
+// Transcript is a conversation with its experiments and their messages.
+type Transcript struct {
+	Format       string       `json:"format"`
+	ExportedAt   time.Time    `json:"exported_at"`
+	Conversation Conversation `json:"conversation"`
+	Experiments  []Experiment `json:"experiments"`
+}
+
+// Conversation is the exported conversation row.
+type Conversation struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Project     string         `json:"project,omitempty"`
+	Role        string         `json:"role"`
+	Tags        map[string]any `json:"tags,omitempty"`
+	Notes       string         `json:"notes,omitempty"`
+	Created     *time.Time     `json:"created,omitempty"`
+}
+
+// Experiment is one experiment of the conversation and its messages.
+type Experiment struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name,omitempty"`
+	Status   string             `json:"status"`
+	Params   map[string]any     `json:"params,omitempty"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+	Started  *time.Time         `json:"started,omitempty"`
+	Finished *time.Time         `json:"finished,omitempty"`
+	Messages []Message          `json:"messages,omitempty"`
+}
+
+// Message is one message with its content.
+type Message struct {
+	ID           string          `json:"id"`
+	ExperimentID string          `json:"experiment_id"`
+	Role         string          `json:"role"`
+	ReplyTo      string          `json:"reply_to,omitempty"`
+	ToRole       []string        `json:"to_role,omitempty"`
+	Status       string          `json:"status"`
+	Error        string          `json:"error,omitempty"`
+	Tags         map[string]any  `json:"tags,omitempty"`
+	Created      time.Time       `json:"created"`
+	Task         *TaskRef        `json:"task,omitempty"`
+	Text         string          `json:"text"`
+	JSON         json.RawMessage `json:"json,omitempty"`
+}
+
+// TaskRef names the task a message was produced by. On import the task is
+// looked up again by variant since ids differ between environments.
+type TaskRef struct {
+	ID      string `json:"id,omitempty"`
+	Variant string `json:"variant,omitempty"`
+}
+
+// Counts returns the number of experiments and messages.
+func (t *Transcript) Counts() (experiments, messages int) {
+	for _, e := range t.Experiments {
+		messages += len(e.Messages)
+	}
+	return len(t.Experiments), messages
+}
+
+// record is one line of the JSONL form.
+type record struct {
+	Kind         string        `json:"kind"`
+	Format       string        `json:"format,omitempty"`
+	ExportedAt   *time.Time    `json:"exported_at,omitempty"`
+	Conversation *Conversation `json:"conversation,omitempty"`
+	Experiment   *Experiment   `json:"experiment,omitempty"`
+	Message      *Message      `json:"message,omitempty"`
+}
+
+// WriteJSON writes t as one indented JSON document.
+func WriteJSON(w io.Writer, t *Transcript) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteJSONL writes t one record per line.
+func WriteJSONL(w io.Writer, t *Transcript) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(record{Kind: "transcript", Format: t.Format, ExportedAt: &t.ExportedAt}); err != nil {
+		return err
+	}
+	c := t.Conversation
+	if err := enc.Encode(record{Kind: "conversation", Conversation: &c}); err != nil {
+		return err
+	}
+	for _, e := range t.Experiments {
+		msgs := e.Messages
+		e.Messages = nil
+		if err := enc.Encode(record{Kind: "experiment", Experiment: &e}); err != nil {
+			return err
+		}
+		for i := range msgs {
+			if err := enc.Encode(record{Kind: "message", Message: &msgs[i]}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadJSONL reads a transcript written by WriteJSONL. Messages are attached
+// to their experiment by experiment_id, whatever the line order.
+func ReadJSONL(r io.Reader) (*Transcript, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	var t Transcript
+	var haveHeader, haveConv bool
+	byID := map[string]int{}
+	var pending []Message
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		switch rec.Kind {
+		case "transcript":
+			if rec.Format != Format {
+				return nil, fmt.Errorf("line %d: unsupported format %q (want %s)", n, rec.Format, Format)
+			}
+			t.Format = rec.Format
+			if rec.ExportedAt != nil {
+				t.ExportedAt = *rec.ExportedAt
+			}
+			haveHeader = true
+		case "conversation":
+			if rec.Conversation == nil {
+				return nil, fmt.Errorf("line %d: conversation record without conversation", n)
+			}
+			if haveConv {
+				return nil, fmt.Errorf("line %d: more than one conversation", n)
+			}
+			t.Conversation = *rec.Conversation
+			haveConv = true
+		case "experiment":
+			if rec.Experiment == nil || rec.Experiment.ID == "" {
+				return nil, fmt.Errorf("line %d: experiment record without id", n)
+			}
+			if _, dup := byID[rec.Experiment.ID]; dup {
+				return nil, fmt.Errorf("line %d: duplicate experiment %s", n, rec.Experiment.ID)
+			}
+			byID[rec.Experiment.ID] = len(t.Experiments)
+			t.Experiments = append(t.Experiments, *rec.Experiment)
+		case "message":
+			if rec.Message == nil {
+				return nil, fmt.Errorf("line %d: message record without message", n)
+			}
+			pending = append(pending, *rec.Message)
+		default:
+			return nil, fmt.Errorf("line %d: unknown record kind %q", n, rec.Kind)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !haveHeader {
+		return nil, errors.New("missing transcript header line")
+	}
+	if !haveConv {
+		return nil, errors.New("missing conversation record")
+	}
+	for _, m := range pending {
+		i, ok := byID[m.ExperimentID]
+		if !ok {
+			return nil, fmt.Errorf("message %s references unknown experiment %q", m.ID, m.ExperimentID)
+		}
+		t.Experiments[i].Messages = append(t.Experiments[i].Messages, m)
+	}
+	for i := range t.Experiments {
+		msgs := t.Experiments[i].Messages
+		sort.SliceStable(msgs, func(a, b int) bool { return msgs[a].Created.Before(msgs[b].Created) })
+	}
+	return &t, nil
+}
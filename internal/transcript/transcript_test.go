@@ -0,0 +1,93 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sample() *Transcript {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(90 * time.Second)
+	return &Transcript{
+		Format:     Format,
+		ExportedAt: t1,
+		Conversation: Conversation{
+			ID: "c1", Title: "Fix flaky login", Role: "dev", Project: "web",
+			Tags: map[string]any{"ticket": "WEB-12"}, Created: &t0,
+		},
+		Experiments: []Experiment{{
+			ID: "e1", Name: "prompt-v2", Status: "failed", Params: map[string]any{"model": "m"},
+			Metrics: map[string]float64{"task_runs": 1}, Started: &t0, Finished: &t1,
+			Messages: []Message{
+				{ID: "m1", ExperimentID: "e1", Role: "dev", Status: "ingested", Created: t0, Text: "Why does ```login``` fail?"},
+				{ID: "m2", ExperimentID: "e1", Role: "dev", ReplyTo: "m1", ToRole: []string{"qa", "dev"}, Status: "failed", Error: "exit status 1", Created: t1,
+					Task: &TaskRef{ID: "t1", Variant: "unit/go"}, Tags: map[string]any{"task": true, "run": true},
+					Text: "--- FAIL: TestLogin", JSON: json.RawMessage(`{"exit_code":1}`)},
+			},
+		}},
+	}
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	want := sample()
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 5 {
+		t.Fatalf("lines = %d, want 5:\n%s", n, buf.String())
+	}
+	got, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadJSONLErrors(t *testing.T) {
+	head := `{"kind":"transcript","format":"` + Format + `"}` + "\n"
+	conv := `{"kind":"conversation","conversation":{"id":"c1","title":"x","role":"dev"}}` + "\n"
+	cases := map[string]string{
+		"no header":         conv,
+		"bad format":        `{"kind":"transcript","format":"other/9"}` + "\n" + conv,
+		"no conversation":   head,
+		"unknown kind":      head + conv + `{"kind":"stickie"}` + "\n",
+		"orphan message":    head + conv + `{"kind":"message","message":{"id":"m1","experiment_id":"nope"}}` + "\n",
+		"duplicate exp":     head + conv + `{"kind":"experiment","experiment":{"id":"e1"}}` + "\n" + `{"kind":"experiment","experiment":{"id":"e1"}}` + "\n",
+		"invalid json line": head + "{",
+	}
+	for name, in := range cases {
+		if _, err := ReadJSONL(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, sample()); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{
+		"# Fix flaky login\n",
+		"- **experiments**: 1 · **messages**: 2\n",
+		"## Experiment prompt-v2 — failed\n",
+		"· **duration**: 1m30s",
+		"### 2026-03-01 10:01:30 · dev · failed → qa, dev · task `unit/go`\n",
+		"_in reply to `m1`_\n",
+		"> **error**: exit status 1\n",
+		"```\n--- FAIL: TestLogin\n```\n",
+		"Why does ```login``` fail?\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/flarebyte/baldrick-rebec/cmd"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
+		// errors carrying their own exit status (e.g. sync conflicts) keep it
+		var coded interface{ ExitCode() int }
+		if errors.As(err, &coded) {
+			log.Print(err)
+			os.Exit(coded.ExitCode())
+		}
 		log.Fatal(err)
 	}
 }